@@ -81,12 +81,50 @@ var (
 	RatingNotFound  = &AppErrorType{http.StatusNotFound, "rating-not-found"}
 	InvalidRatingId = &AppErrorType{http.StatusBadRequest, "invalid-rating-id"}
 
-	InvalidAgreementId = &AppErrorType{http.StatusBadRequest, "invalid-agreement-id"}
-	AgreementNotFound  = &AppErrorType{http.StatusNotFound, "agreement-not-found"}
-	DuplicateAgreement = &AppErrorType{http.StatusBadRequest, "duplicate-agreement"}
+	InvalidAgreementId   = &AppErrorType{http.StatusBadRequest, "invalid-agreement-id"}
+	AgreementNotFound    = &AppErrorType{http.StatusNotFound, "agreement-not-found"}
+	PaymentNotFound      = &AppErrorType{http.StatusNotFound, "payment-not-found"}
+	NotAgreementDweller  = &AppErrorType{http.StatusForbidden, "not-agreement-dweller"}
+	AgreementNotPayable  = &AppErrorType{http.StatusBadRequest, "agreement-not-payable"}
+	NotRentingAgreement  = &AppErrorType{http.StatusBadRequest, "not-renting-agreement"}
+	NotAgreementOwner    = &AppErrorType{http.StatusForbidden, "not-agreement-owner"}
+	PaymentNotRefundable = &AppErrorType{http.StatusBadRequest, "payment-not-refundable"}
+	PaymentNotPaid       = &AppErrorType{http.StatusBadRequest, "payment-not-paid"}
+	PaymentInProgress    = &AppErrorType{http.StatusConflict, "payment-in-progress"}
+	AlreadyPaid          = &AppErrorType{http.StatusConflict, "already-paid"}
+	IdempotencyKeyReused = &AppErrorType{http.StatusConflict, "idempotency-key-reused"}
+	PayoutNotFound       = &AppErrorType{http.StatusNotFound, "payout-not-found"}
+	PayoutNotPending     = &AppErrorType{http.StatusBadRequest, "payout-not-pending"}
+	InvalidSignature     = &AppErrorType{http.StatusBadRequest, "invalid-signature"}
+	DuplicateAgreement   = &AppErrorType{http.StatusBadRequest, "duplicate-agreement"}
 
 	WebSocketDuplicatedConnection = &AppErrorType{http.StatusBadRequest, "websocket-duplicated-connection"}
 	NotInChat                     = &AppErrorType{http.StatusBadRequest, "not-in-chat"}
+	ChatNotFound                  = &AppErrorType{http.StatusNotFound, "chat-not-found"}
+	NotGroupChat                  = &AppErrorType{http.StatusBadRequest, "not-group-chat"}
+	NotChatCreator                = &AppErrorType{http.StatusForbidden, "not-chat-creator"}
+	ChatMembersLimitExceeded      = &AppErrorType{http.StatusBadRequest, "chat-members-limit-exceeded"}
+	MessageNotFound               = &AppErrorType{http.StatusNotFound, "message-not-found"}
+	NotMessageAuthor              = &AppErrorType{http.StatusForbidden, "not-message-author"}
+	MessageBlocked                = &AppErrorType{http.StatusUnprocessableEntity, "message-blocked"}
+	MessageActionNotFound         = &AppErrorType{http.StatusNotFound, "message-action-not-found"}
+	MessageActionResolved         = &AppErrorType{http.StatusConflict, "message-action-resolved"}
+	NotMessageActionActor         = &AppErrorType{http.StatusForbidden, "not-message-action-actor"}
+	InvalidMessageAction          = &AppErrorType{http.StatusBadRequest, "invalid-message-action"}
+	MessageEditExpired            = &AppErrorType{http.StatusBadRequest, "message-edit-expired"}
+	MessageDeleted                = &AppErrorType{http.StatusBadRequest, "message-deleted"}
+	InvalidReaction               = &AppErrorType{http.StatusBadRequest, "invalid-reaction"}
+	InvalidMessageFile            = &AppErrorType{http.StatusBadRequest, "invalid-message-file"}
+	MessageFileTooLarge           = &AppErrorType{http.StatusRequestEntityTooLarge, "message-file-too-large"}
+	MessageFileNotFound           = &AppErrorType{http.StatusNotFound, "message-file-not-found"}
+	UnsupportedProtocol           = &AppErrorType{http.StatusBadRequest, "unsupported-protocol"}
+	UnknownEvent                  = &AppErrorType{http.StatusBadRequest, "unknown-event"}
+	InvalidPayload                = &AppErrorType{http.StatusBadRequest, "invalid-payload"}
+	UserBlocked                   = &AppErrorType{http.StatusForbidden, "user-blocked"}
+	InvalidTicket                 = &AppErrorType{http.StatusUnauthorized, "invalid-ticket"}
+	InvalidOrigin                 = &AppErrorType{http.StatusForbidden, "invalid-origin"}
+	SessionExpired                = &AppErrorType{http.StatusUnauthorized, "session-expired"}
+	SessionRevoked                = &AppErrorType{http.StatusUnauthorized, "session-revoked"}
 
 	InvalidCallbackRequest = &AppErrorType{http.StatusBadRequest, "invalid-callback-request"}
 
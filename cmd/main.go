@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/brain-flowing-company/pprp-backend/config"
 	"github.com/brain-flowing-company/pprp-backend/database"
@@ -11,13 +12,17 @@ import (
 	"github.com/brain-flowing-company/pprp-backend/internal/core/auth"
 	"github.com/brain-flowing-company/pprp-backend/internal/core/chats"
 	"github.com/brain-flowing-company/pprp-backend/internal/core/emails"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/events"
 	"github.com/brain-flowing-company/pprp-backend/internal/core/google"
 	"github.com/brain-flowing-company/pprp-backend/internal/core/greetings"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/ledger"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/notifications"
 	"github.com/brain-flowing-company/pprp-backend/internal/core/payments"
 	"github.com/brain-flowing-company/pprp-backend/internal/core/properties"
 	"github.com/brain-flowing-company/pprp-backend/internal/core/ratings"
 	"github.com/brain-flowing-company/pprp-backend/internal/core/users"
 	"github.com/brain-flowing-company/pprp-backend/internal/middleware"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
 	"github.com/brain-flowing-company/pprp-backend/storage"
 	"github.com/gofiber/contrib/fiberzap"
 	"github.com/gofiber/contrib/websocket"
@@ -57,7 +62,11 @@ func main() {
 		panic(fmt.Sprintf("Could not establish connection with AWS S3 with err: %v", err.Error()))
 	}
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		// chat messages can carry up to 5 files of 10 MB each, every other
+		// route keeps the default limit through mw.WithBodyLimit
+		BodyLimit: 64 * 1024 * 1024,
+	})
 
 	var logger *zap.Logger
 	if cfg.IsDevelopment() {
@@ -76,17 +85,23 @@ func main() {
 	}))
 
 	if cfg.IsDevelopment() {
+		app.Get("/docs/websocket.json", func(c *fiber.Ctx) error {
+			return c.SendFile("./docs/websocket.json")
+		})
 		app.Get("/docs/*", swagger.HandlerDefault)
 
 		// test websocket
 		app.Static("/", "./internal/core/chats/public/")
 	}
 
+	// live updates for connected users, delivered over the chat websocket
+	eventStream := events.NewStream()
+
 	hwService := greetings.NewService()
 	hwHandler := greetings.NewHandler(hwService)
 
 	propertyRepo := properties.NewRepository(db)
-	propertyService := properties.NewService(logger, propertyRepo, storage)
+	propertyService := properties.NewService(logger, propertyRepo, storage, eventStream)
 	propertyHandler := properties.NewHandler(propertyService)
 
 	usersRepo := users.NewRepository(db)
@@ -106,21 +121,40 @@ func main() {
 	authService := auth.NewService(logger, cfg, authRepository, googleService, emailService)
 	authHandler := auth.NewHandler(cfg, authService)
 
+	notificationRepository := notifications.NewRepository(db)
+	notificationService := notifications.NewService(logger, cfg, notificationRepository, emailService)
+	notificationHandler := notifications.NewHandler(cfg, notificationService)
+	go notificationService.RunDigestWorker()
+
+	appointmentRepository := appointments.NewRepository(db)
+	appointmentService := appointments.NewService(logger, appointmentRepository, eventStream)
+
+	agreementsRepo := agreements.NewRepository(db)
+	agreementsService := agreements.NewService(logger, agreementsRepo, eventStream)
+
 	chatRepository := chats.NewRepository(db)
-	chatService := chats.NewService(logger, chatRepository)
-	hub := chats.NewHub(chatService)
+	chatModerator := chats.NewModerator(cfg)
+	chatService := chats.NewService(logger, cfg, chatRepository, storage, chatModerator, appointmentService, agreementsService)
+	hub := chats.NewHub(chatService, notificationService, eventStream)
+	go hub.WatchSessions()
 	chatHandler := chats.NewHandler(logger, cfg, hub, chatService)
 
-	appointmentRepository := appointments.NewRepository(db)
-	appointmentService := appointments.NewService(logger, appointmentRepository)
 	appointmentHandler := appointments.NewHandler(hub, appointmentService)
 
-	agreementsRepo := agreements.NewRepository(db)
-	agreementsService := agreements.NewService(logger, agreementsRepo)
 	agreementsHandler := agreements.NewHandler(hub, agreementsService)
 
+	ledgerRepository := ledger.NewRepository(db)
+	ledgerService := ledger.NewService(logger, cfg, ledgerRepository)
+	ledgerHandler := ledger.NewHandler(ledgerService)
+	go ledgerService.RunPayoutWorker()
+
 	paymentsRepository := payments.NewRepository(db)
-	paymentsService := payments.NewService(logger, paymentsRepository, cfg)
+	paymentProvider := payments.NewProvider(cfg)
+	paymentsService := payments.NewService(logger, paymentsRepository, cfg, eventStream, agreementsService, hub, paymentProvider, emailService, ledgerService, storage)
+	if fake, ok := paymentProvider.(*payments.FakeProvider); ok {
+		fake.SetWebhook(paymentsService.HandleWebhook)
+	}
+	go paymentsService.RunBillingWorker()
 	paymentsHandler := payments.NewHandler(cfg, paymentsService)
 
 	ratingsRepository := ratings.NewRepository(db)
@@ -129,11 +163,17 @@ func main() {
 
 	mw := middleware.NewMiddleware(cfg)
 
+	app.Use(mw.WithBodyLimit(fiber.DefaultBodyLimit, regexp.MustCompile(`^/api/v1/chats/[^/]+/files$`)))
+
 	apiv1 := app.Group("/api/v1", mw.SessionMiddleware)
 
-	apiv1.Get("/checkout", mw.WithAuthentication(paymentsHandler.CreatePayment))
+	apiv1.Post("/checkout", mw.WithAuthentication(paymentsHandler.CreatePayment))
 	apiv1.Get("/payments", mw.WithAuthentication(paymentsHandler.GetPaymentByUserId))
 	apiv1.Get("/payments/history", mw.WithAuthentication(paymentsHandler.GetHistoryPaymentByUserId))
+	apiv1.Post("/payments/webhook", paymentsHandler.HandleWebhook)
+	apiv1.Get("/payments/:paymentId", mw.WithAuthentication(paymentsHandler.GetPaymentById))
+	apiv1.Get("/payments/:paymentId/receipt", mw.WithAuthentication(paymentsHandler.GetReceipt))
+	apiv1.Post("/payments/:paymentId/refunds", mw.WithOwnerAccess(paymentsHandler.CreateRefund))
 
 	apiv1.Get("/greeting", hwHandler.Greeting)
 	apiv1.Get("/user/greeting", mw.WithAuthentication(hwHandler.UserGreeting))
@@ -159,6 +199,7 @@ func main() {
 	apiv1.Get("/users", usersHandler.GetAllUsers)
 	apiv1.Get("/user/me/personal-information", mw.WithAuthentication(usersHandler.GetCurrentUser))
 	apiv1.Get("/user/me/financial-information", mw.WithAuthentication(usersHandler.GetUserFinancialInformation))
+	apiv1.Get("/user/me/earnings", mw.WithOwnerAccess(ledgerHandler.GetEarnings))
 	apiv1.Get("/user/me/registered", usersHandler.GetRegisteredType)
 	apiv1.Get("/user/:userId", usersHandler.GetUserById)
 	apiv1.Put("/user/me/personal-information", mw.WithAuthentication(usersHandler.UpdateUser))
@@ -172,6 +213,7 @@ func main() {
 
 	apiv1.Get("/agreements", mw.WithAuthentication(agreementsHandler.GetAllAgreements))
 	apiv1.Get("/agreements/:agreementId", mw.WithAuthentication(agreementsHandler.GetAgreementById))
+	apiv1.Get("/agreements/:agreementId/schedule", mw.WithAuthentication(paymentsHandler.GetRentSchedule))
 	apiv1.Get("/user/me/agreements", mw.WithAuthentication(agreementsHandler.GetMyAgreements))
 	apiv1.Post("/agreements", mw.WithOwnerAccess(agreementsHandler.CreateAgreement))
 	apiv1.Delete("/agreements/:agreementId", mw.WithOwnerAccess(agreementsHandler.DeleteAgreement))
@@ -182,7 +224,30 @@ func main() {
 	apiv1.Get("/auth/callback", authHandler.Callback)
 
 	apiv1.Get("/chats", mw.WithAuthentication(chatHandler.GetAllChats))
-	apiv1.Get("/chats/:recvUserId", mw.WithAuthentication(chatHandler.GetMessagesInChat))
+	apiv1.Post("/chats", mw.WithAuthentication(chatHandler.CreateChat))
+	apiv1.Post("/chats/groups", mw.WithAuthentication(chatHandler.CreateGroupChat))
+	apiv1.Get("/chats/unread", mw.WithAuthentication(chatHandler.GetUnreadSummary))
+	apiv1.Post("/chats/tickets", mw.WithAuthentication(chatHandler.IssueWebsocketTicket))
+	apiv1.Get("/chats/search", mw.WithAuthentication(chatHandler.SearchMessages))
+	apiv1.Get("/chats/files/:fileId", mw.WithAuthentication(chatHandler.GetMessageFile))
+	apiv1.Get("/chats/:chatId", mw.WithAuthentication(chatHandler.GetMessagesInChat))
+	apiv1.Get("/chats/:chatId/export", mw.WithAuthentication(chatHandler.ExportChat))
+	apiv1.Post("/chats/:chatId/files", mw.WithAuthentication(chatHandler.SendFiles))
+	apiv1.Post("/chats/:chatId/members", mw.WithAuthentication(chatHandler.AddMembers))
+	apiv1.Delete("/chats/:chatId/members/:userId", mw.WithAuthentication(chatHandler.RemoveMember))
+	apiv1.Post("/chats/:chatId/leave", mw.WithAuthentication(chatHandler.LeaveChat))
+	apiv1.Post("/chats/:chatId/mute", mw.WithAuthentication(chatHandler.MuteChat))
+	apiv1.Delete("/chats/:chatId/mute", mw.WithAuthentication(chatHandler.UnmuteChat))
+	apiv1.Post("/chats/:chatId/report", mw.WithAuthentication(chatHandler.ReportChat))
+	apiv1.Get("/user/me/blocks", mw.WithAuthentication(chatHandler.GetBlockedUsers))
+	apiv1.Post("/user/:userId/block", mw.WithAuthentication(chatHandler.BlockUser))
+	apiv1.Delete("/user/:userId/block", mw.WithAuthentication(chatHandler.UnblockUser))
+
+	apiv1.Get("/notifications/vapid-key", notificationHandler.GetVapidKey)
+	apiv1.Post("/notifications/push-subscriptions", mw.WithAuthentication(notificationHandler.SubscribePush))
+	apiv1.Delete("/notifications/push-subscriptions", mw.WithAuthentication(notificationHandler.UnsubscribePush))
+	apiv1.Get("/notifications/preferences", mw.WithAuthentication(notificationHandler.GetPreferences))
+	apiv1.Put("/notifications/preferences", mw.WithAuthentication(notificationHandler.UpdatePreferences))
 
 	apiv1.Post("/ratings", mw.WithAuthentication(ratingsHandler.CreateRating))
 	apiv1.Get("/ratings/:propertyId", mw.WithAuthentication(ratingsHandler.GetRatingByPropertyId))
@@ -193,7 +258,9 @@ func main() {
 	apiv1.Delete("/ratings/:ratingId", mw.WithAuthentication(ratingsHandler.DeleteRating))
 
 	ws := app.Group("/ws")
-	ws.Get("/chats", websocket.New(chatHandler.OpenConnection))
+	ws.Get("/chats", websocket.New(chatHandler.OpenConnection, websocket.Config{
+		Subprotocols: models.ChatProtocols,
+	}))
 
 	err = app.Listen(fmt.Sprintf(":%v", cfg.AppPort))
 	if err != nil {
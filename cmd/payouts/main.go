@@ -0,0 +1,92 @@
+// Command payouts lists and settles owner payouts. Bank transfers are made by
+// hand, so once one has gone through, or bounced, it is recorded here. Run it
+// through `make payouts`.
+//
+//	payouts                          list pending payouts
+//	payouts -create                  batch owner balances into payouts now
+//	payouts -paid <id> -ref <ref>    mark a payout as transferred
+//	payouts -failed <id> -reason <>  mark a payout as failed, returning its
+//	                                 amount to the owner's balance
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/database"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/ledger"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	create := flag.Bool("create", false, "create payouts for every owner balance due")
+	paid := flag.String("paid", "", "id of a payout that was transferred")
+	reference := flag.String("ref", "", "bank transfer reference, with -paid")
+	failed := flag.String("failed", "", "id of a payout that could not be transferred")
+	reason := flag.String("reason", "", "why the transfer failed, with -failed")
+	flag.Parse()
+
+	cfg := &config.Config{}
+	err := config.Load(cfg)
+	if err != nil {
+		panic(fmt.Sprintf("Could not load config with error: %v", err.Error()))
+	}
+
+	db, err := database.New(cfg)
+	if err != nil {
+		panic(fmt.Sprintf("Could not establish connection with database with err: %v", err.Error()))
+	}
+
+	logger := zap.Must(zap.NewDevelopment())
+	service := ledger.NewService(logger, cfg, ledger.NewRepository(db))
+
+	payout := models.Payouts{}
+	var apperr *apperror.AppError
+	switch {
+	case *create:
+		service.CreatePayouts()
+	case *paid != "":
+		apperr = service.CompletePayout(&payout, parseId(*paid), *reference)
+	case *failed != "":
+		apperr = service.FailPayout(&payout, parseId(*failed), *reason)
+	}
+	if apperr != nil {
+		fail(apperr)
+	}
+
+	payouts := []models.Payouts{}
+	apperr = service.GetPendingPayouts(&payouts)
+	if apperr != nil {
+		fail(apperr)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PAYOUT\tOWNER\tAMOUNT\tBANK\tACCOUNT\tCREATED")
+	for _, p := range payouts {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", p.PayoutId, p.UserId, p.Amount, p.BankName, p.BankAccountNumber, p.CreatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	w.Flush()
+}
+
+func fail(apperr *apperror.AppError) {
+	fmt.Fprintln(os.Stderr, apperr.Error())
+	os.Exit(1)
+}
+
+func parseId(id string) uuid.UUID {
+	payoutId, err := uuid.Parse(id)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid payout id: %v", id))
+	}
+
+	return payoutId
+}
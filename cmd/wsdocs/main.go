@@ -0,0 +1,235 @@
+// Command wsdocs writes the AsyncAPI spec of the chat websocket protocol next
+// to the swagger docs. Run it through `make docs`.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
+	"github.com/google/uuid"
+)
+
+type object = map[string]interface{}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	uuidType   = reflect.TypeOf(uuid.UUID{})
+	rawType    = reflect.TypeOf(json.RawMessage{})
+	amountType = reflect.TypeOf(money.Amount(0))
+)
+
+func main() {
+	output := flag.String("o", "./docs/websocket.json", "output file")
+	flag.Parse()
+
+	spec := generate()
+
+	data, err := json.MarshalIndent(spec, "", "    ")
+	if err != nil {
+		panic(fmt.Sprintf("Could not encode spec: %v", err))
+	}
+
+	err = os.WriteFile(*output, append(data, '\n'), 0644)
+	if err != nil {
+		panic(fmt.Sprintf("Could not write spec: %v", err))
+	}
+}
+
+func generate() object {
+	schemas := object{}
+	messages := object{}
+
+	inbound := []interface{}{}
+	for _, event := range sortedKeys(models.InBoundPayloadTypes) {
+		name := "inbound." + string(event)
+		payload := reflect.TypeOf(models.InBoundPayloadTypes[event]())
+
+		messages[name] = message(string(event), schemaOf(schemas, payload), object{
+			"v":   object{"type": "integer", "const": models.ChatProtocolVersion},
+			"tag": object{"type": "string", "maxLength": 64, "description": "Echoed back on the response, including ERROR frames"},
+		}, []string{"v", "event"})
+		inbound = append(inbound, object{"$ref": "#/components/messages/" + name})
+	}
+
+	outbound := []interface{}{}
+	for _, event := range sortedKeys(models.OutBoundPayloadTypes) {
+		name := "outbound." + string(event)
+
+		payload := object{"type": "null"}
+		if p := models.OutBoundPayloadTypes[event]; p != nil {
+			payload = schemaOf(schemas, reflect.TypeOf(p))
+		}
+
+		messages[name] = message(string(event), payload, object{
+			"v":   object{"type": "integer", "const": models.ChatProtocolVersion},
+			"tag": object{"type": "string", "description": "Tag of the inbound frame this frame answers"},
+		}, []string{"v", "event", "payload"})
+		outbound = append(outbound, object{"$ref": "#/components/messages/" + name})
+	}
+
+	return object{
+		"asyncapi": "2.6.0",
+		"info": object{
+			"title":       "Bangkok Property Matchmaking Platform chat",
+			"version":     strconv.Itoa(models.ChatProtocolVersion),
+			"description": "Chat websocket protocol. Connect with the " + models.ChatProtocolV2 + " subprotocol; connections without a subprotocol use the deprecated " + models.ChatProtocolV1 + " flat frames.",
+		},
+		"defaultContentType": "application/json",
+		"channels": object{
+			"/ws/chats": object{
+				"bindings": object{
+					"ws": object{
+						"method": "GET",
+						"headers": object{
+							"type": "object",
+							"properties": object{
+								"Sec-WebSocket-Protocol": object{"type": "string", "enum": models.ChatProtocols},
+							},
+						},
+					},
+				},
+				"publish":   object{"message": object{"oneOf": inbound}},
+				"subscribe": object{"message": object{"oneOf": outbound}},
+			},
+		},
+		"components": object{
+			"messages": messages,
+			"schemas":  schemas,
+		},
+	}
+}
+
+func message(event string, payload object, properties object, required []string) object {
+	properties["event"] = object{"type": "string", "const": event}
+	properties["payload"] = payload
+
+	return object{
+		"name": event,
+		"payload": object{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		},
+	}
+}
+
+// schemaOf returns the json schema of t. Structs are added to schemas and
+// referenced by name.
+func schemaOf(schemas object, t reflect.Type) object {
+	switch t {
+	case timeType:
+		return object{"type": "string", "format": "date-time"}
+	case uuidType:
+		return object{"type": "string", "format": "uuid"}
+	case rawType:
+		return object{}
+	case amountType:
+		// amounts are sent as decimal numbers of baht
+		return object{"type": "number"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(schemas, t.Elem())
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return object{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return object{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return object{"type": "array", "items": schemaOf(schemas, t.Elem())}
+	case reflect.Map:
+		return object{"type": "object", "additionalProperties": schemaOf(schemas, t.Elem())}
+	case reflect.Struct:
+		if _, ok := schemas[t.Name()]; !ok {
+			// placeholder so that recursive types terminate
+			schemas[t.Name()] = object{}
+			properties, required := object{}, []string{}
+			fieldsOf(schemas, t, properties, &required)
+
+			schema := object{"type": "object", "properties": properties}
+			if len(required) > 0 {
+				schema["required"] = required
+			}
+			schemas[t.Name()] = schema
+		}
+
+		return object{"$ref": "#/components/schemas/" + t.Name()}
+	}
+
+	return object{}
+}
+
+func fieldsOf(schemas object, t reflect.Type, properties object, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// embedded structs without a json name are flattened by encoding/json
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			fieldsOf(schemas, field.Type, properties, required)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := schemaOf(schemas, field.Type)
+		if _, isRef := schema["$ref"]; !isRef && field.Tag.Get("example") != "" {
+			schema["examples"] = []string{field.Tag.Get("example")}
+		}
+
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			key, param, _ := strings.Cut(rule, "=")
+			switch key {
+			case "required":
+				*required = append(*required, name)
+			case "max", "min":
+				limit, err := strconv.Atoi(param)
+				if err != nil {
+					continue
+				}
+
+				if field.Type.Kind() == reflect.String {
+					schema[key+"Length"] = limit
+				} else {
+					schema[map[string]string{"max": "maximum", "min": "minimum"}[key]] = limit
+				}
+			}
+		}
+
+		properties[name] = schema
+	}
+}
+
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	return keys
+}
@@ -10,6 +10,7 @@ type Config struct {
 	DBUrl                  string   `mapstructure:"DB_URL"`
 	JWTSecret              string   `mapstructure:"JWT_SECRET"`
 	SessionExpire          int      `mapstructure:"SESSION_EXPIRE"`
+	WebsocketTicketExpire  int      `mapstructure:"WS_TICKET_EXPIRE"`
 	GoogleClientId         string   `mapstructure:"GOOGLE_CLIENT_ID"`
 	GoogleSecret           string   `mapstructure:"GOOGLE_CLIENT_SECRET"`
 	GoogleScopes           []string `mapstructure:"GOOGLE_SCOPE"`
@@ -23,7 +24,30 @@ type Config struct {
 	AuthRedirect           string   `mapstructure:"AUTH_REDIRECT"`
 	AuthVerificationExpire int      `mapstructure:"AUTH_VERIFICATION_EXPIRE"`
 	STRIPE_SECRET_KEY      string   `mapstructure:"STRIPE_SECRET_KEY"`
+	StripeWebhookSecret    string   `mapstructure:"STRIPE_WEBHOOK_SECRET"`
+	PaymentProvider        string   `mapstructure:"PAYMENT_PROVIDER"`
+	FakePaymentOutcome     string   `mapstructure:"FAKE_PAYMENT_OUTCOME"`
+	FakePaymentDelay       int      `mapstructure:"FAKE_PAYMENT_DELAY"`
+	BillingInterval        int      `mapstructure:"BILLING_INTERVAL"`
+	InvoiceLeadDays        int      `mapstructure:"INVOICE_LEAD_DAYS"`
+	OverdueGraceDays       int      `mapstructure:"OVERDUE_GRACE_DAYS"`
+	LateFeePercent         float64  `mapstructure:"LATE_FEE_PERCENT"`
+	PlatformFeePercent     float64  `mapstructure:"PLATFORM_FEE_PERCENT"`
+	PayoutInterval         int      `mapstructure:"PAYOUT_INTERVAL"`
+	PayoutMinimum          float64  `mapstructure:"PAYOUT_MINIMUM"`
+	VatPercent             float64  `mapstructure:"VAT_PERCENT"`
+	ReceiptFont            string   `mapstructure:"RECEIPT_FONT"`
 	FRONTEND_URL           string   `mapstructure:"FRONTEND_URL"`
+	ChatEditWindow         int      `mapstructure:"CHAT_EDIT_WINDOW"`
+	VapidPublicKey         string   `mapstructure:"VAPID_PUBLIC_KEY"`
+	VapidPrivateKey        string   `mapstructure:"VAPID_PRIVATE_KEY"`
+	NotificationInterval   int      `mapstructure:"NOTIFICATION_DIGEST_INTERVAL"`
+	ContactAction          string   `mapstructure:"MODERATION_CONTACT_ACTION"`
+	ProfanityAction        string   `mapstructure:"MODERATION_PROFANITY_ACTION"`
+	LinkAction             string   `mapstructure:"MODERATION_LINK_ACTION"`
+	AllowedLinkHosts       []string `mapstructure:"MODERATION_ALLOWED_HOSTS"`
+	RateLimitAction        string   `mapstructure:"MODERATION_RATE_LIMIT_ACTION"`
+	RateLimit              int      `mapstructure:"MODERATION_RATE_LIMIT"`
 }
 
 func (cfg *Config) IsDevelopment() bool {
@@ -38,6 +62,7 @@ func Load(config *Config) error {
 	_ = viper.BindEnv("DB_URL")
 	_ = viper.BindEnv("JWT_SECRET")
 	_ = viper.BindEnv("SESSION_EXPIRE")
+	_ = viper.BindEnv("WS_TICKET_EXPIRE")
 	_ = viper.BindEnv("GOOGLE_CLIENT_ID")
 	_ = viper.BindEnv("GOOGLE_CLIENT_SECRET")
 	_ = viper.BindEnv("GOOGLE_SCOPE")
@@ -51,7 +76,30 @@ func Load(config *Config) error {
 	_ = viper.BindEnv("SMTP_HOST")
 	_ = viper.BindEnv("SMTP_PORT")
 	_ = viper.BindEnv("STRIPE_SECRET_KEY")
+	_ = viper.BindEnv("STRIPE_WEBHOOK_SECRET")
+	_ = viper.BindEnv("PAYMENT_PROVIDER")
+	_ = viper.BindEnv("FAKE_PAYMENT_OUTCOME")
+	_ = viper.BindEnv("FAKE_PAYMENT_DELAY")
+	_ = viper.BindEnv("BILLING_INTERVAL")
+	_ = viper.BindEnv("INVOICE_LEAD_DAYS")
+	_ = viper.BindEnv("OVERDUE_GRACE_DAYS")
+	_ = viper.BindEnv("LATE_FEE_PERCENT")
+	_ = viper.BindEnv("PLATFORM_FEE_PERCENT")
+	_ = viper.BindEnv("PAYOUT_INTERVAL")
+	_ = viper.BindEnv("PAYOUT_MINIMUM")
+	_ = viper.BindEnv("VAT_PERCENT")
+	_ = viper.BindEnv("RECEIPT_FONT")
 	_ = viper.BindEnv("FRONTEND_URL")
+	_ = viper.BindEnv("CHAT_EDIT_WINDOW")
+	_ = viper.BindEnv("VAPID_PUBLIC_KEY")
+	_ = viper.BindEnv("VAPID_PRIVATE_KEY")
+	_ = viper.BindEnv("NOTIFICATION_DIGEST_INTERVAL")
+	_ = viper.BindEnv("MODERATION_CONTACT_ACTION")
+	_ = viper.BindEnv("MODERATION_PROFANITY_ACTION")
+	_ = viper.BindEnv("MODERATION_LINK_ACTION")
+	_ = viper.BindEnv("MODERATION_ALLOWED_HOSTS")
+	_ = viper.BindEnv("MODERATION_RATE_LIMIT_ACTION")
+	_ = viper.BindEnv("MODERATION_RATE_LIMIT")
 
 	viper.AutomaticEnv()
 	viper.AllowEmptyEnv(false)
@@ -207,6 +207,59 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/agreements/{agreementId}/schedule": {
+            "get": {
+                "description": "Get every monthly installment of a renting agreement with its due date, amount and whether it is paid, unpaid or overdue. Installments are payable once invoiced, which happens INVOICE_LEAD_DAYS before they are due. Only the owner and the dweller can see it, and it is empty until the agreement starts renting.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Get rent schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Agreement ID",
+                        "name": "agreementId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RentSchedules"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/appointments": {
             "get": {
                 "description": "Get all appointments",
@@ -463,7 +516,7 @@ const docTemplate = `{
         },
         "/api/v1/chats": {
             "get": {
-                "description": "Get current users chat",
+                "description": "Get current users chat. Chats about a property come with a preview of the property.",
                 "produces": [
                     "application/json"
                 ],
@@ -488,40 +541,35 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/chats/:recvUserId": {
-            "get": {
-                "description": "Get messages chatting with recvUserId. Pagination is available.",
+            },
+            "post": {
+                "description": "Get the chat with user_id about property_id, creating it if it does not exist. Omit property_id for a general chat.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "chats"
                 ],
-                "summary": "Get messages in a chat with recvUserId *use cookies*",
+                "summary": "Start a chat *use cookies*",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "offset",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "default 50, max 50",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Chat partner and property",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatingConversations"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Messages"
-                            }
+                            "$ref": "#/definitions/models.Conversations"
                         }
                     },
                     "400": {
@@ -530,6 +578,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -539,51 +599,54 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/checkout": {
+        "/api/v1/chats/:chatId": {
             "get": {
-                "description": "Create payment",
+                "description": "Get messages in chatId, oldest first. Without a cursor the latest messages are returned.\nUse **before**/**after** with the cursors of a previous page to scroll, or **around** with a message id to jump to it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "payments"
+                    "chats"
                 ],
-                "summary": "Create payment",
+                "summary": "Get messages in a chat *use cookies*",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Agreement ID",
-                        "name": "agreement_id",
-                        "in": "query",
+                        "description": "Chat ID",
+                        "name": "chatId",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Name",
-                        "name": "name",
-                        "in": "query",
-                        "required": true
+                        "description": "before_cursor from a previous page",
+                        "name": "before",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Price",
-                        "name": "price",
-                        "in": "query",
-                        "required": true
+                        "description": "after_cursor from a previous page",
+                        "name": "after",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Payment Method",
-                        "name": "payment_method",
-                        "in": "query",
-                        "required": true
+                        "description": "message id to load the messages around",
+                        "name": "around",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "default 50, max 50",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Payments"
+                            "$ref": "#/definitions/models.ChatHistories"
                         }
                     },
                     "400": {
@@ -592,8 +655,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -607,32 +670,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/email": {
-            "post": {
-                "description": "Send a verification email to the user",
+        "/api/v1/chats/:chatId/export": {
+            "get": {
+                "description": "Download the whole chat with timestamps, read times and resolved property, appointment and agreement details. format=html gives a printable page that can be saved as PDF. Only participants of the chat can export it.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/html"
                 ],
                 "tags": [
-                    "emails"
+                    "chats"
                 ],
-                "summary": "Send verification email",
+                "summary": "Export a chat transcript *use cookies*",
                 "parameters": [
                     {
-                        "description": "User email address",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.SendingEmailRequests"
-                        }
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or html",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponses"
+                            "$ref": "#/definitions/models.ChatTranscripts"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
                     "500": {
@@ -644,57 +724,56 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/greeting": {
-            "get": {
-                "description": "says hello, world",
+        "/api/v1/chats/:chatId/files": {
+            "post": {
+                "description": "Send a message with up to 5 image (jpeg, png) or pdf files, at most 10 MB each. Images get a thumbnail.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "greeting"
+                    "chats"
                 ],
-                "summary": "Greeting",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.Greetings"
-                        }
+                "summary": "Send files to a chat *use cookies*",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "files to send",
+                        "name": "files",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "message text",
+                        "name": "content",
+                        "in": "formData"
                     }
-                }
-            }
-        },
-        "/api/v1/login": {
-            "post": {
-                "description": "Login with email and password",
-                "produces": [
-                    "application/json"
                 ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Login with email",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Properties"
+                            "$ref": "#/definitions/models.Messages"
                         }
                     },
                     "400": {
-                        "description": "Empty or invalid credentials",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    },
-                    "401": {
-                        "description": "Password mismatch",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -708,55 +787,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/logout": {
+        "/api/v1/chats/:chatId/leave": {
             "post": {
-                "description": "Logout",
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Logout",
-                "responses": {
-                    "200": {
-                        "description": "OK"
-                    }
-                }
-            }
-        },
-        "/api/v1/oauth/google": {
-            "get": {
-                "description": "Redirect to this endpoint to login with Google OAuth2. When logging in is completed, the redirection to /register in client will occur.",
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Login with Google",
-                "responses": {
-                    "307": {
-                        "description": "Temporary Redirect"
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "model"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/payments": {
-            "get": {
-                "description": "Get payment by user id",
+                "description": "Leave a group chat. One-to-one chats can not be left.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "payments"
+                    "chats"
+                ],
+                "summary": "Leave a group chat *use cookies*",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get payment by user id",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MyPaymentsResponse"
+                            "$ref": "#/definitions/models.MessageResponses"
                         }
                     },
                     "400": {
@@ -765,8 +819,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -780,21 +834,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/payments/history": {
-            "get": {
-                "description": "Get history payment by user id",
+        "/api/v1/chats/:chatId/members": {
+            "post": {
+                "description": "Any member of a group chat can add more members",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "payments"
+                    "chats"
                 ],
-                "summary": "Get history payment by user id",
-                "responses": {
-                    "200": {
+                "summary": "Add members to a group chat *use cookies*",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chat ID",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Users to add",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddingConversationMembers"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.HistoryResponse"
+                            "$ref": "#/definitions/models.Conversations"
                         }
                     },
                     "400": {
@@ -803,8 +878,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -818,295 +893,297 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/properties": {
-            "get": {
-                "description": "Get all properties or search properties by query",
+        "/api/v1/chats/:chatId/members/:userId": {
+            "delete": {
+                "description": "Only the creator of a group chat can remove other members",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "property"
+                    "chats"
                 ],
-                "summary": "Get or search properties",
+                "summary": "Remove a member from a group chat *use cookies*",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search query",
-                        "name": "query",
-                        "in": "query"
+                        "description": "Chat ID",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Pagination limit per page, max 50, default 20",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
                     },
-                    {
-                        "type": "integer",
-                        "description": "Pagination page index as 1-based index, default 1",
-                        "name": "page",
-                        "in": "query"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Sort in format ` + "`" + `\u003cjson_field\u003e:\u003cdirection\u003e` + "`" + ` where direction can only be ` + "`" + `desc` + "`" + ` or ` + "`" + `asc` + "`" + `. Ex. ` + "`" + `?sort=selling_property.price:desc` + "`" + `",
-                        "name": "sort",
-                        "in": "query"
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/chats/:chatId/mute": {
+            "post": {
+                "description": "Mute a chat. Muted chats do not count unread messages or send notifications.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chats"
+                ],
+                "summary": "Mute a chat *use cookies*",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter in format ` + "`" + `\u003cjson_field\u003e[\u003coperator\u003e]:\u003cvalue\u003e` + "`" + ` where operator can only be greater than or equal ` + "`" + `gte` + "`" + ` or less than or equal ` + "`" + `lte` + "`" + `. Multiple filters can be done with ` + "`" + `,` + "`" + ` separating each filters. Ex. ` + "`" + `?filter=floor_size[gte]:22,floor_size[lte]:45.5` + "`" + `",
-                        "name": "filter",
-                        "in": "query"
+                        "description": "Chat ID",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.AllPropertiesResponses"
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
                     "500": {
-                        "description": "Could not get properties",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     }
                 }
             },
-            "post": {
-                "description": "Create a property with formData *upload property images (array of images) in formData with field ` + "`" + `property_images` + "`" + `. Available formats are .png / .jpg / .jpeg",
+            "delete": {
+                "description": "Unmute a chat",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "property"
+                    "chats"
                 ],
-                "summary": "Create a property *user cookies*",
+                "summary": "Unmute a chat *use cookies*",
                 "parameters": [
                     {
                         "type": "string",
-                        "example": "123/4",
-                        "name": "address",
-                        "in": "formData"
+                        "description": "Chat ID",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/chats/:chatId/report": {
+            "post": {
+                "description": "Report the other participant of a chat, or user_id of a group chat. The latest messages of the chat are kept for moderator review.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chats"
+                ],
+                "summary": "Report a chat *use cookies*",
+                "parameters": [
                     {
                         "type": "string",
-                        "example": "Pattaya Nua 78",
-                        "name": "alley",
-                        "in": "formData"
+                        "description": "Chat ID",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "example": 2,
-                        "name": "bathrooms",
-                        "in": "formData"
+                        "description": "Report reason",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatingChatReports"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChatReports"
+                        }
                     },
-                    {
-                        "type": "integer",
-                        "example": 3,
-                        "name": "bedrooms",
-                        "in": "formData"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "example": "Thailand",
-                        "name": "country",
-                        "in": "formData"
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/chats/files/:fileId": {
+            "get": {
+                "description": "Download a file sent in chat. Only participants of the chat can access it.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "chats"
+                ],
+                "summary": "Get a file sent in chat *use cookies*",
+                "parameters": [
                     {
                         "type": "string",
-                        "example": "Bang Phli",
-                        "name": "district",
-                        "in": "formData"
+                        "description": "File ID",
+                        "name": "fileId",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "example": 5,
-                        "name": "floor",
-                        "in": "formData"
+                        "type": "boolean",
+                        "description": "get the image thumbnail instead",
+                        "name": "thumbnail",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
                     },
-                    {
-                        "type": "number",
-                        "example": 123.45,
-                        "name": "floor_size",
-                        "in": "formData"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
                     },
-                    {
-                        "enum": [
-                            "SQM",
-                            "SQFT"
-                        ],
-                        "type": "string",
-                        "example": "SQM",
-                        "x-enum-varnames": [
-                            "SQM",
-                            "SQFT"
-                        ],
-                        "name": "floor_size_unit",
-                        "in": "formData"
-                    },
-                    {
-                        "enum": [
-                            "UNFURNISHED",
-                            "PARTIALLY_FURNISHED",
-                            "FULLY_FURNISHED",
-                            "READY_TO_MOVE_IN"
-                        ],
-                        "type": "string",
-                        "example": "UNFURNISHED",
-                        "x-enum-varnames": [
-                            "UNFURNISHED",
-                            "PARTIALLY_FURNISHED",
-                            "FULLY_FURNISHED",
-                            "READY_TO_MOVE_IN"
-                        ],
-                        "name": "furnishing",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "csv",
-                        "example": [
-                            "https://image_url.com/abcd",
-                            "https://image_url.com/abcd",
-                            "https://image_url.com/abcd"
-                        ],
-                        "name": "image_urls",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "boolean",
-                        "example": false,
-                        "name": "is_occupied",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "boolean",
-                        "example": true,
-                        "name": "is_sold",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "123e4567-e89b-12d3-a456-426614174000",
-                        "name": "-",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "69096",
-                        "name": "postal_code",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "number",
-                        "example": 12345.67,
-                        "name": "price",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "number",
-                        "example": 12345.67,
-                        "name": "price_per_month",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Et sequi dolor praes",
-                        "name": "property_description",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "123e4567-e89b-12d3-a456-426614174000",
-                        "name": "-",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Supalai",
-                        "name": "property_name",
-                        "in": "formData"
-                    },
-                    {
-                        "enum": [
-                            "CONDOMINIUM",
-                            "APARTMENT",
-                            "SEMI-DETACHED_HOUSE",
-                            "HOUSE",
-                            "SERVICED_APARTMENT",
-                            "TOWNHOUSE"
-                        ],
-                        "type": "string",
-                        "example": "CONDOMINIUM",
-                        "x-enum-varnames": [
-                            "CONDOMINIUM",
-                            "APARTMENT",
-                            "SEMI_DETACHED_HOUSE",
-                            "HOUSE",
-                            "SERVICED_APARTMENT",
-                            "TOWNHOUSE"
-                        ],
-                        "name": "property_type",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Pattaya",
-                        "name": "province",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Pattaya",
-                        "name": "street",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Bang Bon",
-                        "name": "sub_district",
-                        "in": "formData"
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/chats/groups": {
+            "post": {
+                "description": "Create a group chat with the current user and user_ids, optionally about property_id. Groups have at most 20 members.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chats"
+                ],
+                "summary": "Start a group chat *use cookies*",
+                "parameters": [
                     {
-                        "type": "integer",
-                        "example": 123,
-                        "name": "unit_number",
-                        "in": "formData"
+                        "description": "Group name and members",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatingGroupConversations"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Property created",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponses"
+                            "$ref": "#/definitions/models.Conversations"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    },
-                    "403": {
-                        "description": "Unauthorized",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
                     "404": {
-                        "description": "Property id not found",
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
                     "500": {
-                        "description": "Could not create property",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -1114,40 +1191,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/properties/:propertyId": {
+        "/api/v1/chats/search": {
             "get": {
-                "description": "Get property by its id",
+                "description": "Search message content across all of the current user's chats, newest first. Each hit comes with the surrounding messages and the chat it belongs to.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "property"
+                    "chats"
                 ],
-                "summary": "Get property by propertyId",
+                "summary": "Search messages in all chats *use cookies*",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Property id",
-                        "name": "propertyId",
-                        "in": "path",
+                        "description": "search text",
+                        "name": "q",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "default 20, max 50",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Properties"
+                            "$ref": "#/definitions/models.ChatSearchResults"
                         }
                     },
                     "400": {
-                        "description": "Invalid property id",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    },
-                    "404": {
-                        "description": "Property id not found",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -1159,297 +1242,147 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a property, owned by the current user, by its id",
+            }
+        },
+        "/api/v1/chats/tickets": {
+            "post": {
+                "description": "Issue a single use ticket to open /ws/chats?ticket= from clients that can not send the session cookie to the websocket, such as native apps or frontends on another domain. The ticket is bound to the Origin of this request and expires after WS_TICKET_EXPIRE seconds.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "property"
-                ],
-                "summary": "Delete a property *use cookies*",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Property id",
-                        "name": "propertyId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "chats"
                 ],
+                "summary": "Issue a websocket ticket *use cookies*",
                 "responses": {
-                    "200": {
-                        "description": "Property deleted",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponses"
+                            "$ref": "#/definitions/models.WebsocketTickets"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "401": {
+                        "description": "Invalid, expired or logged out session",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
                     "403": {
-                        "description": "Unauthorized",
+                        "description": "Origin is not allowed",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "404": {
-                        "description": "Property id not found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
+                    }
+                }
+            }
+        },
+        "/api/v1/chats/unread": {
+            "get": {
+                "description": "Get the number of unread messages in every chat. total leaves out muted chats. The same summary is pushed over the websocket as an UNREAD event whenever it changes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chats"
+                ],
+                "summary": "Get unread message counters *use cookies*",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UnreadSummaries"
+                        }
                     },
                     "500": {
-                        "description": "Could not delete property",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Update a property with formData *upload **NEW** property images (array of images) in formData with field ` + "`" + `property_images` + "`" + `. Available formats are .png / .jpg / .jpeg *If you want to keep the old images, you need to include them in the formData with field ` + "`" + `image_urls` + "`" + ` as an array of strings",
+            }
+        },
+        "/api/v1/checkout": {
+            "post": {
+                "description": "Create a payment for the next amount due on an agreement. The amount is the deposit while the agreement awaits it, then one monthly installment for renting or the remaining balance for selling. Renting agreements pay the earliest invoiced installment, or the one given by **installment**. Only the dweller can pay.\n\nCheckout is safe to retry. A request with an **Idempotency-Key** that was used before returns the payment it created, and while a checkout for the same deposit, balance or installment is still open its URL is returned instead of a new one.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "property"
+                    "payments"
                 ],
-                "summary": "Update a property *user cookies*",
+                "summary": "Create payment",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Property id",
-                        "name": "propertyId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "example": "123/4",
-                        "name": "address",
-                        "in": "formData"
+                        "description": "Unique key of this checkout attempt, at most 255 characters",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     },
                     {
-                        "type": "string",
-                        "example": "Pattaya Nua 78",
-                        "name": "alley",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "integer",
-                        "example": 2,
-                        "name": "bathrooms",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "integer",
-                        "example": 3,
-                        "name": "bedrooms",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Thailand",
-                        "name": "country",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Bang Phli",
-                        "name": "district",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "integer",
-                        "example": 5,
-                        "name": "floor",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "number",
-                        "example": 123.45,
-                        "name": "floor_size",
-                        "in": "formData"
-                    },
-                    {
-                        "enum": [
-                            "SQM",
-                            "SQFT"
-                        ],
-                        "type": "string",
-                        "example": "SQM",
-                        "x-enum-varnames": [
-                            "SQM",
-                            "SQFT"
-                        ],
-                        "name": "floor_size_unit",
-                        "in": "formData"
-                    },
-                    {
-                        "enum": [
-                            "UNFURNISHED",
-                            "PARTIALLY_FURNISHED",
-                            "FULLY_FURNISHED",
-                            "READY_TO_MOVE_IN"
-                        ],
-                        "type": "string",
-                        "example": "UNFURNISHED",
-                        "x-enum-varnames": [
-                            "UNFURNISHED",
-                            "PARTIALLY_FURNISHED",
-                            "FULLY_FURNISHED",
-                            "READY_TO_MOVE_IN"
-                        ],
-                        "name": "furnishing",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "csv",
-                        "example": [
-                            "https://image_url.com/abcd",
-                            "https://image_url.com/abcd",
-                            "https://image_url.com/abcd"
-                        ],
-                        "name": "image_urls",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "boolean",
-                        "example": false,
-                        "name": "is_occupied",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "boolean",
-                        "example": true,
-                        "name": "is_sold",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "123e4567-e89b-12d3-a456-426614174000",
-                        "name": "-",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "69096",
-                        "name": "postal_code",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "number",
-                        "example": 12345.67,
-                        "name": "price",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "number",
-                        "example": 12345.67,
-                        "name": "price_per_month",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Et sequi dolor praes",
-                        "name": "property_description",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "123e4567-e89b-12d3-a456-426614174000",
-                        "name": "-",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Supalai",
-                        "name": "property_name",
-                        "in": "formData"
-                    },
-                    {
-                        "enum": [
-                            "CONDOMINIUM",
-                            "APARTMENT",
-                            "SEMI-DETACHED_HOUSE",
-                            "HOUSE",
-                            "SERVICED_APARTMENT",
-                            "TOWNHOUSE"
-                        ],
-                        "type": "string",
-                        "example": "CONDOMINIUM",
-                        "x-enum-varnames": [
-                            "CONDOMINIUM",
-                            "APARTMENT",
-                            "SEMI_DETACHED_HOUSE",
-                            "HOUSE",
-                            "SERVICED_APARTMENT",
-                            "TOWNHOUSE"
-                        ],
-                        "name": "property_type",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Pattaya",
-                        "name": "province",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Pattaya",
-                        "name": "street",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Bang Bon",
-                        "name": "sub_district",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "integer",
-                        "example": 123,
-                        "name": "unit_number",
-                        "in": "formData"
+                        "description": "Agreement, payment method and installment",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatingPayments"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Property updated",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponses"
+                            "$ref": "#/definitions/models.Payments"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "403": {
+                    "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
                     "404": {
-                        "description": "Property id not found",
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
                     "500": {
-                        "description": "Could not update property",
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -1457,91 +1390,100 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/properties/favorites/:propertyId": {
+        "/api/v1/email": {
             "post": {
-                "description": "Add property to the current user favorites",
+                "description": "Send a verification email to the user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "property"
+                    "emails"
                 ],
-                "summary": "Add property to favorites *use cookies*",
+                "summary": "Send verification email",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Property id",
-                        "name": "propertyId",
-                        "in": "path",
-                        "required": true
+                        "description": "User email address",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SendingEmailRequests"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Property added to favorites",
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/models.MessageResponses"
                         }
                     },
-                    "403": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    },
-                    "404": {
-                        "description": "Property id not found",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    },
                     "500": {
-                        "description": "Could not add favorite property",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Remove property to the current user favorites",
-                "produces": [
-                    "application/json"
+            }
+        },
+        "/api/v1/greeting": {
+            "get": {
+                "description": "says hello, world",
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
-                    "property"
+                    "greeting"
                 ],
-                "summary": "Remove property to favorites *use cookies*",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Property id",
-                        "name": "propertyId",
-                        "in": "path",
-                        "required": true
+                "summary": "Greeting",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Greetings"
+                        }
                     }
+                }
+            }
+        },
+        "/api/v1/login": {
+            "post": {
+                "description": "Login with email and password",
+                "produces": [
+                    "application/json"
                 ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login with email",
                 "responses": {
                     "200": {
-                        "description": "Property removed from favorites",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponses"
+                            "$ref": "#/definitions/models.Properties"
                         }
                     },
-                    "403": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Empty or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "401": {
+                        "description": "Password mismatch",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
                     "404": {
-                        "description": "Property id not found",
+                        "description": "User not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
                     "500": {
-                        "description": "Could not remove favorite property",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -1549,33 +1491,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/ratings": {
+        "/api/v1/logout": {
+            "post": {
+                "description": "Logout",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/notifications/preferences": {
             "get": {
-                "description": "Get all ratings",
+                "description": "Get how the current user is notified of messages received while offline",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "notifications"
                 ],
-                "summary": "Get all ratings",
+                "summary": "Get notification preferences *use cookies*",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RatingResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
+                            "$ref": "#/definitions/models.NotificationPreferences"
                         }
                     },
                     "500": {
@@ -1586,41 +1536,23 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "Create rating",
+            "put": {
+                "description": "Update web push and email digest settings. Quiet hours are HH:MM in the given timezone and may wrap around midnight; omit both to disable.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "notifications"
                 ],
-                "summary": "Create rating",
+                "summary": "Update notification preferences *use cookies*",
                 "parameters": [
                     {
-                        "description": "rating",
-                        "name": "rating",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "integer"
-                        }
-                    },
-                    {
-                        "description": "review",
-                        "name": "review",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    {
-                        "description": "property_id",
-                        "name": "property_id",
+                        "description": "Notification preferences",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.NotificationPreferences"
                         }
                     }
                 ],
@@ -1628,7 +1560,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RatingResponse"
+                            "$ref": "#/definitions/models.NotificationPreferences"
                         }
                     },
                     "400": {
@@ -1637,12 +1569,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1652,30 +1578,32 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/ratings/:propertyId": {
-            "get": {
-                "description": "Get rating by property id",
+        "/api/v1/notifications/push-subscriptions": {
+            "post": {
+                "description": "Register the browser's push subscription. Subscribing an endpoint again moves it to the current user.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "notifications"
                 ],
-                "summary": "Get rating by property id",
+                "summary": "Subscribe a device to web push *use cookies*",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "propertyId",
-                        "name": "propertyId",
-                        "in": "path",
-                        "required": true
+                        "description": "Push subscription",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatingPushSubscriptions"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.RatingResponse"
+                            "$ref": "#/definitions/models.PushSubscriptions"
                         }
                     },
                     "400": {
@@ -1684,12 +1612,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1697,25 +1619,25 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/ratings/:ratingId": {
+            },
             "delete": {
-                "description": "Delete rating",
+                "description": "Remove the push subscription with the given endpoint",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "notifications"
                 ],
-                "summary": "Delete rating",
+                "summary": "Unsubscribe a device from web push *use cookies*",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ratingId",
-                        "name": "ratingId",
-                        "in": "path",
-                        "required": true
+                        "description": "Push subscription endpoint",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DeletingPushSubscriptions"
+                        }
                     }
                 ],
                 "responses": {
@@ -1731,12 +1653,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1744,48 +1660,63 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Update rating status",
+            }
+        },
+        "/api/v1/notifications/vapid-key": {
+            "get": {
+                "description": "Get the application server key to pass to PushManager.subscribe",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "notifications"
                 ],
-                "summary": "Update rating status",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "ratingId",
-                        "name": "ratingId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "review",
-                        "name": "review",
-                        "in": "body",
-                        "required": true,
+                "summary": "Get VAPID public key",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.VapidKeys"
                         }
+                    }
+                }
+            }
+        },
+        "/api/v1/oauth/google": {
+            "get": {
+                "description": "Redirect to this endpoint to login with Google OAuth2. When logging in is completed, the redirection to /register in client will occur.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login with Google",
+                "responses": {
+                    "307": {
+                        "description": "Temporary Redirect"
                     },
-                    {
-                        "description": "rating",
-                        "name": "rating",
-                        "in": "body",
-                        "required": true,
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "integer"
+                            "type": "model"
                         }
                     }
+                }
+            }
+        },
+        "/api/v1/payments": {
+            "get": {
+                "description": "Get payment by user id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
                 ],
+                "summary": "Get payment by user id",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponses"
+                            "$ref": "#/definitions/models.MyPaymentsResponse"
                         }
                     },
                     "400": {
@@ -1809,30 +1740,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/ratings/newest/:propertyId": {
+        "/api/v1/payments/history": {
             "get": {
-                "description": "Get rating by property id sorted by newest",
+                "description": "Get history payment by user id",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
-                ],
-                "summary": "Get rating by property id sorted by newest",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "propertyId",
-                        "name": "propertyId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "payments"
                 ],
+                "summary": "Get history payment by user id",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RatingResponse"
+                            "$ref": "#/definitions/models.HistoryResponse"
                         }
                     },
                     "400": {
@@ -1856,22 +1778,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/ratings/sorted/:propertyId": {
-            "get": {
-                "description": "Get rating by property id sorted by rating",
+        "/api/v1/payments/webhook": {
+            "post": {
+                "description": "Receives events from the payment provider, signed with STRIPE_WEBHOOK_SECRET for Stripe. Checkout completion, expiry, failed payments and refunds update the payment and notify both sides of its agreement. Each event is applied at most once.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "payments"
                 ],
-                "summary": "Get rating by property id sorted by rating",
+                "summary": "Receive payment provider webhook events",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "propertyId",
-                        "name": "propertyId",
-                        "in": "path",
+                        "description": "Stripe signature",
+                        "name": "Stripe-Signature",
+                        "in": "header",
                         "required": true
                     }
                 ],
@@ -1879,7 +1804,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RatingResponse"
+                            "$ref": "#/definitions/models.MessageResponses"
                         }
                     },
                     "400": {
@@ -1888,12 +1813,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1903,105 +1822,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/register": {
-            "post": {
-                "description": "Create user with formData **\\***upload profile image in formData with field ` + "`" + `profile_image` + "`" + `. Available formats are .png / .jpg / .jpeg",
+        "/api/v1/payments/{paymentId}": {
+            "get": {
+                "description": "Get a payment of the current user, as its payer or the owner of its agreement. Payments still waiting for the provider are refreshed from it first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "payments"
                 ],
-                "summary": "Register *use cookies*",
+                "summary": "Get payment by id",
                 "parameters": [
                     {
                         "type": "string",
-                        "example": "email@email.com",
-                        "name": "email",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "John",
-                        "name": "first_name",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "Doe",
-                        "name": "last_name",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "password1234",
-                        "name": "password",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "0812345678",
-                        "name": "phone_number",
-                        "in": "formData"
-                    },
-                    {
-                        "enum": [
-                            "EMAIL",
-                            "GOOGLE"
-                        ],
-                        "type": "string",
-                        "x-enum-varnames": [
-                            "EMAIL",
-                            "GOOGLE"
-                        ],
-                        "name": "registered_type",
-                        "in": "formData"
+                        "description": "Payment ID",
+                        "name": "paymentId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User created",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponses"
+                            "$ref": "#/definitions/models.Payments"
                         }
                     },
                     "400": {
-                        "description": "Invalid user info",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "500": {
-                        "description": "Could not create user",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
-                    }
-                }
-            }
-        },
-        "/api/v1/top10properties": {
-            "get": {
-                "description": "Get top 10 properties with the most favorites, sorted by the number of favorites then by the newest properties",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "property"
-                ],
-                "summary": "Get top 10 properties",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Properties"
-                            }
+                            "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
                     "500": {
-                        "description": "Could not get top 10 properties",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -2009,31 +1875,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/user/:userId": {
+        "/api/v1/payments/{paymentId}/receipt": {
             "get": {
-                "description": "Get a user by its id",
+                "description": "Download the numbered receipt of a paid payment as a PDF, a tax invoice when VAT_PERCENT is set. It shows the payer, the owner, the property address, the agreement reference, the amount with the VAT it includes and the payment method. Receipts are issued and emailed to the payer when the payment succeeds. Only the payer and the owner can get it.",
                 "produces": [
-                    "application/json"
+                    "application/pdf"
                 ],
                 "tags": [
-                    "users"
+                    "payments"
+                ],
+                "summary": "Get payment receipt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment ID",
+                        "name": "paymentId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get user by id",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/models.Users"
+                            "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "400": {
-                        "description": "Invalid user id",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -2045,111 +1923,124 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a user by its id",
+            }
+        },
+        "/api/v1/payments/{paymentId}/refunds": {
+            "post": {
+                "description": "Pay a deposit back to the dweller once its agreement is cancelled or completed. The whole deposit is refunded, less any **deductions**, which are listed to the dweller in chat along with the **reason**. Only the owner can refund, once per deposit. The refund is pending until the payment provider confirms it.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "payments"
+                ],
+                "summary": "Refund a deposit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payment ID",
+                        "name": "paymentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason and deductions",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatingRefunds"
+                        }
+                    }
                 ],
-                "summary": "Delete user by id  *use cookies*",
                 "responses": {
-                    "200": {
-                        "description": "User deleted",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.MessageResponses"
+                            "$ref": "#/definitions/models.Refunds"
                         }
                     },
                     "400": {
-                        "description": "Invalid user id",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
-                    }
-                }
-            }
-        },
-        "/api/v1/user/greeting": {
-            "get": {
-                "description": "says hello to current user",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "greeting"
-                ],
-                "summary": "Greeting with auth required *use cookies*",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.Greetings"
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
-                    }
-                }
-            }
-        },
-        "/api/v1/user/me": {
-            "get": {
-                "description": "Get current user info",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "users"
-                ],
-                "summary": "Get current user info *use cookies*",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.Users"
-                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
                     }
                 }
             }
         },
-        "/api/v1/user/me/agreements": {
+        "/api/v1/properties": {
             "get": {
-                "description": "Get all agreements related to the user",
+                "description": "Get all properties or search properties by query",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "agreements"
+                    "property"
                 ],
-                "summary": "Get my agreements *use cookies*",
+                "summary": "Get or search properties",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Order by ASC or DESC",
-                        "name": "order",
+                        "description": "Search query",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pagination limit per page, max 50, default 20",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pagination page index as 1-based index, default 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort in format ` + "`" + `\u003cjson_field\u003e:\u003cdirection\u003e` + "`" + ` where direction can only be ` + "`" + `desc` + "`" + ` or ` + "`" + `asc` + "`" + `. Ex. ` + "`" + `?sort=selling_property.price:desc` + "`" + `",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter in format ` + "`" + `\u003cjson_field\u003e[\u003coperator\u003e]:\u003cvalue\u003e` + "`" + ` where operator can only be greater than or equal ` + "`" + `gte` + "`" + ` or less than or equal ` + "`" + `lte` + "`" + `. Multiple filters can be done with ` + "`" + `,` + "`" + ` separating each filters. Ex. ` + "`" + `?filter=floor_size[gte]:22,floor_size[lte]:45.5` + "`" + `",
+                        "name": "filter",
                         "in": "query"
                     }
                 ],
@@ -2157,87 +2048,242 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.MyAgreementResponses"
+                            "$ref": "#/definitions/models.AllPropertiesResponses"
                         }
                     },
                     "500": {
-                        "description": "Could not get my agreements",
+                        "description": "Could not get properties",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/user/me/appointments": {
-            "get": {
-                "description": "Get all appointments related to the user",
+            },
+            "post": {
+                "description": "Create a property with formData *upload property images (array of images) in formData with field ` + "`" + `property_images` + "`" + `. Available formats are .png / .jpg / .jpeg",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "appointments"
+                    "property"
                 ],
-                "summary": "Get my appointments *use cookies*",
+                "summary": "Create a property *user cookies*",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Order by ASC or DESC",
-                        "name": "order",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.MyAppointmentResponses"
-                        }
+                        "example": "123/4",
+                        "name": "address",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "Pattaya Nua 78",
+                        "name": "alley",
+                        "in": "formData"
                     },
-                    "500": {
-                        "description": "Could not get my appointments",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/user/me/favorites": {
-            "get": {
-                "description": "Get all properties that the current user has added to favorites",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "property"
-                ],
-                "summary": "Get my favorite properties *use cookies*",
-                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Pagination limit per page, max 50, default 20",
-                        "name": "limit",
-                        "in": "query"
+                        "example": 2,
+                        "name": "bathrooms",
+                        "in": "formData"
                     },
                     {
                         "type": "integer",
-                        "description": "Pagination page index as 1-based index, default 1",
-                        "name": "page",
-                        "in": "query"
+                        "example": 3,
+                        "name": "bedrooms",
+                        "in": "formData"
                     },
                     {
                         "type": "string",
-                        "description": "Sort in format ` + "`" + `\u003cjson_field\u003e:\u003cdirection\u003e` + "`" + ` where direction can only be ` + "`" + `desc` + "`" + ` or ` + "`" + `asc` + "`" + `. Ex. ` + "`" + `?sort=selling_property.price:desc` + "`" + `",
-                        "name": "sort",
-                        "in": "query"
+                        "example": "Thailand",
+                        "name": "country",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "Bang Phli",
+                        "name": "district",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 5,
+                        "name": "floor",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "example": 123.45,
+                        "name": "floor_size",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "SQM",
+                            "SQFT"
+                        ],
+                        "type": "string",
+                        "example": "SQM",
+                        "x-enum-varnames": [
+                            "SQM",
+                            "SQFT"
+                        ],
+                        "name": "floor_size_unit",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "UNFURNISHED",
+                            "PARTIALLY_FURNISHED",
+                            "FULLY_FURNISHED",
+                            "READY_TO_MOVE_IN"
+                        ],
+                        "type": "string",
+                        "example": "UNFURNISHED",
+                        "x-enum-varnames": [
+                            "UNFURNISHED",
+                            "PARTIALLY_FURNISHED",
+                            "FULLY_FURNISHED",
+                            "READY_TO_MOVE_IN"
+                        ],
+                        "name": "furnishing",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "example": [
+                            "https://image_url.com/abcd",
+                            "https://image_url.com/abcd",
+                            "https://image_url.com/abcd"
+                        ],
+                        "name": "image_urls",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "name": "is_occupied",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": true,
+                        "name": "is_sold",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "name": "-",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "69096",
+                        "name": "postal_code",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "example": 12345.67,
+                        "name": "price",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "example": 12345.67,
+                        "name": "price_per_month",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Et sequi dolor praes",
+                        "name": "property_description",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "name": "-",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Supalai",
+                        "name": "property_name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "CONDOMINIUM",
+                            "APARTMENT",
+                            "SEMI-DETACHED_HOUSE",
+                            "HOUSE",
+                            "SERVICED_APARTMENT",
+                            "TOWNHOUSE"
+                        ],
+                        "type": "string",
+                        "example": "CONDOMINIUM",
+                        "x-enum-varnames": [
+                            "CONDOMINIUM",
+                            "APARTMENT",
+                            "SEMI_DETACHED_HOUSE",
+                            "HOUSE",
+                            "SERVICED_APARTMENT",
+                            "TOWNHOUSE"
+                        ],
+                        "name": "property_type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Pattaya",
+                        "name": "province",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "Pattaya",
+                        "name": "street",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Bang Bon",
+                        "name": "sub_district",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 123,
+                        "name": "unit_number",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Property created",
                         "schema": {
-                            "$ref": "#/definitions/models.MyFavoritePropertiesResponses"
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
                     "403": {
@@ -2246,8 +2292,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
+                    "404": {
+                        "description": "Property id not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
                     "500": {
-                        "description": "Could not get favorite properties",
+                        "description": "Could not create property",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -2255,31 +2307,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/user/me/financial-information": {
+        "/api/v1/properties/:propertyId": {
             "get": {
-                "description": "Get current user financial information",
+                "description": "Get property by its id",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "property"
+                ],
+                "summary": "Get property by propertyId",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Property id",
+                        "name": "propertyId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get current user financial information *use cookies*",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.UserFinancialInformations"
+                            "$ref": "#/definitions/models.Properties"
                         }
                     },
                     "400": {
-                        "description": "Invalid user id",
+                        "description": "Invalid property id",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
-                    "403": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Property id not found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -2292,35 +2353,33 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
-                "description": "Update the current user financial information with data from the body",
+            "delete": {
+                "description": "Delete a property, owned by the current user, by its id",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "property"
                 ],
-                "summary": "Update the current user financial information *use cookies*",
+                "summary": "Delete a property *use cookies*",
                 "parameters": [
                     {
-                        "description": "User financial information",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UserFinancialInformations"
-                        }
+                        "type": "string",
+                        "description": "Property id",
+                        "name": "propertyId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User financial information updated",
+                        "description": "Property deleted",
                         "schema": {
                             "$ref": "#/definitions/models.MessageResponses"
                         }
                     },
                     "400": {
-                        "description": "Invalid user financial information",
+                        "description": "Invalid request body",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
@@ -2331,396 +2390,2398 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
+                    "404": {
+                        "description": "Property id not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
                     "500": {
-                        "description": "Could not update user financial information",
+                        "description": "Could not delete property",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/user/me/personal-information": {
-            "put": {
-                "description": "Update specifying userId with formData **\\***upload profile image in formData with field ` + "`" + `profile_image` + "`" + `. Available formats are .png / .jpg / .jpeg",
+            },
+            "patch": {
+                "description": "Update a property with formData *upload **NEW** property images (array of images) in formData with field ` + "`" + `property_images` + "`" + `. Available formats are .png / .jpg / .jpeg *If you want to keep the old images, you need to include them in the formData with field ` + "`" + `image_urls` + "`" + ` as an array of strings",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "property"
                 ],
-                "summary": "Update current user personal information *use cookies*",
+                "summary": "Update a property *user cookies*",
                 "parameters": [
                     {
                         "type": "string",
-                        "example": "John",
-                        "name": "first_name",
-                        "in": "formData"
+                        "description": "Property id",
+                        "name": "propertyId",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "example": "Doe",
-                        "name": "last_name",
-                        "in": "formData"
+                        "example": "123/4",
+                        "name": "address",
+                        "in": "formData",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "example": "0812345678",
-                        "name": "phone_number",
+                        "example": "Pattaya Nua 78",
+                        "name": "alley",
                         "in": "formData"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "User personal information updated",
-                        "schema": {
-                            "$ref": "#/definitions/models.MessageResponses"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid user info",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
                     },
-                    "404": {
-                        "description": "User not found",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    },
-                    "500": {
-                        "description": "Could not update user",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/user/me/properties": {
-            "get": {
-                "description": "Get all properties owned by the current user",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "property"
-                ],
-                "summary": "Get my properties *use cookies*",
-                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Pagination limit per page, max 50, default 20",
-                        "name": "limit",
-                        "in": "query"
+                        "example": 2,
+                        "name": "bathrooms",
+                        "in": "formData"
                     },
                     {
                         "type": "integer",
-                        "description": "Pagination page index as 1-based index, default 1",
-                        "name": "page",
-                        "in": "query"
+                        "example": 3,
+                        "name": "bedrooms",
+                        "in": "formData"
                     },
                     {
                         "type": "string",
-                        "description": "Sort in format ` + "`" + `\u003cjson_field\u003e:\u003cdirection\u003e` + "`" + ` where direction can only be ` + "`" + `desc` + "`" + ` or ` + "`" + `asc` + "`" + `. Ex. ` + "`" + `?sort=selling_property.price:desc` + "`" + `",
-                        "name": "sort",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.MyPropertiesResponses"
-                        }
+                        "example": "Thailand",
+                        "name": "country",
+                        "in": "formData",
+                        "required": true
                     },
-                    "403": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
+                    {
+                        "type": "string",
+                        "example": "Bang Phli",
+                        "name": "district",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 5,
+                        "name": "floor",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "example": 123.45,
+                        "name": "floor_size",
+                        "in": "formData",
+                        "required": true
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/user/me/registered": {
-            "get": {
-                "description": "Get user registered type",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "users"
-                ],
-                "summary": "Get user registered type *use cookies*",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.Sessions"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/user/me/verify": {
-            "post": {
-                "description": "Verify user by citizen id and citizen id image",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "users"
-                ],
-                "summary": "Verify user *use cookies*",
-                "parameters": [
                     {
+                        "enum": [
+                            "SQM",
+                            "SQFT"
+                        ],
                         "type": "string",
-                        "example": "1100111111111",
-                        "name": "citizen_id",
+                        "example": "SQM",
+                        "x-enum-varnames": [
+                            "SQM",
+                            "SQFT"
+                        ],
+                        "name": "floor_size_unit",
                         "in": "formData"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Verified",
-                        "schema": {
-                            "$ref": "#/definitions/models.MessageResponses"
-                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponses"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/v1/users": {
-            "get": {
-                "description": "Get all users",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "users"
-                ],
-                "summary": "Get all users",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Users"
-                            }
+                    {
+                        "enum": [
+                            "UNFURNISHED",
+                            "PARTIALLY_FURNISHED",
+                            "FULLY_FURNISHED",
+                            "READY_TO_MOVE_IN"
+                        ],
+                        "type": "string",
+                        "example": "UNFURNISHED",
+                        "x-enum-varnames": [
+                            "UNFURNISHED",
+                            "PARTIALLY_FURNISHED",
+                            "FULLY_FURNISHED",
+                            "READY_TO_MOVE_IN"
+                        ],
+                        "name": "furnishing",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "example": [
+                            "https://image_url.com/abcd",
+                            "https://image_url.com/abcd",
+                            "https://image_url.com/abcd"
+                        ],
+                        "name": "image_urls",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": false,
+                        "name": "is_occupied",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "example": true,
+                        "name": "is_sold",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "name": "-",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "69096",
+                        "name": "postal_code",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "example": 12345.67,
+                        "name": "price",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "example": 12345.67,
+                        "name": "price_per_month",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Et sequi dolor praes",
+                        "name": "property_description",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "123e4567-e89b-12d3-a456-426614174000",
+                        "name": "-",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Supalai",
+                        "name": "property_name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "CONDOMINIUM",
+                            "APARTMENT",
+                            "SEMI-DETACHED_HOUSE",
+                            "HOUSE",
+                            "SERVICED_APARTMENT",
+                            "TOWNHOUSE"
+                        ],
+                        "type": "string",
+                        "example": "CONDOMINIUM",
+                        "x-enum-varnames": [
+                            "CONDOMINIUM",
+                            "APARTMENT",
+                            "SEMI_DETACHED_HOUSE",
+                            "HOUSE",
+                            "SERVICED_APARTMENT",
+                            "TOWNHOUSE"
+                        ],
+                        "name": "property_type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Pattaya",
+                        "name": "province",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "Pattaya",
+                        "name": "street",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Bang Bon",
+                        "name": "sub_district",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 123,
+                        "name": "unit_number",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Property updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "403": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "Property id not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Could not update property",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponses"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "enums.AgreementStatus": {
-            "type": "string",
-            "enum": [
-                "AWAITING_DEPOSIT",
-                "AWAITING_PAYMENT",
-                "RENTING",
-                "CANCELLED",
-                "OVERDUE",
-                "ARCHIVED"
-            ],
-            "x-enum-varnames": [
-                "AwaitingDepositAgreement",
-                "AwaitingPaymentAgreement",
-                "RentingAgreement",
-                "CancelledAgreement",
-                "OverdueAgreement",
-                "ArchivedAgreement"
-            ]
-        },
-        "enums.AgreementTypes": {
-            "type": "string",
-            "enum": [
-                "SELLING",
-                "RENTING"
-            ],
-            "x-enum-varnames": [
-                "AgreementForSell",
-                "AgreementForRent"
-            ]
-        },
-        "enums.AppointmentStatus": {
-            "type": "string",
-            "enum": [
-                "PENDING",
-                "CONFIRMED",
-                "REJECTED",
-                "CANCELLED",
-                "ARCHIVED"
-            ],
-            "x-enum-varnames": [
-                "PendingAppointment",
-                "ConfirmedAppointment",
-                "RejectedAppointment",
-                "CancelledAppointment",
-                "ArchivedAppointment"
-            ]
-        },
-        "enums.BankNames": {
-            "type": "string",
-            "enum": [
-                "KBANK",
-                "BBL",
-                "KTB",
-                "BAY",
-                "CIMB",
-                "TTB",
-                "SCB",
-                "GSB",
-                ""
-            ],
-            "x-enum-comments": {
-                "BAY": "\"BANK OF AYUDHYA\"",
-                "BBL": "\"BANGKOK BANK\"",
-                "CIMB": "\"CIMB THAI BANK\"",
-                "GSB": "\"GOVERNMENT SAVINGS BANK\"",
-                "KBANK": "\"KASIKORN BANK\"",
-                "KTB": "\"KRUNG THAI BANK\"",
-                "NULL": "\"BANK NOT SELECTED\"",
-                "SCB": "\"SIAM COMMERCIAL BANK\"",
-                "TTB": "\"TMBTHANACHART BANK\""
-            },
-            "x-enum-varnames": [
-                "KBANK",
-                "BBL",
-                "KTB",
-                "BAY",
-                "CIMB",
-                "TTB",
-                "SCB",
-                "GSB",
-                "NULL"
-            ]
         },
-        "enums.CardColors": {
-            "type": "string",
-            "enum": [
-                "LIGHT_BLUE",
-                "BLUE",
-                "DARK_BLUE",
-                "VERY_DARK_BLUE"
-            ],
-            "x-enum-varnames": [
-                "LIGHT_BLUE",
-                "BLUE",
-                "DARK_BLUE",
+        "/api/v1/properties/favorites/:propertyId": {
+            "post": {
+                "description": "Add property to the current user favorites",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "property"
+                ],
+                "summary": "Add property to favorites *use cookies*",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Property id",
+                        "name": "propertyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Property added to favorites",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "403": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "Property id not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not add favorite property",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove property to the current user favorites",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "property"
+                ],
+                "summary": "Remove property to favorites *use cookies*",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Property id",
+                        "name": "propertyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Property removed from favorites",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "403": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "Property id not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not remove favorite property",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ratings": {
+            "get": {
+                "description": "Get all ratings",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Get all ratings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RatingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create rating",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Create rating",
+                "parameters": [
+                    {
+                        "description": "rating",
+                        "name": "rating",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "integer"
+                        }
+                    },
+                    {
+                        "description": "review",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "description": "property_id",
+                        "name": "property_id",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RatingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ratings/:propertyId": {
+            "get": {
+                "description": "Get rating by property id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Get rating by property id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "propertyId",
+                        "name": "propertyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RatingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ratings/:ratingId": {
+            "delete": {
+                "description": "Delete rating",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Delete rating",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ratingId",
+                        "name": "ratingId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update rating status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Update rating status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ratingId",
+                        "name": "ratingId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "review",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "description": "rating",
+                        "name": "rating",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ratings/newest/:propertyId": {
+            "get": {
+                "description": "Get rating by property id sorted by newest",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Get rating by property id sorted by newest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "propertyId",
+                        "name": "propertyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RatingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ratings/sorted/:propertyId": {
+            "get": {
+                "description": "Get rating by property id sorted by rating",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Get rating by property id sorted by rating",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "propertyId",
+                        "name": "propertyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RatingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/register": {
+            "post": {
+                "description": "Create user with formData **\\***upload profile image in formData with field ` + "`" + `profile_image` + "`" + `. Available formats are .png / .jpg / .jpeg",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Register *use cookies*",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "email@email.com",
+                        "name": "email",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "John",
+                        "name": "first_name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "Doe",
+                        "name": "last_name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "password1234",
+                        "name": "password",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "0812345678",
+                        "name": "phone_number",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "EMAIL",
+                            "GOOGLE"
+                        ],
+                        "type": "string",
+                        "x-enum-varnames": [
+                            "EMAIL",
+                            "GOOGLE"
+                        ],
+                        "name": "registered_type",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User created",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user info",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not create user",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/top10properties": {
+            "get": {
+                "description": "Get top 10 properties with the most favorites, sorted by the number of favorites then by the newest properties",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "property"
+                ],
+                "summary": "Get top 10 properties",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Properties"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get top 10 properties",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/:userId": {
+            "get": {
+                "description": "Get a user by its id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user by id",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Users"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user id",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a user by its id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete user by id  *use cookies*",
+                "responses": {
+                    "200": {
+                        "description": "User deleted",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user id",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/:userId/block": {
+            "post": {
+                "description": "Block a user. Neither side can send messages, appointments or agreements to the other until unblocked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chats"
+                ],
+                "summary": "Block userId *use cookies*",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Unblock a user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chats"
+                ],
+                "summary": "Unblock userId *use cookies*",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/greeting": {
+            "get": {
+                "description": "says hello to current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "greeting"
+                ],
+                "summary": "Greeting with auth required *use cookies*",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Greetings"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me": {
+            "get": {
+                "description": "Get current user info",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get current user info *use cookies*",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Users"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/agreements": {
+            "get": {
+                "description": "Get all agreements related to the user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "agreements"
+                ],
+                "summary": "Get my agreements *use cookies*",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order by ASC or DESC",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MyAgreementResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get my agreements",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/appointments": {
+            "get": {
+                "description": "Get all appointments related to the user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "appointments"
+                ],
+                "summary": "Get my appointments *use cookies*",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order by ASC or DESC",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MyAppointmentResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get my appointments",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/blocks": {
+            "get": {
+                "description": "Get users blocked by the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "chats"
+                ],
+                "summary": "Get blocked users *use cookies*",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BlockedUsers"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/earnings": {
+            "get": {
+                "description": "Get what the current owner has earned from payments less the platform fee and refunds, the balance waiting for the next payout, what is being paid out and what has been paid out, with the latest payouts. Balances of at least PAYOUT_MINIMUM are paid out to the bank account in the financial information every PAYOUT_INTERVAL seconds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Get my earnings *use cookies*",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OwnerEarnings"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/favorites": {
+            "get": {
+                "description": "Get all properties that the current user has added to favorites",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "property"
+                ],
+                "summary": "Get my favorite properties *use cookies*",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Pagination limit per page, max 50, default 20",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pagination page index as 1-based index, default 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort in format ` + "`" + `\u003cjson_field\u003e:\u003cdirection\u003e` + "`" + ` where direction can only be ` + "`" + `desc` + "`" + ` or ` + "`" + `asc` + "`" + `. Ex. ` + "`" + `?sort=selling_property.price:desc` + "`" + `",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MyFavoritePropertiesResponses"
+                        }
+                    },
+                    "403": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not get favorite properties",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/financial-information": {
+            "get": {
+                "description": "Get current user financial information",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get current user financial information *use cookies*",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserFinancialInformations"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user id",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "403": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the current user financial information with data from the body",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update the current user financial information *use cookies*",
+                "parameters": [
+                    {
+                        "description": "User financial information",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserFinancialInformations"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User financial information updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user financial information",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "403": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update user financial information",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/personal-information": {
+            "put": {
+                "description": "Update specifying userId with formData **\\***upload profile image in formData with field ` + "`" + `profile_image` + "`" + `. Available formats are .png / .jpg / .jpeg",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update current user personal information *use cookies*",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "John",
+                        "name": "first_name",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "Doe",
+                        "name": "last_name",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "0812345678",
+                        "name": "phone_number",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User personal information updated",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user info",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Could not update user",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/properties": {
+            "get": {
+                "description": "Get all properties owned by the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "property"
+                ],
+                "summary": "Get my properties *use cookies*",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Pagination limit per page, max 50, default 20",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Pagination page index as 1-based index, default 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort in format ` + "`" + `\u003cjson_field\u003e:\u003cdirection\u003e` + "`" + ` where direction can only be ` + "`" + `desc` + "`" + ` or ` + "`" + `asc` + "`" + `. Ex. ` + "`" + `?sort=selling_property.price:desc` + "`" + `",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MyPropertiesResponses"
+                        }
+                    },
+                    "403": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/registered": {
+            "get": {
+                "description": "Get user registered type",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user registered type *use cookies*",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sessions"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/me/verify": {
+            "post": {
+                "description": "Verify user by citizen id and citizen id image",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Verify user *use cookies*",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "1100111111111",
+                        "name": "citizen_id",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verified",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponses"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users": {
+            "get": {
+                "description": "Get all users",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get all users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Users"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponses"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "enums.AgreementStatus": {
+            "type": "string",
+            "enum": [
+                "AWAITING_DEPOSIT",
+                "AWAITING_PAYMENT",
+                "RENTING",
+                "CANCELLED",
+                "OVERDUE",
+                "ARCHIVED"
+            ],
+            "x-enum-varnames": [
+                "AwaitingDepositAgreement",
+                "AwaitingPaymentAgreement",
+                "RentingAgreement",
+                "CancelledAgreement",
+                "OverdueAgreement",
+                "ArchivedAgreement"
+            ]
+        },
+        "enums.AgreementTypes": {
+            "type": "string",
+            "enum": [
+                "SELLING",
+                "RENTING"
+            ],
+            "x-enum-varnames": [
+                "AgreementForSell",
+                "AgreementForRent"
+            ]
+        },
+        "enums.AppointmentStatus": {
+            "type": "string",
+            "enum": [
+                "PENDING",
+                "CONFIRMED",
+                "REJECTED",
+                "CANCELLED",
+                "ARCHIVED"
+            ],
+            "x-enum-varnames": [
+                "PendingAppointment",
+                "ConfirmedAppointment",
+                "RejectedAppointment",
+                "CancelledAppointment",
+                "ArchivedAppointment"
+            ]
+        },
+        "enums.BankNames": {
+            "type": "string",
+            "enum": [
+                "KBANK",
+                "BBL",
+                "KTB",
+                "BAY",
+                "CIMB",
+                "TTB",
+                "SCB",
+                "GSB",
+                ""
+            ],
+            "x-enum-comments": {
+                "BAY": "\"BANK OF AYUDHYA\"",
+                "BBL": "\"BANGKOK BANK\"",
+                "CIMB": "\"CIMB THAI BANK\"",
+                "GSB": "\"GOVERNMENT SAVINGS BANK\"",
+                "KBANK": "\"KASIKORN BANK\"",
+                "KTB": "\"KRUNG THAI BANK\"",
+                "NULL": "\"BANK NOT SELECTED\"",
+                "SCB": "\"SIAM COMMERCIAL BANK\"",
+                "TTB": "\"TMBTHANACHART BANK\""
+            },
+            "x-enum-varnames": [
+                "KBANK",
+                "BBL",
+                "KTB",
+                "BAY",
+                "CIMB",
+                "TTB",
+                "SCB",
+                "GSB",
+                "NULL"
+            ]
+        },
+        "enums.CardColors": {
+            "type": "string",
+            "enum": [
+                "LIGHT_BLUE",
+                "BLUE",
+                "DARK_BLUE",
+                "VERY_DARK_BLUE"
+            ],
+            "x-enum-varnames": [
+                "LIGHT_BLUE",
+                "BLUE",
+                "DARK_BLUE",
                 "VERY_DARK_BLUE"
             ]
         },
-        "enums.FloorSizeUnits": {
-            "type": "string",
-            "enum": [
-                "SQM",
-                "SQFT"
-            ],
-            "x-enum-varnames": [
-                "SQM",
-                "SQFT"
-            ]
+        "enums.ChatReportStatus": {
+            "type": "string",
+            "enum": [
+                "PENDING",
+                "REVIEWED",
+                "DISMISSED"
+            ],
+            "x-enum-varnames": [
+                "PendingChatReport",
+                "ReviewedChatReport",
+                "DismissedChatReport"
+            ]
+        },
+        "enums.FloorSizeUnits": {
+            "type": "string",
+            "enum": [
+                "SQM",
+                "SQFT"
+            ],
+            "x-enum-varnames": [
+                "SQM",
+                "SQFT"
+            ]
+        },
+        "enums.Furnishing": {
+            "type": "string",
+            "enum": [
+                "UNFURNISHED",
+                "PARTIALLY_FURNISHED",
+                "FULLY_FURNISHED",
+                "READY_TO_MOVE_IN"
+            ],
+            "x-enum-varnames": [
+                "UNFURNISHED",
+                "PARTIALLY_FURNISHED",
+                "FULLY_FURNISHED",
+                "READY_TO_MOVE_IN"
+            ]
+        },
+        "enums.InstallmentStatus": {
+            "type": "string",
+            "enum": [
+                "PAID",
+                "UNPAID",
+                "OVERDUE"
+            ],
+            "x-enum-varnames": [
+                "PaidInstallment",
+                "UnpaidInstallment",
+                "OverdueInstallment"
+            ]
+        },
+        "enums.MessageActionStatus": {
+            "type": "string",
+            "enum": [
+                "PENDING",
+                "ACCEPTED",
+                "REJECTED",
+                "PAID"
+            ],
+            "x-enum-varnames": [
+                "PendingAction",
+                "AcceptedAction",
+                "RejectedAction",
+                "PaidAction"
+            ]
+        },
+        "enums.MessageActionTypes": {
+            "type": "string",
+            "enum": [
+                "APPOINTMENT_REQUEST",
+                "AGREEMENT_OFFER",
+                "PAYMENT_REQUEST"
+            ],
+            "x-enum-varnames": [
+                "AppointmentRequestAction",
+                "AgreementOfferAction",
+                "PaymentRequestAction"
+            ]
+        },
+        "enums.PaymentMethods": {
+            "type": "string",
+            "enum": [
+                "CREDIT_CARD",
+                "PROMPTPAY"
+            ],
+            "x-enum-varnames": [
+                "CREDIT_CARD",
+                "PROMPTPAY"
+            ]
+        },
+        "enums.PaymentStatus": {
+            "type": "string",
+            "enum": [
+                "PENDING",
+                "SUCCEEDED",
+                "FAILED",
+                "EXPIRED",
+                "PARTIALLY_REFUNDED",
+                "REFUNDED"
+            ],
+            "x-enum-varnames": [
+                "PendingPayment",
+                "SucceededPayment",
+                "FailedPayment",
+                "ExpiredPayment",
+                "PartiallyRefundedPayment",
+                "RefundedPayment"
+            ]
+        },
+        "enums.PaymentTypes": {
+            "type": "string",
+            "enum": [
+                "DEPOSIT",
+                "INSTALLMENT",
+                "BALANCE"
+            ],
+            "x-enum-varnames": [
+                "DepositPayment",
+                "InstallmentPayment",
+                "BalancePayment"
+            ]
+        },
+        "enums.PayoutStatus": {
+            "type": "string",
+            "enum": [
+                "PENDING",
+                "PAID",
+                "FAILED"
+            ],
+            "x-enum-varnames": [
+                "PendingPayout",
+                "PaidPayout",
+                "FailedPayout"
+            ]
+        },
+        "enums.PropertyTypes": {
+            "type": "string",
+            "enum": [
+                "CONDOMINIUM",
+                "APARTMENT",
+                "SEMI-DETACHED_HOUSE",
+                "HOUSE",
+                "SERVICED_APARTMENT",
+                "TOWNHOUSE"
+            ],
+            "x-enum-varnames": [
+                "CONDOMINIUM",
+                "APARTMENT",
+                "SEMI_DETACHED_HOUSE",
+                "HOUSE",
+                "SERVICED_APARTMENT",
+                "TOWNHOUSE"
+            ]
+        },
+        "enums.RefundStatus": {
+            "type": "string",
+            "enum": [
+                "PENDING",
+                "SUCCEEDED",
+                "FAILED"
+            ],
+            "x-enum-varnames": [
+                "PendingRefund",
+                "SucceededRefund",
+                "FailedRefund"
+            ]
+        },
+        "enums.RegisteredTypes": {
+            "type": "string",
+            "enum": [
+                "EMAIL",
+                "GOOGLE"
+            ],
+            "x-enum-varnames": [
+                "EMAIL",
+                "GOOGLE"
+            ]
+        },
+        "enums.SessionType": {
+            "type": "string",
+            "enum": [
+                "REGISTER",
+                "LOGIN"
+            ],
+            "x-enum-varnames": [
+                "SessionRegister",
+                "SessionLogin"
+            ]
+        },
+        "models.AddingConversationMembers": {
+            "type": "object",
+            "properties": {
+                "user_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.AgreementDetails": {
+            "type": "object",
+            "properties": {
+                "agreement_date": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "agreement_id": {
+                    "type": "string",
+                    "example": "00000000-0000-0000-0000-000000000000"
+                },
+                "agreement_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.AgreementTypes"
+                        }
+                    ],
+                    "example": "SELLING"
+                },
+                "cancelled_message": {
+                    "type": "string",
+                    "example": "This is cancelled message."
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deposit_amount": {
+                    "type": "number",
+                    "example": 1000000
+                },
+                "dweller": {
+                    "$ref": "#/definitions/models.DwellerAgreementDetails"
+                },
+                "owner": {
+                    "$ref": "#/definitions/models.OwnerAgreementDetails"
+                },
+                "payment_duration": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "payment_per_month": {
+                    "type": "number",
+                    "example": 1000000
+                },
+                "property": {
+                    "$ref": "#/definitions/models.PropertyAgreementDetails"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.AgreementStatus"
+                        }
+                    ],
+                    "example": "AWAITING_DEPOSIT"
+                },
+                "total_payment": {
+                    "type": "number",
+                    "example": 12000000
+                }
+            }
+        },
+        "models.AgreementLists": {
+            "type": "object",
+            "properties": {
+                "agreement_date": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "agreement_id": {
+                    "type": "string",
+                    "example": "00000000-0000-0000-0000-000000000000"
+                },
+                "agreement_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.AgreementTypes"
+                        }
+                    ],
+                    "example": "SELLING"
+                },
+                "cancelled_message": {
+                    "type": "string",
+                    "example": "This is cancelled message."
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "owner": {
+                    "$ref": "#/definitions/models.OwnerAgreementLists"
+                },
+                "property": {
+                    "$ref": "#/definitions/models.PropertyAgreementLists"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.AgreementStatus"
+                        }
+                    ],
+                    "example": "AWAITING_DEPOSIT"
+                }
+            }
+        },
+        "models.Agreements": {
+            "type": "object",
+            "properties": {
+                "agreement_date": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
+                },
+                "agreement_id": {
+                    "type": "string"
+                },
+                "agreement_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.AgreementTypes"
+                        }
+                    ],
+                    "example": "SELLING"
+                },
+                "cancelled_message": {
+                    "type": "string",
+                    "example": "This is cancelled message."
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deposit_amount": {
+                    "type": "number",
+                    "example": 1000000
+                },
+                "dweller_user_id": {
+                    "type": "string",
+                    "example": "00000000-0000-0000-0000-000000000000"
+                },
+                "owner_user_id": {
+                    "type": "string",
+                    "example": "00000000-0000-0000-0000-000000000000"
+                },
+                "payment_duration": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "payment_per_month": {
+                    "type": "number",
+                    "example": 1000000
+                },
+                "property_id": {
+                    "type": "string",
+                    "example": "00000000-0000-0000-0000-000000000000"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.AgreementStatus"
+                        }
+                    ],
+                    "example": "AWAITING_DEPOSIT"
+                },
+                "total_payment": {
+                    "type": "number",
+                    "example": 12000000
+                }
+            }
+        },
+        "models.AllPropertiesResponses": {
+            "type": "object",
+            "properties": {
+                "properties": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Properties"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.AppointmentDetails": {
+            "type": "object",
+            "properties": {
+                "appointment_date": {
+                    "type": "string",
+                    "example": "2024-02-18T11:00:00Z"
+                },
+                "appointment_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "cancelled_message": {
+                    "type": "string",
+                    "example": "This is a cancelled message"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dweller": {
+                    "$ref": "#/definitions/models.DwellerAppointmentDetails"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "This is a note"
+                },
+                "owner": {
+                    "$ref": "#/definitions/models.OwnerAppointmentDetails"
+                },
+                "property": {
+                    "$ref": "#/definitions/models.PropertyAppointmentDetails"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.AppointmentStatus"
+                        }
+                    ],
+                    "example": "PENDING"
+                }
+            }
+        },
+        "models.AppointmentLists": {
+            "type": "object",
+            "properties": {
+                "appointment_date": {
+                    "type": "string",
+                    "example": "2024-02-18T11:00:00Z"
+                },
+                "appointment_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "cancelled_message": {
+                    "type": "string",
+                    "example": "This is a cancelled message"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "This is a note"
+                },
+                "owner": {
+                    "$ref": "#/definitions/models.OwnerAppointmentLists"
+                },
+                "property": {
+                    "$ref": "#/definitions/models.PropertyAppointmentLists"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.AppointmentStatus"
+                        }
+                    ],
+                    "example": "PENDING"
+                }
+            }
+        },
+        "models.BlockedUsers": {
+            "type": "object",
+            "properties": {
+                "blocked_at": {
+                    "type": "string",
+                    "example": "2024-02-22T03:06:53.313735Z"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Doe"
+                },
+                "profile_image_url": {
+                    "type": "string",
+                    "example": "www.image.com/profile"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
+        },
+        "models.CallbackResponses": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "johnd@email.com"
+                },
+                "registered_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.RegisteredTypes"
+                        }
+                    ],
+                    "example": "EMAIL / GOOGLE"
+                },
+                "session_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.SessionType"
+                        }
+                    ],
+                    "example": "REGISTER / LOGIN"
+                }
+            }
         },
-        "enums.Furnishing": {
-            "type": "string",
-            "enum": [
-                "UNFURNISHED",
-                "PARTIALLY_FURNISHED",
-                "FULLY_FURNISHED",
-                "READY_TO_MOVE_IN"
-            ],
-            "x-enum-varnames": [
-                "UNFURNISHED",
-                "PARTIALLY_FURNISHED",
-                "FULLY_FURNISHED",
-                "READY_TO_MOVE_IN"
-            ]
+        "models.ChatHistories": {
+            "type": "object",
+            "properties": {
+                "after_cursor": {
+                    "type": "string",
+                    "example": "MjAyNC0wMi0yMlQwMzowNjo1My4zMTM3MzVafDI3Yjc5YjE1LWE1NmYtNDY0YS05MGY3LWJhYjUxNWJhNGMwMg"
+                },
+                "before_cursor": {
+                    "type": "string",
+                    "example": "MjAyNC0wMi0yMlQwMzowNjo1My4zMTM3MzVafDI3Yjc5YjE1LWE1NmYtNDY0YS05MGY3LWJhYjUxNWJhNGMwMg"
+                },
+                "has_more_after": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "has_more_before": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Messages"
+                    }
+                }
+            }
         },
-        "enums.PaymentMethods": {
-            "type": "string",
-            "enum": [
-                "CREDIT_CARD",
-                "PROMPTPAY"
-            ],
-            "x-enum-varnames": [
-                "CREDIT_CARD",
-                "PROMPTPAY"
-            ]
+        "models.ChatPreviews": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "hello, world"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "is_group": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Doe"
+                },
+                "muted": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Flatmates"
+                },
+                "profile_image_url": {
+                    "type": "string",
+                    "example": "www.image.com/profile"
+                },
+                "property_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "property_image_url": {
+                    "type": "string",
+                    "example": "www.image.com/property"
+                },
+                "property_name": {
+                    "type": "string",
+                    "example": "Supalai"
+                },
+                "unread_messages": {
+                    "type": "integer",
+                    "example": 9
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
         },
-        "enums.PropertyTypes": {
-            "type": "string",
-            "enum": [
-                "CONDOMINIUM",
-                "APARTMENT",
-                "SEMI-DETACHED_HOUSE",
-                "HOUSE",
-                "SERVICED_APARTMENT",
-                "TOWNHOUSE"
-            ],
-            "x-enum-varnames": [
-                "CONDOMINIUM",
-                "APARTMENT",
-                "SEMI_DETACHED_HOUSE",
-                "HOUSE",
-                "SERVICED_APARTMENT",
-                "TOWNHOUSE"
-            ]
+        "models.ChatReports": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-02-22T03:06:53.313735Z"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Asked me to transfer the deposit outside the app"
+                },
+                "report_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "reported_user_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "reporter_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.ChatReportStatus"
+                        }
+                    ],
+                    "example": "PENDING"
+                }
+            }
         },
-        "enums.RegisteredTypes": {
-            "type": "string",
-            "enum": [
-                "EMAIL",
-                "GOOGLE"
-            ],
-            "x-enum-varnames": [
-                "EMAIL",
-                "GOOGLE"
-            ]
+        "models.ChatSearchHits": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Messages"
+                    }
+                },
+                "before": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Messages"
+                    }
+                },
+                "chat": {
+                    "$ref": "#/definitions/models.ChatSearchPreviews"
+                },
+                "message": {
+                    "$ref": "#/definitions/models.Messages"
+                }
+            }
+        },
+        "models.ChatSearchPreviews": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "is_group": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Doe"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Flatmates"
+                },
+                "profile_image_url": {
+                    "type": "string",
+                    "example": "www.image.com/profile"
+                },
+                "property_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "property_name": {
+                    "type": "string",
+                    "example": "Supalai"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
+        },
+        "models.ChatSearchResults": {
+            "type": "object",
+            "properties": {
+                "hits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChatSearchHits"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "MjAyNC0wMi0yMlQwMzowNjo1My4zMTM3MzVafDI3Yjc5YjE1LWE1NmYtNDY0YS05MGY3LWJhYjUxNWJhNGMwMg"
+                }
+            }
         },
-        "enums.SessionType": {
-            "type": "string",
-            "enum": [
-                "REGISTER",
-                "LOGIN"
-            ],
-            "x-enum-varnames": [
-                "SessionRegister",
-                "SessionLogin"
-            ]
+        "models.ChatTranscripts": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "exported_at": {
+                    "type": "string",
+                    "example": "2024-02-22T03:06:53.313735Z"
+                },
+                "exported_by": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "is_group": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TranscriptMessages"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Viewing on Saturday"
+                },
+                "participants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TranscriptParticipants"
+                    }
+                },
+                "property": {
+                    "$ref": "#/definitions/models.TranscriptProperties"
+                }
+            }
         },
-        "models.AgreementDetails": {
+        "models.Conversations": {
             "type": "object",
             "properties": {
-                "agreement_date": {
+                "chat_id": {
                     "type": "string",
-                    "example": "2021-01-01T00:00:00Z"
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 },
-                "agreement_id": {
+                "created_at": {
                     "type": "string",
-                    "example": "00000000-0000-0000-0000-000000000000"
+                    "example": "2024-02-22T03:06:53.313735Z"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "is_group": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Flatmates"
+                },
+                "participants": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "property_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
+        },
+        "models.CreatingAgreements": {
+            "type": "object",
+            "properties": {
+                "agreement_date": {
+                    "type": "string",
+                    "example": "2021-01-01T00:00:00Z"
                 },
                 "agreement_type": {
                     "allOf": [
@@ -2730,511 +4791,832 @@ const docTemplate = `{
                     ],
                     "example": "SELLING"
                 },
-                "cancelled_message": {
+                "deposit_amount": {
+                    "type": "number",
+                    "example": 1000000
+                },
+                "dweller_user_id": {
                     "type": "string",
-                    "example": "This is cancelled message."
+                    "example": "00000000-0000-0000-0000-000000000000"
                 },
-                "created_at": {
-                    "type": "string"
+                "payment_duration": {
+                    "type": "integer",
+                    "example": 12
                 },
-                "deposit_amount": {
+                "payment_per_month": {
                     "type": "number",
                     "example": 1000000
                 },
-                "dweller": {
-                    "$ref": "#/definitions/models.DwellerAgreementDetails"
+                "property_id": {
+                    "type": "string",
+                    "example": "00000000-0000-0000-0000-000000000000"
                 },
-                "owner": {
-                    "$ref": "#/definitions/models.OwnerAgreementDetails"
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.AgreementStatus"
+                        }
+                    ],
+                    "example": "AWAITING_DEPOSIT"
                 },
-                "payment_duration": {
+                "total_payment": {
+                    "type": "number",
+                    "example": 12000000
+                }
+            }
+        },
+        "models.CreatingAppointments": {
+            "type": "object",
+            "properties": {
+                "appointment_dates": {
+                    "type": "string",
+                    "example": "2024-02-18T11:00:00Z"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "This is a note"
+                },
+                "owner_user_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "property_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
+        },
+        "models.CreatingChatReports": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Asked me to transfer the deposit outside the app"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
+        },
+        "models.CreatingConversations": {
+            "type": "object",
+            "properties": {
+                "property_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
+        },
+        "models.CreatingGroupConversations": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Flatmates"
+                },
+                "property_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "user_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.CreatingPayments": {
+            "type": "object",
+            "properties": {
+                "agreement_id": {
+                    "type": "string",
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
+                },
+                "installment": {
                     "type": "integer",
-                    "example": 12
+                    "example": 2
+                },
+                "payment_method": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.PaymentMethods"
+                        }
+                    ],
+                    "example": "PROMPTPAY"
+                }
+            }
+        },
+        "models.CreatingPushSubscriptions": {
+            "type": "object",
+            "properties": {
+                "endpoint": {
+                    "type": "string",
+                    "example": "https://fcm.googleapis.com/fcm/send/abc123"
+                },
+                "keys": {
+                    "type": "object",
+                    "properties": {
+                        "auth": {
+                            "type": "string",
+                            "example": "tBHItJI5svbpez7KI4CCXg"
+                        },
+                        "p256dh": {
+                            "type": "string",
+                            "example": "BNcRdreALRFXTkOOUHK1EtK2wtaz5Ry4YfYCA_0QTpQtUbVlUls0VJXg7A8u-Ts1XbjhazAkj7I99e8QcYP7DkM"
+                        }
+                    }
+                }
+            }
+        },
+        "models.CreatingRefunds": {
+            "type": "object",
+            "properties": {
+                "deductions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RefundDeductions"
+                    }
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Moved out on 1 Mar"
+                }
+            }
+        },
+        "models.CreditCards": {
+            "type": "object",
+            "required": [
+                "card_nickname",
+                "cardholder_name",
+                "tag_number"
+            ],
+            "properties": {
+                "card_color": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.CardColors"
+                        }
+                    ],
+                    "example": "BLUE"
+                },
+                "card_nickname": {
+                    "type": "string",
+                    "example": "John's Card"
+                },
+                "card_number": {
+                    "type": "string",
+                    "example": "1234567890123456"
+                },
+                "cardholder_name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "cvv": {
+                    "type": "string",
+                    "example": "123"
+                },
+                "expire_month": {
+                    "type": "string",
+                    "example": "12"
+                },
+                "expire_year": {
+                    "type": "string",
+                    "example": "2023"
+                },
+                "tag_number": {
+                    "type": "integer",
+                    "maximum": 4,
+                    "minimum": 1,
+                    "example": 1
+                }
+            }
+        },
+        "models.DeletingPushSubscriptions": {
+            "type": "object",
+            "properties": {
+                "endpoint": {
+                    "type": "string",
+                    "example": "https://fcm.googleapis.com/fcm/send/abc123"
+                }
+            }
+        },
+        "models.DwellerAgreementDetails": {
+            "type": "object",
+            "properties": {
+                "dweller_first_name": {
+                    "type": "string",
+                    "example": "John"
                 },
-                "payment_per_month": {
-                    "type": "number",
-                    "example": 1000000
+                "dweller_last_name": {
+                    "type": "string",
+                    "example": "Doe"
                 },
-                "property": {
-                    "$ref": "#/definitions/models.PropertyAgreementDetails"
+                "dweller_phone_number": {
+                    "type": "string",
+                    "example": "0812345678"
                 },
-                "status": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.AgreementStatus"
-                        }
-                    ],
-                    "example": "AWAITING_DEPOSIT"
+                "dweller_profile_image_url": {
+                    "type": "string",
+                    "example": "https://www.example.com/image.jpg"
                 },
-                "total_payment": {
-                    "type": "number",
-                    "example": 12000000
+                "dweller_user_id": {
+                    "type": "string",
+                    "example": "00000000-0000-0000-0000-000000000000"
                 }
             }
         },
-        "models.AgreementLists": {
+        "models.DwellerAppointmentDetails": {
             "type": "object",
             "properties": {
-                "agreement_date": {
+                "dweller_first_name": {
                     "type": "string",
-                    "example": "2021-01-01T00:00:00Z"
+                    "example": "John"
                 },
-                "agreement_id": {
+                "dweller_last_name": {
                     "type": "string",
-                    "example": "00000000-0000-0000-0000-000000000000"
-                },
-                "agreement_type": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.AgreementTypes"
-                        }
-                    ],
-                    "example": "SELLING"
+                    "example": "Doe"
                 },
-                "cancelled_message": {
+                "dweller_phone_number": {
                     "type": "string",
-                    "example": "This is cancelled message."
+                    "example": "0812345678"
                 },
-                "created_at": {
-                    "type": "string"
+                "dweller_profile_image_url": {
+                    "type": "string",
+                    "example": "https://image_url.com/abcd"
                 },
-                "owner": {
-                    "$ref": "#/definitions/models.OwnerAgreementLists"
+                "dweller_user_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                }
+            }
+        },
+        "models.ErrorResponses": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 500
                 },
-                "property": {
-                    "$ref": "#/definitions/models.PropertyAgreementLists"
+                "message": {
+                    "type": "string",
+                    "example": "internal server error"
                 },
-                "status": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.AgreementStatus"
-                        }
-                    ],
-                    "example": "AWAITING_DEPOSIT"
+                "name": {
+                    "type": "string",
+                    "example": "internal-server-error"
                 }
             }
         },
-        "models.Agreements": {
+        "models.Greetings": {
             "type": "object",
             "properties": {
-                "agreement_date": {
+                "message": {
                     "type": "string",
-                    "example": "2021-01-01T00:00:00Z"
+                    "example": "Hello, World"
+                }
+            }
+        },
+        "models.HistoryResponse": {
+            "type": "object",
+            "properties": {
+                "agreement_date": {
+                    "type": "string"
                 },
                 "agreement_id": {
                     "type": "string"
                 },
                 "agreement_type": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.AgreementTypes"
-                        }
-                    ],
-                    "example": "SELLING"
+                    "$ref": "#/definitions/enums.AgreementTypes"
                 },
                 "cancelled_message": {
-                    "type": "string",
-                    "example": "This is cancelled message."
+                    "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
                 "deposit_amount": {
-                    "type": "number",
-                    "example": 1000000
+                    "type": "number"
                 },
                 "dweller_user_id": {
-                    "type": "string",
-                    "example": "00000000-0000-0000-0000-000000000000"
+                    "type": "string"
+                },
+                "is_success": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
                 },
                 "owner_user_id": {
-                    "type": "string",
-                    "example": "00000000-0000-0000-0000-000000000000"
+                    "type": "string"
                 },
                 "payment_duration": {
-                    "type": "integer",
-                    "example": 12
+                    "type": "integer"
+                },
+                "payment_id": {
+                    "type": "string"
+                },
+                "payment_method": {
+                    "$ref": "#/definitions/enums.PaymentMethods"
                 },
                 "payment_per_month": {
-                    "type": "number",
-                    "example": 1000000
+                    "type": "number"
+                },
+                "payment_status": {
+                    "$ref": "#/definitions/enums.PaymentStatus"
+                },
+                "payment_type": {
+                    "$ref": "#/definitions/enums.PaymentTypes"
+                },
+                "price": {
+                    "type": "number"
                 },
                 "property_id": {
-                    "type": "string",
-                    "example": "00000000-0000-0000-0000-000000000000"
+                    "type": "string"
                 },
-                "status": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.AgreementStatus"
-                        }
-                    ],
-                    "example": "AWAITING_DEPOSIT"
+                "refunded_amount": {
+                    "type": "number"
                 },
-                "total_payment": {
-                    "type": "number",
-                    "example": 12000000
-                }
-            }
-        },
-        "models.AllPropertiesResponses": {
-            "type": "object",
-            "properties": {
-                "properties": {
+                "refunds": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Properties"
+                        "$ref": "#/definitions/models.Refunds"
                     }
                 },
-                "total": {
-                    "type": "integer",
-                    "example": 2
+                "status": {
+                    "$ref": "#/definitions/enums.AgreementStatus"
+                },
+                "total_payment": {
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "models.AppointmentDetails": {
+        "models.MessageActions": {
             "type": "object",
             "properties": {
-                "appointment_date": {
-                    "type": "string",
-                    "example": "2024-02-18T11:00:00Z"
+                "action_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.MessageActionTypes"
+                        }
+                    ],
+                    "example": "APPOINTMENT_REQUEST"
                 },
-                "appointment_id": {
+                "actor_id": {
                     "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
                 },
-                "cancelled_message": {
-                    "type": "string",
-                    "example": "This is a cancelled message"
+                "amount": {
+                    "type": "number",
+                    "example": 12000
                 },
-                "created_at": {
-                    "type": "string"
+                "appointment_date": {
+                    "type": "string",
+                    "example": "2024-02-18T11:00:00Z"
                 },
-                "dweller": {
-                    "$ref": "#/definitions/models.DwellerAppointmentDetails"
+                "installment": {
+                    "type": "integer",
+                    "example": 3
                 },
                 "note": {
                     "type": "string",
-                    "example": "This is a note"
-                },
-                "owner": {
-                    "$ref": "#/definitions/models.OwnerAppointmentDetails"
-                },
-                "property": {
-                    "$ref": "#/definitions/models.PropertyAppointmentDetails"
+                    "example": "I am not available that day"
                 },
                 "status": {
                     "allOf": [
                         {
-                            "$ref": "#/definitions/enums.AppointmentStatus"
+                            "$ref": "#/definitions/enums.MessageActionStatus"
                         }
                     ],
                     "example": "PENDING"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-02-22T03:06:53.313735Z"
                 }
             }
         },
-        "models.AppointmentLists": {
+        "models.MessageAttatchments": {
             "type": "object",
             "properties": {
-                "appointment_date": {
-                    "type": "string",
-                    "example": "2024-02-18T11:00:00Z"
+                "agreement_id": {
+                    "type": "string"
                 },
                 "appointment_id": {
-                    "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                    "type": "string"
                 },
-                "cancelled_message": {
+                "property_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MessageFiles": {
+            "type": "object",
+            "properties": {
+                "content_type": {
                     "type": "string",
-                    "example": "This is a cancelled message"
+                    "example": "application/pdf"
                 },
                 "created_at": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "2024-02-22T03:06:53.313735Z"
                 },
-                "note": {
+                "file_id": {
+                    "type": "string",
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
+                },
+                "file_name": {
+                    "type": "string",
+                    "example": "payslip.pdf"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 102400
+                },
+                "thumbnail_url": {
+                    "type": "string",
+                    "example": "/api/v1/chats/files/27b79b15-a56f-464a-90f7-bab515ba4c02?thumbnail=true"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "/api/v1/chats/files/27b79b15-a56f-464a-90f7-bab515ba4c02"
+                }
+            }
+        },
+        "models.MessageReactions": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string",
-                    "example": "This is a note"
-                },
-                "owner": {
-                    "$ref": "#/definitions/models.OwnerAppointmentLists"
+                    "example": "2024-02-22T03:06:53.313735Z"
                 },
-                "property": {
-                    "$ref": "#/definitions/models.PropertyAppointmentLists"
+                "emoji": {
+                    "type": "string",
+                    "example": "👍"
                 },
-                "status": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.AppointmentStatus"
-                        }
-                    ],
-                    "example": "PENDING"
+                "user_id": {
+                    "type": "string",
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
                 }
             }
         },
-        "models.CallbackResponses": {
+        "models.MessageReceipts": {
             "type": "object",
             "properties": {
-                "email": {
+                "delivered_at": {
                     "type": "string",
-                    "example": "johnd@email.com"
+                    "example": "2024-02-22T03:06:53.313735Z"
                 },
-                "registered_type": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.RegisteredTypes"
-                        }
-                    ],
-                    "example": "EMAIL / GOOGLE"
+                "read_at": {
+                    "type": "string",
+                    "example": "2024-02-22T03:06:53.313735Z"
                 },
-                "session_type": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.SessionType"
-                        }
-                    ],
-                    "example": "REGISTER / LOGIN"
+                "user_id": {
+                    "type": "string",
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
                 }
             }
         },
-        "models.ChatPreviews": {
+        "models.MessageResponses": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Message sent successfully"
+                }
+            }
+        },
+        "models.Messages": {
             "type": "object",
             "properties": {
+                "action": {
+                    "$ref": "#/definitions/models.MessageActions"
+                },
+                "attatchment": {
+                    "$ref": "#/definitions/models.MessageAttatchments"
+                },
+                "author": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "chat_id": {
+                    "type": "string",
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
+                },
                 "content": {
                     "type": "string",
                     "example": "hello, world"
                 },
-                "first_name": {
+                "deleted_at": {
                     "type": "string",
-                    "example": "John"
+                    "example": "2024-02-22T03:06:53.313735Z"
                 },
-                "last_name": {
+                "edited_at": {
                     "type": "string",
-                    "example": "Doe"
+                    "example": "2024-02-22T03:06:53.313735Z"
                 },
-                "profile_image_url": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MessageFiles"
+                    }
+                },
+                "message_id": {
                     "type": "string",
-                    "example": "www.image.com/profile"
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
                 },
-                "unread_messages": {
-                    "type": "integer",
-                    "example": 9
+                "reactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MessageReactions"
+                    }
                 },
-                "user_id": {
+                "receipts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MessageReceipts"
+                    }
+                },
+                "sender_id": {
                     "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
+                },
+                "sent_at": {
+                    "type": "string",
+                    "example": "2024-02-22T03:06:53.313735Z"
                 }
             }
         },
-        "models.CreatingAgreements": {
+        "models.MyAgreementResponses": {
             "type": "object",
             "properties": {
-                "agreement_date": {
-                    "type": "string",
-                    "example": "2021-01-01T00:00:00Z"
-                },
-                "agreement_type": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.AgreementTypes"
-                        }
-                    ],
-                    "example": "SELLING"
+                "dweller_agreements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AgreementLists"
+                    }
                 },
-                "deposit_amount": {
-                    "type": "number",
-                    "example": 1000000
+                "owner_agreements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AgreementLists"
+                    }
+                }
+            }
+        },
+        "models.MyAppointmentResponses": {
+            "type": "object",
+            "properties": {
+                "dweller_appointments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AppointmentLists"
+                    }
                 },
-                "dweller_user_id": {
-                    "type": "string",
-                    "example": "00000000-0000-0000-0000-000000000000"
+                "owner_appointments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AppointmentLists"
+                    }
+                }
+            }
+        },
+        "models.MyFavoritePropertiesResponses": {
+            "type": "object",
+            "properties": {
+                "properties": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Properties"
+                    }
                 },
-                "payment_duration": {
+                "total": {
                     "type": "integer",
-                    "example": 12
-                },
-                "payment_per_month": {
-                    "type": "number",
-                    "example": 1000000
-                },
-                "property_id": {
-                    "type": "string",
-                    "example": "00000000-0000-0000-0000-000000000000"
-                },
-                "status": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.AgreementStatus"
-                        }
-                    ],
-                    "example": "AWAITING_DEPOSIT"
+                    "example": 2
+                }
+            }
+        },
+        "models.MyPaymentsResponse": {
+            "type": "object",
+            "properties": {
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Payments"
+                    }
+                }
+            }
+        },
+        "models.MyPropertiesResponses": {
+            "type": "object",
+            "properties": {
+                "properties": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Properties"
+                    }
                 },
-                "total_payment": {
-                    "type": "number",
-                    "example": 12000000
+                "total": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         },
-        "models.CreatingAppointments": {
+        "models.NotificationPreferences": {
             "type": "object",
             "properties": {
-                "appointment_dates": {
+                "digest_delay_minutes": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "email_digest": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "quiet_hours_end": {
                     "type": "string",
-                    "example": "2024-02-18T11:00:00Z"
+                    "example": "07:00"
                 },
-                "note": {
+                "quiet_hours_start": {
                     "type": "string",
-                    "example": "This is a note"
+                    "example": "22:00"
                 },
-                "owner_user_id": {
+                "timezone": {
                     "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                    "example": "Asia/Bangkok"
                 },
-                "property_id": {
+                "updated_at": {
                     "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                    "example": "2024-02-22T03:06:53.313735Z"
+                },
+                "web_push": {
+                    "type": "boolean",
+                    "example": true
                 }
             }
         },
-        "models.CreditCards": {
+        "models.OwnerAgreementDetails": {
             "type": "object",
             "properties": {
-                "card_color": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.CardColors"
-                        }
-                    ],
-                    "example": "BLUE"
-                },
-                "card_nickname": {
-                    "type": "string",
-                    "example": "John's Card"
-                },
-                "card_number": {
-                    "type": "string",
-                    "example": "1234567890123456"
-                },
-                "cardholder_name": {
+                "owner_first_name": {
                     "type": "string",
-                    "example": "John Doe"
+                    "example": "John"
                 },
-                "cvv": {
+                "owner_last_name": {
                     "type": "string",
-                    "example": "123"
+                    "example": "Doe"
                 },
-                "expire_month": {
+                "owner_phone_number": {
                     "type": "string",
-                    "example": "12"
+                    "example": "0812345678"
                 },
-                "expire_year": {
+                "owner_profile_image_url": {
                     "type": "string",
-                    "example": "2023"
+                    "example": "https://www.example.com/image.jpg"
                 },
-                "tag_number": {
-                    "type": "integer",
-                    "example": 1
+                "owner_user_id": {
+                    "type": "string",
+                    "example": "00000000-0000-0000-0000-000000000000"
                 }
             }
         },
-        "models.DwellerAgreementDetails": {
+        "models.OwnerAgreementLists": {
             "type": "object",
             "properties": {
-                "dweller_first_name": {
+                "owner_first_name": {
                     "type": "string",
                     "example": "John"
                 },
-                "dweller_last_name": {
+                "owner_last_name": {
                     "type": "string",
                     "example": "Doe"
                 },
-                "dweller_phone_number": {
-                    "type": "string",
-                    "example": "0812345678"
-                },
-                "dweller_profile_image_url": {
+                "owner_profile_image_url": {
                     "type": "string",
                     "example": "https://www.example.com/image.jpg"
                 },
-                "dweller_user_id": {
+                "owner_user_id": {
                     "type": "string",
                     "example": "00000000-0000-0000-0000-000000000000"
                 }
             }
         },
-        "models.DwellerAppointmentDetails": {
+        "models.OwnerAppointmentDetails": {
             "type": "object",
             "properties": {
-                "dweller_first_name": {
+                "owner_first_name": {
                     "type": "string",
                     "example": "John"
                 },
-                "dweller_last_name": {
+                "owner_last_name": {
                     "type": "string",
                     "example": "Doe"
                 },
-                "dweller_phone_number": {
+                "owner_phone_number": {
                     "type": "string",
                     "example": "0812345678"
                 },
-                "dweller_profile_image_url": {
+                "owner_profile_image_url": {
                     "type": "string",
                     "example": "https://image_url.com/abcd"
                 },
-                "dweller_user_id": {
+                "owner_user_id": {
                     "type": "string",
                     "example": "123e4567-e89b-12d3-a456-426614174000"
                 }
             }
         },
-        "models.ErrorResponses": {
+        "models.OwnerAppointmentLists": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 500
+                "owner_first_name": {
+                    "type": "string",
+                    "example": "John"
                 },
-                "message": {
+                "owner_last_name": {
                     "type": "string",
-                    "example": "internal server error"
+                    "example": "Doe"
                 },
-                "name": {
+                "owner_profile_image_url": {
                     "type": "string",
-                    "example": "internal-server-error"
+                    "example": "https://image_url.com/abcd"
+                },
+                "owner_user_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 }
             }
         },
-        "models.Greetings": {
+        "models.OwnerEarnings": {
             "type": "object",
             "properties": {
-                "message": {
+                "balance": {
+                    "type": "number",
+                    "example": 29100
+                },
+                "bank_account_number": {
                     "type": "string",
-                    "example": "Hello, World"
+                    "example": "******7890"
+                },
+                "bank_name": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.BankNames"
+                        }
+                    ],
+                    "example": "KBANK"
+                },
+                "earned": {
+                    "type": "number",
+                    "example": 87300
+                },
+                "fees": {
+                    "type": "number",
+                    "example": 2700
+                },
+                "paid_out": {
+                    "type": "number",
+                    "example": 58200
+                },
+                "payouts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Payouts"
+                    }
+                },
+                "pending": {
+                    "type": "number",
+                    "example": 0
+                },
+                "refunded": {
+                    "type": "number",
+                    "example": 0
                 }
             }
         },
-        "models.HistoryResponse": {
+        "models.Payments": {
             "type": "object",
             "properties": {
-                "agreement_date": {
-                    "type": "string"
-                },
                 "agreement_id": {
                     "type": "string"
                 },
-                "agreement_type": {
-                    "$ref": "#/definitions/enums.AgreementTypes"
-                },
-                "cancelled_message": {
-                    "type": "string"
-                },
                 "created_at": {
                     "type": "string"
                 },
-                "deposit_amount": {
-                    "type": "number"
+                "currency": {
+                    "type": "string",
+                    "example": "THB"
                 },
-                "dweller_user_id": {
-                    "type": "string"
+                "installment": {
+                    "type": "integer"
                 },
                 "is_success": {
                     "type": "boolean"
@@ -3242,705 +5624,862 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
-                "owner_user_id": {
-                    "type": "string"
-                },
-                "payment_duration": {
-                    "type": "integer"
-                },
                 "payment_id": {
                     "type": "string"
                 },
                 "payment_method": {
                     "$ref": "#/definitions/enums.PaymentMethods"
                 },
-                "payment_per_month": {
-                    "type": "number"
+                "payment_type": {
+                    "$ref": "#/definitions/enums.PaymentTypes"
                 },
                 "price": {
                     "type": "number"
                 },
-                "property_id": {
-                    "type": "string"
+                "refunded_amount": {
+                    "type": "number"
                 },
                 "status": {
-                    "$ref": "#/definitions/enums.AgreementStatus"
-                },
-                "total_payment": {
-                    "type": "number"
+                    "$ref": "#/definitions/enums.PaymentStatus"
                 },
                 "user_id": {
                     "type": "string"
                 }
             }
         },
-        "models.MessageAttatchments": {
+        "models.Payouts": {
             "type": "object",
             "properties": {
-                "agreement_id": {
-                    "type": "string"
+                "amount": {
+                    "type": "number",
+                    "example": 29100
                 },
-                "appointment_id": {
-                    "type": "string"
+                "bank_account_number": {
+                    "type": "string",
+                    "example": "******7890"
                 },
-                "property_id": {
+                "bank_name": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.BankNames"
+                        }
+                    ],
+                    "example": "KBANK"
+                },
+                "created_at": {
                     "type": "string"
+                },
+                "failure_reason": {
+                    "type": "string",
+                    "example": "Account closed"
+                },
+                "paid_at": {
+                    "type": "string",
+                    "example": "2024-03-02T10:00:00Z"
+                },
+                "payout_id": {
+                    "type": "string",
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "KB20240301-000123"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.PayoutStatus"
+                        }
+                    ],
+                    "example": "PENDING"
                 }
             }
         },
-        "models.MessageResponses": {
+        "models.Properties": {
             "type": "object",
             "properties": {
-                "message": {
+                "address": {
                     "type": "string",
-                    "example": "Message sent successfully"
+                    "example": "123/4"
+                },
+                "alley": {
+                    "type": "string",
+                    "example": "Pattaya Nua 78"
+                },
+                "bathrooms": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "bedrooms": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Thailand"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "district": {
+                    "type": "string",
+                    "example": "Bang Phli"
+                },
+                "floor": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "floor_size": {
+                    "type": "number",
+                    "example": 123.45
+                },
+                "floor_size_unit": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.FloorSizeUnits"
+                        }
+                    ],
+                    "example": "SQM"
+                },
+                "furnishing": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.Furnishing"
+                        }
+                    ],
+                    "example": "UNFURNISHED"
+                },
+                "is_favorite": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "owner_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "postal_code": {
+                    "type": "string",
+                    "example": "69096"
+                },
+                "property_description": {
+                    "type": "string",
+                    "example": "Et sequi dolor praes"
+                },
+                "property_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "property_images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PropertyImages"
+                    }
+                },
+                "property_name": {
+                    "type": "string",
+                    "example": "Supalai"
+                },
+                "property_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.PropertyTypes"
+                        }
+                    ],
+                    "example": "CONDOMINIUM"
+                },
+                "province": {
+                    "type": "string",
+                    "example": "Pattaya"
+                },
+                "renting_property": {
+                    "$ref": "#/definitions/models.RentingProperties"
+                },
+                "selling_property": {
+                    "$ref": "#/definitions/models.SellingProperties"
+                },
+                "street": {
+                    "type": "string",
+                    "example": "Pattaya"
+                },
+                "sub_district": {
+                    "type": "string",
+                    "example": "Bang Bon"
+                },
+                "unit_number": {
+                    "type": "integer",
+                    "example": 123
                 }
             }
         },
-        "models.Messages": {
+        "models.PropertyAgreementDetails": {
             "type": "object",
             "properties": {
-                "attatchment": {
-                    "$ref": "#/definitions/models.MessageAttatchments"
-                },
-                "author": {
-                    "type": "boolean",
-                    "example": true
+                "address": {
+                    "type": "string",
+                    "example": "123/456 The Base Sukhumvit 77"
                 },
-                "chat_id": {
+                "alley": {
                     "type": "string",
-                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
+                    "example": "77"
                 },
-                "content": {
+                "country": {
                     "type": "string",
-                    "example": "hello, world"
+                    "example": "Thailand"
                 },
-                "message_id": {
+                "district": {
                     "type": "string",
-                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
+                    "example": "Bangkok"
                 },
-                "read_at": {
+                "postal_code": {
                     "type": "string",
-                    "example": "2024-02-22T03:06:53.313735Z"
+                    "example": "10110"
                 },
-                "sent_at": {
+                "price": {
+                    "type": "number",
+                    "example": 1000000
+                },
+                "price_per_month": {
+                    "type": "number",
+                    "example": 1000000
+                },
+                "property_id": {
                     "type": "string",
-                    "example": "2024-02-22T03:06:53.313735Z"
-                }
-            }
-        },
-        "models.MyAgreementResponses": {
-            "type": "object",
-            "properties": {
-                "dweller_agreements": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.AgreementLists"
-                    }
+                    "example": "00000000-0000-0000-0000-000000000000"
                 },
-                "owner_agreements": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.AgreementLists"
-                    }
-                }
-            }
-        },
-        "models.MyAppointmentResponses": {
-            "type": "object",
-            "properties": {
-                "dweller_appointments": {
+                "property_images": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.AppointmentLists"
+                        "$ref": "#/definitions/models.PropertyImageAgreements"
                     }
                 },
-                "owner_appointments": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.AppointmentLists"
-                    }
+                "property_name": {
+                    "type": "string",
+                    "example": "The Base Sukhumvit 77"
+                },
+                "property_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.PropertyTypes"
+                        }
+                    ],
+                    "example": "CONDO"
+                },
+                "province": {
+                    "type": "string",
+                    "example": "Bangkok"
+                },
+                "street": {
+                    "type": "string",
+                    "example": "Sukhumvit"
+                },
+                "sub_district": {
+                    "type": "string",
+                    "example": "Phra Khanong"
                 }
             }
         },
-        "models.MyFavoritePropertiesResponses": {
+        "models.PropertyAgreementLists": {
             "type": "object",
             "properties": {
-                "properties": {
+                "property_id": {
+                    "type": "string",
+                    "example": "00000000-0000-0000-0000-000000000000"
+                },
+                "property_images": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Properties"
+                        "$ref": "#/definitions/models.PropertyImageAgreements"
                     }
                 },
-                "total": {
-                    "type": "integer",
-                    "example": 2
+                "property_name": {
+                    "type": "string",
+                    "example": "The Base Sukhumvit 77"
+                },
+                "property_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.PropertyTypes"
+                        }
+                    ],
+                    "example": "CONDO"
                 }
             }
         },
-        "models.MyPaymentsResponse": {
+        "models.PropertyAppointmentDetails": {
             "type": "object",
             "properties": {
-                "payments": {
+                "address": {
+                    "type": "string",
+                    "example": "123/4"
+                },
+                "alley": {
+                    "type": "string",
+                    "example": "Pattaya Nua 78"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "Thailand"
+                },
+                "district": {
+                    "type": "string",
+                    "example": "Bang Phli"
+                },
+                "postal_code": {
+                    "type": "string",
+                    "example": "69096"
+                },
+                "price": {
+                    "type": "number",
+                    "example": 12345.67
+                },
+                "price_per_month": {
+                    "type": "number",
+                    "example": 12345.67
+                },
+                "property_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "property_images": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Payments"
+                        "$ref": "#/definitions/models.PropertyImageAppointments"
                     }
+                },
+                "property_name": {
+                    "type": "string",
+                    "example": "The Base Sukhumvit 77"
+                },
+                "property_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.PropertyTypes"
+                        }
+                    ],
+                    "example": "CONDO"
+                },
+                "province": {
+                    "type": "string",
+                    "example": "Pattaya"
+                },
+                "street": {
+                    "type": "string",
+                    "example": "Pattaya"
+                },
+                "sub_district": {
+                    "type": "string",
+                    "example": "Bang Bon"
                 }
             }
         },
-        "models.MyPropertiesResponses": {
+        "models.PropertyAppointmentLists": {
             "type": "object",
             "properties": {
-                "properties": {
+                "property_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "property_images": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Properties"
+                        "$ref": "#/definitions/models.PropertyImageAppointments"
                     }
                 },
-                "total": {
-                    "type": "integer",
-                    "example": 2
+                "property_name": {
+                    "type": "string",
+                    "example": "The Base Sukhumvit 77"
+                },
+                "property_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.PropertyTypes"
+                        }
+                    ],
+                    "example": "CONDO"
                 }
             }
         },
-        "models.OwnerAgreementDetails": {
+        "models.PropertyImageAgreements": {
             "type": "object",
             "properties": {
-                "owner_first_name": {
-                    "type": "string",
-                    "example": "John"
-                },
-                "owner_last_name": {
-                    "type": "string",
-                    "example": "Doe"
-                },
-                "owner_phone_number": {
-                    "type": "string",
-                    "example": "0812345678"
-                },
-                "owner_profile_image_url": {
+                "image_url": {
                     "type": "string",
                     "example": "https://www.example.com/image.jpg"
-                },
-                "owner_user_id": {
-                    "type": "string",
-                    "example": "00000000-0000-0000-0000-000000000000"
                 }
             }
         },
-        "models.OwnerAgreementLists": {
+        "models.PropertyImageAppointments": {
             "type": "object",
             "properties": {
-                "owner_first_name": {
-                    "type": "string",
-                    "example": "John"
-                },
-                "owner_last_name": {
-                    "type": "string",
-                    "example": "Doe"
-                },
-                "owner_profile_image_url": {
-                    "type": "string",
-                    "example": "https://www.example.com/image.jpg"
-                },
-                "owner_user_id": {
+                "image_url": {
                     "type": "string",
-                    "example": "00000000-0000-0000-0000-000000000000"
+                    "example": "https://image_url.com/abcd"
                 }
             }
         },
-        "models.OwnerAppointmentDetails": {
+        "models.PropertyImages": {
             "type": "object",
             "properties": {
-                "owner_first_name": {
-                    "type": "string",
-                    "example": "John"
-                },
-                "owner_last_name": {
-                    "type": "string",
-                    "example": "Doe"
-                },
-                "owner_phone_number": {
-                    "type": "string",
-                    "example": "0812345678"
+                "created_at": {
+                    "type": "string"
                 },
-                "owner_profile_image_url": {
+                "image_url": {
                     "type": "string",
                     "example": "https://image_url.com/abcd"
-                },
-                "owner_user_id": {
-                    "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 }
             }
         },
-        "models.OwnerAppointmentLists": {
+        "models.PushSubscriptions": {
             "type": "object",
             "properties": {
-                "owner_first_name": {
+                "created_at": {
                     "type": "string",
-                    "example": "John"
+                    "example": "2024-02-22T03:06:53.313735Z"
                 },
-                "owner_last_name": {
+                "endpoint": {
                     "type": "string",
-                    "example": "Doe"
+                    "example": "https://fcm.googleapis.com/fcm/send/abc123"
                 },
-                "owner_profile_image_url": {
+                "subscription_id": {
                     "type": "string",
-                    "example": "https://image_url.com/abcd"
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 },
-                "owner_user_id": {
+                "user_agent": {
                     "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                    "example": "Mozilla/5.0"
                 }
             }
         },
-        "models.Payments": {
+        "models.RatingResponse": {
             "type": "object",
             "properties": {
-                "agreement_id": {
+                "created_at": {
                     "type": "string"
                 },
-                "created_at": {
+                "dweller_user_id": {
                     "type": "string"
                 },
-                "is_success": {
-                    "type": "boolean"
+                "first_name": {
+                    "type": "string"
                 },
-                "name": {
+                "last_name": {
                     "type": "string"
                 },
-                "payment_id": {
+                "property_id": {
                     "type": "string"
                 },
-                "payment_method": {
-                    "$ref": "#/definitions/enums.PaymentMethods"
+                "rating": {
+                    "type": "integer"
                 },
-                "price": {
-                    "type": "number"
+                "review": {
+                    "type": "string"
                 },
-                "user_id": {
+                "review_id": {
                     "type": "string"
                 }
             }
         },
-        "models.Properties": {
+        "models.RefundDeductions": {
             "type": "object",
             "properties": {
-                "address": {
-                    "type": "string",
-                    "example": "123/4"
+                "amount": {
+                    "type": "number",
+                    "example": 2000
                 },
-                "alley": {
+                "description": {
                     "type": "string",
-                    "example": "Pattaya Nua 78"
-                },
-                "bathrooms": {
-                    "type": "integer",
-                    "example": 2
-                },
-                "bedrooms": {
-                    "type": "integer",
-                    "example": 3
-                },
-                "country": {
+                    "example": "Broken window"
+                }
+            }
+        },
+        "models.Refunds": {
+            "type": "object",
+            "properties": {
+                "agreement_id": {
                     "type": "string",
-                    "example": "Thailand"
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
+                },
+                "amount": {
+                    "type": "number",
+                    "example": 8000
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "district": {
+                "deductions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RefundDeductions"
+                    }
+                },
+                "payment_id": {
                     "type": "string",
-                    "example": "Bang Phli"
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
                 },
-                "floor": {
-                    "type": "integer",
-                    "example": 5
+                "reason": {
+                    "type": "string",
+                    "example": "Moved out on 1 Mar"
                 },
-                "floor_size": {
-                    "type": "number",
-                    "example": 123.45
+                "refund_id": {
+                    "type": "string",
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
                 },
-                "floor_size_unit": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.FloorSizeUnits"
-                        }
-                    ],
-                    "example": "SQM"
+                "requested_by": {
+                    "type": "string",
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
                 },
-                "furnishing": {
+                "status": {
                     "allOf": [
                         {
-                            "$ref": "#/definitions/enums.Furnishing"
+                            "$ref": "#/definitions/enums.RefundStatus"
                         }
                     ],
-                    "example": "UNFURNISHED"
-                },
-                "is_favorite": {
-                    "type": "boolean",
-                    "example": true
+                    "example": "PENDING"
+                }
+            }
+        },
+        "models.RentInstallments": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 15000
                 },
-                "owner_id": {
+                "due_date": {
                     "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                    "example": "2024-03-01T00:00:00Z"
                 },
-                "postal_code": {
-                    "type": "string",
-                    "example": "69096"
+                "installment": {
+                    "type": "integer",
+                    "example": 2
                 },
-                "property_description": {
+                "invoiced_at": {
                     "type": "string",
-                    "example": "Et sequi dolor praes"
+                    "example": "2024-02-23T00:00:00Z"
                 },
-                "property_id": {
+                "late_fee": {
+                    "type": "number",
+                    "example": 750
+                },
+                "paid_at": {
                     "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                    "example": "2024-02-25T10:00:00Z"
                 },
-                "property_images": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.PropertyImages"
-                    }
+                "payable": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "property_name": {
+                "payment_id": {
                     "type": "string",
-                    "example": "Supalai"
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
                 },
-                "property_type": {
+                "status": {
                     "allOf": [
                         {
-                            "$ref": "#/definitions/enums.PropertyTypes"
+                            "$ref": "#/definitions/enums.InstallmentStatus"
                         }
                     ],
-                    "example": "CONDOMINIUM"
-                },
-                "province": {
+                    "example": "UNPAID"
+                }
+            }
+        },
+        "models.RentSchedules": {
+            "type": "object",
+            "properties": {
+                "agreement_id": {
                     "type": "string",
-                    "example": "Pattaya"
-                },
-                "renting_property": {
-                    "$ref": "#/definitions/models.RentingProperties"
-                },
-                "selling_property": {
-                    "$ref": "#/definitions/models.SellingProperties"
+                    "example": "27b79b15-a56f-464a-90f7-bab515ba4c02"
                 },
-                "street": {
-                    "type": "string",
-                    "example": "Pattaya"
+                "installments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RentInstallments"
+                    }
                 },
-                "sub_district": {
-                    "type": "string",
-                    "example": "Bang Bon"
+                "paid_installments": {
+                    "type": "integer",
+                    "example": 1
                 },
-                "unit_number": {
+                "total_installments": {
                     "type": "integer",
-                    "example": 123
+                    "example": 12
                 }
             }
         },
-        "models.PropertyAgreementDetails": {
+        "models.RentingProperties": {
             "type": "object",
             "properties": {
-                "address": {
-                    "type": "string",
-                    "example": "123/456 The Base Sukhumvit 77"
-                },
-                "alley": {
-                    "type": "string",
-                    "example": "77"
-                },
-                "country": {
-                    "type": "string",
-                    "example": "Thailand"
-                },
-                "district": {
-                    "type": "string",
-                    "example": "Bangkok"
-                },
-                "postal_code": {
-                    "type": "string",
-                    "example": "10110"
+                "created_at": {
+                    "type": "string"
                 },
-                "price": {
-                    "type": "number",
-                    "example": 1000000
+                "is_occupied": {
+                    "type": "boolean",
+                    "example": true
                 },
                 "price_per_month": {
                     "type": "number",
-                    "example": 1000000
+                    "example": 12345.67
+                }
+            }
+        },
+        "models.SellingProperties": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
                 },
-                "property_id": {
-                    "type": "string",
-                    "example": "00000000-0000-0000-0000-000000000000"
+                "is_sold": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "property_images": {
+                "price": {
+                    "type": "number",
+                    "example": 12345.67
+                }
+            }
+        },
+        "models.SendingEmailRequests": {
+            "type": "object",
+            "properties": {
+                "emails": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.PropertyImageAgreements"
+                        "type": "string"
                     }
-                },
-                "property_name": {
-                    "type": "string",
-                    "example": "The Base Sukhumvit 77"
-                },
-                "property_type": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.PropertyTypes"
-                        }
-                    ],
-                    "example": "CONDO"
-                },
-                "province": {
-                    "type": "string",
-                    "example": "Bangkok"
-                },
-                "street": {
-                    "type": "string",
-                    "example": "Sukhumvit"
-                },
-                "sub_district": {
-                    "type": "string",
-                    "example": "Phra Khanong"
                 }
             }
         },
-        "models.PropertyAgreementLists": {
+        "models.Sessions": {
             "type": "object",
             "properties": {
-                "property_id": {
+                "email": {
                     "type": "string",
-                    "example": "00000000-0000-0000-0000-000000000000"
+                    "example": "admim@email.com"
                 },
-                "property_images": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.PropertyImageAgreements"
-                    }
+                "is_owner": {
+                    "type": "boolean"
                 },
-                "property_name": {
+                "user_id": {
                     "type": "string",
-                    "example": "The Base Sukhumvit 77"
-                },
-                "property_type": {
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/enums.PropertyTypes"
-                        }
-                    ],
-                    "example": "CONDO"
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 }
             }
         },
-        "models.PropertyAppointmentDetails": {
+        "models.TranscriptAgreements": {
             "type": "object",
             "properties": {
-                "address": {
-                    "type": "string",
-                    "example": "123/4"
-                },
-                "alley": {
+                "agreement_date": {
                     "type": "string",
-                    "example": "Pattaya Nua 78"
+                    "example": "2024-02-18T11:00:00Z"
                 },
-                "country": {
+                "agreement_id": {
                     "type": "string",
-                    "example": "Thailand"
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 },
-                "district": {
-                    "type": "string",
-                    "example": "Bang Phli"
+                "agreement_type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/enums.AgreementTypes"
+                        }
+                    ],
+                    "example": "RENTING"
                 },
-                "postal_code": {
+                "cancelled_message": {
                     "type": "string",
-                    "example": "69096"
-                },
-                "price": {
-                    "type": "number",
-                    "example": 12345.67
+                    "example": "This is a cancelled message"
                 },
-                "price_per_month": {
+                "deposit_amount": {
                     "type": "number",
-                    "example": 12345.67
-                },
-                "property_id": {
-                    "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                    "example": 20000
                 },
-                "property_images": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.PropertyImageAppointments"
-                    }
+                "payment_duration": {
+                    "type": "integer",
+                    "example": 12
                 },
-                "property_name": {
-                    "type": "string",
-                    "example": "The Base Sukhumvit 77"
+                "payment_per_month": {
+                    "type": "number",
+                    "example": 10000
                 },
-                "property_type": {
+                "status": {
                     "allOf": [
                         {
-                            "$ref": "#/definitions/enums.PropertyTypes"
+                            "$ref": "#/definitions/enums.AgreementStatus"
                         }
                     ],
-                    "example": "CONDO"
-                },
-                "province": {
-                    "type": "string",
-                    "example": "Pattaya"
-                },
-                "street": {
-                    "type": "string",
-                    "example": "Pattaya"
+                    "example": "RENTING"
                 },
-                "sub_district": {
-                    "type": "string",
-                    "example": "Bang Bon"
+                "total_payment": {
+                    "type": "number",
+                    "example": 140000
                 }
             }
         },
-        "models.PropertyAppointmentLists": {
+        "models.TranscriptAppointments": {
             "type": "object",
             "properties": {
-                "property_id": {
+                "appointment_date": {
+                    "type": "string",
+                    "example": "2024-02-18T11:00:00Z"
+                },
+                "appointment_id": {
                     "type": "string",
                     "example": "123e4567-e89b-12d3-a456-426614174000"
                 },
-                "property_images": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.PropertyImageAppointments"
-                    }
+                "cancelled_message": {
+                    "type": "string",
+                    "example": "This is a cancelled message"
                 },
-                "property_name": {
+                "note": {
                     "type": "string",
-                    "example": "The Base Sukhumvit 77"
+                    "example": "This is a note"
                 },
-                "property_type": {
+                "status": {
                     "allOf": [
                         {
-                            "$ref": "#/definitions/enums.PropertyTypes"
+                            "$ref": "#/definitions/enums.AppointmentStatus"
                         }
                     ],
-                    "example": "CONDO"
+                    "example": "CONFIRMED"
                 }
             }
         },
-        "models.PropertyImageAgreements": {
+        "models.TranscriptFiles": {
             "type": "object",
             "properties": {
-                "image_url": {
+                "content_type": {
                     "type": "string",
-                    "example": "https://www.example.com/image.jpg"
-                }
-            }
-        },
-        "models.PropertyImageAppointments": {
-            "type": "object",
-            "properties": {
-                "image_url": {
+                    "example": "application/pdf"
+                },
+                "file_name": {
                     "type": "string",
-                    "example": "https://image_url.com/abcd"
+                    "example": "payslip.pdf"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 102400
                 }
             }
         },
-        "models.PropertyImages": {
+        "models.TranscriptMessages": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
+                "action": {
+                    "$ref": "#/definitions/models.MessageActions"
                 },
-                "image_url": {
+                "agreement": {
+                    "$ref": "#/definitions/models.TranscriptAgreements"
+                },
+                "appointment": {
+                    "$ref": "#/definitions/models.TranscriptAppointments"
+                },
+                "content": {
                     "type": "string",
-                    "example": "https://image_url.com/abcd"
+                    "example": "hello, world"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2024-02-22T03:06:53.313735Z"
+                },
+                "edited_at": {
+                    "type": "string",
+                    "example": "2024-02-22T03:06:53.313735Z"
+                },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TranscriptFiles"
+                    }
+                },
+                "message_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "property": {
+                    "$ref": "#/definitions/models.TranscriptProperties"
+                },
+                "receipts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MessageReceipts"
+                    }
+                },
+                "sender_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "sender_name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "sent_at": {
+                    "type": "string",
+                    "example": "2024-02-22T03:06:53.313735Z"
                 }
             }
         },
-        "models.RatingResponse": {
+        "models.TranscriptParticipants": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "dweller_user_id": {
-                    "type": "string"
-                },
                 "first_name": {
-                    "type": "string"
-                },
-                "last_name": {
-                    "type": "string"
-                },
-                "property_id": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "John"
                 },
-                "rating": {
-                    "type": "integer"
+                "joined_at": {
+                    "type": "string",
+                    "example": "2024-02-22T03:06:53.313735Z"
                 },
-                "review": {
-                    "type": "string"
+                "last_name": {
+                    "type": "string",
+                    "example": "Doe"
                 },
-                "review_id": {
-                    "type": "string"
+                "user_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 }
             }
         },
-        "models.RentingProperties": {
+        "models.TranscriptProperties": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "is_occupied": {
-                    "type": "boolean",
-                    "example": true
+                "property_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 },
-                "price_per_month": {
-                    "type": "number",
-                    "example": 12345.67
+                "property_name": {
+                    "type": "string",
+                    "example": "The Base Sukhumvit 77"
                 }
             }
         },
-        "models.SellingProperties": {
+        "models.UnreadChats": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
+                "chat_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 },
-                "is_sold": {
+                "muted": {
                     "type": "boolean",
-                    "example": true
+                    "example": false
                 },
-                "price": {
-                    "type": "number",
-                    "example": 12345.67
+                "unread_messages": {
+                    "type": "integer",
+                    "example": 9
                 }
             }
         },
-        "models.SendingEmailRequests": {
+        "models.UnreadSummaries": {
             "type": "object",
             "properties": {
-                "emails": {
+                "chats": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.UnreadChats"
                     }
-                }
-            }
-        },
-        "models.Sessions": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "admim@email.com"
                 },
-                "user_id": {
-                    "type": "string",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                "total": {
+                    "type": "integer",
+                    "example": 9
                 }
             }
         },
@@ -4044,6 +6583,28 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.VapidKeys": {
+            "type": "object",
+            "properties": {
+                "public_key": {
+                    "type": "string",
+                    "example": "BNcRdreALRFXTkOOUHK1EtK2wtaz5Ry4YfYCA_0QTpQtUbVlUls0VJXg7A8u-Ts1XbjhazAkj7I99e8QcYP7DkM"
+                }
+            }
+        },
+        "models.WebsocketTickets": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-02-22T03:07:23.313735Z"
+                },
+                "ticket": {
+                    "type": "string",
+                    "example": "q3Xz2f0bR1m7bT8sV5yJkA9cW4eH6nLpU2dG0iO1tYs"
+                }
+            }
         }
     }
 }`
@@ -118,7 +118,12 @@ func (h *handlerImpl) CreateAgreement(c *fiber.Ctx) error {
 			Describe(fmt.Sprintf("Could not parse body: %v", err.Error())))
 	}
 
-	apperr := h.service.CreateAgreement(agreement)
+	apperr := h.hub.CheckBlocked(agreement.OwnerUserId, agreement.DwellerUserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	apperr = h.service.CreateAgreement(agreement)
 	if apperr != nil {
 		return utils.ResponseError(c, apperr)
 	}
@@ -14,6 +14,7 @@ type Repository interface {
 	CreateAgreement(*models.CreatingAgreements) error
 	DeleteAgreement(string) error
 	UpdateAgreementStatus(*models.UpdatingAgreementStatus, string) error
+	GetAgreement(*models.Agreements, string) error
 }
 
 type repositoryImpl struct {
@@ -238,3 +239,7 @@ func (repo *repositoryImpl) UpdateAgreementStatus(updatingAgreement *models.Upda
 
 	return repo.db.Model(&models.Agreements{}).Where("agreement_id = ?", agreementId).Updates(updatingAgreement).Error
 }
+
+func (repo *repositoryImpl) GetAgreement(agreement *models.Agreements, agreementId string) error {
+	return repo.db.Model(&models.Agreements{}).First(agreement, "agreement_id = ?", agreementId).Error
+}
@@ -2,10 +2,13 @@ package agreements
 
 import (
 	"errors"
+	"time"
 
 	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/events"
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
 	"github.com/brain-flowing-company/pprp-backend/internal/utils"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -17,17 +20,20 @@ type Service interface {
 	CreateAgreement(*models.CreatingAgreements) *apperror.AppError
 	DeleteAgreement(string) *apperror.AppError
 	UpdateAgreementStatus(*models.UpdatingAgreementStatus, string) *apperror.AppError
+	PublishAgreement(string)
 }
 
 type serviceImpl struct {
 	repo   Repository
 	logger *zap.Logger
+	events events.Publisher
 }
 
-func NewService(logger *zap.Logger, repo Repository) Service {
+func NewService(logger *zap.Logger, repo Repository, events events.Publisher) Service {
 	return &serviceImpl{
 		repo,
 		logger,
+		events,
 	}
 }
 func (s *serviceImpl) GetAllAgreements(agreements *[]models.AgreementLists) *apperror.AppError {
@@ -136,5 +142,31 @@ func (s *serviceImpl) UpdateAgreementStatus(updatingAgreement *models.UpdatingAg
 			Describe("Could not update agreement status")
 	}
 
+	s.PublishAgreement(agreementId)
+
 	return nil
+}
+
+// PublishAgreement pushes the current status of an agreement to both of its
+// sides, also after changes saved outside this service. The change is already
+// saved, so failures are only logged.
+func (s *serviceImpl) PublishAgreement(agreementId string) {
+	agreement := &models.Agreements{}
+	err := s.repo.GetAgreement(agreement, agreementId)
+	if err != nil {
+		s.logger.Error("Could not get agreement to publish", zap.Error(err), zap.String("agreementId", agreementId))
+		return
+	}
+
+	event := &models.AgreementEvents{
+		AgreementId: agreement.AgreementId,
+		PropertyId:  agreement.PropertyId,
+		Status:      agreement.Status,
+		UpdatedAt:   time.Now(),
+	}
+	if agreement.UpdatedAt != nil {
+		event.UpdatedAt = *agreement.UpdatedAt
+	}
+
+	s.events.Publish([]uuid.UUID{agreement.OwnerUserId, agreement.DwellerUserId}, event)
 }
\ No newline at end of file
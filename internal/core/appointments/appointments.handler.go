@@ -118,7 +118,12 @@ func (h *handlerImpl) CreateAppointment(c *fiber.Ctx) error {
 			Describe(fmt.Sprintf("Could not parse body: %v", err.Error())))
 	}
 
-	apperr := h.service.CreateAppointment(appointment)
+	apperr := h.hub.CheckBlocked(userId, appointment.OwnerUserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	apperr = h.service.CreateAppointment(appointment)
 	if apperr != nil {
 		return utils.ResponseError(c, apperr)
 	}
@@ -14,6 +14,8 @@ type Repository interface {
 	CreateAppointment(*models.CreatingAppointments) error
 	DeleteAppointment(string) error
 	UpdateAppointmentStatus(*models.UpdatingAppointmentStatus, string) error
+	RescheduleAppointment(*models.ReschedulingAppointments, string) error
+	GetAppointment(*models.Appointments, string) error
 }
 
 type repositoryImpl struct {
@@ -227,3 +229,15 @@ func (repo *repositoryImpl) UpdateAppointmentStatus(updatingAppointment *models.
 
 	return repo.db.Model(&models.Appointments{}).Where("appointment_id = ?", appointmentId).Updates(updatingAppointment).Error
 }
+
+func (repo *repositoryImpl) RescheduleAppointment(reschedulingAppointment *models.ReschedulingAppointments, appointmentId string) error {
+	if err := repo.db.Model(&models.Appointments{}).First(&models.Appointments{}, "appointment_id = ?", appointmentId).Error; err != nil {
+		return err
+	}
+
+	return repo.db.Model(&models.Appointments{}).Where("appointment_id = ?", appointmentId).Updates(reschedulingAppointment).Error
+}
+
+func (repo *repositoryImpl) GetAppointment(appointment *models.Appointments, appointmentId string) error {
+	return repo.db.Model(&models.Appointments{}).First(appointment, "appointment_id = ?", appointmentId).Error
+}
@@ -2,11 +2,14 @@ package appointments
 
 import (
 	"errors"
+	"time"
 
 	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/events"
 	"github.com/brain-flowing-company/pprp-backend/internal/enums"
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
 	"github.com/brain-flowing-company/pprp-backend/internal/utils"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -18,17 +21,21 @@ type Service interface {
 	CreateAppointment(*models.CreatingAppointments) *apperror.AppError
 	DeleteAppointment(string) *apperror.AppError
 	UpdateAppointmentStatus(*models.UpdatingAppointmentStatus, string) *apperror.AppError
+	RescheduleAppointment(*models.ReschedulingAppointments, string) *apperror.AppError
+	PublishAppointment(string)
 }
 
 type serviceImpl struct {
 	logger *zap.Logger
 	repo   Repository
+	events events.Publisher
 }
 
-func NewService(logger *zap.Logger, repo Repository) Service {
+func NewService(logger *zap.Logger, repo Repository, events events.Publisher) Service {
 	return &serviceImpl{
 		logger,
 		repo,
+		events,
 	}
 }
 
@@ -146,5 +153,62 @@ func (s *serviceImpl) UpdateAppointmentStatus(updatingAppointment *models.Updati
 			Describe("Could not set appointment status")
 	}
 
+	s.PublishAppointment(appointmentId)
+
 	return nil
 }
+
+func (s *serviceImpl) RescheduleAppointment(reschedulingAppointment *models.ReschedulingAppointments, appointmentId string) *apperror.AppError {
+	if !utils.IsValidUUID(appointmentId) {
+		return apperror.
+			New(apperror.InvalidAppointmentId).
+			Describe("Invalid appointment id")
+	}
+
+	if !reschedulingAppointment.AppointmentDate.After(time.Now()) {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Appointment date must be in the future")
+	}
+
+	err := s.repo.RescheduleAppointment(reschedulingAppointment, appointmentId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.
+			New(apperror.AppointmentNotFound).
+			Describe("Could not find the specified appointment")
+	} else if err != nil {
+		s.logger.Error("Could not reschedule appointment", zap.Error(err))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not reschedule appointment")
+	}
+
+	s.PublishAppointment(appointmentId)
+
+	return nil
+}
+
+// PublishAppointment pushes the current state of an appointment to both of
+// its sides, also after changes saved outside this service. The change is
+// already saved, so failures are only logged.
+func (s *serviceImpl) PublishAppointment(appointmentId string) {
+	appointment := &models.Appointments{}
+	err := s.repo.GetAppointment(appointment, appointmentId)
+	if err != nil {
+		s.logger.Error("Could not get appointment to publish", zap.Error(err), zap.String("appointmentId", appointmentId))
+		return
+	}
+
+	event := &models.AppointmentEvents{
+		AppointmentId:   appointment.AppointmentId,
+		PropertyId:      appointment.PropertyId,
+		Status:          appointment.Status,
+		AppointmentDate: appointment.AppointmentDate,
+		UpdatedAt:       time.Now(),
+	}
+	if appointment.UpdatedAt != nil {
+		event.UpdatedAt = *appointment.UpdatedAt
+	}
+
+	s.events.Publish([]uuid.UUID{appointment.OwnerUserId, appointment.DwellerUserId}, event)
+}
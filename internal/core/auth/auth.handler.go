@@ -66,8 +66,13 @@ func (h *handlerImpl) Login(c *fiber.Ctx) error {
 // @description Logout
 // @tags        auth
 // @success     200
+// @failure     500 {object} models.ErrorResponses
 func (h *handlerImpl) Logout(c *fiber.Ctx) error {
+	err := h.service.RevokeSession(c.Cookies("session"))
 	c.Cookie(utils.CreateSessionCookie("", 0))
+	if err != nil {
+		return utils.ResponseError(c, err)
+	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
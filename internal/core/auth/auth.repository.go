@@ -4,10 +4,12 @@ package auth
 import (
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
 	GetUserByEmail(email string) (*models.Users, error)
+	RevokeSession(*models.RevokedSessions) error
 }
 
 type repositoryImpl struct {
@@ -25,3 +27,7 @@ func (repo *repositoryImpl) GetUserByEmail(email string) (*models.Users, error)
 	err := repo.db.Where("email = ?", email).First(user).Error
 	return user, err
 }
+
+func (repo *repositoryImpl) RevokeSession(session *models.RevokedSessions) error {
+	return repo.db.Clauses(clause.OnConflict{DoNothing: true}).Create(session).Error
+}
@@ -11,12 +11,14 @@ import (
 	"github.com/brain-flowing-company/pprp-backend/internal/core/google"
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
 	"github.com/brain-flowing-company/pprp-backend/internal/utils"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type Service interface {
 	AuthenticateUser(email, password string) (string, *apperror.AppError)
 	Callback(ctx context.Context, callback *models.Callbacks, callbackResponse *models.CallbackResponses) *apperror.AppError
+	RevokeSession(token string) *apperror.AppError
 }
 
 type serviceImpl struct {
@@ -86,3 +88,27 @@ func (s *serviceImpl) Callback(ctx context.Context, callback *models.Callbacks,
 
 	return err
 }
+
+// RevokeSession records the session of token as logged out so that it can no
+// longer open chat websockets, and open ones get closed. Invalid tokens and
+// tokens without a session id have nothing to revoke.
+func (s *serviceImpl) RevokeSession(token string) *apperror.AppError {
+	claim, err := utils.ParseToken(token, s.cfg.JWTSecret)
+	if err != nil || claim.SessionId() == uuid.Nil {
+		return nil
+	}
+
+	err = s.repo.RevokeSession(&models.RevokedSessions{
+		SessionId: claim.SessionId(),
+		UserId:    claim.Session.UserId,
+		ExpiresAt: claim.ExpiresTime(),
+	})
+	if err != nil {
+		s.logger.Error("Could not revoke session", zap.Error(err))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not logout. Please try again later")
+	}
+
+	return nil
+}
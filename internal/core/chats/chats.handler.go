@@ -1,6 +1,9 @@
 package chats
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/brain-flowing-company/pprp-backend/apperror"
 	"github.com/brain-flowing-company/pprp-backend/config"
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
@@ -13,7 +16,24 @@ import (
 
 type Handler interface {
 	GetAllChats(c *fiber.Ctx) error
+	CreateChat(c *fiber.Ctx) error
+	CreateGroupChat(c *fiber.Ctx) error
+	AddMembers(c *fiber.Ctx) error
+	RemoveMember(c *fiber.Ctx) error
+	LeaveChat(c *fiber.Ctx) error
 	GetMessagesInChat(c *fiber.Ctx) error
+	GetUnreadSummary(c *fiber.Ctx) error
+	SearchMessages(c *fiber.Ctx) error
+	SendFiles(c *fiber.Ctx) error
+	GetMessageFile(c *fiber.Ctx) error
+	ExportChat(c *fiber.Ctx) error
+	BlockUser(c *fiber.Ctx) error
+	UnblockUser(c *fiber.Ctx) error
+	GetBlockedUsers(c *fiber.Ctx) error
+	MuteChat(c *fiber.Ctx) error
+	UnmuteChat(c *fiber.Ctx) error
+	ReportChat(c *fiber.Ctx) error
+	IssueWebsocketTicket(c *fiber.Ctx) error
 	OpenConnection(conn *websocket.Conn)
 }
 
@@ -35,7 +55,7 @@ func NewHandler(logger *zap.Logger, cfg *config.Config, hub *Hub, service Servic
 
 // @router      /api/v1/chats [get]
 // @summary     Get current users chat *use cookies*
-// @description Get current users chat
+// @description Get current users chat. Chats about a property come with a preview of the property.
 // @tags        chats
 // @produce     json
 // @success     200	{object} []models.ChatPreviews
@@ -57,15 +77,230 @@ func (h *handlerImpl) GetAllChats(c *fiber.Ctx) error {
 	return c.JSON(chats)
 }
 
-// @router      /api/v1/chats/:recvUserId [get]
-// @summary     Get messages in a chat with recvUserId *use cookies*
-// @description Get messages chatting with recvUserId. Pagination is available.
+// @router      /api/v1/chats [post]
+// @summary     Start a chat *use cookies*
+// @description Get the chat with user_id about property_id, creating it if it does not exist. Omit property_id for a general chat.
+// @tags        chats
+// @accept      json
+// @produce     json
+// @param       body body models.CreatingConversations true "Chat partner and property"
+// @success     200	{object} models.Conversations
+// @failure     400 {object} models.ErrorResponses
+// @failure     403 {object} models.ErrorResponses
+// @failure     404 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) CreateChat(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	body := models.CreatingConversations{}
+	err := c.BodyParser(&body)
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.InvalidBody).
+			Describe(fmt.Sprintf("Could not parse body: %v", err.Error())))
+	}
+
+	conversation := models.Conversations{}
+	apperr := h.service.CreateConversation(&conversation, session.UserId, &body)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.JSON(conversation)
+}
+
+// @router      /api/v1/chats/groups [post]
+// @summary     Start a group chat *use cookies*
+// @description Create a group chat with the current user and user_ids, optionally about property_id. Groups have at most 20 members.
+// @tags        chats
+// @accept      json
+// @produce     json
+// @param       body body models.CreatingGroupConversations true "Group name and members"
+// @success     201	{object} models.Conversations
+// @failure     400 {object} models.ErrorResponses
+// @failure     404 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) CreateGroupChat(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	body := models.CreatingGroupConversations{}
+	err := c.BodyParser(&body)
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.InvalidBody).
+			Describe(fmt.Sprintf("Could not parse body: %v", err.Error())))
+	}
+
+	conversation := models.Conversations{}
+	apperr := h.service.CreateGroupConversation(&conversation, session.UserId, &body)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	h.hub.Broadcast(conversation.Participants, &models.MemberEvents{
+		ChatId:       conversation.ConversationId,
+		Participants: conversation.Participants,
+	})
+
+	return c.Status(fiber.StatusCreated).JSON(conversation)
+}
+
+// @router      /api/v1/chats/:chatId/members [post]
+// @summary     Add members to a group chat *use cookies*
+// @description Any member of a group chat can add more members
+// @tags        chats
+// @accept      json
+// @produce     json
+// @param       chatId path string true "Chat ID"
+// @param       body body models.AddingConversationMembers true "Users to add"
+// @success     200	{object} models.Conversations
+// @failure     400 {object} models.ErrorResponses
+// @failure     404 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) AddMembers(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	chatId, err := uuid.Parse(c.Params("chatId"))
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid chat id"))
+	}
+
+	body := models.AddingConversationMembers{}
+	err = c.BodyParser(&body)
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.InvalidBody).
+			Describe(fmt.Sprintf("Could not parse body: %v", err.Error())))
+	}
+
+	conversation := models.Conversations{}
+	apperr := h.service.GetConversation(&conversation, chatId, session.UserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	apperr = h.service.AddMembers(&conversation, session.UserId, body.UserIds)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	h.hub.Broadcast(conversation.Participants, &models.MemberEvents{
+		ChatId:       conversation.ConversationId,
+		Participants: conversation.Participants,
+	})
+
+	return c.JSON(conversation)
+}
+
+// @router      /api/v1/chats/:chatId/members/:userId [delete]
+// @summary     Remove a member from a group chat *use cookies*
+// @description Only the creator of a group chat can remove other members
+// @tags        chats
+// @produce     json
+// @param       chatId path string true "Chat ID"
+// @param       userId path string true "User ID"
+// @success     200	{object} models.MessageResponses
+// @failure     400 {object} models.ErrorResponses
+// @failure     403 {object} models.ErrorResponses
+// @failure     404 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) RemoveMember(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	userId, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return utils.ResponseError(c, apperror.InvalidUserId)
+	}
+
+	apperr := h.removeMember(c, session.UserId, userId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return utils.ResponseMessage(c, fiber.StatusOK, "Member removed")
+}
+
+// @router      /api/v1/chats/:chatId/leave [post]
+// @summary     Leave a group chat *use cookies*
+// @description Leave a group chat. One-to-one chats can not be left.
+// @tags        chats
+// @produce     json
+// @param       chatId path string true "Chat ID"
+// @success     200	{object} models.MessageResponses
+// @failure     400 {object} models.ErrorResponses
+// @failure     404 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) LeaveChat(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	apperr := h.removeMember(c, session.UserId, session.UserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return utils.ResponseMessage(c, fiber.StatusOK, "Left chat")
+}
+
+func (h *handlerImpl) removeMember(c *fiber.Ctx, userId uuid.UUID, memberId uuid.UUID) *apperror.AppError {
+	chatId, err := uuid.Parse(c.Params("chatId"))
+	if err != nil {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid chat id")
+	}
+
+	conversation := models.Conversations{}
+	apperr := h.service.GetConversation(&conversation, chatId, userId)
+	if apperr != nil {
+		return apperr
+	}
+
+	notified := conversation.Participants
+	apperr = h.service.RemoveMember(&conversation, userId, memberId)
+	if apperr != nil {
+		return apperr
+	}
+
+	h.hub.Broadcast(notified, &models.MemberEvents{
+		ChatId:       conversation.ConversationId,
+		Participants: conversation.Participants,
+	})
+	h.hub.SendUnreadSummary(memberId)
+
+	return nil
+}
+
+// @router      /api/v1/chats/:chatId [get]
+// @summary     Get messages in a chat *use cookies*
+// @description Get messages in chatId, oldest first. Without a cursor the latest messages are returned.
+// @description Use **before**/**after** with the cursors of a previous page to scroll, or **around** with a message id to jump to it.
 // @tags        chats
 // @produce     json
-// @param       offset query int false "offset"
-// @param       limit query int false "default 50, max 50"
-// @success     200	{object} []models.Messages
+// @param       chatId path  string true  "Chat ID"
+// @param       before query string false "before_cursor from a previous page"
+// @param       after  query string false "after_cursor from a previous page"
+// @param       around query string false "message id to load the messages around"
+// @param       limit  query int    false "default 50, max 50"
+// @success     200	{object} models.ChatHistories
 // @failure     400 {object} models.ErrorResponses
+// @failure     404 {object} models.ErrorResponses
 // @failure     500 {object} models.ErrorResponses
 func (h *handlerImpl) GetMessagesInChat(c *fiber.Ctx) error {
 	session, ok := c.Locals("session").(models.Sessions)
@@ -73,29 +308,449 @@ func (h *handlerImpl) GetMessagesInChat(c *fiber.Ctx) error {
 		session = models.Sessions{}
 	}
 
-	recvUserId, err := uuid.Parse(c.Params("recvUserId"))
+	chatId, err := uuid.Parse(c.Params("chatId"))
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid chat id"))
+	}
+
+	getting := &models.GettingChatHistories{
+		UserId: session.UserId,
+		ChatId: chatId,
+		Before: c.Query("before"),
+		After:  c.Query("after"),
+		Around: c.Query("around"),
+		Limit:  c.QueryInt("limit", 50),
+	}
+
+	histories := models.ChatHistories{}
+	apperr := h.service.GetMessagesInChat(&histories, getting)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.JSON(histories)
+}
+
+// @router      /api/v1/chats/unread [get]
+// @summary     Get unread message counters *use cookies*
+// @description Get the number of unread messages in every chat. total leaves out muted chats. The same summary is pushed over the websocket as an UNREAD event whenever it changes.
+// @tags        chats
+// @produce     json
+// @success     200	{object} models.UnreadSummaries
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) GetUnreadSummary(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	summary := models.UnreadSummaries{}
+	apperr := h.service.GetUnreadSummary(&summary, session.UserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.JSON(summary)
+}
+
+// @router      /api/v1/chats/search [get]
+// @summary     Search messages in all chats *use cookies*
+// @description Search message content across all of the current user's chats, newest first. Each hit comes with the surrounding messages and the chat it belongs to.
+// @tags        chats
+// @produce     json
+// @param       q      query string true  "search text"
+// @param       cursor query string false "next_cursor from the previous page"
+// @param       limit  query int    false "default 20, max 50"
+// @success     200	{object} models.ChatSearchResults
+// @failure     400 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) SearchMessages(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	results := models.ChatSearchResults{}
+	apperr := h.service.SearchMessages(&results, session.UserId, c.Query("q"), c.Query("cursor"), c.QueryInt("limit", 20))
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.JSON(results)
+}
+
+// @router      /api/v1/chats/:chatId/files [post]
+// @summary     Send files to a chat *use cookies*
+// @description Send a message with up to 5 image (jpeg, png) or pdf files, at most 10 MB each. Images get a thumbnail.
+// @tags        chats
+// @accept      multipart/form-data
+// @produce     json
+// @param       chatId  path     string true  "Chat ID"
+// @param       files   formData file   true  "files to send"
+// @param       content formData string false "message text"
+// @success     201	{object} models.Messages
+// @failure     400 {object} models.ErrorResponses
+// @failure     413 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) SendFiles(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	chatId, err := uuid.Parse(c.Params("chatId"))
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid chat id"))
+	}
+
+	conversation := models.Conversations{}
+	apperr := h.service.GetConversation(&conversation, chatId, session.UserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.InvalidBody).
+			Describe(fmt.Sprintf("Could not parse body: %v", err.Error())))
+	}
+
+	msg := &models.Messages{
+		MessageId: uuid.New(),
+		ChatId:    chatId,
+		SenderId:  session.UserId,
+		Author:    true,
+		Content:   c.FormValue("content"),
+		SentAt:    time.Now(),
+	}
+
+	apperr = h.service.SaveFileMessages(msg, &conversation, form.File["files"])
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	h.hub.BroadcastMessage(msg, conversation.Participants)
+
+	msg.Author = true
+	return c.Status(fiber.StatusCreated).JSON(msg)
+}
+
+// @router      /api/v1/chats/files/:fileId [get]
+// @summary     Get a file sent in chat *use cookies*
+// @description Download a file sent in chat. Only participants of the chat can access it.
+// @tags        chats
+// @produce     octet-stream
+// @param       fileId    path  string true  "File ID"
+// @param       thumbnail query bool   false "get the image thumbnail instead"
+// @success     200
+// @failure     400 {object} models.ErrorResponses
+// @failure     404 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) GetMessageFile(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	fileId, err := uuid.Parse(c.Params("fileId"))
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid file id"))
+	}
+
+	file := models.MessageFiles{}
+	reader, apperr := h.service.GetMessageFile(&file, session.UserId, fileId, c.QueryBool("thumbnail", false))
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	c.Set(fiber.HeaderContentType, file.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", file.FileName))
+	c.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+
+	return c.SendStream(reader)
+}
+
+// @router      /api/v1/chats/:chatId/export [get]
+// @summary     Export a chat transcript *use cookies*
+// @description Download the whole chat with timestamps, read times and resolved property, appointment and agreement details. format=html gives a printable page that can be saved as PDF. Only participants of the chat can export it.
+// @tags        chats
+// @produce     json,html
+// @param       chatId path  string true  "Chat ID"
+// @param       format query string false "json (default) or html"
+// @success     200	{object} models.ChatTranscripts
+// @failure     400 {object} models.ErrorResponses
+// @failure     404 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) ExportChat(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	chatId, err := uuid.Parse(c.Params("chatId"))
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid chat id"))
+	}
+
+	format := c.Query("format", "json")
+	if format != "json" && format != "html" {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe("format must be json or html"))
+	}
+
+	transcript := models.ChatTranscripts{}
+	apperr := h.service.ExportChat(&transcript, chatId, session.UserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	filename := fmt.Sprintf("chat-%v-%v.%v", chatId, transcript.ExportedAt.Format("20060102"), format)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	if format == "json" {
+		return c.JSON(transcript)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	apperr = h.service.RenderTranscript(c, &transcript)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return nil
+}
+
+// @router      /api/v1/user/:userId/block [post]
+// @summary     Block userId *use cookies*
+// @description Block a user. Neither side can send messages, appointments or agreements to the other until unblocked.
+// @tags        chats
+// @produce     json
+// @param       userId path string true "User ID"
+// @success     200	{object} models.MessageResponses
+// @failure     400 {object} models.ErrorResponses
+// @failure     404 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) BlockUser(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	userId, err := uuid.Parse(c.Params("userId"))
 	if err != nil {
 		return utils.ResponseError(c, apperror.InvalidUserId)
 	}
 
-	offset := c.QueryInt("offset", 0)
-	limit := c.QueryInt("limit", 50)
+	apperr := h.service.BlockUser(session.UserId, userId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
 
-	var msgs []models.Messages
-	apperr := h.service.GetMessagesInChat(&msgs, session.UserId, recvUserId, offset, limit)
+	return utils.ResponseMessage(c, fiber.StatusOK, "User blocked")
+}
+
+// @router      /api/v1/user/:userId/block [delete]
+// @summary     Unblock userId *use cookies*
+// @description Unblock a user
+// @tags        chats
+// @produce     json
+// @param       userId path string true "User ID"
+// @success     200	{object} models.MessageResponses
+// @failure     400 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) UnblockUser(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	userId, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return utils.ResponseError(c, apperror.InvalidUserId)
+	}
+
+	apperr := h.service.UnblockUser(session.UserId, userId)
 	if apperr != nil {
 		return utils.ResponseError(c, apperr)
 	}
 
-	return c.JSON(msgs)
+	return utils.ResponseMessage(c, fiber.StatusOK, "User unblocked")
 }
 
-func (h *handlerImpl) OpenConnection(conn *websocket.Conn) {
-	session := conn.Cookies("session")
+// @router      /api/v1/user/me/blocks [get]
+// @summary     Get blocked users *use cookies*
+// @description Get users blocked by the current user
+// @tags        chats
+// @produce     json
+// @success     200	{object} []models.BlockedUsers
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) GetBlockedUsers(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	blockedUsers := []models.BlockedUsers{}
+	apperr := h.service.GetBlockedUsers(&blockedUsers, session.UserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
 
-	claim, err := utils.ParseToken(session, h.cfg.JWTSecret)
+	return c.JSON(blockedUsers)
+}
+
+// @router      /api/v1/chats/:chatId/mute [post]
+// @summary     Mute a chat *use cookies*
+// @description Mute a chat. Muted chats do not count unread messages or send notifications.
+// @tags        chats
+// @produce     json
+// @param       chatId path string true "Chat ID"
+// @success     200	{object} models.MessageResponses
+// @failure     400 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) MuteChat(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	chatId, err := uuid.Parse(c.Params("chatId"))
 	if err != nil {
-		err := utils.WebsocketFatal(conn, apperror.Unauthorized)
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid chat id"))
+	}
+
+	apperr := h.service.MuteChat(session.UserId, chatId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	h.hub.SendUnreadSummary(session.UserId)
+
+	return utils.ResponseMessage(c, fiber.StatusOK, "Chat muted")
+}
+
+// @router      /api/v1/chats/:chatId/mute [delete]
+// @summary     Unmute a chat *use cookies*
+// @description Unmute a chat
+// @tags        chats
+// @produce     json
+// @param       chatId path string true "Chat ID"
+// @success     200	{object} models.MessageResponses
+// @failure     400 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) UnmuteChat(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	chatId, err := uuid.Parse(c.Params("chatId"))
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid chat id"))
+	}
+
+	apperr := h.service.UnmuteChat(session.UserId, chatId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	h.hub.SendUnreadSummary(session.UserId)
+
+	return utils.ResponseMessage(c, fiber.StatusOK, "Chat unmuted")
+}
+
+// @router      /api/v1/chats/:chatId/report [post]
+// @summary     Report a chat *use cookies*
+// @description Report the other participant of a chat, or user_id of a group chat. The latest messages of the chat are kept for moderator review.
+// @tags        chats
+// @produce     json
+// @param       chatId path string true "Chat ID"
+// @param       body body models.CreatingChatReports true "Report reason"
+// @success     201	{object} models.ChatReports
+// @failure     400 {object} models.ErrorResponses
+// @failure     404 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) ReportChat(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	chatId, err := uuid.Parse(c.Params("chatId"))
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid chat id"))
+	}
+
+	body := models.CreatingChatReports{}
+	err = c.BodyParser(&body)
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.InvalidBody).
+			Describe(fmt.Sprintf("Could not parse body: %v", err.Error())))
+	}
+
+	report := &models.ChatReports{
+		ReporterId: session.UserId,
+		ChatId:     chatId,
+		Reason:     body.Reason,
+	}
+
+	if body.UserId != nil {
+		report.ReportedUserId = *body.UserId
+	}
+
+	apperr := h.service.ReportChat(report)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(report)
+}
+
+// @router      /api/v1/chats/tickets [post]
+// @summary     Issue a websocket ticket *use cookies*
+// @description Issue a single use ticket to open /ws/chats?ticket= from clients that can not send the session cookie to the websocket, such as native apps or frontends on another domain. The ticket is bound to the Origin of this request and expires after WS_TICKET_EXPIRE seconds.
+// @tags        chats
+// @produce     json
+// @success     201 {object} models.WebsocketTickets
+// @failure     401 {object} models.ErrorResponses "Invalid, expired or logged out session"
+// @failure     403 {object} models.ErrorResponses "Origin is not allowed"
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) IssueWebsocketTicket(c *fiber.Ctx) error {
+	ticket := &models.WebsocketTickets{}
+	apperr := h.service.IssueWebsocketTicket(ticket, c.Cookies("session"), c.Get(fiber.HeaderOrigin))
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(ticket)
+}
+
+// OpenConnection serves /ws/chats. The session comes from a ticket in the
+// query string or else from the session cookie, and the Origin has to be one
+// of APP_ALLOW_ORIGIN.
+func (h *handlerImpl) OpenConnection(conn *websocket.Conn) {
+	// the upgrader leaves Subprotocol empty when none of the requested ones is
+	// supported, which would otherwise silently fall back to v1
+	if conn.Headers("Sec-Websocket-Protocol") != "" && conn.Subprotocol() == "" {
+		err := utils.WebsocketFatal(conn, apperror.UnsupportedProtocol)
 		if err != nil {
 			h.logger.Error("Could not send error message", zap.Error(err))
 		}
@@ -103,7 +758,18 @@ func (h *handlerImpl) OpenConnection(conn *websocket.Conn) {
 		return
 	}
 
-	client, apperr := NewClient(h.logger, conn, h.hub, h.service, claim.Session.UserId)
+	claim := &models.SessionClaims{}
+	apperr := h.service.AuthenticateWebsocket(claim, conn.Query("ticket"), conn.Cookies("session"), conn.Headers("Origin"))
+	if apperr != nil {
+		err := utils.WebsocketFatal(conn, apperr)
+		if err != nil {
+			h.logger.Error("Could not send error message", zap.Error(err))
+		}
+
+		return
+	}
+
+	client, apperr := NewClient(h.logger, conn, h.hub, h.service, claim)
 	if apperr != nil {
 		err := utils.WebsocketFatal(conn, apperror.Unauthorized)
 		if err != nil {
@@ -118,6 +784,14 @@ func (h *handlerImpl) OpenConnection(conn *websocket.Conn) {
 	ok := models.OKResponses{}
 	client.SendOutBoundMessage(ok.ToOutBound())
 
+	apperr = h.service.DeliverAllMessages(client.UserId)
+	if apperr == nil {
+		apperr = h.hub.SendUnreadSummary(client.UserId)
+	}
+	if apperr != nil {
+		h.logger.Error("Could not send unread summary", zap.Error(apperr))
+	}
+
 	client.Listen()
 
 	defer func() {
@@ -3,8 +3,11 @@ package chats
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -12,9 +15,54 @@ import (
 
 type Repository interface {
 	GetAllChats(*[]models.ChatPreviews, uuid.UUID, string) error
-	GetMessagesInChat(*[]models.Messages, uuid.UUID, uuid.UUID, int, int) error
+	GetConversationById(*models.Conversations, uuid.UUID) error
+	GetOrCreateConversation(*models.Conversations, []uuid.UUID) error
+	CreateConversation(*models.Conversations) error
+	AddParticipants(uuid.UUID, []uuid.UUID, time.Time) error
+	DeleteParticipant(uuid.UUID, uuid.UUID) error
+	CountProperties(*int64, uuid.UUID) error
+	CountUsers(*int64, []uuid.UUID) error
 	SaveMessages(msg *models.Messages) error
-	ReadMessages(sendUserId uuid.UUID, recvUserId uuid.UUID) error
+	ReadMessages(*int64, uuid.UUID, uuid.UUID, *models.MessageCursors, time.Time) error
+	DeliverMessage(uuid.UUID, uuid.UUID, time.Time) error
+	DeliverAllMessages(uuid.UUID, time.Time) error
+	GetReceiptsInMessages(*[]models.MessageReceipts, []uuid.UUID) error
+	GetActionsInMessages(*[]models.MessageActions, []uuid.UUID) error
+	GetMessageAction(*models.MessageActions, uuid.UUID) error
+	UpdateMessageAction(*models.MessageActions) error
+	RespondToAction(*models.MessageActions, *models.ActionResponses) error
+	GetPendingPaymentRequests(*[]models.Messages, uuid.UUID) error
+	GetPendingInstallmentRequest(*models.Messages, uuid.UUID, int) error
+	GetUnreadChats(*[]models.UnreadChats, uuid.UUID) error
+	GetMessageById(*models.Messages, uuid.UUID) error
+	EditMessage(uuid.UUID, string, time.Time) error
+	DeleteMessage(uuid.UUID, time.Time) error
+	GetReactionsInMessages(*[]models.MessageReactions, []uuid.UUID) error
+	CreateReaction(*models.MessageReactions) error
+	DeleteReaction(*models.MessageReactions) error
+	GetFilesInMessages(*[]models.MessageFiles, []uuid.UUID) error
+	GetMessageFileById(*models.MessageFiles, uuid.UUID, uuid.UUID) error
+	SearchMessages(*[]models.ChatSearchRows, *models.SearchingMessages) error
+	GetSearchContext(*[]models.SearchContextRows, []uuid.UUID, uuid.UUID, int) error
+	GetMessagesBefore(*[]models.Messages, uuid.UUID, uuid.UUID, *models.MessageCursors, int) error
+	GetMessagesAfter(*[]models.Messages, uuid.UUID, uuid.UUID, *models.MessageCursors, int) error
+	GetAllMessagesInChat(*[]models.Messages, uuid.UUID) error
+	GetTranscriptParticipants(*[]models.TranscriptParticipants, uuid.UUID) error
+	GetTranscriptProperties(*[]models.TranscriptProperties, []uuid.UUID) error
+	GetTranscriptAppointments(*[]models.TranscriptAppointments, []uuid.UUID) error
+	GetTranscriptAgreements(*[]models.TranscriptAgreements, []uuid.UUID) error
+	GetRevokedSessions(*[]uuid.UUID, []uuid.UUID) error
+	CreateUserBlock(*models.UserBlocks) error
+	DeleteUserBlock(uuid.UUID, uuid.UUID) error
+	GetBlockedUsers(*[]models.BlockedUsers, uuid.UUID) error
+	CountUserBlocksBetween(*int64, uuid.UUID, uuid.UUID) error
+	CountUserBlocksWith(*int64, uuid.UUID, []uuid.UUID) error
+	GetBlockersAmong(*[]uuid.UUID, uuid.UUID, []uuid.UUID) error
+	CreateChatMute(*models.ChatMutes) error
+	DeleteChatMute(uuid.UUID, uuid.UUID) error
+	CreateChatReport(*models.ChatReports, int) error
+	CountAgreementsBetween(*int64, uuid.UUID, []uuid.UUID) error
+	CreateMessageModerations(*[]models.MessageModerations) error
 }
 
 type repositoryImpl struct {
@@ -27,89 +75,243 @@ func NewRepository(db *gorm.DB) Repository {
 	}
 }
 
+// notFromBlockedSender leaves out the group messages of senders @user_id has
+// blocked, which are not delivered to them live either. Nobody can send into
+// a direct chat with a blocked user, so the history from before the block is
+// kept there.
+const notFromBlockedSender = `NOT EXISTS (
+	SELECT 1
+	FROM user_blocks
+	JOIN conversations AS blocked_in
+	ON blocked_in.conversation_id = messages.conversation_id AND blocked_in.is_group
+	WHERE user_blocks.blocker_id = @user_id AND user_blocks.blocked_id = messages.sender_id
+)`
+
 func (repo *repositoryImpl) GetAllChats(results *[]models.ChatPreviews, userId uuid.UUID, query string) error {
-	return repo.db.Model(&models.Messages{}).
+	return repo.db.Model(&models.Conversations{}).
 		Raw(`
-		SELECT users.user_id, profile_image_url, first_name, last_name, unread_messages, content
-			FROM (
-				SELECT
-					DISTINCT ON (user_id) a.user_id,
-					COALESCE(unread_messages, 0) AS unread_messages,
-					content, sent_at
-				FROM (
-					SELECT 
-						CASE
-							WHEN sender_id = @user_id THEN receiver_id
-							WHEN receiver_id = @user_id THEN sender_id 
-						END AS user_id, message_id, content, read_at, sent_at
-					FROM messages
-					WHERE sender_id = @user_id OR receiver_id = @user_id
-					ORDER BY user_id, sent_at DESC
-				) AS a
-				LEFT JOIN (
-					SELECT
-						CASE
-							WHEN sender_id = @user_id THEN receiver_id
-							WHEN receiver_id = @user_id THEN sender_id
-						END AS user_id, count(*) AS unread_messages
-					FROM messages
-					WHERE receiver_id = @user_id AND read_at IS NULL
-					GROUP BY user_id
-				) AS b
-				ON a.user_id = b.user_id
-			) AS c
+		SELECT
+			conversations.conversation_id AS chat_id,
+			conversations.is_group, conversations.name,
+			COALESCE(users.user_id, '00000000-0000-0000-0000-000000000000') AS user_id,
+			COALESCE(users.profile_image_url, '') AS profile_image_url,
+			COALESCE(users.first_name, '') AS first_name,
+			COALESCE(users.last_name, '') AS last_name,
+			conversations.property_id, properties.property_name,
+			(
+				SELECT image_url
+				FROM property_images
+				WHERE property_images.property_id = conversations.property_id
+				ORDER BY created_at ASC
+				LIMIT 1
+			) AS property_image_url,
+			COALESCE(last_message.content, '') AS content,
+			CASE WHEN chat_mutes.user_id IS NULL THEN unread.unread_messages ELSE 0 END AS unread_messages,
+			chat_mutes.user_id IS NOT NULL AS muted
+		FROM conversation_participants AS me
+		JOIN conversations
+		ON conversations.conversation_id = me.conversation_id
+		LEFT JOIN LATERAL (
+			SELECT partner.user_id
+			FROM conversation_participants AS partner
+			WHERE partner.conversation_id = me.conversation_id
+				AND partner.user_id <> me.user_id
+				AND NOT conversations.is_group
+			LIMIT 1
+		) AS partner
+		ON true
 		LEFT JOIN users
-		ON users.user_id = c.user_id
-		WHERE LOWER(first_name || ' ' || last_name) LIKE LOWER(@query)
-		ORDER BY unread_messages DESC, sent_at DESC
+		ON users.user_id = partner.user_id
+		LEFT JOIN properties
+		ON properties.property_id = conversations.property_id
+		LEFT JOIN LATERAL (
+			SELECT CASE WHEN deleted_at IS NULL THEN content ELSE '' END AS content, sent_at
+			FROM messages
+			WHERE messages.conversation_id = me.conversation_id
+				AND `+notFromBlockedSender+`
+			ORDER BY sent_at DESC, message_id DESC
+			LIMIT 1
+		) AS last_message
+		ON true
+		LEFT JOIN LATERAL (
+			SELECT count(*) AS unread_messages
+			FROM message_receipts
+			JOIN messages
+			ON messages.message_id = message_receipts.message_id
+			WHERE messages.conversation_id = me.conversation_id
+				AND message_receipts.user_id = me.user_id
+				AND message_receipts.read_at IS NULL
+				AND messages.deleted_at IS NULL
+				AND `+notFromBlockedSender+`
+		) AS unread
+		ON true
+		LEFT JOIN chat_mutes
+		ON chat_mutes.user_id = @user_id AND chat_mutes.conversation_id = me.conversation_id
+		WHERE me.user_id = @user_id
+			AND (conversations.is_group OR users.user_id IS NOT NULL)
+			AND LOWER(
+				COALESCE(conversations.name, '') || ' ' ||
+				COALESCE(users.first_name || ' ' || users.last_name, '') || ' ' ||
+				COALESCE(properties.property_name, '')
+			) LIKE LOWER(@query)
+		ORDER BY unread_messages DESC, COALESCE(last_message.sent_at, conversations.created_at) DESC
 		`, sql.Named("user_id", userId), sql.Named("query", fmt.Sprintf("%%%v%%", query))).
 		Scan(results).Error
 }
 
-func (repo *repositoryImpl) GetMessagesInChat(msgs *[]models.Messages, sendUserId uuid.UUID, recvUserId uuid.UUID, offset int, limit int) error {
-	query := fmt.Sprintf(`
-		SELECT *
-		FROM (
-			SELECT *
-			FROM messages
-			WHERE (sender_id = @sender_id AND receiver_id = @receiver_id)
-				OR (sender_id = @receiver_id AND receiver_id = @sender_id)
-			ORDER BY sent_at DESC
-			LIMIT %d OFFSET %d
-		) AS a
-		LEFT JOIN message_attatchments
-		ON a.message_id = message_attatchments.message_id
-		ORDER BY sent_at ASC
-	`, limit, offset)
-	return repo.db.Model(&models.Messages{}).
-		Raw(query,
-			sql.Named("sender_id", sendUserId),
-			sql.Named("receiver_id", recvUserId),
-		).Scan(msgs).Error
+func (repo *repositoryImpl) GetConversationById(conversation *models.Conversations, chatId uuid.UUID) error {
+	err := repo.db.Model(&models.Conversations{}).
+		Where("conversation_id = ?", chatId).
+		First(conversation).Error
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Model(&models.ConversationParticipants{}).
+		Where("conversation_id = ?", chatId).
+		Order("joined_at ASC").
+		Pluck("user_id", &conversation.Participants).Error
 }
 
-func (repo *repositoryImpl) SaveMessages(msg *models.Messages) error {
+// GetOrCreateConversation looks up the conversation with conversation.DirectKey
+// and conversation.PropertyId, creating it with participants if it does not
+// exist yet.
+func (repo *repositoryImpl) GetOrCreateConversation(conversation *models.Conversations, participants []uuid.UUID) error {
 	return repo.db.Transaction(func(tx *gorm.DB) error {
-		fmt.Println(msg.MessageId, msg.SenderId, msg.ReceiverId, msg.Content, msg.ReadAt, msg.SentAt)
 		err := tx.Exec(`
-			INSERT INTO messages (message_id, sender_id, receiver_id, content, read_at, sent_at)
+			INSERT INTO conversations (conversation_id, property_id, is_group, created_by, direct_key, created_at)
+			VALUES (?, ?, FALSE, ?, ?, ?)
+			ON CONFLICT DO NOTHING
+		`, conversation.ConversationId, conversation.PropertyId, conversation.CreatedBy, conversation.DirectKey, conversation.CreatedAt).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.Conversations{}).
+			Where("direct_key = ? AND property_id IS NOT DISTINCT FROM ?", conversation.DirectKey, conversation.PropertyId).
+			First(conversation).Error
+		if err != nil {
+			return err
+		}
+
+		for _, userId := range participants {
+			err = tx.Exec(`
+				INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
+				VALUES (?, ?, ?)
+				ON CONFLICT DO NOTHING
+			`, conversation.ConversationId, userId, conversation.CreatedAt).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		conversation.Participants = participants
+
+		return nil
+	})
+}
+
+func (repo *repositoryImpl) CreateConversation(conversation *models.Conversations) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO conversations (conversation_id, property_id, is_group, name, created_by, created_at)
 			VALUES (?, ?, ?, ?, ?, ?)
-		`, msg.MessageId, msg.SenderId, msg.ReceiverId, msg.Content, msg.ReadAt, msg.SentAt).Error
+		`, conversation.ConversationId, conversation.PropertyId, conversation.IsGroup, conversation.Name, conversation.CreatedBy, conversation.CreatedAt).Error
+		if err != nil {
+			return err
+		}
+
+		return addParticipants(tx, conversation.ConversationId, conversation.Participants, conversation.CreatedAt)
+	})
+}
+
+func (repo *repositoryImpl) AddParticipants(chatId uuid.UUID, userIds []uuid.UUID, joinedAt time.Time) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		return addParticipants(tx, chatId, userIds, joinedAt)
+	})
+}
+
+func addParticipants(tx *gorm.DB, chatId uuid.UUID, userIds []uuid.UUID, joinedAt time.Time) error {
+	for _, userId := range userIds {
+		err := tx.Exec(`
+			INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
+			VALUES (?, ?, ?)
+			ON CONFLICT DO NOTHING
+		`, chatId, userId, joinedAt).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *repositoryImpl) DeleteParticipant(chatId uuid.UUID, userId uuid.UUID) error {
+	return repo.db.
+		Where("conversation_id = ? AND user_id = ?", chatId, userId).
+		Delete(&models.ConversationParticipants{}).Error
+}
+
+func (repo *repositoryImpl) CountUsers(count *int64, userIds []uuid.UUID) error {
+	return repo.db.Model(&models.Users{}).
+		Where("user_id IN ?", userIds).
+		Count(count).Error
+}
+
+func (repo *repositoryImpl) CountProperties(count *int64, propertyId uuid.UUID) error {
+	return repo.db.Model(&models.Properties{}).
+		Where("property_id = ?", propertyId).
+		Count(count).Error
+}
+
+func (repo *repositoryImpl) SaveMessages(msg *models.Messages) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO messages (message_id, conversation_id, sender_id, content, sent_at)
+			VALUES (?, ?, ?, ?, ?)
+		`, msg.MessageId, msg.ChatId, msg.SenderId, msg.Content, msg.SentAt).Error
 		if err != nil {
 			return err
 		}
 
-		fmt.Println(msg.Attatchment)
+		// members who blocked the sender do not receive the message
+		recipients := tx.Table("conversation_participants").
+			Select("CAST(? AS UUID), user_id", msg.MessageId).
+			Where("conversation_id = ? AND user_id <> ?", msg.ChatId, msg.SenderId)
+		if len(msg.BlockedBy) > 0 {
+			recipients = recipients.Where("user_id NOT IN ?", msg.BlockedBy)
+		}
+
+		err = tx.Exec("INSERT INTO message_receipts (message_id, user_id) ?", recipients).Error
+		if err != nil {
+			return err
+		}
 
 		if (models.MessageAttatchments{}) != msg.Attatchment {
-			fmt.Println("attatchment", msg.MessageId, msg.Attatchment.PropertyId, msg.Attatchment.AppointmentId, msg.Attatchment.AgreementId)
 			err = tx.Exec(`
 				INSERT INTO message_attatchments (message_id, property_id, appointment_id, agreement_id)
 				VALUES (?, ?, ?, ?)
 			`, msg.MessageId, msg.Attatchment.PropertyId, msg.Attatchment.AppointmentId, msg.Attatchment.AgreementId).Error
-			fmt.Println(err)
 			if err != nil {
-				return nil
+				return err
+			}
+		}
+
+		if msg.Action != nil {
+			msg.Action.MessageId = msg.MessageId
+			err = tx.Create(msg.Action).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, file := range msg.Files {
+			err = tx.Exec(`
+				INSERT INTO message_files (file_id, message_id, file_name, content_type, size, file_key, thumbnail_key, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			`, file.FileId, msg.MessageId, file.FileName, file.ContentType, file.Size, file.FileKey, file.ThumbnailKey, file.CreatedAt).Error
+			if err != nil {
+				return err
 			}
 		}
 
@@ -117,8 +319,635 @@ func (repo *repositoryImpl) SaveMessages(msg *models.Messages) error {
 	})
 }
 
-func (repo *repositoryImpl) ReadMessages(sendUserId uuid.UUID, recvUserId uuid.UUID) error {
+// ReadMessages marks every message in the chat up to and including cursor as
+// read by userId and counts how many were unread.
+func (repo *repositoryImpl) ReadMessages(count *int64, chatId uuid.UUID, userId uuid.UUID, cursor *models.MessageCursors, readAt time.Time) error {
+	result := repo.db.Exec(`
+		UPDATE message_receipts
+		SET read_at = @read_at, delivered_at = COALESCE(delivered_at, @read_at)
+		FROM messages
+		WHERE messages.message_id = message_receipts.message_id
+			AND messages.conversation_id = @conversation_id
+			AND (messages.sent_at, messages.message_id) <= (@sent_at, @message_id)
+			AND message_receipts.user_id = @user_id
+			AND message_receipts.read_at IS NULL
+	`, sql.Named("read_at", readAt),
+		sql.Named("conversation_id", chatId),
+		sql.Named("sent_at", cursor.SentAt),
+		sql.Named("message_id", cursor.MessageId),
+		sql.Named("user_id", userId))
+
+	*count = result.RowsAffected
+	return result.Error
+}
+
+func (repo *repositoryImpl) DeliverMessage(messageId uuid.UUID, userId uuid.UUID, deliveredAt time.Time) error {
+	return repo.db.Model(&models.MessageReceipts{}).
+		Where("message_id = ? AND user_id = ? AND delivered_at IS NULL", messageId, userId).
+		Update("delivered_at", deliveredAt).Error
+}
+
+func (repo *repositoryImpl) DeliverAllMessages(userId uuid.UUID, deliveredAt time.Time) error {
+	return repo.db.Model(&models.MessageReceipts{}).
+		Where("user_id = ? AND delivered_at IS NULL", userId).
+		Update("delivered_at", deliveredAt).Error
+}
+
+func (repo *repositoryImpl) GetActionsInMessages(actions *[]models.MessageActions, messageIds []uuid.UUID) error {
+	if len(messageIds) == 0 {
+		return nil
+	}
+
+	return repo.db.Model(&models.MessageActions{}).
+		Where("message_id IN ?", messageIds).
+		Find(actions).Error
+}
+
+func (repo *repositoryImpl) GetMessageAction(action *models.MessageActions, messageId uuid.UUID) error {
+	return repo.db.First(action, "message_id = ?", messageId).Error
+}
+
+// UpdateMessageAction saves action if it is still pending.
+func (repo *repositoryImpl) UpdateMessageAction(action *models.MessageActions) error {
+	return updateMessageAction(repo.db, action).Error
+}
+
+// updateMessageAction saves action only while it is pending, so that of two
+// responses racing each other only the first one is applied.
+func updateMessageAction(tx *gorm.DB, action *models.MessageActions) *gorm.DB {
+	return tx.Model(&models.MessageActions{}).
+		Where("message_id = ? AND status = ?", action.MessageId, enums.PendingAction).
+		Updates(map[string]interface{}{
+			"status":           action.Status,
+			"actor_id":         action.ActorId,
+			"appointment_date": action.AppointmentDate,
+			"amount":           action.Amount,
+			"note":             action.Note,
+			"updated_at":       action.UpdatedAt,
+		})
+}
+
+// RespondToAction saves action together with the change response makes to its
+// appointment or agreement, so that neither is saved without the other. It
+// fails with MessageActionResolved if the action is no longer pending and
+// returns gorm.ErrRecordNotFound if the appointment or agreement is gone.
+func (repo *repositoryImpl) RespondToAction(action *models.MessageActions, response *models.ActionResponses) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		// the action row stays locked until commit, so a concurrent response
+		// waits here and then finds it resolved
+		claimed := updateMessageAction(tx, action)
+		if claimed.Error != nil {
+			return claimed.Error
+		} else if claimed.RowsAffected == 0 {
+			return apperror.
+				New(apperror.MessageActionResolved).
+				Describe("This request has already been responded to")
+		}
+
+		var result *gorm.DB
+		switch {
+		case response.AppointmentStatus != nil:
+			result = tx.Model(&models.Appointments{}).
+				Where("appointment_id = ?", response.AppointmentId).
+				Updates(response.AppointmentStatus)
+		case response.Rescheduling != nil:
+			result = tx.Model(&models.Appointments{}).
+				Where("appointment_id = ?", response.AppointmentId).
+				Updates(response.Rescheduling)
+		case response.AgreementStatus != nil:
+			result = tx.Model(&models.Agreements{}).
+				Where("agreement_id = ?", response.AgreementId).
+				Updates(response.AgreementStatus)
+		}
+
+		if result != nil && result.Error != nil {
+			return result.Error
+		} else if result != nil && result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	})
+}
+
+// GetPendingPaymentRequests loads the messages carrying a pending payment
+// request for agreementId.
+func (repo *repositoryImpl) GetPendingPaymentRequests(msgs *[]models.Messages, agreementId uuid.UUID) error {
+	return repo.db.Raw(`
+		SELECT
+			messages.message_id,
+			messages.conversation_id,
+			messages.sender_id,
+			messages.content,
+			messages.sent_at,
+			message_attatchments.property_id,
+			message_attatchments.appointment_id,
+			message_attatchments.agreement_id
+		FROM messages
+		JOIN message_attatchments
+		ON messages.message_id = message_attatchments.message_id
+		JOIN message_actions
+		ON messages.message_id = message_actions.message_id
+		WHERE message_attatchments.agreement_id = ?
+		AND message_actions.action_type = ?
+		AND message_actions.status = ?
+		AND messages.deleted_at IS NULL
+	`, agreementId, enums.PaymentRequestAction, enums.PendingAction).Scan(msgs).Error
+}
+
+// GetPendingInstallmentRequest loads the newest message carrying a pending
+// payment request for installment of agreementId, if there is one.
+func (repo *repositoryImpl) GetPendingInstallmentRequest(msg *models.Messages, agreementId uuid.UUID, installment int) error {
+	return repo.db.Raw(`
+		SELECT
+			messages.message_id,
+			messages.conversation_id,
+			messages.sender_id,
+			messages.content,
+			messages.sent_at,
+			message_attatchments.property_id,
+			message_attatchments.appointment_id,
+			message_attatchments.agreement_id
+		FROM messages
+		JOIN message_attatchments
+		ON messages.message_id = message_attatchments.message_id
+		JOIN message_actions
+		ON messages.message_id = message_actions.message_id
+		WHERE message_attatchments.agreement_id = ?
+		AND message_actions.action_type = ?
+		AND message_actions.status = ?
+		AND message_actions.installment = ?
+		AND messages.deleted_at IS NULL
+		ORDER BY messages.sent_at DESC
+		LIMIT 1
+	`, agreementId, enums.PaymentRequestAction, enums.PendingAction, installment).Scan(msg).Error
+}
+
+func (repo *repositoryImpl) GetReceiptsInMessages(receipts *[]models.MessageReceipts, messageIds []uuid.UUID) error {
+	if len(messageIds) == 0 {
+		return nil
+	}
+
+	return repo.db.Model(&models.MessageReceipts{}).
+		Where("message_id IN ?", messageIds).
+		Find(receipts).Error
+}
+
+func (repo *repositoryImpl) GetUnreadChats(chats *[]models.UnreadChats, userId uuid.UUID) error {
+	return repo.db.Model(&models.MessageReceipts{}).
+		Raw(`
+		SELECT messages.conversation_id AS chat_id,
+			count(*) AS unread_messages,
+			chat_mutes.user_id IS NOT NULL AS muted
+		FROM message_receipts
+		JOIN messages
+		ON messages.message_id = message_receipts.message_id
+		JOIN conversation_participants
+		ON conversation_participants.conversation_id = messages.conversation_id
+			AND conversation_participants.user_id = message_receipts.user_id
+		LEFT JOIN chat_mutes
+		ON chat_mutes.user_id = message_receipts.user_id
+			AND chat_mutes.conversation_id = messages.conversation_id
+		WHERE message_receipts.user_id = ?
+			AND message_receipts.read_at IS NULL
+			AND messages.deleted_at IS NULL
+		GROUP BY messages.conversation_id, chat_mutes.user_id
+		`, userId).
+		Scan(chats).Error
+}
+
+func (repo *repositoryImpl) GetMessageById(msg *models.Messages, messageId uuid.UUID) error {
 	return repo.db.Model(&models.Messages{}).
-		Where("sender_id = ? AND receiver_id = ?", sendUserId, recvUserId).
-		Update("read_at", time.Now()).Error
+		Raw(`
+		SELECT messages.*,
+			message_attatchments.property_id,
+			message_attatchments.appointment_id,
+			message_attatchments.agreement_id
+		FROM messages
+		LEFT JOIN message_attatchments
+		ON messages.message_id = message_attatchments.message_id
+		WHERE messages.message_id = ?
+		`, messageId).
+		First(msg).Error
+}
+
+func (repo *repositoryImpl) EditMessage(messageId uuid.UUID, content string, editedAt time.Time) error {
+	return repo.db.Model(&models.Messages{}).
+		Where("message_id = ? AND deleted_at IS NULL", messageId).
+		Updates(map[string]interface{}{
+			"content":   content,
+			"edited_at": editedAt,
+		}).Error
+}
+
+func (repo *repositoryImpl) DeleteMessage(messageId uuid.UUID, deletedAt time.Time) error {
+	return repo.db.Model(&models.Messages{}).
+		Where("message_id = ? AND deleted_at IS NULL", messageId).
+		Update("deleted_at", deletedAt).Error
+}
+
+func (repo *repositoryImpl) GetReactionsInMessages(reactions *[]models.MessageReactions, messageIds []uuid.UUID) error {
+	if len(messageIds) == 0 {
+		return nil
+	}
+
+	return repo.db.Model(&models.MessageReactions{}).
+		Where("message_id IN ?", messageIds).
+		Order("created_at ASC").
+		Find(reactions).Error
+}
+
+func (repo *repositoryImpl) CreateReaction(reaction *models.MessageReactions) error {
+	return repo.db.Exec(`
+		INSERT INTO message_reactions (message_id, user_id, emoji, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`, reaction.MessageId, reaction.UserId, reaction.Emoji, reaction.CreatedAt).Error
+}
+
+func (repo *repositoryImpl) DeleteReaction(reaction *models.MessageReactions) error {
+	return repo.db.
+		Where("message_id = ? AND user_id = ? AND emoji = ?", reaction.MessageId, reaction.UserId, reaction.Emoji).
+		Delete(&models.MessageReactions{}).Error
+}
+
+func (repo *repositoryImpl) GetFilesInMessages(files *[]models.MessageFiles, messageIds []uuid.UUID) error {
+	if len(messageIds) == 0 {
+		return nil
+	}
+
+	return repo.db.Model(&models.MessageFiles{}).
+		Where("message_id IN ?", messageIds).
+		Order("created_at ASC").
+		Find(files).Error
+}
+
+func (repo *repositoryImpl) GetMessageFileById(file *models.MessageFiles, fileId uuid.UUID, userId uuid.UUID) error {
+	return repo.db.Model(&models.MessageFiles{}).
+		Raw(`
+		SELECT message_files.*
+		FROM message_files
+		JOIN messages
+		ON messages.message_id = message_files.message_id
+		JOIN conversation_participants
+		ON conversation_participants.conversation_id = messages.conversation_id
+		WHERE message_files.file_id = ?
+			AND conversation_participants.user_id = ?
+			AND messages.deleted_at IS NULL
+		`, fileId, userId).
+		First(file).Error
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchMessages matches whole words through the full text index, and any
+// part of a message through the trigram index, since Thai is written without
+// spaces between words.
+func (repo *repositoryImpl) SearchMessages(results *[]models.ChatSearchRows, searching *models.SearchingMessages) error {
+	cursorQuery := ""
+	if searching.Cursor != nil {
+		cursorQuery = "AND (messages.sent_at, messages.message_id) < (@cursor_sent_at, @cursor_message_id)"
+	} else {
+		searching.Cursor = &models.MessageCursors{}
+	}
+
+	return repo.db.Model(&models.Messages{}).
+		Raw(`
+		SELECT
+			messages.*,
+			message_attatchments.property_id,
+			message_attatchments.appointment_id,
+			message_attatchments.agreement_id,
+			conversations.property_id AS chat_property_id,
+			properties.property_name AS chat_property_name,
+			conversations.is_group AS chat_is_group,
+			conversations.name AS chat_name,
+			COALESCE(users.user_id, '00000000-0000-0000-0000-000000000000') AS partner_id,
+			COALESCE(users.first_name, '') AS partner_first_name,
+			COALESCE(users.last_name, '') AS partner_last_name,
+			COALESCE(users.profile_image_url, '') AS partner_profile_image_url
+		FROM messages
+		JOIN conversation_participants AS me
+		ON me.conversation_id = messages.conversation_id AND me.user_id = @user_id
+		JOIN conversations
+		ON conversations.conversation_id = messages.conversation_id
+		LEFT JOIN LATERAL (
+			SELECT partner.user_id
+			FROM conversation_participants AS partner
+			WHERE partner.conversation_id = messages.conversation_id
+				AND partner.user_id <> @user_id
+				AND NOT conversations.is_group
+			LIMIT 1
+		) AS partner
+		ON true
+		LEFT JOIN users
+		ON users.user_id = partner.user_id
+		LEFT JOIN properties
+		ON properties.property_id = conversations.property_id
+		LEFT JOIN message_attatchments
+		ON messages.message_id = message_attatchments.message_id
+		WHERE messages.deleted_at IS NULL
+			AND `+notFromBlockedSender+`
+			AND (
+				to_tsvector('simple', messages.content) @@ plainto_tsquery('simple', @query)
+				OR messages.content ILIKE @pattern
+			)
+			`+cursorQuery+`
+		ORDER BY messages.sent_at DESC, messages.message_id DESC
+		LIMIT @limit
+		`,
+			sql.Named("user_id", searching.UserId),
+			sql.Named("query", searching.Query),
+			sql.Named("pattern", "%"+likeEscaper.Replace(searching.Query)+"%"),
+			sql.Named("cursor_sent_at", searching.Cursor.SentAt),
+			sql.Named("cursor_message_id", searching.Cursor.MessageId),
+			sql.Named("limit", searching.Limit),
+		).Scan(results).Error
+}
+
+// GetSearchContext loads up to size messages on each side of every hit, ordered
+// from oldest to newest within a hit.
+func (repo *repositoryImpl) GetSearchContext(msgs *[]models.SearchContextRows, hitIds []uuid.UUID, userId uuid.UUID, size int) error {
+	if len(hitIds) == 0 {
+		return nil
+	}
+
+	return repo.db.Model(&models.Messages{}).
+		Raw(`
+		SELECT context.*,
+			hits.message_id AS hit_id,
+			message_attatchments.property_id,
+			message_attatchments.appointment_id,
+			message_attatchments.agreement_id
+		FROM messages AS hits
+		CROSS JOIN LATERAL (
+			(
+				SELECT messages.*, true AS is_before
+				FROM messages
+				WHERE messages.conversation_id = hits.conversation_id
+					AND (messages.sent_at, messages.message_id) < (hits.sent_at, hits.message_id)
+					AND `+notFromBlockedSender+`
+				ORDER BY messages.sent_at DESC, messages.message_id DESC
+				LIMIT @size
+			)
+			UNION ALL
+			(
+				SELECT messages.*, false AS is_before
+				FROM messages
+				WHERE messages.conversation_id = hits.conversation_id
+					AND (messages.sent_at, messages.message_id) > (hits.sent_at, hits.message_id)
+					AND `+notFromBlockedSender+`
+				ORDER BY messages.sent_at ASC, messages.message_id ASC
+				LIMIT @size
+			)
+		) AS context
+		LEFT JOIN message_attatchments
+		ON context.message_id = message_attatchments.message_id
+		WHERE hits.message_id IN @hit_ids
+		ORDER BY hits.message_id, context.sent_at ASC, context.message_id ASC
+		`,
+			sql.Named("hit_ids", hitIds),
+			sql.Named("user_id", userId),
+			sql.Named("size", size),
+		).Scan(msgs).Error
+}
+
+func (repo *repositoryImpl) GetMessagesBefore(msgs *[]models.Messages, chatId uuid.UUID, userId uuid.UUID, cursor *models.MessageCursors, limit int) error {
+	return repo.getMessagesFromCursor(msgs, chatId, userId, cursor, limit, "<", "DESC")
+}
+
+func (repo *repositoryImpl) GetMessagesAfter(msgs *[]models.Messages, chatId uuid.UUID, userId uuid.UUID, cursor *models.MessageCursors, limit int) error {
+	return repo.getMessagesFromCursor(msgs, chatId, userId, cursor, limit, ">", "ASC")
+}
+
+// getMessagesFromCursor walks away from cursor in the given direction and
+// always returns messages from oldest to newest, as userId sees them. A nil
+// cursor starts from the respective end of the chat.
+func (repo *repositoryImpl) getMessagesFromCursor(msgs *[]models.Messages, chatId uuid.UUID, userId uuid.UUID, cursor *models.MessageCursors, limit int, op string, order string) error {
+	cursorQuery := fmt.Sprintf("AND (sent_at, message_id) %v (@sent_at, @message_id)", op)
+	if cursor == nil {
+		cursorQuery = ""
+		cursor = &models.MessageCursors{}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT a.*,
+			message_attatchments.property_id,
+			message_attatchments.appointment_id,
+			message_attatchments.agreement_id
+		FROM (
+			SELECT *
+			FROM messages
+			WHERE conversation_id = @conversation_id
+				AND `+notFromBlockedSender+`
+				%v
+			ORDER BY sent_at %v, message_id %v
+			LIMIT @limit
+		) AS a
+		LEFT JOIN message_attatchments
+		ON a.message_id = message_attatchments.message_id
+		ORDER BY sent_at ASC, a.message_id ASC
+	`, cursorQuery, order, order)
+
+	return repo.db.Model(&models.Messages{}).
+		Raw(query,
+			sql.Named("conversation_id", chatId),
+			sql.Named("user_id", userId),
+			sql.Named("sent_at", cursor.SentAt),
+			sql.Named("message_id", cursor.MessageId),
+			sql.Named("limit", limit),
+		).Scan(msgs).Error
+}
+
+func (repo *repositoryImpl) GetAllMessagesInChat(msgs *[]models.Messages, chatId uuid.UUID) error {
+	return repo.db.Model(&models.Messages{}).
+		Raw(`
+		SELECT messages.*,
+			message_attatchments.property_id,
+			message_attatchments.appointment_id,
+			message_attatchments.agreement_id
+		FROM messages
+		LEFT JOIN message_attatchments
+		ON messages.message_id = message_attatchments.message_id
+		WHERE messages.conversation_id = ?
+		ORDER BY messages.sent_at ASC, messages.message_id ASC
+		`, chatId).
+		Scan(msgs).Error
+}
+
+// the transcript getters read the underlying tables so that deleted users,
+// properties, appointments and agreements are still resolved
+
+func (repo *repositoryImpl) GetTranscriptParticipants(participants *[]models.TranscriptParticipants, chatId uuid.UUID) error {
+	return repo.db.
+		Raw(`
+		SELECT _users.user_id,
+			_users.first_name,
+			_users.last_name,
+			conversation_participants.joined_at
+		FROM conversation_participants
+		JOIN _users
+		ON _users.user_id = conversation_participants.user_id
+		WHERE conversation_participants.conversation_id = ?
+		ORDER BY conversation_participants.joined_at ASC
+		`, chatId).
+		Scan(participants).Error
+}
+
+func (repo *repositoryImpl) GetTranscriptProperties(properties *[]models.TranscriptProperties, propertyIds []uuid.UUID) error {
+	if len(propertyIds) == 0 {
+		return nil
+	}
+
+	return repo.db.
+		Raw(`
+		SELECT property_id, property_name
+		FROM _properties
+		WHERE property_id IN ?
+		`, propertyIds).
+		Scan(properties).Error
+}
+
+func (repo *repositoryImpl) GetTranscriptAppointments(appointments *[]models.TranscriptAppointments, appointmentIds []uuid.UUID) error {
+	if len(appointmentIds) == 0 {
+		return nil
+	}
+
+	return repo.db.
+		Raw(`
+		SELECT appointment_id,
+			appointment_date,
+			status,
+			COALESCE(note, '') AS note,
+			cancelled_message
+		FROM _appointments
+		WHERE appointment_id IN ?
+		`, appointmentIds).
+		Scan(appointments).Error
+}
+
+func (repo *repositoryImpl) GetTranscriptAgreements(agreements *[]models.TranscriptAgreements, agreementIds []uuid.UUID) error {
+	if len(agreementIds) == 0 {
+		return nil
+	}
+
+	return repo.db.
+		Raw(`
+		SELECT agreement_id,
+			agreement_type,
+			agreement_date,
+			status,
+			deposit_amount,
+			payment_per_month,
+			payment_duration,
+			total_payment,
+			cancelled_message
+		FROM _agreements
+		WHERE agreement_id IN ?
+		`, agreementIds).
+		Scan(agreements).Error
+}
+
+func (repo *repositoryImpl) CreateUserBlock(block *models.UserBlocks) error {
+	return repo.db.Exec(`
+		INSERT INTO user_blocks (blocker_id, blocked_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`, block.BlockerId, block.BlockedId, block.CreatedAt).Error
+}
+
+func (repo *repositoryImpl) DeleteUserBlock(blockerId uuid.UUID, blockedId uuid.UUID) error {
+	return repo.db.
+		Where("blocker_id = ? AND blocked_id = ?", blockerId, blockedId).
+		Delete(&models.UserBlocks{}).Error
+}
+
+func (repo *repositoryImpl) GetBlockedUsers(blockedUsers *[]models.BlockedUsers, userId uuid.UUID) error {
+	return repo.db.Model(&models.UserBlocks{}).
+		Raw(`
+		SELECT users.user_id, users.profile_image_url, users.first_name, users.last_name, user_blocks.created_at AS blocked_at
+		FROM user_blocks
+		JOIN users
+		ON users.user_id = user_blocks.blocked_id
+		WHERE user_blocks.blocker_id = ?
+		ORDER BY user_blocks.created_at DESC
+		`, userId).
+		Scan(blockedUsers).Error
+}
+
+func (repo *repositoryImpl) CountUserBlocksBetween(count *int64, userId uuid.UUID, otherUserId uuid.UUID) error {
+	return repo.db.Model(&models.UserBlocks{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userId, otherUserId, otherUserId, userId).
+		Count(count).Error
+}
+
+// CountUserBlocksWith counts the blocks in either direction between userId and
+// any of otherUserIds.
+func (repo *repositoryImpl) CountUserBlocksWith(count *int64, userId uuid.UUID, otherUserIds []uuid.UUID) error {
+	return repo.db.Model(&models.UserBlocks{}).
+		Where("(blocker_id = ? AND blocked_id IN ?) OR (blocker_id IN ? AND blocked_id = ?)", userId, otherUserIds, otherUserIds, userId).
+		Count(count).Error
+}
+
+// GetBlockersAmong loads the users in userIds who have blocked blockedId.
+func (repo *repositoryImpl) GetBlockersAmong(blockers *[]uuid.UUID, blockedId uuid.UUID, userIds []uuid.UUID) error {
+	return repo.db.Model(&models.UserBlocks{}).
+		Where("blocked_id = ? AND blocker_id IN ?", blockedId, userIds).
+		Pluck("blocker_id", blockers).Error
+}
+
+func (repo *repositoryImpl) CreateChatMute(mute *models.ChatMutes) error {
+	return repo.db.Exec(`
+		INSERT INTO chat_mutes (user_id, conversation_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`, mute.UserId, mute.ChatId, mute.CreatedAt).Error
+}
+
+func (repo *repositoryImpl) DeleteChatMute(userId uuid.UUID, chatId uuid.UUID) error {
+	return repo.db.
+		Where("user_id = ? AND conversation_id = ?", userId, chatId).
+		Delete(&models.ChatMutes{}).Error
+}
+
+func (repo *repositoryImpl) CreateChatReport(report *models.ChatReports, snapshotSize int) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO chat_reports (report_id, reporter_id, reported_user_id, conversation_id, reason, status, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, report.ReportId, report.ReporterId, report.ReportedUserId, report.ChatId, report.Reason, report.Status, report.CreatedAt).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			INSERT INTO chat_report_messages (report_id, message_id, sender_id, content, sent_at, edited_at, deleted_at)
+			SELECT @report_id, message_id, sender_id, content, sent_at, edited_at, deleted_at
+			FROM messages
+			WHERE conversation_id = @conversation_id
+			ORDER BY sent_at DESC
+			LIMIT @limit
+		`, sql.Named("report_id", report.ReportId),
+			sql.Named("conversation_id", report.ChatId),
+			sql.Named("limit", snapshotSize)).Error
+	})
+}
+
+// CountAgreementsBetween counts the live agreements userId has with any of
+// otherUserIds, on either side.
+func (repo *repositoryImpl) CountAgreementsBetween(count *int64, userId uuid.UUID, otherUserIds []uuid.UUID) error {
+	return repo.db.Model(&models.Agreements{}).
+		Where("(owner_user_id = ? AND dweller_user_id IN ?) OR (dweller_user_id = ? AND owner_user_id IN ?)", userId, otherUserIds, userId, otherUserIds).
+		Where("status <> ?", enums.CancelledAgreement).
+		Count(count).Error
+}
+
+func (repo *repositoryImpl) CreateMessageModerations(moderations *[]models.MessageModerations) error {
+	return repo.db.Create(moderations).Error
+}
+
+func (repo *repositoryImpl) GetRevokedSessions(revoked *[]uuid.UUID, sessionIds []uuid.UUID) error {
+	return repo.db.Model(&models.RevokedSessions{}).
+		Where("session_id IN ?", sessionIds).
+		Pluck("session_id", revoked).Error
 }
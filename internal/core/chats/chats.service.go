@@ -1,29 +1,124 @@
 package chats
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
 	"github.com/brain-flowing-company/pprp-backend/internal/utils"
+	"github.com/brain-flowing-company/pprp-backend/storage"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type Service interface {
 	GetAllChats(*[]models.ChatPreviews, uuid.UUID, string) *apperror.AppError
-	GetMessagesInChat(*[]models.Messages, uuid.UUID, uuid.UUID, int, int) *apperror.AppError
-	SaveMessages(*models.Messages) *apperror.AppError
-	ReadMessages(uuid.UUID, uuid.UUID) *apperror.AppError
+	GetConversation(*models.Conversations, uuid.UUID, uuid.UUID) *apperror.AppError
+	CreateConversation(*models.Conversations, uuid.UUID, *models.CreatingConversations) *apperror.AppError
+	CreateGroupConversation(*models.Conversations, uuid.UUID, *models.CreatingGroupConversations) *apperror.AppError
+	AddMembers(*models.Conversations, uuid.UUID, []uuid.UUID) *apperror.AppError
+	RemoveMember(*models.Conversations, uuid.UUID, uuid.UUID) *apperror.AppError
+	GetMessagesInChat(*models.ChatHistories, *models.GettingChatHistories) *apperror.AppError
+	ModerateMessage(*models.Messages, *models.Conversations) *apperror.AppError
+	SaveMessages(*models.Messages, *models.Conversations) *apperror.AppError
+	ReadMessages(*models.ReadEvents) (bool, *apperror.AppError)
+	DeliverMessage(*models.DeliveryEvents) *apperror.AppError
+	DeliverAllMessages(uuid.UUID) *apperror.AppError
+	GetUnreadSummary(*models.UnreadSummaries, uuid.UUID) *apperror.AppError
+	EditMessage(*models.Messages, uuid.UUID, string) *apperror.AppError
+	DeleteMessage(*models.Messages, uuid.UUID) *apperror.AppError
+	ReactMessage(*models.Messages, *models.MessageReactions) *apperror.AppError
+	UnreactMessage(*models.Messages, *models.MessageReactions) *apperror.AppError
+	SaveFileMessages(*models.Messages, *models.Conversations, []*multipart.FileHeader) *apperror.AppError
+	GetMessageFile(*models.MessageFiles, uuid.UUID, uuid.UUID, bool) (io.ReadCloser, *apperror.AppError)
+	SearchMessages(*models.ChatSearchResults, uuid.UUID, string, string, int) *apperror.AppError
+	BlockUser(uuid.UUID, uuid.UUID) *apperror.AppError
+	UnblockUser(uuid.UUID, uuid.UUID) *apperror.AppError
+	GetBlockedUsers(*[]models.BlockedUsers, uuid.UUID) *apperror.AppError
+	CheckBlocked(uuid.UUID, uuid.UUID) *apperror.AppError
+	MuteChat(uuid.UUID, uuid.UUID) *apperror.AppError
+	UnmuteChat(uuid.UUID, uuid.UUID) *apperror.AppError
+	ReportChat(*models.ChatReports) *apperror.AppError
+	RespondToAction(*models.ActionEvents, *models.RespondingActions) *apperror.AppError
+	GetPaymentRequest(*models.PaymentRequests, uuid.UUID) *apperror.AppError
+	SettlePaymentRequests(*[]models.ActionEvents, uuid.UUID) *apperror.AppError
+	RenewPaymentRequest(*models.ActionEvents, *models.PaymentRequests) *apperror.AppError
+	ExportChat(*models.ChatTranscripts, uuid.UUID, uuid.UUID) *apperror.AppError
+	RenderTranscript(io.Writer, *models.ChatTranscripts) *apperror.AppError
+	IssueWebsocketTicket(*models.WebsocketTickets, string, string) *apperror.AppError
+	AuthenticateWebsocket(*models.SessionClaims, string, string, string) *apperror.AppError
+	GetRevokedSessions(*[]uuid.UUID, []uuid.UUID) *apperror.AppError
+}
+
+// AppointmentService and AgreementService are the parts of the appointments and
+// agreements services that action messages need. Those packages depend on the
+// hub so they can not be imported here. Responses to actions are saved by this
+// package's repository, together with the action, and then published.
+type AppointmentService interface {
+	PublishAppointment(string)
+}
+
+type AgreementService interface {
+	GetAgreementById(*models.AgreementDetails, string) *apperror.AppError
+	PublishAgreement(string)
+}
+
+const (
+	maxMessageFiles    = 5
+	maxMessageFileSize = 10 << 20
+	thumbnailSize      = 320
+	searchContextSize  = 2
+	reportSnapshotSize = 200
+	maxGroupMembers    = 20
+	transcriptTemplate = "internal/templates/ChatTranscript.html"
+	transcriptTimezone = "Asia/Bangkok"
+	// seconds, used when WS_TICKET_EXPIRE is not set
+	defaultTicketExpire = 30
+	// seconds, used when CHAT_EDIT_WINDOW is not set
+	defaultEditWindow = 900
+)
+
+var messageFileTypes = map[string]string{
+	"image/jpeg":      ".jpeg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
 }
 
 type serviceImpl struct {
-	repo   Repository
-	logger *zap.Logger
+	repo         Repository
+	logger       *zap.Logger
+	cfg          *config.Config
+	storage      storage.Storage
+	moderator    *Moderator
+	appointments AppointmentService
+	agreements   AgreementService
+	tickets      *ticketStore
 }
 
-func NewService(logger *zap.Logger, repo Repository) Service {
+func NewService(logger *zap.Logger, cfg *config.Config, repo Repository, storage storage.Storage, moderator *Moderator, appointments AppointmentService, agreements AgreementService) Service {
 	return &serviceImpl{
 		repo,
 		logger,
+		cfg,
+		storage,
+		moderator,
+		appointments,
+		agreements,
+		newTicketStore(),
 	}
 }
 
@@ -39,52 +134,2033 @@ func (s *serviceImpl) GetAllChats(chats *[]models.ChatPreviews, userId uuid.UUID
 	return nil
 }
 
-func (s *serviceImpl) GetMessagesInChat(msgs *[]models.Messages, sendUserId uuid.UUID, recvUserId uuid.UUID, offset int, limit int) *apperror.AppError {
-	limit = utils.Min(limit, 50)
+// GetConversation loads the conversation with chatId, failing unless userId
+// participates in it.
+func (s *serviceImpl) GetConversation(conversation *models.Conversations, chatId uuid.UUID, userId uuid.UUID) *apperror.AppError {
+	err := s.repo.GetConversationById(conversation, chatId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.
+			New(apperror.ChatNotFound).
+			Describe("Could not find the specified chat")
+	} else if err != nil {
+		s.logger.Error("Could not get conversation", zap.Error(err), zap.String("chatId", chatId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get chat")
+	}
+
+	if !conversation.HasParticipant(userId) {
+		return apperror.
+			New(apperror.ChatNotFound).
+			Describe("Could not find the specified chat")
+	}
+
+	return nil
+}
+
+// CreateConversation returns the conversation between userId and
+// creating.UserId about creating.PropertyId, creating it on first use.
+func (s *serviceImpl) CreateConversation(conversation *models.Conversations, userId uuid.UUID, creating *models.CreatingConversations) *apperror.AppError {
+	if userId == creating.UserId {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("could not send message to yourself")
+	}
+
+	apperr := s.CheckBlocked(userId, creating.UserId)
+	if apperr != nil {
+		return apperr
+	}
+
+	apperr = s.checkProperty(creating.PropertyId)
+	if apperr != nil {
+		return apperr
+	}
+
+	key := directKey(userId, creating.UserId)
+	participants := []uuid.UUID{userId, creating.UserId}
+	*conversation = models.Conversations{
+		ConversationId: uuid.New(),
+		PropertyId:     creating.PropertyId,
+		CreatedBy:      &userId,
+		DirectKey:      &key,
+		CreatedAt:      time.Now(),
+	}
+
+	err := s.repo.GetOrCreateConversation(conversation, participants)
+	if err != nil {
+		s.logger.Error("Could not create conversation", zap.Error(err), zap.String("userId", userId.String()), zap.String("partnerId", creating.UserId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not create chat")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) checkProperty(propertyId *uuid.UUID) *apperror.AppError {
+	if propertyId == nil {
+		return nil
+	}
+
+	var count int64
+	err := s.repo.CountProperties(&count, *propertyId)
+	if err != nil {
+		s.logger.Error("Could not count properties", zap.Error(err), zap.String("propertyId", propertyId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not create chat")
+	}
+
+	if count == 0 {
+		return apperror.
+			New(apperror.PropertyNotFound).
+			Describe("Could not find the specified property")
+	}
+
+	return nil
+}
+
+// checkUsers fails unless every user in userIds exists.
+func (s *serviceImpl) checkUsers(userIds []uuid.UUID) *apperror.AppError {
+	var count int64
+	err := s.repo.CountUsers(&count, userIds)
+	if err != nil {
+		s.logger.Error("Could not count users", zap.Error(err))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not check chat members")
+	}
+
+	if count != int64(len(userIds)) {
+		return apperror.
+			New(apperror.UserNotFound).
+			Describe("Could not find some of the specified users")
+	}
+
+	return nil
+}
+
+// uniqueUsers drops duplicates and the ids in excluded while keeping order.
+func uniqueUsers(userIds []uuid.UUID, excluded []uuid.UUID) []uuid.UUID {
+	seen := map[uuid.UUID]bool{}
+	for _, userId := range excluded {
+		seen[userId] = true
+	}
+
+	unique := []uuid.UUID{}
+	for _, userId := range userIds {
+		if !seen[userId] {
+			seen[userId] = true
+			unique = append(unique, userId)
+		}
+	}
+
+	return unique
+}
+
+func (s *serviceImpl) CreateGroupConversation(conversation *models.Conversations, userId uuid.UUID, creating *models.CreatingGroupConversations) *apperror.AppError {
+	name := strings.TrimSpace(creating.Name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Group name must be between 1 and 100 characters")
+	}
+
+	members := uniqueUsers(creating.UserIds, []uuid.UUID{userId})
+	if len(members) == 0 {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("A group needs at least one other member")
+	}
+
+	if len(members)+1 > maxGroupMembers {
+		return apperror.
+			New(apperror.ChatMembersLimitExceeded).
+			Describe(fmt.Sprintf("A group can have at most %v members", maxGroupMembers))
+	}
+
+	apperr := s.checkUsers(members)
+	if apperr != nil {
+		return apperr
+	}
+
+	apperr = s.checkGroupBlocks(userId, members)
+	if apperr != nil {
+		return apperr
+	}
+
+	apperr = s.checkProperty(creating.PropertyId)
+	if apperr != nil {
+		return apperr
+	}
+
+	*conversation = models.Conversations{
+		ConversationId: uuid.New(),
+		PropertyId:     creating.PropertyId,
+		IsGroup:        true,
+		Name:           &name,
+		CreatedBy:      &userId,
+		CreatedAt:      time.Now(),
+		Participants:   append([]uuid.UUID{userId}, members...),
+	}
+
+	err := s.repo.CreateConversation(conversation)
+	if err != nil {
+		s.logger.Error("Could not create group conversation", zap.Error(err), zap.String("userId", userId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not create chat")
+	}
+
+	return nil
+}
+
+// AddMembers lets any member of a group bring in more people. conversation
+// must be loaded with GetConversation and is updated in place.
+func (s *serviceImpl) AddMembers(conversation *models.Conversations, userId uuid.UUID, userIds []uuid.UUID) *apperror.AppError {
+	if !conversation.IsGroup {
+		return apperror.
+			New(apperror.NotGroupChat).
+			Describe("Only group chats can have members added")
+	}
+
+	members := uniqueUsers(userIds, conversation.Participants)
+	if len(members) == 0 {
+		return nil
+	}
+
+	if len(conversation.Participants)+len(members) > maxGroupMembers {
+		return apperror.
+			New(apperror.ChatMembersLimitExceeded).
+			Describe(fmt.Sprintf("A group can have at most %v members", maxGroupMembers))
+	}
+
+	apperr := s.checkUsers(members)
+	if apperr != nil {
+		return apperr
+	}
+
+	apperr = s.checkGroupBlocks(userId, members)
+	if apperr != nil {
+		return apperr
+	}
+
+	err := s.repo.AddParticipants(conversation.ConversationId, members, time.Now())
+	if err != nil {
+		s.logger.Error("Could not add chat members", zap.Error(err), zap.String("chatId", conversation.ConversationId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not add members")
+	}
+
+	conversation.Participants = append(conversation.Participants, members...)
+
+	return nil
+}
+
+// RemoveMember removes memberId from a group. Members may always remove
+// themselves; removing someone else is reserved for the group creator.
+// conversation must be loaded with GetConversation and is updated in place.
+func (s *serviceImpl) RemoveMember(conversation *models.Conversations, userId uuid.UUID, memberId uuid.UUID) *apperror.AppError {
+	if !conversation.IsGroup {
+		return apperror.
+			New(apperror.NotGroupChat).
+			Describe("Could not leave a one-to-one chat")
+	}
+
+	isCreator := conversation.CreatedBy != nil && *conversation.CreatedBy == userId
+	if memberId != userId && !isCreator {
+		return apperror.
+			New(apperror.NotChatCreator).
+			Describe("Only the group creator can remove members")
+	}
+
+	if !conversation.HasParticipant(memberId) {
+		return apperror.
+			New(apperror.UserNotFound).
+			Describe("User is not a member of this chat")
+	}
 
-	err := s.repo.GetMessagesInChat(msgs, sendUserId, recvUserId, offset, limit)
+	err := s.repo.DeleteParticipant(conversation.ConversationId, memberId)
 	if err != nil {
+		s.logger.Error("Could not remove chat member", zap.Error(err), zap.String("chatId", conversation.ConversationId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not remove member")
+	}
+
+	participants := []uuid.UUID{}
+	for _, participant := range conversation.Participants {
+		if participant != memberId {
+			participants = append(participants, participant)
+		}
+	}
+	conversation.Participants = participants
+
+	return nil
+}
+
+// directKey identifies the pair of users regardless of who started the chat.
+func directKey(userId uuid.UUID, otherUserId uuid.UUID) string {
+	a, b := userId.String(), otherUserId.String()
+	if a > b {
+		a, b = b, a
+	}
+
+	return a + ":" + b
+}
+
+func (s *serviceImpl) GetMessagesInChat(histories *models.ChatHistories, getting *models.GettingChatHistories) *apperror.AppError {
+	limit := utils.Clamp(getting.Limit, 1, 50)
+
+	conversation := models.Conversations{}
+	apperr := s.GetConversation(&conversation, getting.ChatId, getting.UserId)
+	if apperr != nil {
+		return apperr
+	}
+
+	var err error
+	switch {
+	case getting.Around != "":
+		apperr = s.getMessagesAround(histories, getting, limit)
+
+	case getting.Before != "":
+		cursor := &models.MessageCursors{}
+		if utils.DecodeMessageCursor(cursor, getting.Before) != nil {
+			return apperror.
+				New(apperror.BadRequest).
+				Describe("Invalid before cursor")
+		}
+
+		histories.HasMoreBefore, err = s.getMessagesBefore(&histories.Messages, getting, cursor, limit)
+		if err == nil {
+			histories.HasMoreAfter, err = s.hasMessagesAfter(getting, cursor)
+		}
+
+	case getting.After != "":
+		cursor := &models.MessageCursors{}
+		if utils.DecodeMessageCursor(cursor, getting.After) != nil {
+			return apperror.
+				New(apperror.BadRequest).
+				Describe("Invalid after cursor")
+		}
+
+		histories.HasMoreAfter, err = s.getMessagesAfter(&histories.Messages, getting, cursor, limit)
+		if err == nil {
+			histories.HasMoreBefore, err = s.hasMessagesBefore(getting, cursor)
+		}
+
+	default:
+		histories.HasMoreBefore, err = s.getMessagesBefore(&histories.Messages, getting, nil, limit)
+	}
+
+	if apperr != nil {
+		return apperr
+	} else if err != nil {
 		s.logger.Error("Could not get messages in chat",
 			zap.Error(err),
-			zap.String("senderUserId", sendUserId.String()),
-			zap.String("receiveruserId", recvUserId.String()))
+			zap.String("userId", getting.UserId.String()),
+			zap.String("chatId", getting.ChatId.String()))
 		return apperror.
 			New(apperror.InternalServerError).
 			Describe("Could not get messages in chat")
 	}
 
-	for i := 0; i < len(*msgs); i++ {
-		(*msgs)[i].ChatId = recvUserId
-		(*msgs)[i].Author = (*msgs)[i].SenderId == sendUserId
+	if histories.Messages == nil {
+		histories.Messages = []models.Messages{}
 	}
 
+	if len(histories.Messages) > 0 {
+		first, last := histories.Messages[0], histories.Messages[len(histories.Messages)-1]
+		histories.BeforeCursor = utils.EncodeMessageCursor(&models.MessageCursors{SentAt: first.SentAt, MessageId: first.MessageId})
+		histories.AfterCursor = utils.EncodeMessageCursor(&models.MessageCursors{SentAt: last.SentAt, MessageId: last.MessageId})
+	}
+
+	apperr = s.attachReactions(histories.Messages)
+	if apperr != nil {
+		return apperr
+	}
+
+	apperr = s.attachFiles(histories.Messages)
+	if apperr != nil {
+		return apperr
+	}
+
+	apperr = s.attachReceipts(histories.Messages)
+	if apperr != nil {
+		return apperr
+	}
+
+	apperr = s.attachActions(histories.Messages)
+	if apperr != nil {
+		return apperr
+	}
+
+	setChatPerspective(histories.Messages, getting.UserId)
+
 	return nil
 }
 
-func (s *serviceImpl) SaveMessages(msg *models.Messages) *apperror.AppError {
-	err := s.repo.SaveMessages(msg)
+// getMessagesAround loads the message with id getting.Around together with up
+// to limit/2 messages on each side of it.
+func (s *serviceImpl) getMessagesAround(histories *models.ChatHistories, getting *models.GettingChatHistories, limit int) *apperror.AppError {
+	messageId, err := uuid.Parse(getting.Around)
 	if err != nil {
-		s.logger.Error("Could not save message",
-			zap.Error(err))
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid around message id")
+	}
+
+	target := models.Messages{}
+	apperr := s.getMessage(&target, messageId)
+	if apperr != nil {
+		return apperr
+	}
+
+	if target.ChatId != getting.ChatId {
+		return apperror.
+			New(apperror.MessageNotFound).
+			Describe("Could not find the specified message")
+	}
+
+	cursor := &models.MessageCursors{SentAt: target.SentAt, MessageId: target.MessageId}
+	before, after := []models.Messages{}, []models.Messages{}
+	histories.HasMoreBefore, err = s.getMessagesBefore(&before, getting, cursor, utils.Max(limit/2, 1))
+	if err == nil {
+		histories.HasMoreAfter, err = s.getMessagesAfter(&after, getting, cursor, utils.Max(limit/2, 1))
+	}
+	if err != nil {
+		s.logger.Error("Could not get messages around", zap.Error(err), zap.String("messageId", messageId.String()))
 		return apperror.
 			New(apperror.InternalServerError).
-			Describe("error while sending message")
+			Describe("Could not get messages in chat")
 	}
 
+	histories.Messages = append(append(before, target), after...)
+
 	return nil
 }
 
-func (s *serviceImpl) ReadMessages(sendUserId uuid.UUID, recvUserId uuid.UUID) *apperror.AppError {
-	err := s.repo.ReadMessages(sendUserId, recvUserId)
+// getMessagesBefore fetches one extra row to find out whether more messages exist.
+func (s *serviceImpl) getMessagesBefore(msgs *[]models.Messages, getting *models.GettingChatHistories, cursor *models.MessageCursors, limit int) (bool, error) {
+	err := s.repo.GetMessagesBefore(msgs, getting.ChatId, getting.UserId, cursor, limit+1)
 	if err != nil {
-		s.logger.Error("Could not update mesages read status",
-			zap.Error(err),
-			zap.String("senderUserId", sendUserId.String()),
-			zap.String("receiveruserId", recvUserId.String()))
+		return false, err
+	}
+
+	hasMore := len(*msgs) > limit
+	if hasMore {
+		*msgs = (*msgs)[1:]
+	}
+
+	return hasMore, nil
+}
+
+func (s *serviceImpl) getMessagesAfter(msgs *[]models.Messages, getting *models.GettingChatHistories, cursor *models.MessageCursors, limit int) (bool, error) {
+	err := s.repo.GetMessagesAfter(msgs, getting.ChatId, getting.UserId, cursor, limit+1)
+	if err != nil {
+		return false, err
+	}
+
+	hasMore := len(*msgs) > limit
+	if hasMore {
+		*msgs = (*msgs)[:limit]
+	}
+
+	return hasMore, nil
+}
+
+func (s *serviceImpl) hasMessagesBefore(getting *models.GettingChatHistories, cursor *models.MessageCursors) (bool, error) {
+	msgs := []models.Messages{}
+	err := s.repo.GetMessagesBefore(&msgs, getting.ChatId, getting.UserId, cursor, 1)
+	return len(msgs) > 0, err
+}
+
+func (s *serviceImpl) hasMessagesAfter(getting *models.GettingChatHistories, cursor *models.MessageCursors) (bool, error) {
+	msgs := []models.Messages{}
+	err := s.repo.GetMessagesAfter(&msgs, getting.ChatId, getting.UserId, cursor, 1)
+	return len(msgs) > 0, err
+}
+
+// setChatPerspective fills the fields that depend on who is reading the chat.
+// Deleted messages keep their content for reports, readers only see that they
+// were deleted.
+func setChatPerspective(msgs []models.Messages, userId uuid.UUID) {
+	for i := 0; i < len(msgs); i++ {
+		msgs[i].Author = msgs[i].SenderId == userId
+		if msgs[i].DeletedAt != nil {
+			msgs[i].Content = ""
+		}
+	}
+}
+
+func (s *serviceImpl) attachReactions(msgs []models.Messages) *apperror.AppError {
+	messageIds := make([]uuid.UUID, len(msgs))
+	for i, msg := range msgs {
+		messageIds[i] = msg.MessageId
+	}
+
+	reactions := []models.MessageReactions{}
+	err := s.repo.GetReactionsInMessages(&reactions, messageIds)
+	if err != nil {
+		s.logger.Error("Could not get message reactions", zap.Error(err))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get messages in chat")
+	}
+
+	reactionsByMessage := map[uuid.UUID][]models.MessageReactions{}
+	for _, reaction := range reactions {
+		reactionsByMessage[reaction.MessageId] = append(reactionsByMessage[reaction.MessageId], reaction)
+	}
+
+	for i := 0; i < len(msgs); i++ {
+		msgs[i].Reactions = reactionsByMessage[msgs[i].MessageId]
+		if msgs[i].Reactions == nil {
+			msgs[i].Reactions = []models.MessageReactions{}
+		}
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) attachFiles(msgs []models.Messages) *apperror.AppError {
+	messageIds := make([]uuid.UUID, len(msgs))
+	for i, msg := range msgs {
+		messageIds[i] = msg.MessageId
+	}
+
+	files := []models.MessageFiles{}
+	err := s.repo.GetFilesInMessages(&files, messageIds)
+	if err != nil {
+		s.logger.Error("Could not get message files", zap.Error(err))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get messages in chat")
+	}
+
+	filesByMessage := map[uuid.UUID][]models.MessageFiles{}
+	for _, file := range files {
+		setMessageFileUrls(&file)
+		filesByMessage[file.MessageId] = append(filesByMessage[file.MessageId], file)
+	}
+
+	for i := 0; i < len(msgs); i++ {
+		msgs[i].Files = filesByMessage[msgs[i].MessageId]
+		if msgs[i].Files == nil || msgs[i].DeletedAt != nil {
+			msgs[i].Files = []models.MessageFiles{}
+		}
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) attachReceipts(msgs []models.Messages) *apperror.AppError {
+	messageIds := make([]uuid.UUID, len(msgs))
+	for i, msg := range msgs {
+		messageIds[i] = msg.MessageId
+	}
+
+	receipts := []models.MessageReceipts{}
+	err := s.repo.GetReceiptsInMessages(&receipts, messageIds)
+	if err != nil {
+		s.logger.Error("Could not get message receipts", zap.Error(err))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get messages in chat")
+	}
+
+	receiptsByMessage := map[uuid.UUID][]models.MessageReceipts{}
+	for _, receipt := range receipts {
+		receiptsByMessage[receipt.MessageId] = append(receiptsByMessage[receipt.MessageId], receipt)
+	}
+
+	for i := 0; i < len(msgs); i++ {
+		msgs[i].Receipts = receiptsByMessage[msgs[i].MessageId]
+		if msgs[i].Receipts == nil {
+			msgs[i].Receipts = []models.MessageReceipts{}
+		}
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) attachActions(msgs []models.Messages) *apperror.AppError {
+	messageIds := make([]uuid.UUID, len(msgs))
+	for i, msg := range msgs {
+		messageIds[i] = msg.MessageId
+	}
+
+	actions := []models.MessageActions{}
+	err := s.repo.GetActionsInMessages(&actions, messageIds)
+	if err != nil {
+		s.logger.Error("Could not get message actions", zap.Error(err))
 		return apperror.
 			New(apperror.InternalServerError).
-			Describe("error while opening chat")
+			Describe("Could not get messages in chat")
+	}
+
+	actionsByMessage := map[uuid.UUID]*models.MessageActions{}
+	for i := range actions {
+		actionsByMessage[actions[i].MessageId] = &actions[i]
+	}
+
+	for i := 0; i < len(msgs); i++ {
+		msgs[i].Action = actionsByMessage[msgs[i].MessageId]
 	}
 
 	return nil
 }
+
+func setMessageFileUrls(file *models.MessageFiles) {
+	file.Url = fmt.Sprintf("/api/v1/chats/files/%v", file.FileId)
+	if file.ThumbnailKey != nil {
+		thumbnailUrl := file.Url + "?thumbnail=true"
+		file.ThumbnailUrl = &thumbnailUrl
+	}
+}
+
+// ModerateMessage runs msg through the moderator before it is saved. Every rule
+// hit is audited; masked parts are replaced in msg.Content and blocked messages
+// are rejected.
+func (s *serviceImpl) ModerateMessage(msg *models.Messages, conversation *models.Conversations) *apperror.AppError {
+	result := s.moderator.Moderate(&ModerationInputs{
+		Message:      msg,
+		Conversation: conversation,
+		HasAgreement: func() bool {
+			var count int64
+			err := s.repo.CountAgreementsBetween(&count, msg.SenderId, without(conversation.Participants, msg.SenderId))
+			if err != nil {
+				s.logger.Error("Could not count agreements", zap.Error(err), zap.String("chatId", conversation.ConversationId.String()))
+				return false
+			}
+
+			return count > 0
+		},
+	})
+
+	if len(result.Hits) == 0 {
+		return nil
+	}
+
+	moderations := make([]models.MessageModerations, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		moderations = append(moderations, models.MessageModerations{
+			ModerationId: uuid.New(),
+			MessageId:    msg.MessageId,
+			ChatId:       conversation.ConversationId,
+			SenderId:     msg.SenderId,
+			Rule:         hit.Rule.Name(),
+			Action:       hit.Action,
+			Content:      msg.Content,
+			CreatedAt:    time.Now(),
+		})
+	}
+
+	err := s.repo.CreateMessageModerations(&moderations)
+	if err != nil {
+		s.logger.Error("Could not audit message moderation", zap.Error(err), zap.String("messageId", msg.MessageId.String()))
+	}
+
+	if result.Blocked != nil {
+		return apperror.
+			New(apperror.MessageBlocked).
+			Describe(result.Blocked.Reason())
+	}
+
+	msg.Content = result.Content
+
+	return nil
+}
+
+// SaveMessages stores msg in conversation, which the caller has already
+// checked the sender belongs to. Direct messages are refused when either side
+// has blocked the other, group messages are kept from the members who blocked
+// the sender through msg.BlockedBy.
+func (s *serviceImpl) SaveMessages(msg *models.Messages, conversation *models.Conversations) *apperror.AppError {
+	if !conversation.IsGroup {
+		apperr := s.CheckBlocked(msg.SenderId, conversation.PartnerOf(msg.SenderId))
+		if apperr != nil {
+			return apperr
+		}
+	} else {
+		err := s.repo.GetBlockersAmong(&msg.BlockedBy, msg.SenderId, without(conversation.Participants, msg.SenderId))
+		if err != nil {
+			s.logger.Error("Could not get blocking members", zap.Error(err), zap.String("chatId", conversation.ConversationId.String()))
+			return apperror.
+				New(apperror.InternalServerError).
+				Describe("error while sending message")
+		}
+	}
+
+	err := s.repo.SaveMessages(msg)
+	if err != nil {
+		s.logger.Error("Could not save message",
+			zap.Error(err),
+			zap.String("messageId", msg.MessageId.String()),
+			zap.String("chatId", msg.ChatId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("error while sending message")
+	}
+
+	return nil
+}
+
+// ReadMessages marks every message in read.ChatId up to read.MessageId as read
+// by read.UserId. It reports whether any of them was still unread.
+func (s *serviceImpl) ReadMessages(read *models.ReadEvents) (bool, *apperror.AppError) {
+	msg := models.Messages{}
+	apperr := s.getMessage(&msg, read.MessageId)
+	if apperr != nil {
+		return false, apperr
+	}
+
+	if msg.ChatId != read.ChatId {
+		return false, apperror.
+			New(apperror.MessageNotFound).
+			Describe("Could not find the specified message")
+	}
+
+	var count int64
+	read.ReadAt = time.Now()
+	cursor := &models.MessageCursors{SentAt: msg.SentAt, MessageId: msg.MessageId}
+	err := s.repo.ReadMessages(&count, read.ChatId, read.UserId, cursor, read.ReadAt)
+	if err != nil {
+		s.logger.Error("Could not update mesages read status",
+			zap.Error(err),
+			zap.String("chatId", read.ChatId.String()),
+			zap.String("userId", read.UserId.String()))
+		return false, apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not read messages")
+	}
+
+	return count > 0, nil
+}
+
+func (s *serviceImpl) DeliverMessage(delivery *models.DeliveryEvents) *apperror.AppError {
+	delivery.DeliveredAt = time.Now()
+	err := s.repo.DeliverMessage(delivery.MessageId, delivery.UserId, delivery.DeliveredAt)
+	if err != nil {
+		s.logger.Error("Could not update message delivery status",
+			zap.Error(err),
+			zap.String("messageId", delivery.MessageId.String()),
+			zap.String("userId", delivery.UserId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not deliver message")
+	}
+
+	return nil
+}
+
+// DeliverAllMessages marks everything sent to userId while offline as delivered.
+func (s *serviceImpl) DeliverAllMessages(userId uuid.UUID) *apperror.AppError {
+	err := s.repo.DeliverAllMessages(userId, time.Now())
+	if err != nil {
+		s.logger.Error("Could not update message delivery status", zap.Error(err), zap.String("userId", userId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not deliver messages")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) GetUnreadSummary(summary *models.UnreadSummaries, userId uuid.UUID) *apperror.AppError {
+	summary.Chats = []models.UnreadChats{}
+	err := s.repo.GetUnreadChats(&summary.Chats, userId)
+	if err != nil {
+		s.logger.Error("Could not get unread chats", zap.Error(err), zap.String("userId", userId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get unread messages")
+	}
+
+	summary.Total = 0
+	for _, chat := range summary.Chats {
+		if !chat.Muted {
+			summary.Total += chat.UnreadMessages
+		}
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) getMessage(msg *models.Messages, messageId uuid.UUID) *apperror.AppError {
+	err := s.repo.GetMessageById(msg, messageId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.
+			New(apperror.MessageNotFound).
+			Describe("Could not find the specified message")
+	} else if err != nil {
+		s.logger.Error("Could not get message", zap.Error(err), zap.String("messageId", messageId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get message")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) getAuthoredMessage(msg *models.Messages, userId uuid.UUID) *apperror.AppError {
+	apperr := s.getMessage(msg, msg.MessageId)
+	if apperr != nil {
+		return apperr
+	}
+
+	if msg.SenderId != userId {
+		return apperror.
+			New(apperror.NotMessageAuthor).
+			Describe("Only the author can change this message")
+	}
+
+	if msg.DeletedAt != nil {
+		return apperror.
+			New(apperror.MessageDeleted).
+			Describe("Message has already been deleted")
+	}
+
+	window := s.cfg.ChatEditWindow
+	if window <= 0 {
+		window = defaultEditWindow
+	}
+
+	if time.Since(msg.SentAt) > time.Duration(window)*time.Second {
+		return apperror.
+			New(apperror.MessageEditExpired).
+			Describe("Message can no longer be changed")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) EditMessage(msg *models.Messages, userId uuid.UUID, content string) *apperror.AppError {
+	if content == "" || utf8.RuneCountInString(content) > 4096 {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Message content must be between 1 and 4096 characters")
+	}
+
+	apperr := s.getAuthoredMessage(msg, userId)
+	if apperr != nil {
+		return apperr
+	}
+
+	conversation := models.Conversations{}
+	apperr = s.GetConversation(&conversation, msg.ChatId, userId)
+	if apperr != nil {
+		return apperr
+	}
+
+	// edits go through the same moderation as new messages
+	edited := *msg
+	edited.Content = content
+	apperr = s.ModerateMessage(&edited, &conversation)
+	if apperr != nil {
+		return apperr
+	}
+	content = edited.Content
+
+	editedAt := time.Now()
+	err := s.repo.EditMessage(msg.MessageId, content, editedAt)
+	if err != nil {
+		s.logger.Error("Could not edit message", zap.Error(err), zap.String("messageId", msg.MessageId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not edit message")
+	}
+
+	msg.Content = content
+	msg.EditedAt = &editedAt
+
+	return nil
+}
+
+func (s *serviceImpl) DeleteMessage(msg *models.Messages, userId uuid.UUID) *apperror.AppError {
+	apperr := s.getAuthoredMessage(msg, userId)
+	if apperr != nil {
+		return apperr
+	}
+
+	deletedAt := time.Now()
+	err := s.repo.DeleteMessage(msg.MessageId, deletedAt)
+	if err != nil {
+		s.logger.Error("Could not delete message", zap.Error(err), zap.String("messageId", msg.MessageId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not delete message")
+	}
+
+	msg.Content = ""
+	msg.DeletedAt = &deletedAt
+
+	return nil
+}
+
+func (s *serviceImpl) getReactableMessage(msg *models.Messages, reaction *models.MessageReactions) *apperror.AppError {
+	if reaction.Emoji == "" || len(reaction.Emoji) > 16 {
+		return apperror.
+			New(apperror.InvalidReaction).
+			Describe("Reaction must be a single emoji")
+	}
+
+	apperr := s.getMessage(msg, reaction.MessageId)
+	if apperr != nil {
+		return apperr
+	}
+
+	conversation := models.Conversations{}
+	apperr = s.GetConversation(&conversation, msg.ChatId, reaction.UserId)
+	if apperr != nil {
+		return apperr
+	}
+
+	if msg.DeletedAt != nil {
+		return apperror.
+			New(apperror.MessageDeleted).
+			Describe("Message has already been deleted")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) ReactMessage(msg *models.Messages, reaction *models.MessageReactions) *apperror.AppError {
+	apperr := s.getReactableMessage(msg, reaction)
+	if apperr != nil {
+		return apperr
+	}
+
+	reaction.CreatedAt = time.Now()
+	err := s.repo.CreateReaction(reaction)
+	if err != nil {
+		s.logger.Error("Could not react to message", zap.Error(err), zap.String("messageId", reaction.MessageId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not react to message")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) UnreactMessage(msg *models.Messages, reaction *models.MessageReactions) *apperror.AppError {
+	apperr := s.getReactableMessage(msg, reaction)
+	if apperr != nil {
+		return apperr
+	}
+
+	err := s.repo.DeleteReaction(reaction)
+	if err != nil {
+		s.logger.Error("Could not remove message reaction", zap.Error(err), zap.String("messageId", reaction.MessageId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not remove reaction")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) SaveFileMessages(msg *models.Messages, conversation *models.Conversations, files []*multipart.FileHeader) *apperror.AppError {
+	if len(files) == 0 || len(files) > maxMessageFiles {
+		return apperror.
+			New(apperror.InvalidMessageFile).
+			Describe(fmt.Sprintf("A message must contain between 1 and %v files", maxMessageFiles))
+	}
+
+	if utf8.RuneCountInString(msg.Content) > 4096 {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Message content must not exceed 4096 characters")
+	}
+
+	msg.Files = make([]models.MessageFiles, 0, len(files))
+	for _, file := range files {
+		messageFile := models.MessageFiles{}
+		apperr := s.uploadMessageFile(&messageFile, msg.MessageId, file)
+		if apperr != nil {
+			s.deleteMessageFiles(msg.Files)
+			return apperr
+		}
+		msg.Files = append(msg.Files, messageFile)
+	}
+
+	apperr := s.SaveMessages(msg, conversation)
+	if apperr != nil {
+		s.deleteMessageFiles(msg.Files)
+		return apperr
+	}
+
+	for i := range msg.Files {
+		setMessageFileUrls(&msg.Files[i])
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) uploadMessageFile(messageFile *models.MessageFiles, messageId uuid.UUID, fileHeader *multipart.FileHeader) *apperror.AppError {
+	if fileHeader.Size > maxMessageFileSize {
+		return apperror.
+			New(apperror.MessageFileTooLarge).
+			Describe(fmt.Sprintf("%v exceeds the %v MB limit", fileHeader.Filename, maxMessageFileSize>>20))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not upload file")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxMessageFileSize+1))
+	if err != nil {
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not upload file")
+	}
+
+	if len(data) > maxMessageFileSize {
+		return apperror.
+			New(apperror.MessageFileTooLarge).
+			Describe(fmt.Sprintf("%v exceeds the %v MB limit", fileHeader.Filename, maxMessageFileSize>>20))
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := messageFileTypes[contentType]
+	if !ok {
+		return apperror.
+			New(apperror.InvalidMessageFile).
+			Describe(fmt.Sprintf("App does not support %v files", filepath.Ext(fileHeader.Filename)))
+	}
+
+	*messageFile = models.MessageFiles{
+		FileId:      uuid.New(),
+		MessageId:   messageId,
+		FileName:    filepath.Base(fileHeader.Filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		CreatedAt:   time.Now(),
+	}
+
+	var thumbnail io.Reader
+	if contentType != "application/pdf" {
+		thumbnail, err = createThumbnail(contentType, data)
+		if err != nil {
+			s.logger.Error("Could not create thumbnail", zap.Error(err))
+			return apperror.
+				New(apperror.InternalServerError).
+				Describe("Could not process image")
+		}
+	}
+
+	messageFile.FileKey = fmt.Sprintf("chats/%v/%v%v", messageId.String(), messageFile.FileId.String(), ext)
+	_, err = s.storage.Upload(messageFile.FileKey, bytes.NewReader(data), types.ObjectCannedACLPrivate)
+	if err != nil {
+		s.logger.Error("Could not upload message file", zap.Error(err))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not upload file")
+	}
+
+	if thumbnail == nil {
+		return nil
+	}
+
+	thumbnailKey := fmt.Sprintf("chats/%v/%v-thumbnail.jpeg", messageId.String(), messageFile.FileId.String())
+	_, err = s.storage.Upload(thumbnailKey, thumbnail, types.ObjectCannedACLPrivate)
+	if err != nil {
+		s.logger.Error("Could not upload message file thumbnail", zap.Error(err))
+		s.deleteMessageFiles([]models.MessageFiles{*messageFile})
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not upload file")
+	}
+	messageFile.ThumbnailKey = &thumbnailKey
+
+	return nil
+}
+
+// deleteMessageFiles removes the uploads of a message that could not be saved.
+func (s *serviceImpl) deleteMessageFiles(files []models.MessageFiles) {
+	for _, file := range files {
+		keys := []string{file.FileKey}
+		if file.ThumbnailKey != nil {
+			keys = append(keys, *file.ThumbnailKey)
+		}
+
+		for _, key := range keys {
+			err := s.storage.Delete(key)
+			if err != nil {
+				s.logger.Error("Could not delete message file", zap.Error(err), zap.String("key", key))
+			}
+		}
+	}
+}
+
+func createThumbnail(contentType string, data []byte) (io.Reader, error) {
+	ip := utils.NewImageProcessor()
+
+	var err error
+	switch contentType {
+	case "image/jpeg":
+		err = ip.LoadJPEG(bytes.NewReader(data))
+
+	case "image/png":
+		err = ip.LoadPNG(bytes.NewReader(data))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = ip.Resize(thumbnailSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return ip.Save()
+}
+
+func (s *serviceImpl) GetMessageFile(file *models.MessageFiles, userId uuid.UUID, fileId uuid.UUID, thumbnail bool) (io.ReadCloser, *apperror.AppError) {
+	err := s.repo.GetMessageFileById(file, fileId, userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.
+			New(apperror.MessageFileNotFound).
+			Describe("Could not find the specified file")
+	} else if err != nil {
+		s.logger.Error("Could not get message file", zap.Error(err), zap.String("fileId", fileId.String()))
+		return nil, apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get file")
+	}
+
+	key := file.FileKey
+	if thumbnail {
+		if file.ThumbnailKey == nil {
+			return nil, apperror.
+				New(apperror.MessageFileNotFound).
+				Describe("File does not have a thumbnail")
+		}
+
+		key = *file.ThumbnailKey
+		file.ContentType = "image/jpeg"
+	}
+
+	reader, err := s.storage.Download(key)
+	if err != nil {
+		s.logger.Error("Could not download message file", zap.Error(err), zap.String("fileId", fileId.String()))
+		return nil, apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get file")
+	}
+
+	return reader, nil
+}
+
+func (s *serviceImpl) SearchMessages(results *models.ChatSearchResults, userId uuid.UUID, query string, cursor string, limit int) *apperror.AppError {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Search query is required")
+	}
+
+	// one extra row tells whether there is another page
+	limit = utils.Clamp(limit, 1, 50)
+	searching := &models.SearchingMessages{
+		UserId: userId,
+		Query:  query,
+		Limit:  limit + 1,
+	}
+
+	if cursor != "" {
+		searching.Cursor = &models.MessageCursors{}
+		err := utils.DecodeMessageCursor(searching.Cursor, cursor)
+		if err != nil {
+			return apperror.
+				New(apperror.BadRequest).
+				Describe("Invalid cursor")
+		}
+	}
+
+	rows := []models.ChatSearchRows{}
+	err := s.repo.SearchMessages(&rows, searching)
+	if err != nil {
+		s.logger.Error("Could not search messages", zap.Error(err), zap.String("userId", userId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not search messages")
+	}
+
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+
+	hitIds := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		hitIds[i] = row.MessageId
+	}
+
+	contextRows := []models.SearchContextRows{}
+	err = s.repo.GetSearchContext(&contextRows, hitIds, userId, searchContextSize)
+	if err != nil {
+		s.logger.Error("Could not get search context", zap.Error(err), zap.String("userId", userId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not search messages")
+	}
+
+	beforeByHit := map[uuid.UUID][]models.Messages{}
+	afterByHit := map[uuid.UUID][]models.Messages{}
+	for _, msg := range contextRows {
+		if msg.IsBefore {
+			beforeByHit[msg.HitId] = append(beforeByHit[msg.HitId], msg.Messages)
+		} else {
+			afterByHit[msg.HitId] = append(afterByHit[msg.HitId], msg.Messages)
+		}
+	}
+
+	results.Hits = make([]models.ChatSearchHits, len(rows))
+	for i, row := range rows {
+		hit := &results.Hits[i]
+		hit.Chat = models.ChatSearchPreviews{
+			ChatId:          row.ChatId,
+			IsGroup:         row.ChatIsGroup,
+			Name:            row.ChatName,
+			PropertyId:      row.ChatPropertyId,
+			PropertyName:    row.ChatPropertyName,
+			UserId:          row.PartnerId,
+			ProfileImageUrl: row.PartnerProfileImageUrl,
+			FirstName:       row.PartnerFirstName,
+			LastName:        row.PartnerLastName,
+		}
+		hit.Message = row.Messages
+		hit.Before = beforeByHit[row.MessageId]
+		if hit.Before == nil {
+			hit.Before = []models.Messages{}
+		}
+		hit.After = afterByHit[row.MessageId]
+		if hit.After == nil {
+			hit.After = []models.Messages{}
+		}
+
+		hit.Message.Author = hit.Message.SenderId == userId
+		setChatPerspective(hit.Before, userId)
+		setChatPerspective(hit.After, userId)
+	}
+
+	if hasMore {
+		last := rows[len(rows)-1]
+		results.NextCursor = utils.EncodeMessageCursor(&models.MessageCursors{
+			SentAt:    last.SentAt,
+			MessageId: last.MessageId,
+		})
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) BlockUser(userId uuid.UUID, blockedId uuid.UUID) *apperror.AppError {
+	if userId == blockedId {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Could not block yourself")
+	}
+
+	var count int64
+	err := s.repo.CountUsers(&count, []uuid.UUID{blockedId})
+	if err != nil {
+		s.logger.Error("Could not count users", zap.Error(err), zap.String("blockedId", blockedId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not block user")
+	} else if count == 0 {
+		return apperror.
+			New(apperror.UserNotFound).
+			Describe("Could not find the user to block")
+	}
+
+	block := &models.UserBlocks{
+		BlockerId: userId,
+		BlockedId: blockedId,
+		CreatedAt: time.Now(),
+	}
+
+	err = s.repo.CreateUserBlock(block)
+	if err != nil {
+		s.logger.Error("Could not block user", zap.Error(err), zap.String("userId", userId.String()), zap.String("blockedId", blockedId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not block user")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) UnblockUser(userId uuid.UUID, blockedId uuid.UUID) *apperror.AppError {
+	err := s.repo.DeleteUserBlock(userId, blockedId)
+	if err != nil {
+		s.logger.Error("Could not unblock user", zap.Error(err), zap.String("userId", userId.String()), zap.String("blockedId", blockedId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not unblock user")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) GetBlockedUsers(blockedUsers *[]models.BlockedUsers, userId uuid.UUID) *apperror.AppError {
+	err := s.repo.GetBlockedUsers(blockedUsers, userId)
+	if err != nil {
+		s.logger.Error("Could not get blocked users", zap.Error(err), zap.String("userId", userId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get blocked users")
+	}
+
+	return nil
+}
+
+// checkGroupBlocks refuses to put userId in a group with anyone they have
+// blocked or been blocked by.
+func (s *serviceImpl) checkGroupBlocks(userId uuid.UUID, members []uuid.UUID) *apperror.AppError {
+	var count int64
+	err := s.repo.CountUserBlocksWith(&count, userId, members)
+	if err != nil {
+		s.logger.Error("Could not count user blocks", zap.Error(err), zap.String("userId", userId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not check user blocks")
+	}
+
+	if count > 0 {
+		return apperror.
+			New(apperror.UserBlocked).
+			Describe("You can not chat with some of the specified users")
+	}
+
+	return nil
+}
+
+// CheckBlocked fails when either user has blocked the other.
+func (s *serviceImpl) CheckBlocked(senderId uuid.UUID, receiverId uuid.UUID) *apperror.AppError {
+	var count int64
+	err := s.repo.CountUserBlocksBetween(&count, senderId, receiverId)
+	if err != nil {
+		s.logger.Error("Could not count user blocks", zap.Error(err), zap.String("senderId", senderId.String()), zap.String("receiverId", receiverId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not check user blocks")
+	}
+
+	if count > 0 {
+		return apperror.
+			New(apperror.UserBlocked).
+			Describe("You can not contact this user")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) MuteChat(userId uuid.UUID, chatId uuid.UUID) *apperror.AppError {
+	conversation := models.Conversations{}
+	apperr := s.GetConversation(&conversation, chatId, userId)
+	if apperr != nil {
+		return apperr
+	}
+
+	mute := &models.ChatMutes{
+		UserId:    userId,
+		ChatId:    chatId,
+		CreatedAt: time.Now(),
+	}
+
+	err := s.repo.CreateChatMute(mute)
+	if err != nil {
+		s.logger.Error("Could not mute chat", zap.Error(err), zap.String("userId", userId.String()), zap.String("chatId", chatId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not mute chat")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) UnmuteChat(userId uuid.UUID, chatId uuid.UUID) *apperror.AppError {
+	err := s.repo.DeleteChatMute(userId, chatId)
+	if err != nil {
+		s.logger.Error("Could not unmute chat", zap.Error(err), zap.String("userId", userId.String()), zap.String("chatId", chatId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not unmute chat")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) ReportChat(report *models.ChatReports) *apperror.AppError {
+	report.Reason = strings.TrimSpace(report.Reason)
+	if report.Reason == "" {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Report reason is required")
+	}
+
+	conversation := models.Conversations{}
+	apperr := s.GetConversation(&conversation, report.ChatId, report.ReporterId)
+	if apperr != nil {
+		return apperr
+	}
+
+	if !conversation.IsGroup {
+		report.ReportedUserId = conversation.PartnerOf(report.ReporterId)
+	}
+
+	if report.ReportedUserId == report.ReporterId {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Could not report yourself")
+	}
+
+	if !conversation.HasParticipant(report.ReportedUserId) {
+		return apperror.
+			New(apperror.UserNotFound).
+			Describe("Reported user is not a member of this chat")
+	}
+
+	report.ReportId = uuid.New()
+	report.Status = enums.PendingChatReport
+	report.CreatedAt = time.Now()
+
+	err := s.repo.CreateChatReport(report, reportSnapshotSize)
+	if err != nil {
+		s.logger.Error("Could not create chat report", zap.Error(err), zap.String("reporterId", report.ReporterId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not report chat")
+	}
+
+	return nil
+}
+
+// RespondToAction applies responding.Response to the action message it names
+// and fills event with the updated card. Only the awaited actor can respond
+// and only while the action is pending.
+func (s *serviceImpl) RespondToAction(event *models.ActionEvents, responding *models.RespondingActions) *apperror.AppError {
+	msg := models.Messages{}
+	apperr := s.getMessage(&msg, responding.MessageId)
+	if apperr != nil {
+		return apperr
+	}
+
+	conversation := models.Conversations{}
+	apperr = s.GetConversation(&conversation, msg.ChatId, responding.UserId)
+	if apperr != nil {
+		return apperr
+	}
+
+	action := models.MessageActions{}
+	err := s.repo.GetMessageAction(&action, msg.MessageId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.
+			New(apperror.MessageActionNotFound).
+			Describe("This message can not be responded to")
+	} else if err != nil {
+		s.logger.Error("Could not get message action", zap.Error(err), zap.String("messageId", msg.MessageId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not respond to message")
+	}
+
+	if action.Status != enums.PendingAction {
+		return apperror.
+			New(apperror.MessageActionResolved).
+			Describe("This request has already been responded to")
+	}
+
+	if action.ActorId != responding.UserId {
+		return apperror.
+			New(apperror.NotMessageActionActor).
+			Describe("This request is waiting for the other party")
+	}
+
+	response := models.ActionResponses{}
+	switch action.ActionType {
+	case enums.AppointmentRequestAction:
+		apperr = s.respondToAppointment(&action, &response, &msg, &conversation, responding)
+	case enums.AgreementOfferAction:
+		apperr = s.respondToAgreement(&action, &response, &msg, responding)
+	default:
+		apperr = apperror.
+			New(apperror.InvalidMessageAction).
+			Describe("Payment requests are completed by paying")
+	}
+
+	if apperr != nil {
+		return apperr
+	}
+
+	action.UpdatedAt = time.Now()
+	err = s.repo.RespondToAction(&action, &response)
+	if appErr, ok := err.(*apperror.AppError); ok {
+		return appErr
+	} else if errors.Is(err, gorm.ErrRecordNotFound) && response.AgreementId != nil {
+		return apperror.
+			New(apperror.AgreementNotFound).
+			Describe("Could not find the specified agreement")
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.
+			New(apperror.AppointmentNotFound).
+			Describe("Could not find the specified appointment")
+	} else if err != nil {
+		s.logger.Error("Could not respond to message action", zap.Error(err), zap.String("messageId", msg.MessageId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not respond to message")
+	}
+
+	if response.AppointmentId != nil {
+		s.appointments.PublishAppointment(response.AppointmentId.String())
+	}
+	if response.AgreementId != nil && response.AgreementStatus != nil {
+		s.agreements.PublishAgreement(response.AgreementId.String())
+	}
+
+	*event = models.ActionEvents{
+		ChatId:      msg.ChatId,
+		MessageId:   msg.MessageId,
+		Attatchment: msg.Attatchment,
+		Action:      action,
+	}
+
+	return nil
+}
+
+// respondToAppointment updates action and fills response with the change to
+// the appointment, both are saved by the caller.
+func (s *serviceImpl) respondToAppointment(action *models.MessageActions, response *models.ActionResponses, msg *models.Messages, conversation *models.Conversations, responding *models.RespondingActions) *apperror.AppError {
+	if msg.Attatchment.AppointmentId == nil {
+		return apperror.
+			New(apperror.AppointmentNotFound).
+			Describe("Could not find the specified appointment")
+	}
+	response.AppointmentId = msg.Attatchment.AppointmentId
+
+	switch responding.Response {
+	case enums.INBOUND_ACCEPT:
+		action.Status = enums.AcceptedAction
+		response.AppointmentStatus = &models.UpdatingAppointmentStatus{
+			Status: enums.ConfirmedAppointment,
+		}
+		return nil
+
+	case enums.INBOUND_REJECT:
+		action.Status = enums.RejectedAction
+		action.Note = &responding.Reason
+		response.AppointmentStatus = &models.UpdatingAppointmentStatus{
+			Status:           enums.RejectedAppointment,
+			CancelledMessage: responding.Reason,
+		}
+		return nil
+
+	case enums.INBOUND_RESCHEDULE:
+		if responding.AppointmentDate == nil {
+			return apperror.
+				New(apperror.BadRequest).
+				Describe("appointment_date is required to reschedule")
+		}
+
+		if !responding.AppointmentDate.After(time.Now()) {
+			return apperror.
+				New(apperror.BadRequest).
+				Describe("Appointment date must be in the future")
+		}
+
+		response.Rescheduling = &models.ReschedulingAppointments{
+			AppointmentDate: *responding.AppointmentDate,
+		}
+
+		// the other party now has to accept the new date
+		action.AppointmentDate = responding.AppointmentDate
+		action.ActorId = conversation.PartnerOf(responding.UserId)
+		if responding.Reason != "" {
+			action.Note = &responding.Reason
+		}
+
+		return nil
+
+	default:
+		return apperror.
+			New(apperror.InvalidMessageAction).
+			Describe("Appointment requests can only be accepted, rejected or rescheduled")
+	}
+}
+
+// respondToAgreement updates action and fills response with the change to the
+// agreement, both are saved by the caller.
+func (s *serviceImpl) respondToAgreement(action *models.MessageActions, response *models.ActionResponses, msg *models.Messages, responding *models.RespondingActions) *apperror.AppError {
+	if msg.Attatchment.AgreementId == nil {
+		return apperror.
+			New(apperror.AgreementNotFound).
+			Describe("Could not find the specified agreement")
+	}
+	response.AgreementId = msg.Attatchment.AgreementId
+
+	switch responding.Response {
+	case enums.INBOUND_ACCEPT:
+		// the agreement is already awaiting its deposit, which the caller requests
+		action.Status = enums.AcceptedAction
+		return nil
+
+	case enums.INBOUND_REJECT:
+		action.Status = enums.RejectedAction
+		action.Note = &responding.Reason
+		response.AgreementStatus = &models.UpdatingAgreementStatus{
+			Status:           enums.CancelledAgreement,
+			CancelledMessage: responding.Reason,
+		}
+		return nil
+
+	default:
+		return apperror.
+			New(apperror.InvalidMessageAction).
+			Describe("Agreement offers can only be accepted or declined")
+	}
+}
+
+// GetPaymentRequest builds the deposit request for agreementId.
+func (s *serviceImpl) GetPaymentRequest(request *models.PaymentRequests, agreementId uuid.UUID) *apperror.AppError {
+	agreement := models.AgreementDetails{}
+	apperr := s.agreements.GetAgreementById(&agreement, agreementId.String())
+	if apperr != nil {
+		return apperr
+	}
+
+	*request = models.PaymentRequests{
+		AgreementId:   agreement.AgreementId,
+		PropertyId:    agreement.Property.PropertyId,
+		OwnerUserId:   agreement.Owner.OwnerUserId,
+		DwellerUserId: agreement.Dweller.DwellerUserId,
+		Amount:        agreement.DepositAmount,
+	}
+
+	return nil
+}
+
+// SettlePaymentRequests marks every pending payment request for agreementId as
+// paid and fills events with the updated cards.
+func (s *serviceImpl) SettlePaymentRequests(events *[]models.ActionEvents, agreementId uuid.UUID) *apperror.AppError {
+	msgs := []models.Messages{}
+	err := s.repo.GetPendingPaymentRequests(&msgs, agreementId)
+	if err != nil {
+		s.logger.Error("Could not get pending payment requests", zap.Error(err), zap.String("agreementId", agreementId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not settle payment requests")
+	}
+
+	for _, msg := range msgs {
+		action := models.MessageActions{}
+		err = s.repo.GetMessageAction(&action, msg.MessageId)
+		if err != nil {
+			s.logger.Error("Could not get message action", zap.Error(err), zap.String("messageId", msg.MessageId.String()))
+			return apperror.
+				New(apperror.InternalServerError).
+				Describe("Could not settle payment requests")
+		}
+
+		action.Status = enums.PaidAction
+		action.UpdatedAt = time.Now()
+		err = s.repo.UpdateMessageAction(&action)
+		if err != nil {
+			s.logger.Error("Could not update message action", zap.Error(err), zap.String("messageId", msg.MessageId.String()))
+			return apperror.
+				New(apperror.InternalServerError).
+				Describe("Could not settle payment requests")
+		}
+
+		*events = append(*events, models.ActionEvents{
+			ChatId:      msg.ChatId,
+			MessageId:   msg.MessageId,
+			Attatchment: msg.Attatchment,
+			Action:      action,
+		})
+	}
+
+	return nil
+}
+
+// RenewPaymentRequest moves the open card for the installment of request to its
+// new amount and fills event with it. event is left empty when there is no open
+// card to renew.
+func (s *serviceImpl) RenewPaymentRequest(event *models.ActionEvents, request *models.PaymentRequests) *apperror.AppError {
+	msg := models.Messages{}
+	err := s.repo.GetPendingInstallmentRequest(&msg, request.AgreementId, *request.Installment)
+	if err != nil {
+		s.logger.Error("Could not get pending installment request", zap.Error(err), zap.String("agreementId", request.AgreementId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not renew payment request")
+	}
+
+	if msg.MessageId == uuid.Nil {
+		return nil
+	}
+
+	action := models.MessageActions{}
+	err = s.repo.GetMessageAction(&action, msg.MessageId)
+	if err != nil {
+		s.logger.Error("Could not get message action", zap.Error(err), zap.String("messageId", msg.MessageId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not renew payment request")
+	}
+
+	action.Amount = &request.Amount
+	action.UpdatedAt = time.Now()
+	err = s.repo.UpdateMessageAction(&action)
+	if err != nil {
+		s.logger.Error("Could not update message action", zap.Error(err), zap.String("messageId", msg.MessageId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not renew payment request")
+	}
+
+	*event = models.ActionEvents{
+		ChatId:      msg.ChatId,
+		MessageId:   msg.MessageId,
+		Attatchment: msg.Attatchment,
+		Action:      action,
+	}
+
+	return nil
+}
+
+// ExportChat builds the full transcript of chatId for userId, who has to be a
+// participant.
+func (s *serviceImpl) ExportChat(transcript *models.ChatTranscripts, chatId uuid.UUID, userId uuid.UUID) *apperror.AppError {
+	conversation := models.Conversations{}
+	apperr := s.GetConversation(&conversation, chatId, userId)
+	if apperr != nil {
+		return apperr
+	}
+
+	participants := []models.TranscriptParticipants{}
+	err := s.repo.GetTranscriptParticipants(&participants, chatId)
+	if err != nil {
+		s.logger.Error("Could not get transcript participants", zap.Error(err), zap.String("chatId", chatId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not export chat")
+	}
+
+	msgs := []models.Messages{}
+	err = s.repo.GetAllMessagesInChat(&msgs, chatId)
+	if err != nil {
+		s.logger.Error("Could not get messages to export", zap.Error(err), zap.String("chatId", chatId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not export chat")
+	}
+
+	for _, attach := range []func([]models.Messages) *apperror.AppError{s.attachFiles, s.attachReceipts, s.attachActions} {
+		apperr = attach(msgs)
+		if apperr != nil {
+			return apperr
+		}
+	}
+
+	propertyIds, appointmentIds, agreementIds := []uuid.UUID{}, []uuid.UUID{}, []uuid.UUID{}
+	if conversation.PropertyId != nil {
+		propertyIds = append(propertyIds, *conversation.PropertyId)
+	}
+	for _, msg := range msgs {
+		if msg.DeletedAt != nil {
+			continue
+		}
+		if msg.Attatchment.PropertyId != nil {
+			propertyIds = append(propertyIds, *msg.Attatchment.PropertyId)
+		}
+		if msg.Attatchment.AppointmentId != nil {
+			appointmentIds = append(appointmentIds, *msg.Attatchment.AppointmentId)
+		}
+		if msg.Attatchment.AgreementId != nil {
+			agreementIds = append(agreementIds, *msg.Attatchment.AgreementId)
+		}
+	}
+
+	properties := []models.TranscriptProperties{}
+	appointments := []models.TranscriptAppointments{}
+	agreements := []models.TranscriptAgreements{}
+
+	err = s.repo.GetTranscriptProperties(&properties, propertyIds)
+	if err == nil {
+		err = s.repo.GetTranscriptAppointments(&appointments, appointmentIds)
+	}
+	if err == nil {
+		err = s.repo.GetTranscriptAgreements(&agreements, agreementIds)
+	}
+	if err != nil {
+		s.logger.Error("Could not resolve transcript attachments", zap.Error(err), zap.String("chatId", chatId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not export chat")
+	}
+
+	propertiesById := map[uuid.UUID]*models.TranscriptProperties{}
+	for i := range properties {
+		propertiesById[properties[i].PropertyId] = &properties[i]
+	}
+	appointmentsById := map[uuid.UUID]*models.TranscriptAppointments{}
+	for i := range appointments {
+		appointmentsById[appointments[i].AppointmentId] = &appointments[i]
+	}
+	agreementsById := map[uuid.UUID]*models.TranscriptAgreements{}
+	for i := range agreements {
+		agreementsById[agreements[i].AgreementId] = &agreements[i]
+	}
+
+	names := map[uuid.UUID]string{}
+	for _, participant := range participants {
+		names[participant.UserId] = fmt.Sprintf("%v %v", participant.FirstName, participant.LastName)
+	}
+
+	*transcript = models.ChatTranscripts{
+		ChatId:       conversation.ConversationId,
+		IsGroup:      conversation.IsGroup,
+		Name:         conversation.Name,
+		Participants: participants,
+		ExportedBy:   userId,
+		ExportedAt:   time.Now(),
+		Messages:     make([]models.TranscriptMessages, 0, len(msgs)),
+	}
+
+	if conversation.PropertyId != nil {
+		transcript.Property = propertiesById[*conversation.PropertyId]
+	}
+
+	for _, msg := range msgs {
+		exported := models.TranscriptMessages{
+			MessageId:  msg.MessageId,
+			SenderId:   msg.SenderId,
+			SenderName: names[msg.SenderId],
+			SentAt:     msg.SentAt,
+			EditedAt:   msg.EditedAt,
+			DeletedAt:  msg.DeletedAt,
+			Receipts:   msg.Receipts,
+			Files:      []models.TranscriptFiles{},
+		}
+
+		// former members are no longer participants
+		if exported.SenderName == "" {
+			exported.SenderName = "Former member"
+		}
+
+		// every participant can export, so a deleted message is left with
+		// only the fact that it was deleted, as in the chat itself
+		if msg.DeletedAt != nil {
+			transcript.Messages = append(transcript.Messages, exported)
+			continue
+		}
+
+		exported.Content = msg.Content
+		exported.Action = msg.Action
+
+		for _, file := range msg.Files {
+			exported.Files = append(exported.Files, models.TranscriptFiles{
+				FileName:    file.FileName,
+				ContentType: file.ContentType,
+				Size:        file.Size,
+			})
+		}
+
+		if msg.Attatchment.PropertyId != nil {
+			exported.Property = propertiesById[*msg.Attatchment.PropertyId]
+		}
+		if msg.Attatchment.AppointmentId != nil {
+			exported.Appointment = appointmentsById[*msg.Attatchment.AppointmentId]
+		}
+		if msg.Attatchment.AgreementId != nil {
+			exported.Agreement = agreementsById[*msg.Attatchment.AgreementId]
+		}
+
+		transcript.Messages = append(transcript.Messages, exported)
+	}
+
+	return nil
+}
+
+// RenderTranscript writes transcript as a printable HTML page. Browsers can
+// save it as PDF from the print dialog.
+func (s *serviceImpl) RenderTranscript(w io.Writer, transcript *models.ChatTranscripts) *apperror.AppError {
+	loc, err := time.LoadLocation(transcriptTimezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	names := map[uuid.UUID]string{}
+	for _, participant := range transcript.Participants {
+		names[participant.UserId] = fmt.Sprintf("%v %v", participant.FirstName, participant.LastName)
+	}
+
+	t, err := template.New(filepath.Base(transcriptTemplate)).
+		Funcs(template.FuncMap{
+			"time": func(t time.Time) string {
+				return t.In(loc).Format("2 Jan 2006 15:04:05 MST")
+			},
+			"name": func(userId uuid.UUID) string {
+				if name, ok := names[userId]; ok {
+					return name
+				}
+				return "Former member"
+			},
+		}).
+		ParseFiles(transcriptTemplate)
+	if err != nil {
+		s.logger.Error("Could not parse transcript template", zap.Error(err))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not export chat")
+	}
+
+	err = t.Execute(w, transcript)
+	if err != nil {
+		s.logger.Error("Could not execute transcript template", zap.Error(err))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not export chat")
+	}
+
+	return nil
+}
+
+// IssueWebsocketTicket issues a single use ticket for the session of token,
+// bound to the origin that requested it.
+func (s *serviceImpl) IssueWebsocketTicket(ticket *models.WebsocketTickets, token string, origin string) *apperror.AppError {
+	if !s.isAllowedOrigin(origin) {
+		return apperror.
+			New(apperror.InvalidOrigin).
+			Describe("Origin is not allowed")
+	}
+
+	claim, err := utils.ParseToken(token, s.cfg.JWTSecret)
+	if err != nil {
+		return apperror.
+			New(apperror.Unauthorized).
+			Describe("Invalid session")
+	}
+
+	apperr := s.checkSession(claim)
+	if apperr != nil {
+		return apperr
+	}
+
+	expire := s.cfg.WebsocketTicketExpire
+	if expire <= 0 {
+		expire = defaultTicketExpire
+	}
+
+	ticket.Origin = origin
+	ticket.Claims = claim
+	ticket.ExpiresAt = time.Now().Add(time.Duration(expire) * time.Second)
+	if ticket.ExpiresAt.After(claim.ExpiresTime()) {
+		ticket.ExpiresAt = claim.ExpiresTime()
+	}
+
+	err = s.tickets.Issue(ticket)
+	if err != nil {
+		s.logger.Error("Could not issue websocket ticket", zap.Error(err))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not issue ticket")
+	}
+
+	return nil
+}
+
+// AuthenticateWebsocket resolves the session of a websocket connecting from
+// origin, either from a ticket or, when there is none, from the session
+// cookie.
+func (s *serviceImpl) AuthenticateWebsocket(claim *models.SessionClaims, ticket string, token string, origin string) *apperror.AppError {
+	if !s.isAllowedOrigin(origin) {
+		return apperror.
+			New(apperror.InvalidOrigin).
+			Describe("Origin is not allowed")
+	}
+
+	if ticket != "" {
+		issued, ok := s.tickets.Redeem(ticket)
+		if !ok || issued.Origin != origin {
+			return apperror.
+				New(apperror.InvalidTicket).
+				Describe("Ticket is invalid, expired or already used")
+		}
+
+		*claim = *issued.Claims
+	} else {
+		parsed, err := utils.ParseToken(token, s.cfg.JWTSecret)
+		if err != nil {
+			return apperror.
+				New(apperror.Unauthorized).
+				Describe("Invalid session")
+		}
+
+		*claim = *parsed
+	}
+
+	return s.checkSession(claim)
+}
+
+func (s *serviceImpl) checkSession(claim *models.SessionClaims) *apperror.AppError {
+	if time.Now().After(claim.ExpiresTime()) {
+		return apperror.
+			New(apperror.SessionExpired).
+			Describe("Session has expired")
+	}
+
+	revoked := []uuid.UUID{}
+	apperr := s.GetRevokedSessions(&revoked, []uuid.UUID{claim.SessionId()})
+	if apperr != nil {
+		return apperr
+	}
+
+	if len(revoked) > 0 {
+		return apperror.
+			New(apperror.SessionRevoked).
+			Describe("Session has been logged out")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) GetRevokedSessions(revoked *[]uuid.UUID, sessionIds []uuid.UUID) *apperror.AppError {
+	err := s.repo.GetRevokedSessions(revoked, sessionIds)
+	if err != nil {
+		s.logger.Error("Could not get revoked sessions", zap.Error(err))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not check session")
+	}
+
+	return nil
+}
+
+// isAllowedOrigin accepts the origins of APP_ALLOW_ORIGIN. Native clients do
+// not send an Origin at all and are let through.
+func (s *serviceImpl) isAllowedOrigin(origin string) bool {
+	if origin == "" {
+		return true
+	}
+
+	for _, allowed := range strings.Split(s.cfg.AllowOrigin, ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,239 @@
+package chats
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// transcriptRepo serves a single conversation for exports. Methods the tests
+// do not reach are left to the embedded interface and panic.
+type transcriptRepo struct {
+	Repository
+
+	conversation models.Conversations
+	messages     []models.Messages
+	files        []models.MessageFiles
+	properties   []models.TranscriptProperties
+}
+
+func (r *transcriptRepo) GetConversationById(conversation *models.Conversations, _ uuid.UUID) error {
+	*conversation = r.conversation
+	return nil
+}
+
+func (r *transcriptRepo) GetTranscriptParticipants(participants *[]models.TranscriptParticipants, _ uuid.UUID) error {
+	*participants = []models.TranscriptParticipants{}
+	for _, userId := range r.conversation.Participants {
+		*participants = append(*participants, models.TranscriptParticipants{UserId: userId, FirstName: "Test", LastName: "User"})
+	}
+	return nil
+}
+
+func (r *transcriptRepo) GetAllMessagesInChat(msgs *[]models.Messages, _ uuid.UUID) error {
+	*msgs = append([]models.Messages{}, r.messages...)
+	return nil
+}
+
+func (r *transcriptRepo) GetFilesInMessages(files *[]models.MessageFiles, _ []uuid.UUID) error {
+	*files = append([]models.MessageFiles{}, r.files...)
+	return nil
+}
+
+func (r *transcriptRepo) GetReceiptsInMessages(*[]models.MessageReceipts, []uuid.UUID) error {
+	return nil
+}
+
+func (r *transcriptRepo) GetActionsInMessages(*[]models.MessageActions, []uuid.UUID) error {
+	return nil
+}
+
+func (r *transcriptRepo) GetTranscriptProperties(properties *[]models.TranscriptProperties, propertyIds []uuid.UUID) error {
+	for _, property := range r.properties {
+		if slices.Contains(propertyIds, property.PropertyId) {
+			*properties = append(*properties, property)
+		}
+	}
+	return nil
+}
+
+func (r *transcriptRepo) GetTranscriptAppointments(*[]models.TranscriptAppointments, []uuid.UUID) error {
+	return nil
+}
+
+func (r *transcriptRepo) GetTranscriptAgreements(*[]models.TranscriptAgreements, []uuid.UUID) error {
+	return nil
+}
+
+func TestExportChatLeavesOutDeletedMessages(t *testing.T) {
+	author, reader := uuid.New(), uuid.New()
+	chatId := uuid.New()
+	propertyId := uuid.New()
+	deletedAt := time.Now()
+
+	live := models.Messages{MessageId: uuid.New(), ChatId: chatId, SenderId: author, Content: "see you at the viewing", SentAt: time.Now()}
+	deleted := models.Messages{MessageId: uuid.New(), ChatId: chatId, SenderId: author, Content: "my bank pin is 4242", SentAt: time.Now(), DeletedAt: &deletedAt}
+	deleted.Attatchment.PropertyId = &propertyId
+
+	repo := &transcriptRepo{
+		conversation: models.Conversations{ConversationId: chatId, Participants: []uuid.UUID{author, reader}},
+		messages:     []models.Messages{live, deleted},
+		files:        []models.MessageFiles{{FileId: uuid.New(), MessageId: deleted.MessageId, FileName: "bank-statement.pdf", ContentType: "application/pdf", Size: 1024}},
+		properties:   []models.TranscriptProperties{{PropertyId: propertyId, PropertyName: "Hidden Condo"}},
+	}
+	service := NewService(zap.NewNop(), &config.Config{}, repo, nil, nil, nil, nil)
+
+	transcript := models.ChatTranscripts{}
+	if apperr := service.ExportChat(&transcript, chatId, reader); apperr != nil {
+		t.Fatal(apperr)
+	}
+
+	exported, err := json.Marshal(transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(exported), live.Content) {
+		t.Fatalf("export is missing the live message: %s", exported)
+	}
+	for _, hidden := range []string{deleted.Content, "bank-statement.pdf", "Hidden Condo"} {
+		if strings.Contains(string(exported), hidden) {
+			t.Fatalf("export contains %q of a deleted message: %s", hidden, exported)
+		}
+	}
+
+	if len(transcript.Messages) != 2 || transcript.Messages[1].DeletedAt == nil {
+		t.Fatalf("deleted message should still be listed as deleted: %+v", transcript.Messages)
+	}
+}
+
+// searchRepo finds the first Limit of its hits, like the database would.
+type searchRepo struct {
+	Repository
+
+	hits []models.ChatSearchRows
+}
+
+func (r *searchRepo) SearchMessages(rows *[]models.ChatSearchRows, searching *models.SearchingMessages) error {
+	*rows = r.hits[:min(len(r.hits), searching.Limit)]
+	return nil
+}
+
+func (r *searchRepo) GetSearchContext(*[]models.SearchContextRows, []uuid.UUID, uuid.UUID, int) error {
+	return nil
+}
+
+func TestSearchMessagesPaging(t *testing.T) {
+	hits := make([]models.ChatSearchRows, 3)
+	for i := range hits {
+		hits[i].MessageId = uuid.New()
+		hits[i].SentAt = time.Now().Add(-time.Duration(i) * time.Minute)
+	}
+
+	tests := []struct {
+		limit int
+		found int
+		more  bool
+	}{
+		{2, 2, true},
+		{3, 3, false},
+		{4, 3, false},
+	}
+
+	for _, tt := range tests {
+		service := NewService(zap.NewNop(), &config.Config{}, &searchRepo{hits: hits}, nil, nil, nil, nil)
+
+		results := models.ChatSearchResults{}
+		if apperr := service.SearchMessages(&results, uuid.New(), "บ้าน", "", tt.limit); apperr != nil {
+			t.Fatal(apperr)
+		}
+
+		if len(results.Hits) != tt.found || (results.NextCursor != "") != tt.more {
+			t.Fatalf("limit %v: got %v hits with cursor %q, expected %v hits and more %v", tt.limit, len(results.Hits), results.NextCursor, tt.found, tt.more)
+		}
+	}
+}
+
+// messageRepo serves a single message.
+type messageRepo struct {
+	Repository
+
+	msg models.Messages
+}
+
+func (r *messageRepo) GetMessageById(msg *models.Messages, _ uuid.UUID) error {
+	*msg = r.msg
+	return nil
+}
+
+func TestGetAuthoredMessageEditWindow(t *testing.T) {
+	author := uuid.New()
+
+	tests := []struct {
+		window  int
+		sent    time.Duration
+		expired bool
+	}{
+		{0, time.Minute, false},
+		{0, time.Hour, true},
+		{7200, time.Hour, false},
+		{60, 2 * time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		repo := &messageRepo{msg: models.Messages{MessageId: uuid.New(), SenderId: author, SentAt: time.Now().Add(-tt.sent)}}
+		service := &serviceImpl{repo: repo, logger: zap.NewNop(), cfg: &config.Config{ChatEditWindow: tt.window}}
+
+		msg := models.Messages{MessageId: repo.msg.MessageId}
+		apperr := service.getAuthoredMessage(&msg, author)
+		if expired := apperr != nil && apperr.Name() == apperror.MessageEditExpired.Name; expired != tt.expired || (apperr != nil && !expired) {
+			t.Fatalf("window %v, sent %v ago: got %v, expected expired %v", tt.window, tt.sent, apperr, tt.expired)
+		}
+	}
+}
+
+// blockRepo knows the users in users and records the blocks it creates.
+type blockRepo struct {
+	Repository
+
+	users  []uuid.UUID
+	blocks []models.UserBlocks
+}
+
+func (r *blockRepo) CountUsers(count *int64, userIds []uuid.UUID) error {
+	*count = 0
+	for _, userId := range userIds {
+		if slices.Contains(r.users, userId) {
+			*count++
+		}
+	}
+	return nil
+}
+
+func (r *blockRepo) CreateUserBlock(block *models.UserBlocks) error {
+	r.blocks = append(r.blocks, *block)
+	return nil
+}
+
+func TestBlockUserNeedsAnExistingUser(t *testing.T) {
+	blocker, blocked := uuid.New(), uuid.New()
+	repo := &blockRepo{users: []uuid.UUID{blocker, blocked}}
+	service := NewService(zap.NewNop(), &config.Config{}, repo, nil, nil, nil, nil)
+
+	apperr := service.BlockUser(blocker, uuid.New())
+	if apperr == nil || apperr.Name() != apperror.UserNotFound.Name || len(repo.blocks) != 0 {
+		t.Fatalf("blocking an unknown user: got %v with %v blocks", apperr, len(repo.blocks))
+	}
+
+	if apperr = service.BlockUser(blocker, blocked); apperr != nil || len(repo.blocks) != 1 {
+		t.Fatalf("blocking a user: got %v with %v blocks", apperr, len(repo.blocks))
+	}
+}
@@ -3,99 +3,311 @@ package chats
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/events"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/notifications"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
 	"github.com/google/uuid"
 )
 
+const sessionCheckInterval = 15 * time.Second
+
+// Hub tracks the chat state of every connected client. Clients are also
+// subscribed to stream so that other services can push their events over the
+// same connection. Clients come and go on their own goroutines while services
+// and workers send through the hub, so clients is only used under the lock.
 type Hub struct {
-	sync.Mutex
-	clients map[uuid.UUID]*WebsocketClients
-	service Service
+	sync.RWMutex
+	clients       map[uuid.UUID]*WebsocketClients
+	service       Service
+	notifications notifications.Service
+	stream        *events.Stream
 }
 
-func NewHub(service Service) *Hub {
+func NewHub(service Service, notifications notifications.Service, stream *events.Stream) *Hub {
 	return &Hub{
-		clients: make(map[uuid.UUID]*WebsocketClients),
-		service: service,
+		clients:       make(map[uuid.UUID]*WebsocketClients),
+		service:       service,
+		notifications: notifications,
+		stream:        stream,
 	}
 }
 
+// GetUser returns the client of userId, or nil if they are offline.
 func (h *Hub) GetUser(userId uuid.UUID) *WebsocketClients {
-	return h.clients[userId]
+	client, _ := h.getClient(userId)
+	return client
 }
 
-func (h *Hub) SendNotificationMessage(attatchment interface{}, content string, senderId uuid.UUID, receiverId uuid.UUID) *apperror.AppError {
-	var readAt *time.Time
-	now := time.Now()
-	if h.IsReceiverInChat(senderId, receiverId) {
-		readAt = &now
-	}
+// getClient looks up the client of userId once, so that a client found online
+// can be used even if it unregisters right after.
+func (h *Hub) getClient(userId uuid.UUID) (*WebsocketClients, bool) {
+	h.RLock()
+	defer h.RUnlock()
+
+	client, online := h.clients[userId]
+	return client, online
+}
 
+func (h *Hub) SendNotificationMessage(attatchment interface{}, content string, senderId uuid.UUID, receiverId uuid.UUID) *apperror.AppError {
 	msg := &models.Messages{
 		MessageId:   uuid.New(),
 		SenderId:    senderId,
-		ReceiverId:  receiverId,
-		ChatId:      senderId,
 		Author:      true,
-		ReadAt:      readAt,
 		Content:     content,
-		SentAt:      now,
+		SentAt:      time.Now(),
 		Attatchment: models.MessageAttatchments{},
 	}
 
+	var propertyId uuid.UUID
 	switch attch := attatchment.(type) {
 	case *models.CreatingAppointments:
 		msg.Attatchment.AppointmentId = &attch.AppointmentId
+		msg.Action = newAction(enums.AppointmentRequestAction, receiverId)
+		msg.Action.AppointmentDate = &attch.AppointmentDate
+		propertyId = attch.PropertyId
 	case *models.CreatingAgreements:
 		msg.Attatchment.AgreementId = &attch.AgreementId
+		msg.Action = newAction(enums.AgreementOfferAction, receiverId)
+		propertyId = attch.PropertyId
+	case *models.PaymentRequests:
+		msg.Attatchment.AgreementId = &attch.AgreementId
+		msg.Action = newAction(enums.PaymentRequestAction, receiverId)
+		msg.Action.Amount = &attch.Amount
+		msg.Action.Installment = attch.Installment
+		propertyId = attch.PropertyId
+	case *models.PaymentNotifications:
+		msg.Attatchment.AgreementId = &attch.AgreementId
+		propertyId = attch.PropertyId
 	case *models.Properties:
 		msg.Attatchment.PropertyId = &attch.PropertyId
+		propertyId = attch.PropertyId
 	default:
 		return apperror.New(apperror.BadRequest).Describe(fmt.Sprintf("notification message does not support %v", reflect.TypeOf(attatchment)))
 	}
 
-	err := h.service.SaveMessages(msg)
+	conversation := models.Conversations{}
+	err := h.service.CreateConversation(&conversation, senderId, &models.CreatingConversations{
+		UserId:     receiverId,
+		PropertyId: &propertyId,
+	})
 	if err != nil {
 		return err
 	}
 
-	if h.IsUserOnline(senderId) {
-		msg.ChatId = receiverId
-		msg.Author = true
-		h.GetUser(senderId).SendOutBoundMessage(msg.ToOutBound())
+	// an installment keeps a single open card, so a later request for it moves
+	// that card to the new amount and is otherwise sent as a plain message
+	if request, ok := attatchment.(*models.PaymentRequests); ok && request.Installment != nil {
+		renewed := models.ActionEvents{}
+		err = h.service.RenewPaymentRequest(&renewed, request)
+		if err != nil {
+			return err
+		}
+
+		if renewed.MessageId != uuid.Nil {
+			h.Broadcast(conversation.Participants, &renewed)
+			msg.Action = nil
+		}
+	}
+
+	msg.ChatId = conversation.ConversationId
+	err = h.service.SaveMessages(msg, &conversation)
+	if err != nil {
+		return err
+	}
+
+	h.BroadcastMessage(msg, conversation.Participants)
+
+	return nil
+}
+
+// SettlePaymentRequests marks the pending payment requests of agreementId as
+// paid and updates the cards for participants, the two sides of the agreement.
+func (h *Hub) SettlePaymentRequests(agreementId uuid.UUID, participants []uuid.UUID) *apperror.AppError {
+	settled := []models.ActionEvents{}
+	apperr := h.service.SettlePaymentRequests(&settled, agreementId)
+	if apperr != nil {
+		return apperr
+	}
+
+	for i := range settled {
+		h.Broadcast(participants, &settled[i])
+	}
+
+	return nil
+}
+
+func newAction(actionType enums.MessageActionTypes, actorId uuid.UUID) *models.MessageActions {
+	return &models.MessageActions{
+		ActionType: actionType,
+		Status:     enums.PendingAction,
+		ActorId:    actorId,
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// BroadcastMessage sends msg to every online participant, tagged only for the
+// sender. Recipients who get it right away have it marked as delivered and
+// receive their new unread summary; offline recipients get a web push.
+// Participants in msg.BlockedBy get nothing.
+func (h *Hub) BroadcastMessage(msg *models.Messages, participants []uuid.UUID) {
+	tag := msg.Tag
+	defer func() { msg.Tag = tag }()
+
+	offline := []uuid.UUID{}
+	for _, userId := range participants {
+		if slices.Contains(msg.BlockedBy, userId) {
+			continue
+		}
+
+		client, online := h.getClient(userId)
+		if !online {
+			if userId != msg.SenderId {
+				offline = append(offline, userId)
+			}
+			continue
+		}
+
+		msg.Author = userId == msg.SenderId
+		msg.Tag = ""
+		if msg.Author {
+			msg.Tag = tag
+		}
+		client.SendOutBoundMessage(msg.ToOutBound())
+
+		if !msg.Author {
+			h.deliverMessage(msg, userId)
+			h.SendUnreadSummary(userId)
+		}
+	}
+
+	if len(offline) > 0 {
+		notified := *msg
+		go h.notifications.NotifyMessage(&notified, offline)
 	}
+}
 
-	if h.IsUserOnline(receiverId) {
-		msg.ChatId = senderId
-		msg.Author = false
-		h.GetUser(receiverId).SendOutBoundMessage(msg.ToOutBound())
+func (h *Hub) deliverMessage(msg *models.Messages, userId uuid.UUID) {
+	delivery := &models.DeliveryEvents{
+		ChatId:    msg.ChatId,
+		UserId:    userId,
+		MessageId: msg.MessageId,
 	}
 
+	apperr := h.service.DeliverMessage(delivery)
+	if apperr != nil {
+		return
+	}
+
+	if sender, online := h.getClient(msg.SenderId); online {
+		sender.SendOutBoundMessage(delivery.ToOutBound())
+	}
+}
+
+// Broadcast sends event to every online user in userIds.
+func (h *Hub) Broadcast(userIds []uuid.UUID, event models.OutBoundPayload) {
+	h.stream.Publish(userIds, event)
+}
+
+func (h *Hub) SendUnreadSummaries(userIds []uuid.UUID) {
+	for _, userId := range userIds {
+		h.SendUnreadSummary(userId)
+	}
+}
+
+// SendUnreadSummary pushes the current unread counters to userId if online.
+func (h *Hub) SendUnreadSummary(userId uuid.UUID) *apperror.AppError {
+	client, online := h.getClient(userId)
+	if !online {
+		return nil
+	}
+
+	summary := &models.UnreadSummaries{}
+	apperr := h.service.GetUnreadSummary(summary, userId)
+	if apperr != nil {
+		return apperr
+	}
+
+	client.SendOutBoundMessage(summary.ToOutBound())
+
 	return nil
 }
 
+func without(userIds []uuid.UUID, excluded uuid.UUID) []uuid.UUID {
+	result := make([]uuid.UUID, 0, len(userIds))
+	for _, userId := range userIds {
+		if userId != excluded {
+			result = append(result, userId)
+		}
+	}
+
+	return result
+}
+
+func (h *Hub) CheckBlocked(senderId uuid.UUID, receiverId uuid.UUID) *apperror.AppError {
+	return h.service.CheckBlocked(senderId, receiverId)
+}
+
 func (h *Hub) IsUserOnline(userId uuid.UUID) bool {
-	_, online := h.clients[userId]
+	_, online := h.getClient(userId)
 	return online
 }
 
-func (h *Hub) IsReceiverInChat(sendUserId uuid.UUID, recvUserId uuid.UUID) bool {
-	recvUser, recvOnline := h.clients[recvUserId]
-
-	if !recvOnline {
+// IsUserInChat reports whether userId currently has the chat open.
+func (h *Hub) IsUserInChat(userId uuid.UUID, chatId uuid.UUID) bool {
+	user, online := h.getClient(userId)
+	if !online {
 		return false
 	}
 
-	return recvUser.RecvUserId != nil && *recvUser.RecvUserId == sendUserId
+	return user.ChatId != nil && *user.ChatId == chatId
 }
 
-func (h *Hub) IsUserBothInChat(sendUserId uuid.UUID, recvUserId uuid.UUID) bool {
-	return h.IsReceiverInChat(sendUserId, recvUserId) &&
-		h.IsReceiverInChat(recvUserId, sendUserId)
+// WatchSessions closes the connections of sessions that have expired or have
+// been logged out. It never returns.
+func (h *Hub) WatchSessions() {
+	ticker := time.NewTicker(sessionCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		h.RLock()
+		clients := make([]*WebsocketClients, 0, len(h.clients))
+		for _, client := range h.clients {
+			clients = append(clients, client)
+		}
+		h.RUnlock()
+
+		sessionIds := make([]uuid.UUID, 0, len(clients))
+		for _, client := range clients {
+			if client.SessionId != uuid.Nil {
+				sessionIds = append(sessionIds, client.SessionId)
+			}
+		}
+
+		revoked := map[uuid.UUID]bool{}
+		if len(sessionIds) > 0 {
+			revokedIds := []uuid.UUID{}
+			if h.service.GetRevokedSessions(&revokedIds, sessionIds) == nil {
+				for _, sessionId := range revokedIds {
+					revoked[sessionId] = true
+				}
+			}
+		}
+
+		now := time.Now()
+		for _, client := range clients {
+			if revoked[client.SessionId] {
+				client.Terminate(apperror.New(apperror.SessionRevoked).Describe("Session has been logged out"))
+			} else if now.After(client.ExpiresAt) {
+				client.Terminate(apperror.New(apperror.SessionExpired).Describe("Session has expired"))
+			}
+		}
+	}
 }
 
 func (h *Hub) Register(client *WebsocketClients) {
@@ -103,6 +315,7 @@ func (h *Hub) Register(client *WebsocketClients) {
 	_, ok := h.clients[client.UserId]
 	if !ok {
 		h.clients[client.UserId] = client
+		h.stream.Subscribe(client.UserId, client)
 	}
 	h.Unlock()
 }
@@ -112,7 +325,12 @@ func (h *Hub) Unregister(client *WebsocketClients) {
 	_, ok := h.clients[client.UserId]
 	if ok {
 		delete(h.clients, client.UserId)
-		client.Close()
+		h.stream.Unsubscribe(client.UserId, client)
 	}
 	h.Unlock()
+
+	// closing waits for sends in flight, which must not hold up the hub
+	if ok {
+		client.Close()
+	}
 }
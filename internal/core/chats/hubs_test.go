@@ -0,0 +1,61 @@
+package chats
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/core/events"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// newTestClient makes a client without a connection, whose messages are
+// drained until it is closed.
+func newTestClient(userId uuid.UUID) *WebsocketClients {
+	router := &WebsocketRouter{outBoundMessages: make(chan *models.OutBoundMessages, 16)}
+	go func() {
+		for range router.outBoundMessages {
+		}
+	}()
+
+	return &WebsocketClients{
+		router: router,
+		UserId: userId,
+	}
+}
+
+func TestHubLooksUpClientsWhileTheyComeAndGo(t *testing.T) {
+	hub := NewHub(nil, nil, events.NewStream())
+	userId := uuid.New()
+	chatId := uuid.New()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			client := newTestClient(userId)
+			hub.Register(client)
+			hub.Unregister(client)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			hub.IsUserOnline(userId)
+			hub.IsUserInChat(userId, chatId)
+			if client, online := hub.getClient(userId); online {
+				// the client may have been closed in the meantime
+				client.SendOutBoundMessage(&models.OutBoundMessages{})
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	if hub.IsUserOnline(userId) {
+		t.Fatal("unregistered client is still online")
+	}
+}
@@ -0,0 +1,291 @@
+package chats
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/google/uuid"
+)
+
+const (
+	moderationMask  = "***"
+	rateLimitWindow = time.Minute
+)
+
+// ModerationInputs is what a rule gets to look at. HasAgreement is evaluated
+// lazily since it costs a query and most messages never need it.
+type ModerationInputs struct {
+	Message      *models.Messages
+	Conversation *models.Conversations
+	HasAgreement func() bool
+}
+
+// ModerationRule inspects an outgoing message. Check reports whether the
+// message violates the rule and, for content rules, the byte ranges of the
+// offending parts so that they can be masked.
+type ModerationRule interface {
+	Name() string
+	Action() enums.ModerationActions
+	Reason() string
+	Check(*ModerationInputs) (bool, [][]int)
+}
+
+type ModerationHits struct {
+	Rule   ModerationRule
+	Action enums.ModerationActions
+}
+
+type ModerationResults struct {
+	Hits    []ModerationHits
+	Content string
+	Blocked ModerationRule
+}
+
+// Moderator runs every registered rule over a message.
+type Moderator struct {
+	rules []ModerationRule
+}
+
+// NewModerator returns a moderator with the built-in rules configured from
+// cfg. More rules can be plugged in with Use.
+func NewModerator(cfg *config.Config) *Moderator {
+	m := &Moderator{}
+
+	m.Use(&contactRule{
+		action: parseModerationAction(cfg.ContactAction, enums.MaskModeration),
+	})
+	m.Use(&profanityRule{
+		action: parseModerationAction(cfg.ProfanityAction, enums.MaskModeration),
+	})
+	m.Use(&linkRule{
+		action:       parseModerationAction(cfg.LinkAction, enums.FlagModeration),
+		allowedHosts: cfg.AllowedLinkHosts,
+	})
+
+	if cfg.RateLimit > 0 {
+		m.Use(&rateLimitRule{
+			action: parseModerationAction(cfg.RateLimitAction, enums.BlockModeration),
+			limit:  cfg.RateLimit,
+			sent:   make(map[uuid.UUID][]time.Time),
+		})
+	}
+
+	return m
+}
+
+func (m *Moderator) Use(rule ModerationRule) {
+	m.rules = append(m.rules, rule)
+}
+
+// Moderate checks the message against every rule. Masking rules have their
+// matches replaced in Content; the first blocking rule is reported in
+// Blocked.
+func (m *Moderator) Moderate(inputs *ModerationInputs) *ModerationResults {
+	result := &ModerationResults{
+		Hits:    []ModerationHits{},
+		Content: inputs.Message.Content,
+	}
+
+	masks := [][]int{}
+	for _, rule := range m.rules {
+		matched, spans := rule.Check(inputs)
+		if !matched {
+			continue
+		}
+
+		action := rule.Action()
+		result.Hits = append(result.Hits, ModerationHits{rule, action})
+
+		switch action {
+		case enums.MaskModeration:
+			masks = append(masks, spans...)
+		case enums.BlockModeration:
+			if result.Blocked == nil {
+				result.Blocked = rule
+			}
+		}
+	}
+
+	result.Content = mask(result.Content, masks)
+
+	return result
+}
+
+func mask(content string, spans [][]int) string {
+	if len(spans) == 0 {
+		return content
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	merged := [][]int{}
+	for _, span := range spans {
+		if n := len(merged); n > 0 && span[0] <= merged[n-1][1] {
+			merged[n-1][1] = max(merged[n-1][1], span[1])
+			continue
+		}
+
+		merged = append(merged, []int{span[0], span[1]})
+	}
+
+	var masked strings.Builder
+	last := 0
+	for _, span := range merged {
+		masked.WriteString(content[last:span[0]])
+		masked.WriteString(moderationMask)
+		last = span[1]
+	}
+	masked.WriteString(content[last:])
+
+	return masked.String()
+}
+
+func parseModerationAction(action string, fallback enums.ModerationActions) enums.ModerationActions {
+	parsed := enums.ModerationActions(strings.ToUpper(action))
+	if parsed.Severity() == 0 {
+		return fallback
+	}
+
+	return parsed
+}
+
+var (
+	phonePattern = regexp.MustCompile(`(?:\+66|\b0)[\s-]?\d(?:[\s-]?\d){7,8}\b`)
+	bankPattern  = regexp.MustCompile(`\b\d{3}[\s-]?\d[\s-]?\d{5}[\s-]?\d(?:\d{2})?\b|\b\d{10,16}\b`)
+)
+
+// contactRule catches phone numbers and bank accounts shared before the
+// participants have an agreement, which is how most off-platform deposit
+// scams start.
+type contactRule struct {
+	action enums.ModerationActions
+}
+
+func (r *contactRule) Name() string                    { return "contact" }
+func (r *contactRule) Action() enums.ModerationActions { return r.action }
+func (r *contactRule) Reason() string {
+	return "Phone numbers and bank accounts can not be shared before an agreement is made"
+}
+
+func (r *contactRule) Check(inputs *ModerationInputs) (bool, [][]int) {
+	content := inputs.Message.Content
+	spans := append(phonePattern.FindAllStringIndex(content, -1), bankPattern.FindAllStringIndex(content, -1)...)
+	if len(spans) == 0 || inputs.HasAgreement() {
+		return false, nil
+	}
+
+	return true, spans
+}
+
+var profanityWords = []string{
+	"fuck", "fucking", "shit", "bitch", "asshole", "bastard", "cunt", "motherfucker",
+}
+
+// thai is written without spaces so these are matched anywhere
+var thaiProfanityWords = []string{
+	"เหี้ย", "ควย", "ไอ้สัตว์", "อีสัตว์", "อีดอก", "เย็ดแม่",
+}
+
+var profanityPattern = regexp.MustCompile(`(?i)\b(?:` + strings.Join(profanityWords, "|") + `)\b|` + strings.Join(thaiProfanityWords, "|"))
+
+type profanityRule struct {
+	action enums.ModerationActions
+}
+
+func (r *profanityRule) Name() string                    { return "profanity" }
+func (r *profanityRule) Action() enums.ModerationActions { return r.action }
+func (r *profanityRule) Reason() string                  { return "Message contains profanity" }
+
+func (r *profanityRule) Check(inputs *ModerationInputs) (bool, [][]int) {
+	spans := profanityPattern.FindAllStringIndex(inputs.Message.Content, -1)
+	return len(spans) > 0, spans
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:com|net|org|io|me|ly|co|th|link|xyz)\b(?:/\S*)?`)
+
+// linkRule catches links to hosts outside of allowedHosts.
+type linkRule struct {
+	action       enums.ModerationActions
+	allowedHosts []string
+}
+
+func (r *linkRule) Name() string                    { return "link" }
+func (r *linkRule) Action() enums.ModerationActions { return r.action }
+func (r *linkRule) Reason() string                  { return "Links to other websites are not allowed" }
+
+func (r *linkRule) Check(inputs *ModerationInputs) (bool, [][]int) {
+	content := inputs.Message.Content
+
+	spans := [][]int{}
+	for _, span := range linkPattern.FindAllStringIndex(content, -1) {
+		if !r.isAllowed(content[span[0]:span[1]]) {
+			spans = append(spans, span)
+		}
+	}
+
+	return len(spans) > 0, spans
+}
+
+func (r *linkRule) isAllowed(link string) bool {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, allowed := range r.allowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rateLimitRule allows each user at most limit messages per minute.
+type rateLimitRule struct {
+	sync.Mutex
+	action enums.ModerationActions
+	limit  int
+	sent   map[uuid.UUID][]time.Time
+}
+
+func (r *rateLimitRule) Name() string                    { return "rate_limit" }
+func (r *rateLimitRule) Action() enums.ModerationActions { return r.action }
+func (r *rateLimitRule) Reason() string                  { return "You are sending messages too fast" }
+
+func (r *rateLimitRule) Check(inputs *ModerationInputs) (bool, [][]int) {
+	r.Lock()
+	defer r.Unlock()
+
+	senderId := inputs.Message.SenderId
+	now := time.Now()
+
+	recent := r.sent[senderId][:0]
+	for _, sentAt := range r.sent[senderId] {
+		if now.Sub(sentAt) < rateLimitWindow {
+			recent = append(recent, sentAt)
+		}
+	}
+
+	if len(recent) >= r.limit {
+		r.sent[senderId] = recent
+		return true, nil
+	}
+
+	r.sent[senderId] = append(recent, now)
+
+	return false, nil
+}
@@ -0,0 +1,62 @@
+package chats
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+)
+
+// ticketStore keeps websocket tickets in memory. Tickets live for seconds and
+// are redeemed by the same instance that holds the hub, so they are not
+// persisted.
+type ticketStore struct {
+	sync.Mutex
+	tickets map[string]*models.WebsocketTickets
+}
+
+func newTicketStore() *ticketStore {
+	return &ticketStore{
+		tickets: make(map[string]*models.WebsocketTickets),
+	}
+}
+
+func (s *ticketStore) Issue(ticket *models.WebsocketTickets) error {
+	value := make([]byte, 32)
+	_, err := rand.Read(value)
+	if err != nil {
+		return err
+	}
+	ticket.Ticket = base64.RawURLEncoding.EncodeToString(value)
+
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	for key, issued := range s.tickets {
+		if now.After(issued.ExpiresAt) {
+			delete(s.tickets, key)
+		}
+	}
+
+	s.tickets[ticket.Ticket] = ticket
+
+	return nil
+}
+
+// Redeem removes the ticket so that it can not be used again and reports
+// whether it was still valid.
+func (s *ticketStore) Redeem(value string) (*models.WebsocketTickets, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	ticket, ok := s.tickets[value]
+	if !ok {
+		return nil, false
+	}
+	delete(s.tickets, value)
+
+	return ticket, time.Now().Before(ticket.ExpiresAt)
+}
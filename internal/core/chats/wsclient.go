@@ -1,7 +1,6 @@
 package chats
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/brain-flowing-company/pprp-backend/apperror"
@@ -13,15 +12,22 @@ import (
 )
 
 type WebsocketClients struct {
-	router     *WebsocketRouter
-	hub        *Hub
-	service    Service
-	UserId     uuid.UUID
-	RecvUserId *uuid.UUID
-	chats      map[uuid.UUID]*models.ChatPreviews
+	router  *WebsocketRouter
+	hub     *Hub
+	service Service
+	UserId  uuid.UUID
+	ChatId  *uuid.UUID
+	chats   map[uuid.UUID]*models.ChatPreviews
+
+	// the session the connection was opened with, which closes it on logout
+	// or expiry
+	SessionId uuid.UUID
+	ExpiresAt time.Time
 }
 
-func NewClient(logger *zap.Logger, conn *websocket.Conn, hub *Hub, service Service, userId uuid.UUID) (*WebsocketClients, *apperror.AppError) {
+func NewClient(logger *zap.Logger, conn *websocket.Conn, hub *Hub, service Service, claim *models.SessionClaims) (*WebsocketClients, *apperror.AppError) {
+	userId := claim.Session.UserId
+
 	chatPreviews := []models.ChatPreviews{}
 	err := service.GetAllChats(&chatPreviews, userId, "")
 	if err != nil {
@@ -30,17 +36,19 @@ func NewClient(logger *zap.Logger, conn *websocket.Conn, hub *Hub, service Servi
 
 	chats := map[uuid.UUID]*models.ChatPreviews{}
 	for _, chat := range chatPreviews {
-		chats[chat.UserId] = new(models.ChatPreviews)
-		*chats[chat.UserId] = chat
+		chats[chat.ChatId] = new(models.ChatPreviews)
+		*chats[chat.ChatId] = chat
 	}
 
 	return &WebsocketClients{
-		router:     NewWebsocketRouter(logger, conn),
-		hub:        hub,
-		service:    service,
-		UserId:     userId,
-		RecvUserId: nil,
-		chats:      chats,
+		router:    NewWebsocketRouter(logger, conn),
+		hub:       hub,
+		service:   service,
+		UserId:    userId,
+		ChatId:    nil,
+		chats:     chats,
+		SessionId: claim.SessionId(),
+		ExpiresAt: claim.ExpiresTime(),
 	}, nil
 }
 
@@ -52,6 +60,14 @@ func (client *WebsocketClients) Listen() {
 	client.router.On(enums.INBOUND_MSG, client.inBoundMsgHandler)
 	client.router.On(enums.INBOUND_JOIN, client.inBoundJoinHandler)
 	client.router.On(enums.INBOUND_LEFT, client.inBoundLeftHandler)
+	client.router.On(enums.INBOUND_EDIT, client.inBoundEditHandler)
+	client.router.On(enums.INBOUND_DELETE, client.inBoundDeleteHandler)
+	client.router.On(enums.INBOUND_REACT, client.inBoundReactHandler)
+	client.router.On(enums.INBOUND_UNREACT, client.inBoundUnreactHandler)
+	client.router.On(enums.INBOUND_READ, client.inBoundReadHandler)
+	client.router.On(enums.INBOUND_ACCEPT, client.inBoundActionHandler)
+	client.router.On(enums.INBOUND_REJECT, client.inBoundActionHandler)
+	client.router.On(enums.INBOUND_RESCHEDULE, client.inBoundActionHandler)
 	client.router.Listen()
 }
 
@@ -59,95 +75,239 @@ func (client *WebsocketClients) Close() {
 	client.router.Close()
 }
 
+// Terminate closes the connection with err as the reason.
+func (client *WebsocketClients) Terminate(err *apperror.AppError) {
+	client.router.Terminate(err)
+}
+
 func (client *WebsocketClients) inBoundMsgHandler(inbound *models.InBoundMessages) *apperror.AppError {
-	if client.RecvUserId == nil {
+	if client.ChatId == nil {
 		return apperror.
 			New(apperror.NotInChat).
 			Describe("Invalid chat")
 	}
 
-	fmt.Println(inbound)
-
-	var readAt *time.Time
-	now := time.Now()
-	if client.hub.IsUserBothInChat(client.UserId, *client.RecvUserId) {
-		readAt = &now
+	// reload on every message so that removed members can not keep sending
+	conversation := &models.Conversations{}
+	apperr := client.service.GetConversation(conversation, *client.ChatId, client.UserId)
+	if apperr != nil {
+		return apperr
 	}
 
 	msg := &models.Messages{
-		MessageId:  uuid.New(),
-		SenderId:   client.UserId,
-		ReceiverId: *client.RecvUserId,
-		ChatId:     *client.RecvUserId,
-		Author:     true,
-		ReadAt:     readAt,
-		Content:    inbound.Content,
-		SentAt:     inbound.SentAt,
+		MessageId: uuid.New(),
+		ChatId:    conversation.ConversationId,
+		SenderId:  client.UserId,
+		Author:    true,
+		Content:   inbound.Content,
+		SentAt:    time.Now(),
+		Tag:       inbound.Tag,
 	}
 
-	err := client.service.SaveMessages(msg)
-	if err != nil {
-		return err
+	apperr = client.service.ModerateMessage(msg, conversation)
+	if apperr != nil {
+		return apperr
 	}
 
-	if client.hub.IsUserOnline(client.UserId) {
-		msg.Tag = inbound.Tag
-		msg.ChatId = *client.RecvUserId
-		msg.Author = true
-		client.SendOutBoundMessage(msg.ToOutBound())
+	apperr = client.service.SaveMessages(msg, conversation)
+	if apperr != nil {
+		return apperr
 	}
 
-	if client.hub.IsUserOnline(*client.RecvUserId) {
-		msg.ChatId = client.UserId
-		msg.Author = false
-		client.hub.GetUser(*client.RecvUserId).SendOutBoundMessage(msg.ToOutBound())
-	}
+	client.hub.BroadcastMessage(msg, conversation.Participants)
 
 	return nil
 }
 
 func (client *WebsocketClients) inBoundJoinHandler(inbound *models.InBoundMessages) *apperror.AppError {
-	uuid, err := uuid.Parse(inbound.Content)
+	chatId, err := uuid.Parse(inbound.Content)
 	if err != nil {
 		return apperror.
 			New(apperror.BadRequest).
-			Describe("invalid receiver uuid")
+			Describe("invalid chat uuid")
 	}
 
-	if client.UserId == uuid {
-		return apperror.
-			New(apperror.BadRequest).
-			Describe("could not send message to yourself")
+	conversation := &models.Conversations{}
+	apperr := client.service.GetConversation(conversation, chatId, client.UserId)
+	if apperr != nil {
+		return apperr
 	}
 
-	if (models.MessageAttatchments{}) != inbound.Attatchment {
+	if inbound.Attatchment.PropertyId != nil && !conversation.IsGroup {
 		property := models.Properties{PropertyId: *inbound.Attatchment.PropertyId}
-		apperr := client.hub.SendNotificationMessage(&property, "Embedded property", client.UserId, uuid)
+		apperr := client.hub.SendNotificationMessage(&property, "Embedded property", client.UserId, conversation.PartnerOf(client.UserId))
 		if apperr != nil {
 			return apperr
 		}
 	}
 
-	client.RecvUserId = &uuid
+	client.ChatId = &chatId
+
+	return nil
+}
+
+func (client *WebsocketClients) inBoundLeftHandler(inbound *models.InBoundMessages) *apperror.AppError {
+	client.ChatId = nil
+
+	return nil
+}
+
+// inBoundReadHandler marks the open chat as read up to inbound.MessageId, the
+// latest message the app has actually shown.
+func (client *WebsocketClients) inBoundReadHandler(inbound *models.InBoundMessages) *apperror.AppError {
+	if client.ChatId == nil {
+		return apperror.
+			New(apperror.NotInChat).
+			Describe("Invalid chat")
+	}
 
-	apperr := client.service.ReadMessages(uuid, client.UserId)
+	conversation := &models.Conversations{}
+	apperr := client.service.GetConversation(conversation, *client.ChatId, client.UserId)
 	if apperr != nil {
 		return apperr
 	}
 
-	if client.hub.IsUserBothInChat(client.UserId, uuid) {
-		read := &models.ReadEvents{
-			ChatId: client.UserId,
-			ReadAt: time.Now(),
-		}
-		client.hub.GetUser(uuid).SendOutBoundMessage(read.ToOutBound())
+	read := &models.ReadEvents{
+		ChatId:    conversation.ConversationId,
+		UserId:    client.UserId,
+		MessageId: inbound.MessageId,
+	}
+
+	changed, apperr := client.service.ReadMessages(read)
+	if apperr != nil || !changed {
+		return apperr
 	}
 
+	client.hub.Broadcast(without(conversation.Participants, client.UserId), read)
+
+	return client.hub.SendUnreadSummary(client.UserId)
+}
+
+func (client *WebsocketClients) inBoundEditHandler(inbound *models.InBoundMessages) *apperror.AppError {
+	msg := &models.Messages{MessageId: inbound.MessageId}
+	apperr := client.service.EditMessage(msg, client.UserId, inbound.Content)
+	if apperr != nil {
+		return apperr
+	}
+
+	return client.broadcast(msg.ChatId, inbound.Tag, &models.EditEvents{
+		ChatId:    msg.ChatId,
+		MessageId: msg.MessageId,
+		Content:   msg.Content,
+		EditedAt:  *msg.EditedAt,
+	})
+}
+
+func (client *WebsocketClients) inBoundDeleteHandler(inbound *models.InBoundMessages) *apperror.AppError {
+	msg := &models.Messages{MessageId: inbound.MessageId}
+	apperr := client.service.DeleteMessage(msg, client.UserId)
+	if apperr != nil {
+		return apperr
+	}
+
+	apperr = client.broadcast(msg.ChatId, inbound.Tag, &models.DeleteEvents{
+		ChatId:    msg.ChatId,
+		MessageId: msg.MessageId,
+		DeletedAt: *msg.DeletedAt,
+	})
+	if apperr != nil {
+		return apperr
+	}
+
+	// deleted messages no longer count as unread
+	conversation := &models.Conversations{}
+	apperr = client.service.GetConversation(conversation, msg.ChatId, client.UserId)
+	if apperr != nil {
+		return apperr
+	}
+
+	client.hub.SendUnreadSummaries(without(conversation.Participants, client.UserId))
+
 	return nil
 }
 
-func (client *WebsocketClients) inBoundLeftHandler(inbound *models.InBoundMessages) *apperror.AppError {
-	client.RecvUserId = nil
+func (client *WebsocketClients) inBoundReactHandler(inbound *models.InBoundMessages) *apperror.AppError {
+	return client.handleReaction(inbound, false)
+}
+
+func (client *WebsocketClients) inBoundUnreactHandler(inbound *models.InBoundMessages) *apperror.AppError {
+	return client.handleReaction(inbound, true)
+}
+
+func (client *WebsocketClients) handleReaction(inbound *models.InBoundMessages, removed bool) *apperror.AppError {
+	msg := &models.Messages{}
+	reaction := &models.MessageReactions{
+		MessageId: inbound.MessageId,
+		UserId:    client.UserId,
+		Emoji:     inbound.Content,
+	}
+
+	var apperr *apperror.AppError
+	if removed {
+		apperr = client.service.UnreactMessage(msg, reaction)
+	} else {
+		apperr = client.service.ReactMessage(msg, reaction)
+	}
+	if apperr != nil {
+		return apperr
+	}
+
+	return client.broadcast(msg.ChatId, inbound.Tag, &models.ReactionEvents{
+		ChatId:    msg.ChatId,
+		MessageId: reaction.MessageId,
+		UserId:    reaction.UserId,
+		Emoji:     reaction.Emoji,
+		Removed:   removed,
+	})
+}
+
+// inBoundActionHandler answers an action message and updates the card for
+// everyone in the chat. Accepting an agreement offer also requests its deposit.
+func (client *WebsocketClients) inBoundActionHandler(inbound *models.InBoundMessages) *apperror.AppError {
+	event := &models.ActionEvents{}
+	apperr := client.service.RespondToAction(event, &models.RespondingActions{
+		MessageId:       inbound.MessageId,
+		UserId:          client.UserId,
+		Response:        inbound.Event,
+		Reason:          inbound.Content,
+		AppointmentDate: inbound.AppointmentDate,
+	})
+	if apperr != nil {
+		return apperr
+	}
+
+	apperr = client.broadcast(event.ChatId, inbound.Tag, event)
+	if apperr != nil {
+		return apperr
+	}
+
+	if event.Action.ActionType != enums.AgreementOfferAction || event.Action.Status != enums.AcceptedAction {
+		return nil
+	}
+
+	request := &models.PaymentRequests{}
+	apperr = client.service.GetPaymentRequest(request, *event.Attatchment.AgreementId)
+	if apperr != nil {
+		return apperr
+	}
+
+	return client.hub.SendNotificationMessage(request, "Deposit request", request.OwnerUserId, request.DwellerUserId)
+}
+
+// broadcast sends an event to this client, tagged with the inbound tag, and
+// to the other online participants of chatId.
+func (client *WebsocketClients) broadcast(chatId uuid.UUID, tag string, event models.OutBoundPayload) *apperror.AppError {
+	conversation := &models.Conversations{}
+	apperr := client.service.GetConversation(conversation, chatId, client.UserId)
+	if apperr != nil {
+		return apperr
+	}
+
+	outbound := event.ToOutBound()
+	outbound.Tag = tag
+	client.SendOutBoundMessage(outbound)
+
+	client.hub.Broadcast(without(conversation.Participants, client.UserId), event)
 
 	return nil
 }
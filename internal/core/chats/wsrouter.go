@@ -1,7 +1,11 @@
 package chats
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/brain-flowing-company/pprp-backend/apperror"
 	"github.com/brain-flowing-company/pprp-backend/internal/enums"
@@ -13,16 +17,37 @@ import (
 
 type handlerFunc func(*models.InBoundMessages) *apperror.AppError
 
+type routerErrors struct {
+	tag string
+	err *apperror.AppError
+}
+
+const closeGracePeriod = 5 * time.Second
+
+var payloadValidator = utils.NewWebsocketValidator()
+
 type WebsocketRouter struct {
 	conn             *websocket.Conn
+	protocol         string
 	handlers         map[enums.MessageInboundEvents]handlerFunc
 	outBoundMessages chan *models.OutBoundMessages
 	logger           *zap.Logger
+
+	// guards outBoundMessages, which senders on other goroutines may still
+	// hold a client for after it is closed
+	mu     sync.RWMutex
+	closed bool
 }
 
 func NewWebsocketRouter(logger *zap.Logger, conn *websocket.Conn) *WebsocketRouter {
+	protocol := conn.Subprotocol()
+	if protocol == "" {
+		protocol = models.ChatProtocolV1
+	}
+
 	return &WebsocketRouter{
 		conn:             conn,
+		protocol:         protocol,
 		handlers:         make(map[enums.MessageInboundEvents]handlerFunc),
 		outBoundMessages: make(chan *models.OutBoundMessages, 16),
 		logger:           logger,
@@ -33,16 +58,28 @@ func (r *WebsocketRouter) On(e enums.MessageInboundEvents, h handlerFunc) {
 	r.handlers[e] = h
 }
 
+// Send queues msg for the client. Messages sent after Close are dropped.
 func (r *WebsocketRouter) Send(msg *models.OutBoundMessages) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		return
+	}
+
 	r.outBoundMessages <- msg
 }
 
 func (r *WebsocketRouter) Listen() {
-	errch := make(chan *apperror.AppError)
+	errch := make(chan routerErrors)
 	term := make(chan bool)
 
 	go r.handleWrite()
-	go r.handleRead(term, errch)
+	if r.protocol == models.ChatProtocolV1 {
+		go r.handleReadV1(term, errch)
+	} else {
+		go r.handleRead(term, errch)
+	}
 
 	for {
 		select {
@@ -50,16 +87,41 @@ func (r *WebsocketRouter) Listen() {
 			return
 
 		case err := <-errch:
-			wserr := utils.WebsocketError(r.conn, err)
+			if r.protocol != models.ChatProtocolV1 {
+				r.Send(utils.WebsocketErrorFrame(err.tag, err.err))
+				continue
+			}
+
+			wserr := utils.WebsocketError(r.conn, err.err)
 			if wserr != nil {
-				r.logger.Error("Could not send error message", zap.Error(err))
+				r.logger.Error("Could not send error message", zap.Error(wserr))
 			}
 		}
 	}
 }
 
+// Terminate sends a close frame with err and stops reading once the client
+// had a moment to answer it.
+func (r *WebsocketRouter) Terminate(err *apperror.AppError) {
+	wserr := utils.WebsocketFatal(r.conn, err)
+	if wserr != nil {
+		r.logger.Error("Could not send close message", zap.Error(wserr))
+	}
+
+	wserr = r.conn.SetReadDeadline(time.Now().Add(closeGracePeriod))
+	if wserr != nil {
+		r.logger.Error("Could not set read deadline", zap.Error(wserr))
+	}
+}
+
 func (r *WebsocketRouter) Close() {
-	close(r.outBoundMessages)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.closed {
+		r.closed = true
+		close(r.outBoundMessages)
+	}
 }
 
 func (r *WebsocketRouter) handleWrite() {
@@ -69,14 +131,100 @@ func (r *WebsocketRouter) handleWrite() {
 			return
 		}
 
-		err := r.conn.WriteJSON(msg)
+		frame := *msg
+		if r.protocol != models.ChatProtocolV1 {
+			frame.Version = models.ChatProtocolVersion
+		}
+
+		err := r.conn.WriteJSON(frame)
 		if err != nil {
 			r.logger.Error("Could not write json data", zap.Error(err))
 		}
 	}
 }
 
-func (r *WebsocketRouter) handleRead(term chan bool, errch chan *apperror.AppError) {
+func (r *WebsocketRouter) handleRead(term chan bool, errch chan routerErrors) {
+	for {
+		_, data, err := r.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				r.logger.Error("WebSocket connection closed unexpectedly", zap.Error(err))
+			}
+			term <- true
+			break
+		}
+
+		var envelope models.InBoundEnvelopes
+		err = json.Unmarshal(data, &envelope)
+		if err != nil {
+			errch <- routerErrors{"", apperror.
+				New(apperror.BadRequest).
+				Describe("could not parse json")}
+			continue
+		}
+
+		msg, apperr := r.parsePayload(&envelope)
+		if apperr != nil {
+			errch <- routerErrors{envelope.Tag, apperr}
+			continue
+		}
+
+		apperr = r.handlers[msg.Event](msg)
+		if apperr != nil {
+			errch <- routerErrors{envelope.Tag, apperr}
+		}
+	}
+}
+
+// parsePayload validates a v2 envelope and its payload and flattens them into
+// the message the handlers expect.
+func (r *WebsocketRouter) parsePayload(envelope *models.InBoundEnvelopes) (*models.InBoundMessages, *apperror.AppError) {
+	err := payloadValidator.Struct(envelope)
+	if err != nil {
+		return nil, apperror.
+			New(apperror.BadRequest).
+			Describe(utils.DescribeValidationError(err))
+	}
+
+	newPayload, ok := models.InBoundPayloadTypes[envelope.Event]
+	if _, handled := r.handlers[envelope.Event]; !ok || !handled {
+		return nil, apperror.
+			New(apperror.UnknownEvent).
+			Describe(fmt.Sprintf("unknown event %v", envelope.Event))
+	}
+
+	payload := newPayload()
+	if len(envelope.Payload) > 0 && !bytes.Equal(envelope.Payload, []byte("null")) {
+		decoder := json.NewDecoder(bytes.NewReader(envelope.Payload))
+		decoder.DisallowUnknownFields()
+
+		err = decoder.Decode(payload)
+		if err != nil {
+			return nil, apperror.
+				New(apperror.InvalidPayload).
+				Describe(err.Error())
+		}
+	}
+
+	err = payloadValidator.Struct(payload)
+	if err != nil {
+		return nil, apperror.
+			New(apperror.InvalidPayload).
+			Describe(utils.DescribeValidationError(err))
+	}
+
+	msg := &models.InBoundMessages{
+		Event: envelope.Event,
+		Tag:   envelope.Tag,
+	}
+	payload.ToInBound(msg)
+
+	return msg, nil
+}
+
+// handleReadV1 reads the deprecated flat frames of clients that did not
+// negotiate a subprotocol. Unknown events are ignored.
+func (r *WebsocketRouter) handleReadV1(term chan bool, errch chan routerErrors) {
 	for {
 		_, data, err := r.conn.ReadMessage()
 		if err != nil {
@@ -90,9 +238,9 @@ func (r *WebsocketRouter) handleRead(term chan bool, errch chan *apperror.AppErr
 		var msg models.InBoundMessages
 		err = json.Unmarshal(data, &msg)
 		if err != nil {
-			errch <- apperror.
+			errch <- routerErrors{"", apperror.
 				New(apperror.BadRequest).
-				Describe("could not parse json")
+				Describe("could not parse json")}
 			continue
 		}
 
@@ -103,7 +251,7 @@ func (r *WebsocketRouter) handleRead(term chan bool, errch chan *apperror.AppErr
 
 		apperr := handler(&msg)
 		if apperr != nil {
-			errch <- apperr
+			errch <- routerErrors{msg.Tag, apperr}
 		}
 	}
 }
@@ -2,9 +2,13 @@ package emails
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"html/template"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"time"
 
 	"github.com/brain-flowing-company/pprp-backend/apperror"
@@ -19,8 +23,16 @@ import (
 type Service interface {
 	SendVerificationEmail([]string) *apperror.AppError
 	VerifyEmail(*models.Callbacks, *models.CallbackResponses) *apperror.AppError
+	SendChatDigestEmail(string, *models.ChatDigestEmails) *apperror.AppError
+	SendRentReminderEmail(string, *models.RentReminderEmails) *apperror.AppError
+	SendOverdueSummaryEmail(string, *models.OverdueSummaryEmails) *apperror.AppError
+	SendReceiptEmail(string, *models.ReceiptEmails, *models.EmailAttachments) *apperror.AppError
 }
 
+// base64LineLength keeps attachment lines within the 78 characters SMTP
+// allows.
+const base64LineLength = 76
+
 type serviceImpl struct {
 	repo   Repository
 	logger *zap.Logger
@@ -83,7 +95,31 @@ func (s *serviceImpl) SendVerificationEmail(emails []string) *apperror.AppError
 	return s.sendEmail(emails, subject, emailStructure)
 }
 
-func (s *serviceImpl) sendEmail(to []string, subject string, emailStructure models.EmailType) *apperror.AppError {
+func (s *serviceImpl) SendChatDigestEmail(email string, digest *models.ChatDigestEmails) *apperror.AppError {
+	subject := "You have unread messages on suechaokhai.com"
+
+	return s.sendEmail([]string{email}, subject, *digest)
+}
+
+func (s *serviceImpl) SendRentReminderEmail(email string, reminder *models.RentReminderEmails) *apperror.AppError {
+	subject := "Rent reminder from suechaokhai.com"
+
+	return s.sendEmail([]string{email}, subject, *reminder)
+}
+
+func (s *serviceImpl) SendOverdueSummaryEmail(email string, summary *models.OverdueSummaryEmails) *apperror.AppError {
+	subject := "Overdue rent on your properties on suechaokhai.com"
+
+	return s.sendEmail([]string{email}, subject, *summary)
+}
+
+func (s *serviceImpl) SendReceiptEmail(email string, receipt *models.ReceiptEmails, file *models.EmailAttachments) *apperror.AppError {
+	subject := fmt.Sprintf("Your receipt %v from suechaokhai.com", receipt.ReceiptNumber)
+
+	return s.sendEmail([]string{email}, subject, *receipt, *file)
+}
+
+func (s *serviceImpl) sendEmail(to []string, subject string, emailStructure models.EmailType, attachments ...models.EmailAttachments) *apperror.AppError {
 	smtpHost := s.cfg.SmtpHost
 	smtpPort := s.cfg.SmtpPort
 	smtpAddr := smtpHost + ":" + smtpPort
@@ -113,6 +149,9 @@ func (s *serviceImpl) sendEmail(to []string, subject string, emailStructure mode
 
 	mimeHeaders := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
 	message := []byte(fmt.Sprintf("Subject: %s \n%s\n\n%s", subject, mimeHeaders, body.String()))
+	if len(attachments) > 0 {
+		message = multipartMessage(subject, body.Bytes(), attachments)
+	}
 
 	err := smtp.SendMail(smtpAddr, auth, from, to, message)
 	if err != nil {
@@ -125,6 +164,39 @@ func (s *serviceImpl) sendEmail(to []string, subject string, emailStructure mode
 	return nil
 }
 
+// multipartMessage builds an email with the HTML body followed by the
+// attachments, base64 encoded.
+func multipartMessage(subject string, body []byte, attachments []models.EmailAttachments) []byte {
+	var message bytes.Buffer
+	writer := multipart.NewWriter(&message)
+
+	fmt.Fprintf(&message, "Subject: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%q\r\n\r\n", mime.QEncoding.Encode("utf-8", subject), writer.Boundary())
+
+	part, _ := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {`text/html; charset="UTF-8"`},
+	})
+	_, _ = part.Write(body)
+
+	for _, attachment := range attachments {
+		part, _ = writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", attachment.FileName)},
+		})
+
+		encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+		for len(encoded) > base64LineLength {
+			_, _ = part.Write([]byte(encoded[:base64LineLength] + "\r\n"))
+			encoded = encoded[base64LineLength:]
+		}
+		_, _ = part.Write([]byte(encoded))
+	}
+
+	_ = writer.Close()
+
+	return message.Bytes()
+}
+
 func (s *serviceImpl) VerifyEmail(verificationReq *models.Callbacks, callbackResponse *models.CallbackResponses) *apperror.AppError {
 	userEmail := verificationReq.Email
 	userCode := verificationReq.Code
@@ -0,0 +1,59 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Publisher pushes events to whichever users are online. Users that are not
+// connected simply miss them and catch up through the REST endpoints.
+type Publisher interface {
+	Publish([]uuid.UUID, models.OutBoundPayload)
+}
+
+// Subscribers is a live connection of a user, such as a chat websocket.
+type Subscribers interface {
+	SendOutBoundMessage(*models.OutBoundMessages)
+}
+
+// Stream is the per-user event stream. Connections subscribe when they open
+// and services publish to it without knowing how users are connected.
+type Stream struct {
+	sync.RWMutex
+	subscribers map[uuid.UUID]Subscribers
+}
+
+func NewStream() *Stream {
+	return &Stream{
+		subscribers: make(map[uuid.UUID]Subscribers),
+	}
+}
+
+func (s *Stream) Subscribe(userId uuid.UUID, subscriber Subscribers) {
+	s.Lock()
+	s.subscribers[userId] = subscriber
+	s.Unlock()
+}
+
+// Unsubscribe removes subscriber, unless userId has already been taken over by
+// another connection.
+func (s *Stream) Unsubscribe(userId uuid.UUID, subscriber Subscribers) {
+	s.Lock()
+	if s.subscribers[userId] == subscriber {
+		delete(s.subscribers, userId)
+	}
+	s.Unlock()
+}
+
+func (s *Stream) Publish(userIds []uuid.UUID, event models.OutBoundPayload) {
+	s.RLock()
+	defer s.RUnlock()
+
+	for _, userId := range userIds {
+		if subscriber, ok := s.subscribers[userId]; ok {
+			subscriber.SendOutBoundMessage(event.ToOutBound())
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package ledger
+
+import (
+	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/brain-flowing-company/pprp-backend/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Handler interface {
+	GetEarnings(c *fiber.Ctx) error
+}
+
+type handlerImpl struct {
+	service Service
+}
+
+func NewHandler(service Service) Handler {
+	return &handlerImpl{
+		service,
+	}
+}
+
+// @router      /api/v1/user/me/earnings [get]
+// @summary     Get my earnings *use cookies*
+// @description Get what the current owner has earned from payments less the platform fee and refunds, the balance waiting for the next payout, what is being paid out and what has been paid out, with the latest payouts. Balances of at least PAYOUT_MINIMUM are paid out to the bank account in the financial information every PAYOUT_INTERVAL seconds.
+// @tags        payments
+// @produce     json
+// @success     200	{object}	models.OwnerEarnings
+// @failure     401 {object}	models.ErrorResponses
+// @failure     403 {object}	models.ErrorResponses
+// @failure     500 {object}	models.ErrorResponses
+func (h *handlerImpl) GetEarnings(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		return utils.ResponseError(c, apperror.New(apperror.Unauthorized).Describe("Unauthorized"))
+	}
+
+	earnings := models.OwnerEarnings{}
+	apperr := h.service.GetEarnings(&earnings, session.UserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.JSON(earnings)
+}
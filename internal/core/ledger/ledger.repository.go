@@ -0,0 +1,208 @@
+package ledger
+
+import (
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	CreateTransaction(*models.LedgerTransactions) (bool, error)
+	SumRecordedRefunds(*money.Amount, uuid.UUID) error
+	GetUnrecordedPayments(*[]models.UnrecordedPayments) error
+	GetLedgerSums(*[]models.LedgerSums, uuid.UUID) error
+	GetPayableBalances(*[]models.OwnerBalances, money.Amount) error
+	GetFinancialInformation(*models.UserFinancialInformations, uuid.UUID) error
+	CreatePayout(*models.Payouts, *models.LedgerTransactions) error
+	SettlePayout(*models.Payouts, *models.LedgerTransactions) (bool, error)
+	GetPayout(*models.Payouts, uuid.UUID) error
+	GetPayoutsByStatus(*[]models.Payouts, enums.PayoutStatus) error
+	GetPayoutsByUserId(*[]models.Payouts, uuid.UUID, int) error
+}
+
+type repositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repositoryImpl{
+		db,
+	}
+}
+
+// CreateTransaction books a transaction with its entries. A transaction whose
+// reference is already booked is skipped, and it reports whether it was
+// booked.
+func (r *repositoryImpl) CreateTransaction(transaction *models.LedgerTransactions) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		created, err = createTransaction(tx, transaction)
+		return err
+	})
+
+	return created, err
+}
+
+func createTransaction(tx *gorm.DB, transaction *models.LedgerTransactions) (bool, error) {
+	result := tx.Exec(`
+		INSERT INTO ledger_transactions (transaction_id, kind, reference, description, payment_id, payout_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (reference) DO NOTHING
+	`, transaction.TransactionId, transaction.Kind, transaction.Reference, transaction.Description, transaction.PaymentId, transaction.PayoutId)
+	if result.Error != nil {
+		return false, result.Error
+	} else if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	for i := range transaction.Entries {
+		transaction.Entries[i].TransactionId = transaction.TransactionId
+	}
+
+	if err := tx.Create(&transaction.Entries).Error; err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SumRecordedRefunds adds up the refunds of paymentId booked so far.
+func (r *repositoryImpl) SumRecordedRefunds(sum *money.Amount, paymentId uuid.UUID) error {
+	return r.db.Raw(`
+		SELECT COALESCE(SUM(-e.amount), 0) FROM ledger_transactions t
+		JOIN ledger_entries e ON e.transaction_id = t.transaction_id
+		WHERE t.payment_id = ? AND t.kind = ? AND e.account = ?
+	`, paymentId, enums.RefundTransaction, enums.CashAccount).Scan(sum).Error
+}
+
+// GetUnrecordedPayments finds the paid payments whose payment, or part of
+// whose refunds, is missing from the ledger.
+func (r *repositoryImpl) GetUnrecordedPayments(payments *[]models.UnrecordedPayments) error {
+	return r.db.Raw(`
+		SELECT p.payment_id, p.user_id, p.price, p.name, p.agreement_id, p.payment_type, p.installment,
+			p.status, p.refunded_amount, p.created_at, p.updated_at, a.owner_user_id
+		FROM payments p
+		JOIN agreements a ON a.agreement_id = p.agreement_id
+		WHERE p.status IN ?
+		AND (
+			NOT EXISTS (
+				SELECT 1 FROM ledger_transactions t
+				WHERE t.payment_id = p.payment_id AND t.kind = ?
+			)
+			OR p.refunded_amount > (
+				SELECT COALESCE(SUM(-e.amount), 0) FROM ledger_transactions t
+				JOIN ledger_entries e ON e.transaction_id = t.transaction_id
+				WHERE t.payment_id = p.payment_id AND t.kind = ? AND e.account = ?
+			)
+		)
+		ORDER BY p.created_at
+	`, recordedStatus, enums.PaymentTransaction, enums.RefundTransaction, enums.CashAccount).Scan(payments).Error
+}
+
+// recordedStatus are the statuses of payments whose money has come in, even
+// if some or all of it went back out.
+var recordedStatus = []enums.PaymentStatus{enums.SucceededPayment, enums.PartiallyRefundedPayment, enums.RefundedPayment}
+
+// GetLedgerSums adds up every entry of userId by the kind of transaction and
+// the account.
+func (r *repositoryImpl) GetLedgerSums(sums *[]models.LedgerSums, userId uuid.UUID) error {
+	return r.db.Raw(`
+		SELECT t.kind, e.account, SUM(e.amount) AS amount FROM ledger_entries e
+		JOIN ledger_transactions t ON t.transaction_id = e.transaction_id
+		WHERE e.user_id = ?
+		GROUP BY t.kind, e.account
+	`, userId).Scan(sums).Error
+}
+
+// GetPayableBalances finds the owners who are owed at least minimum, have
+// bank details to pay them to, and are not already being paid.
+func (r *repositoryImpl) GetPayableBalances(balances *[]models.OwnerBalances, minimum money.Amount) error {
+	return r.db.Raw(`
+		SELECT e.user_id, -SUM(e.amount) AS balance, f.bank_name, f.bank_account_number
+		FROM ledger_entries e
+		JOIN user_financial_informations f ON f.user_id = e.user_id
+		WHERE e.account = ?
+		AND f.bank_name IS NOT NULL AND f.bank_account_number IS NOT NULL AND f.bank_account_number <> ''
+		AND NOT EXISTS (
+			SELECT 1 FROM payouts p WHERE p.user_id = e.user_id AND p.status = ?
+		)
+		GROUP BY e.user_id, f.bank_name, f.bank_account_number
+		HAVING -SUM(e.amount) >= ?
+	`, enums.OwnerPayableAccount, enums.PendingPayout, minimum).Scan(balances).Error
+}
+
+func (r *repositoryImpl) GetFinancialInformation(info *models.UserFinancialInformations, userId uuid.UUID) error {
+	return r.db.Model(&models.UserFinancialInformations{}).
+		Where("user_id = ?", userId).
+		Limit(1).
+		Find(info).Error
+}
+
+// CreatePayout saves a payout together with the transaction that moves its
+// amount out of the owner's balance.
+func (r *repositoryImpl) CreatePayout(payout *models.Payouts, transaction *models.LedgerTransactions) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(payout).Error; err != nil {
+			return err
+		}
+
+		_, err := createTransaction(tx, transaction)
+		return err
+	})
+}
+
+// SettlePayout moves a pending payout to the status of payout, books
+// transaction with it, then loads it. It reports whether the payout changed.
+func (r *repositoryImpl) SettlePayout(payout *models.Payouts, transaction *models.LedgerTransactions) (bool, error) {
+	settled := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`
+			UPDATE payouts
+			SET status = ?, reference = COALESCE(?, reference), failure_reason = ?, paid_at = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE payout_id = ? AND status = ?
+		`, payout.Status, payout.Reference, payout.FailureReason, payout.PaidAt, payout.PayoutId, enums.PendingPayout)
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return nil
+		}
+
+		settled = true
+		_, err := createTransaction(tx, transaction)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.GetPayout(payout, payout.PayoutId); err != nil {
+		return false, err
+	}
+
+	return settled, nil
+}
+
+func (r *repositoryImpl) GetPayout(payout *models.Payouts, payoutId uuid.UUID) error {
+	return r.db.Model(&models.Payouts{}).
+		Where("payout_id = ?", payoutId).
+		Limit(1).
+		Find(payout).Error
+}
+
+func (r *repositoryImpl) GetPayoutsByStatus(payouts *[]models.Payouts, status enums.PayoutStatus) error {
+	return r.db.Model(&models.Payouts{}).
+		Where("status = ?", status).
+		Order("created_at").
+		Find(payouts).Error
+}
+
+func (r *repositoryImpl) GetPayoutsByUserId(payouts *[]models.Payouts, userId uuid.UUID, limit int) error {
+	return r.db.Model(&models.Payouts{}).
+		Where("user_id = ?", userId).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(payouts).Error
+}
@@ -0,0 +1,344 @@
+package ledger
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type Service interface {
+	RecordPayment(*models.Payments, uuid.UUID) *apperror.AppError
+	GetEarnings(*models.OwnerEarnings, uuid.UUID) *apperror.AppError
+	CreatePayouts()
+	GetPendingPayouts(*[]models.Payouts) *apperror.AppError
+	CompletePayout(*models.Payouts, uuid.UUID, string) *apperror.AppError
+	FailPayout(*models.Payouts, uuid.UUID, string) *apperror.AppError
+	RunPayoutWorker()
+}
+
+const (
+	defaultPayoutTick  = 24 * 60 * 60
+	recentPayouts      = 10
+	maxPayoutReference = 255
+	maxFailureReason   = 500
+	visibleAccountTail = 4
+)
+
+type serviceImpl struct {
+	logger *zap.Logger
+	cfg    *config.Config
+	repo   Repository
+}
+
+func NewService(logger *zap.Logger, cfg *config.Config, repo Repository) Service {
+	return &serviceImpl{
+		logger,
+		cfg,
+		repo,
+	}
+}
+
+// RecordPayment brings the ledger up to date with a paid payment to ownerId:
+// the payment is booked as owed to the owner less the platform fee, and
+// whatever has been refunded since is taken back out of what the owner is
+// owed. Recording a payment again only books what is new.
+func (s *serviceImpl) RecordPayment(payment *models.Payments, ownerId uuid.UUID) *apperror.AppError {
+	fee := s.platformFee(payment.Price)
+	_, err := s.repo.CreateTransaction(&models.LedgerTransactions{
+		TransactionId: uuid.New(),
+		Kind:          enums.PaymentTransaction,
+		Reference:     fmt.Sprintf("payment:%v", payment.PaymentId),
+		Description:   fmt.Sprintf("Paid %v", payment.Name),
+		PaymentId:     &payment.PaymentId,
+		Entries: []models.LedgerEntries{
+			{Account: enums.CashAccount, UserId: &ownerId, Amount: payment.Price},
+			{Account: enums.OwnerPayableAccount, UserId: &ownerId, Amount: -(payment.Price - fee)},
+			{Account: enums.PlatformFeesAccount, UserId: &ownerId, Amount: -fee},
+		},
+	})
+	if err != nil {
+		s.logger.Error("Could not record payment", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not record payment")
+	}
+
+	var recorded money.Amount
+	err = s.repo.SumRecordedRefunds(&recorded, payment.PaymentId)
+	if err != nil {
+		s.logger.Error("Could not get recorded refunds", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not record refund")
+	}
+
+	refunded := payment.RefundedAmount - recorded
+	if refunded <= 0 {
+		return nil
+	}
+
+	// the platform keeps its fee, so refunds come out of the owner's share
+	_, err = s.repo.CreateTransaction(&models.LedgerTransactions{
+		TransactionId: uuid.New(),
+		Kind:          enums.RefundTransaction,
+		Reference:     fmt.Sprintf("refund:%v:%v", payment.PaymentId, payment.RefundedAmount.Decimal()),
+		Description:   fmt.Sprintf("Refunded %v THB of %v", refunded, payment.Name),
+		PaymentId:     &payment.PaymentId,
+		Entries: []models.LedgerEntries{
+			{Account: enums.OwnerPayableAccount, UserId: &ownerId, Amount: refunded},
+			{Account: enums.CashAccount, UserId: &ownerId, Amount: -refunded},
+		},
+	})
+	if err != nil {
+		s.logger.Error("Could not record refund", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not record refund")
+	}
+
+	return nil
+}
+
+// GetEarnings sums up what userId has earned, what they are owed and what
+// has been paid out to them.
+func (s *serviceImpl) GetEarnings(earnings *models.OwnerEarnings, userId uuid.UUID) *apperror.AppError {
+	sums := []models.LedgerSums{}
+	err := s.repo.GetLedgerSums(&sums, userId)
+	if err != nil {
+		s.logger.Error("Could not get ledger sums", zap.Error(err), zap.String("userId", userId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get earnings")
+	}
+
+	// credits are negative, so what the owner is owed is the negated sum
+	for _, sum := range sums {
+		switch sum.Account {
+		case enums.OwnerPayableAccount:
+			earnings.Balance -= sum.Amount
+			if sum.Kind == enums.PaymentTransaction {
+				earnings.Earned -= sum.Amount
+			} else if sum.Kind == enums.RefundTransaction {
+				earnings.Refunded += sum.Amount
+			}
+		case enums.OwnerPayoutsPendingAccount:
+			earnings.Pending -= sum.Amount
+			if sum.Kind == enums.PayoutPaidTransaction {
+				earnings.PaidOut += sum.Amount
+			}
+		case enums.PlatformFeesAccount:
+			earnings.Fees -= sum.Amount
+		}
+	}
+
+	info := models.UserFinancialInformations{}
+	err = s.repo.GetFinancialInformation(&info, userId)
+	if err != nil {
+		s.logger.Error("Could not get financial information", zap.Error(err), zap.String("userId", userId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get earnings")
+	}
+	earnings.BankName = info.BankName
+	earnings.BankAccountNumber = maskAccountNumber(info.BankAccountNumber)
+
+	earnings.Payouts = []models.Payouts{}
+	err = s.repo.GetPayoutsByUserId(&earnings.Payouts, userId, recentPayouts)
+	if err != nil {
+		s.logger.Error("Could not get payouts", zap.Error(err), zap.String("userId", userId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get earnings")
+	}
+	for i := range earnings.Payouts {
+		earnings.Payouts[i].BankAccountNumber = maskAccountNumber(earnings.Payouts[i].BankAccountNumber)
+	}
+
+	return nil
+}
+
+// CreatePayouts books any payments the ledger missed, then batches the
+// balance of every owner owed at least PAYOUT_MINIMUM into a payout to the
+// bank account they have on file. Owners with a pending payout wait for it to
+// settle first.
+func (s *serviceImpl) CreatePayouts() {
+	unrecorded := []models.UnrecordedPayments{}
+	err := s.repo.GetUnrecordedPayments(&unrecorded)
+	if err != nil {
+		s.logger.Error("Could not get unrecorded payments", zap.Error(err))
+		return
+	}
+
+	for i := range unrecorded {
+		_ = s.RecordPayment(&unrecorded[i].Payments, unrecorded[i].OwnerUserId)
+	}
+
+	balances := []models.OwnerBalances{}
+	err = s.repo.GetPayableBalances(&balances, max(money.FromBaht(s.cfg.PayoutMinimum), 1))
+	if err != nil {
+		s.logger.Error("Could not get payable balances", zap.Error(err))
+		return
+	}
+
+	for _, balance := range balances {
+		amount := balance.Balance
+		payout := &models.Payouts{
+			PayoutId:          uuid.New(),
+			UserId:            balance.UserId,
+			Amount:            amount,
+			BankName:          balance.BankName,
+			BankAccountNumber: balance.BankAccountNumber,
+			Status:            enums.PendingPayout,
+		}
+
+		err = s.repo.CreatePayout(payout, &models.LedgerTransactions{
+			TransactionId: uuid.New(),
+			Kind:          enums.PayoutTransaction,
+			Reference:     fmt.Sprintf("payout:%v", payout.PayoutId),
+			Description:   fmt.Sprintf("Payout of %v THB to %v %v", amount, balance.BankName, maskAccountNumber(balance.BankAccountNumber)),
+			PayoutId:      &payout.PayoutId,
+			Entries: []models.LedgerEntries{
+				{Account: enums.OwnerPayableAccount, UserId: &balance.UserId, Amount: amount},
+				{Account: enums.OwnerPayoutsPendingAccount, UserId: &balance.UserId, Amount: -amount},
+			},
+		})
+		if err != nil {
+			s.logger.Error("Could not create payout", zap.Error(err), zap.String("userId", balance.UserId.String()))
+		}
+	}
+}
+
+func (s *serviceImpl) GetPendingPayouts(payouts *[]models.Payouts) *apperror.AppError {
+	err := s.repo.GetPayoutsByStatus(payouts, enums.PendingPayout)
+	if err != nil {
+		s.logger.Error("Could not get pending payouts", zap.Error(err))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get payouts")
+	}
+
+	return nil
+}
+
+// CompletePayout marks a pending payout as paid once the bank transfer with
+// the given reference has gone through.
+func (s *serviceImpl) CompletePayout(payout *models.Payouts, payoutId uuid.UUID, reference string) *apperror.AppError {
+	reference = strings.TrimSpace(reference)
+	if reference == "" || len(reference) > maxPayoutReference {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe(fmt.Sprintf("Transfer reference must be between 1 and %v characters", maxPayoutReference))
+	}
+
+	now := time.Now()
+	payout.Status = enums.PaidPayout
+	payout.Reference = &reference
+	payout.PaidAt = &now
+
+	return s.settlePayout(payout, payoutId, enums.PayoutPaidTransaction, func(amount money.Amount, userId *uuid.UUID) []models.LedgerEntries {
+		return []models.LedgerEntries{
+			{Account: enums.OwnerPayoutsPendingAccount, UserId: userId, Amount: amount},
+			{Account: enums.CashAccount, UserId: userId, Amount: -amount},
+		}
+	})
+}
+
+// FailPayout marks a pending payout as failed and gives its amount back to
+// the owner's balance, to be paid out again in a later batch.
+func (s *serviceImpl) FailPayout(payout *models.Payouts, payoutId uuid.UUID, reason string) *apperror.AppError {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxFailureReason {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe(fmt.Sprintf("Failure reason must be between 1 and %v characters", maxFailureReason))
+	}
+
+	payout.Status = enums.FailedPayout
+	payout.FailureReason = &reason
+
+	return s.settlePayout(payout, payoutId, enums.PayoutFailedTransaction, func(amount money.Amount, userId *uuid.UUID) []models.LedgerEntries {
+		return []models.LedgerEntries{
+			{Account: enums.OwnerPayoutsPendingAccount, UserId: userId, Amount: amount},
+			{Account: enums.OwnerPayableAccount, UserId: userId, Amount: -amount},
+		}
+	})
+}
+
+func (s *serviceImpl) settlePayout(payout *models.Payouts, payoutId uuid.UUID, kind enums.LedgerTransactionKinds, entries func(money.Amount, *uuid.UUID) []models.LedgerEntries) *apperror.AppError {
+	current := models.Payouts{}
+	err := s.repo.GetPayout(&current, payoutId)
+	if err != nil {
+		s.logger.Error("Could not get payout", zap.Error(err), zap.String("payoutId", payoutId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not update payout")
+	} else if current.PayoutId == uuid.Nil {
+		return apperror.
+			New(apperror.PayoutNotFound).
+			Describe("Could not find the specified payout")
+	} else if current.Status != enums.PendingPayout {
+		return apperror.
+			New(apperror.PayoutNotPending).
+			Describe(fmt.Sprintf("Payout is already %v", current.Status))
+	}
+
+	payout.PayoutId = payoutId
+	settled, err := s.repo.SettlePayout(payout, &models.LedgerTransactions{
+		TransactionId: uuid.New(),
+		Kind:          kind,
+		Reference:     fmt.Sprintf("%v:%v", strings.ToLower(string(kind)), payoutId),
+		Description:   fmt.Sprintf("Payout of %v THB %v", current.Amount, strings.ToLower(string(payout.Status))),
+		PayoutId:      &payoutId,
+		Entries:       entries(current.Amount, &current.UserId),
+	})
+	if err != nil {
+		s.logger.Error("Could not settle payout", zap.Error(err), zap.String("payoutId", payoutId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not update payout")
+	} else if !settled {
+		return apperror.
+			New(apperror.PayoutNotPending).
+			Describe(fmt.Sprintf("Payout is already %v", payout.Status))
+	}
+
+	return nil
+}
+
+// RunPayoutWorker blocks, creating payouts every PAYOUT_INTERVAL seconds.
+func (s *serviceImpl) RunPayoutWorker() {
+	interval := s.cfg.PayoutInterval
+	if interval <= 0 {
+		interval = defaultPayoutTick
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.CreatePayouts()
+	}
+}
+
+// platformFee is the cut the platform takes of price.
+func (s *serviceImpl) platformFee(price money.Amount) money.Amount {
+	return price.Percent(math.Min(math.Max(s.cfg.PlatformFeePercent, 0), 100))
+}
+
+// maskAccountNumber hides all but the last few digits of a bank account.
+func maskAccountNumber(number string) string {
+	if len(number) <= visibleAccountTail {
+		return number
+	}
+
+	return strings.Repeat("*", len(number)-visibleAccountTail) + number[len(number)-visibleAccountTail:]
+}
@@ -0,0 +1,175 @@
+package notifications
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/brain-flowing-company/pprp-backend/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type Handler interface {
+	GetVapidKey(c *fiber.Ctx) error
+	SubscribePush(c *fiber.Ctx) error
+	UnsubscribePush(c *fiber.Ctx) error
+	GetPreferences(c *fiber.Ctx) error
+	UpdatePreferences(c *fiber.Ctx) error
+}
+
+type handlerImpl struct {
+	service Service
+	cfg     *config.Config
+}
+
+func NewHandler(cfg *config.Config, service Service) Handler {
+	return &handlerImpl{
+		service,
+		cfg,
+	}
+}
+
+// @router      /api/v1/notifications/vapid-key [get]
+// @summary     Get VAPID public key
+// @description Get the application server key to pass to PushManager.subscribe
+// @tags        notifications
+// @produce     json
+// @success     200	{object} models.VapidKeys
+func (h *handlerImpl) GetVapidKey(c *fiber.Ctx) error {
+	return c.JSON(models.VapidKeys{
+		PublicKey: h.cfg.VapidPublicKey,
+	})
+}
+
+// @router      /api/v1/notifications/push-subscriptions [post]
+// @summary     Subscribe a device to web push *use cookies*
+// @description Register the browser's push subscription. Subscribing an endpoint again moves it to the current user.
+// @tags        notifications
+// @produce     json
+// @param       body body models.CreatingPushSubscriptions true "Push subscription"
+// @success     201	{object} models.PushSubscriptions
+// @failure     400 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) SubscribePush(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	body := models.CreatingPushSubscriptions{}
+	err := c.BodyParser(&body)
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.InvalidBody).
+			Describe(fmt.Sprintf("Could not parse body: %v", err.Error())))
+	}
+
+	sub := &models.PushSubscriptions{
+		SubscriptionId: uuid.New(),
+		UserId:         session.UserId,
+		Endpoint:       body.Endpoint,
+		P256dh:         body.Keys.P256dh,
+		Auth:           body.Keys.Auth,
+		CreatedAt:      time.Now(),
+	}
+
+	if userAgent := c.Get(fiber.HeaderUserAgent); userAgent != "" {
+		sub.UserAgent = &userAgent
+	}
+
+	apperr := h.service.SubscribePush(sub)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(sub)
+}
+
+// @router      /api/v1/notifications/push-subscriptions [delete]
+// @summary     Unsubscribe a device from web push *use cookies*
+// @description Remove the push subscription with the given endpoint
+// @tags        notifications
+// @produce     json
+// @param       body body models.DeletingPushSubscriptions true "Push subscription endpoint"
+// @success     200	{object} models.MessageResponses
+// @failure     400 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) UnsubscribePush(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	body := models.DeletingPushSubscriptions{}
+	err := c.BodyParser(&body)
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.InvalidBody).
+			Describe(fmt.Sprintf("Could not parse body: %v", err.Error())))
+	}
+
+	apperr := h.service.UnsubscribePush(session.UserId, body.Endpoint)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return utils.ResponseMessage(c, fiber.StatusOK, "Unsubscribed from push notifications")
+}
+
+// @router      /api/v1/notifications/preferences [get]
+// @summary     Get notification preferences *use cookies*
+// @description Get how the current user is notified of messages received while offline
+// @tags        notifications
+// @produce     json
+// @success     200	{object} models.NotificationPreferences
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) GetPreferences(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	prefs := models.NotificationPreferences{}
+	apperr := h.service.GetPreferences(&prefs, session.UserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.JSON(prefs)
+}
+
+// @router      /api/v1/notifications/preferences [put]
+// @summary     Update notification preferences *use cookies*
+// @description Update web push and email digest settings. Quiet hours are HH:MM in the given timezone and may wrap around midnight; omit both to disable.
+// @tags        notifications
+// @produce     json
+// @param       body body models.NotificationPreferences true "Notification preferences"
+// @success     200	{object} models.NotificationPreferences
+// @failure     400 {object} models.ErrorResponses
+// @failure     500 {object} models.ErrorResponses
+func (h *handlerImpl) UpdatePreferences(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		session = models.Sessions{}
+	}
+
+	prefs := models.NotificationPreferences{}
+	err := c.BodyParser(&prefs)
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.InvalidBody).
+			Describe(fmt.Sprintf("Could not parse body: %v", err.Error())))
+	}
+
+	prefs.UserId = session.UserId
+
+	apperr := h.service.UpdatePreferences(&prefs)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.JSON(prefs)
+}
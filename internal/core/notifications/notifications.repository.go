@@ -0,0 +1,136 @@
+package notifications
+
+import (
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	CreatePushSubscription(*models.PushSubscriptions) error
+	DeletePushSubscription(userId uuid.UUID, endpoint string) error
+	DeletePushSubscriptionByEndpoint(endpoint string) error
+	GetPushTargets(targets *[]models.PushTargets, chatId uuid.UUID, userIds []uuid.UUID) error
+	GetPreferences(*models.NotificationPreferences, uuid.UUID) error
+	SavePreferences(*models.NotificationPreferences) error
+	GetUserById(*models.Users, uuid.UUID) error
+	GetDigestMessages(msgs *[]models.DigestMessages, defaultDelay int) error
+	MarkNotified(userId uuid.UUID, messageIds []uuid.UUID, notifiedAt time.Time) error
+}
+
+type repositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repositoryImpl{
+		db,
+	}
+}
+
+// CreatePushSubscription stores sub, taking over the endpoint if the browser
+// was previously subscribed by another account.
+func (repo *repositoryImpl) CreatePushSubscription(sub *models.PushSubscriptions) error {
+	return repo.db.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "endpoint"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "p256dh", "auth", "user_agent", "created_at"}),
+		}).
+		Create(sub).Error
+}
+
+func (repo *repositoryImpl) DeletePushSubscription(userId uuid.UUID, endpoint string) error {
+	return repo.db.
+		Where("user_id = ? AND endpoint = ?", userId, endpoint).
+		Delete(&models.PushSubscriptions{}).Error
+}
+
+func (repo *repositoryImpl) DeletePushSubscriptionByEndpoint(endpoint string) error {
+	return repo.db.
+		Where("endpoint = ?", endpoint).
+		Delete(&models.PushSubscriptions{}).Error
+}
+
+func (repo *repositoryImpl) GetPushTargets(targets *[]models.PushTargets, chatId uuid.UUID, userIds []uuid.UUID) error {
+	return repo.db.Model(&models.PushSubscriptions{}).
+		Raw(`
+		SELECT push_subscriptions.*,
+			notification_preferences.quiet_hours_start,
+			notification_preferences.quiet_hours_end,
+			COALESCE(notification_preferences.timezone, 'Asia/Bangkok') AS timezone
+		FROM push_subscriptions
+		LEFT JOIN notification_preferences
+		ON notification_preferences.user_id = push_subscriptions.user_id
+		LEFT JOIN chat_mutes
+		ON chat_mutes.user_id = push_subscriptions.user_id
+			AND chat_mutes.conversation_id = ?
+		WHERE push_subscriptions.user_id IN ?
+			AND COALESCE(notification_preferences.web_push, TRUE)
+			AND chat_mutes.user_id IS NULL
+		`, chatId, userIds).
+		Scan(targets).Error
+}
+
+func (repo *repositoryImpl) GetPreferences(prefs *models.NotificationPreferences, userId uuid.UUID) error {
+	return repo.db.First(prefs, "user_id = ?", userId).Error
+}
+
+func (repo *repositoryImpl) SavePreferences(prefs *models.NotificationPreferences) error {
+	return repo.db.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			UpdateAll: true,
+		}).
+		Create(prefs).Error
+}
+
+func (repo *repositoryImpl) GetUserById(user *models.Users, userId uuid.UUID) error {
+	return repo.db.First(user, "user_id = ?", userId).Error
+}
+
+func (repo *repositoryImpl) GetDigestMessages(msgs *[]models.DigestMessages, defaultDelay int) error {
+	return repo.db.Model(&models.MessageReceipts{}).
+		Raw(`
+		SELECT message_receipts.user_id,
+			users.email,
+			users.first_name,
+			messages.conversation_id AS chat_id,
+			messages.message_id,
+			COALESCE(senders.first_name, '') AS sender_first_name,
+			COALESCE(senders.last_name, '') AS sender_last_name,
+			messages.content,
+			messages.sent_at,
+			notification_preferences.quiet_hours_start,
+			notification_preferences.quiet_hours_end,
+			COALESCE(notification_preferences.timezone, 'Asia/Bangkok') AS timezone
+		FROM message_receipts
+		JOIN messages
+		ON messages.message_id = message_receipts.message_id
+		JOIN users
+		ON users.user_id = message_receipts.user_id
+		LEFT JOIN users AS senders
+		ON senders.user_id = messages.sender_id
+		LEFT JOIN notification_preferences
+		ON notification_preferences.user_id = message_receipts.user_id
+		LEFT JOIN chat_mutes
+		ON chat_mutes.user_id = message_receipts.user_id
+			AND chat_mutes.conversation_id = messages.conversation_id
+		WHERE message_receipts.read_at IS NULL
+			AND message_receipts.notified_at IS NULL
+			AND messages.deleted_at IS NULL
+			AND chat_mutes.user_id IS NULL
+			AND COALESCE(notification_preferences.email_digest, TRUE)
+			AND messages.sent_at < NOW() - make_interval(mins => COALESCE(notification_preferences.digest_delay_minutes, ?))
+		ORDER BY message_receipts.user_id, messages.sent_at
+		`, defaultDelay).
+		Scan(msgs).Error
+}
+
+func (repo *repositoryImpl) MarkNotified(userId uuid.UUID, messageIds []uuid.UUID, notifiedAt time.Time) error {
+	return repo.db.Model(&models.MessageReceipts{}).
+		Where("user_id = ? AND message_id IN ?", userId, messageIds).
+		Update("notified_at", notifiedAt).Error
+}
@@ -0,0 +1,330 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SherClockHolmes/webpush-go"
+	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/emails"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultDigestDelay   = 15
+	maxDigestDelay       = 24 * 60
+	defaultTimezone      = "Asia/Bangkok"
+	defaultDigestTick    = 60
+	pushTTL              = 24 * 60 * 60
+	pushPreviewSize      = 120
+	digestPreviewSize    = 200
+	attachmentPreviewMsg = "Sent an attachment"
+)
+
+type Service interface {
+	SubscribePush(*models.PushSubscriptions) *apperror.AppError
+	UnsubscribePush(uuid.UUID, string) *apperror.AppError
+	GetPreferences(*models.NotificationPreferences, uuid.UUID) *apperror.AppError
+	UpdatePreferences(*models.NotificationPreferences) *apperror.AppError
+	NotifyMessage(*models.Messages, []uuid.UUID)
+	SendDigests()
+	RunDigestWorker()
+}
+
+type serviceImpl struct {
+	logger *zap.Logger
+	cfg    *config.Config
+	repo   Repository
+	emails emails.Service
+}
+
+func NewService(logger *zap.Logger, cfg *config.Config, repo Repository, emails emails.Service) Service {
+	return &serviceImpl{
+		logger,
+		cfg,
+		repo,
+		emails,
+	}
+}
+
+func (s *serviceImpl) SubscribePush(sub *models.PushSubscriptions) *apperror.AppError {
+	if sub.Endpoint == "" || sub.P256dh == "" || sub.Auth == "" {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Push subscription must have an endpoint and keys")
+	}
+
+	err := s.repo.CreatePushSubscription(sub)
+	if err != nil {
+		s.logger.Error("Could not create push subscription", zap.Error(err), zap.String("userId", sub.UserId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not subscribe to push notifications")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) UnsubscribePush(userId uuid.UUID, endpoint string) *apperror.AppError {
+	err := s.repo.DeletePushSubscription(userId, endpoint)
+	if err != nil {
+		s.logger.Error("Could not delete push subscription", zap.Error(err), zap.String("userId", userId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not unsubscribe from push notifications")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) GetPreferences(prefs *models.NotificationPreferences, userId uuid.UUID) *apperror.AppError {
+	err := s.repo.GetPreferences(prefs, userId)
+	if err == gorm.ErrRecordNotFound {
+		*prefs = models.NotificationPreferences{
+			UserId:             userId,
+			WebPush:            true,
+			EmailDigest:        true,
+			DigestDelayMinutes: defaultDigestDelay,
+			QuietHours: models.QuietHours{
+				Timezone: defaultTimezone,
+			},
+		}
+	} else if err != nil {
+		s.logger.Error("Could not get notification preferences", zap.Error(err), zap.String("userId", userId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get notification preferences")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) UpdatePreferences(prefs *models.NotificationPreferences) *apperror.AppError {
+	if prefs.DigestDelayMinutes < 1 || prefs.DigestDelayMinutes > maxDigestDelay {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe(fmt.Sprintf("Digest delay must be between 1 and %v minutes", maxDigestDelay))
+	}
+
+	if (prefs.QuietHoursStart == nil) != (prefs.QuietHoursEnd == nil) {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Quiet hours must have both a start and an end")
+	}
+
+	for _, at := range []*string{prefs.QuietHoursStart, prefs.QuietHoursEnd} {
+		if at == nil {
+			continue
+		}
+
+		if _, err := time.Parse("15:04", *at); err != nil {
+			return apperror.
+				New(apperror.BadRequest).
+				Describe("Quiet hours must be in HH:MM format")
+		}
+	}
+
+	if prefs.Timezone == "" {
+		prefs.Timezone = defaultTimezone
+	}
+
+	if _, err := time.LoadLocation(prefs.Timezone); err != nil {
+		return apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid timezone")
+	}
+
+	prefs.UpdatedAt = time.Now()
+
+	err := s.repo.SavePreferences(prefs)
+	if err != nil {
+		s.logger.Error("Could not save notification preferences", zap.Error(err), zap.String("userId", prefs.UserId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not update notification preferences")
+	}
+
+	return nil
+}
+
+// NotifyMessage sends a web push for msg to every subscribed device of the
+// given offline recipients, skipping muted chats and quiet hours.
+func (s *serviceImpl) NotifyMessage(msg *models.Messages, userIds []uuid.UUID) {
+	if len(userIds) == 0 || s.cfg.VapidPrivateKey == "" {
+		return
+	}
+
+	targets := []models.PushTargets{}
+	err := s.repo.GetPushTargets(&targets, msg.ChatId, userIds)
+	if err != nil {
+		s.logger.Error("Could not get push targets", zap.Error(err), zap.String("chatId", msg.ChatId.String()))
+		return
+	}
+
+	if len(targets) == 0 {
+		return
+	}
+
+	sender := models.Users{}
+	err = s.repo.GetUserById(&sender, msg.SenderId)
+	if err != nil {
+		s.logger.Error("Could not get message sender", zap.Error(err), zap.String("userId", msg.SenderId.String()))
+		return
+	}
+
+	payload, err := json.Marshal(models.PushPayloads{
+		ChatId:    msg.ChatId,
+		MessageId: msg.MessageId,
+		Title:     fmt.Sprintf("%v %v", sender.FirstName, sender.LastName),
+		Body:      preview(msg.Content, pushPreviewSize),
+		Url:       s.cfg.FRONTEND_URL,
+		SentAt:    msg.SentAt,
+	})
+	if err != nil {
+		s.logger.Error("Could not marshal push payload", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for _, target := range targets {
+		if target.QuietHours.Contains(now) {
+			continue
+		}
+
+		s.push(payload, &target.PushSubscriptions)
+	}
+}
+
+func (s *serviceImpl) push(payload []byte, sub *models.PushSubscriptions) {
+	resp, err := webpush.SendNotification(payload, &webpush.Subscription{
+		Endpoint: sub.Endpoint,
+		Keys: webpush.Keys{
+			P256dh: sub.P256dh,
+			Auth:   sub.Auth,
+		},
+	}, &webpush.Options{
+		Subscriber:      s.cfg.Email,
+		VAPIDPublicKey:  s.cfg.VapidPublicKey,
+		VAPIDPrivateKey: s.cfg.VapidPrivateKey,
+		TTL:             pushTTL,
+		Urgency:         webpush.UrgencyHigh,
+	})
+	if err != nil {
+		s.logger.Warn("Could not send web push", zap.Error(err), zap.String("subscriptionId", sub.SubscriptionId.String()))
+		return
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		// the browser dropped the subscription
+		err = s.repo.DeletePushSubscriptionByEndpoint(sub.Endpoint)
+		if err != nil {
+			s.logger.Error("Could not delete expired push subscription", zap.Error(err), zap.String("subscriptionId", sub.SubscriptionId.String()))
+		}
+	default:
+		if resp.StatusCode >= 400 {
+			s.logger.Warn("Push service rejected notification", zap.Int("status", resp.StatusCode), zap.String("subscriptionId", sub.SubscriptionId.String()))
+		}
+	}
+}
+
+// SendDigests emails every user a summary of the messages that have stayed
+// unread past their digest delay. Users in quiet hours are retried on a later
+// run; each message is emailed at most once.
+func (s *serviceImpl) SendDigests() {
+	msgs := []models.DigestMessages{}
+	err := s.repo.GetDigestMessages(&msgs, defaultDigestDelay)
+	if err != nil {
+		s.logger.Error("Could not get digest messages", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for start := 0; start < len(msgs); {
+		end := start
+		for end < len(msgs) && msgs[end].UserId == msgs[start].UserId {
+			end++
+		}
+
+		if !msgs[start].QuietHours.Contains(now) {
+			s.sendDigest(msgs[start:end], now)
+		}
+
+		start = end
+	}
+}
+
+func (s *serviceImpl) sendDigest(msgs []models.DigestMessages, now time.Time) {
+	recipient := msgs[0]
+
+	digest := &models.ChatDigestEmails{
+		FirstName:      recipient.FirstName,
+		UnreadMessages: len(msgs),
+		Chats:          []models.ChatDigestEntries{},
+		Url:            s.cfg.FRONTEND_URL,
+	}
+
+	entries := make(map[uuid.UUID]int)
+	messageIds := make([]uuid.UUID, 0, len(msgs))
+	for _, msg := range msgs {
+		messageIds = append(messageIds, msg.MessageId)
+
+		i, ok := entries[msg.ChatId]
+		if !ok {
+			i = len(digest.Chats)
+			entries[msg.ChatId] = i
+			digest.Chats = append(digest.Chats, models.ChatDigestEntries{})
+		}
+
+		// msgs are ordered by sent_at so the last one wins
+		digest.Chats[i].SenderName = fmt.Sprintf("%v %v", msg.SenderFirstName, msg.SenderLastName)
+		digest.Chats[i].UnreadMessages++
+		digest.Chats[i].LatestContent = preview(msg.Content, digestPreviewSize)
+	}
+
+	apperr := s.emails.SendChatDigestEmail(recipient.Email, digest)
+	if apperr != nil {
+		return
+	}
+
+	err := s.repo.MarkNotified(recipient.UserId, messageIds, now)
+	if err != nil {
+		s.logger.Error("Could not mark messages as notified", zap.Error(err), zap.String("userId", recipient.UserId.String()))
+	}
+}
+
+// RunDigestWorker blocks, sending digests every NOTIFICATION_DIGEST_INTERVAL
+// seconds.
+func (s *serviceImpl) RunDigestWorker() {
+	interval := s.cfg.NotificationInterval
+	if interval <= 0 {
+		interval = defaultDigestTick
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.SendDigests()
+	}
+}
+
+func preview(content string, size int) string {
+	if content == "" {
+		return attachmentPreviewMsg
+	}
+
+	if utf8.RuneCountInString(content) <= size {
+		return content
+	}
+
+	return string([]rune(content)[:size]) + "…"
+}
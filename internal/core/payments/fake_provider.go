@@ -0,0 +1,276 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
+	"github.com/google/uuid"
+)
+
+// FakeOutcomes is what FakeProvider does with a checkout once its delay has
+// passed.
+type FakeOutcomes string
+
+const (
+	// the payer pays
+	FakeSuccess FakeOutcomes = "success"
+	// the payment is declined
+	FakeFailure FakeOutcomes = "failure"
+	// the payer never pays and the checkout expires
+	FakeTimeout FakeOutcomes = "timeout"
+)
+
+var fakeOutcomeStatus = map[FakeOutcomes]enums.PaymentStatus{
+	FakeSuccess: enums.SucceededPayment,
+	FakeFailure: enums.FailedPayment,
+	FakeTimeout: enums.ExpiredPayment,
+}
+
+// FakeProvider settles checkouts in process, without any network access. Every
+// checkout ends with the current outcome after FAKE_PAYMENT_DELAY seconds and
+// the result is signed and handed to the webhook, the same way a real
+// provider would post it.
+type FakeProvider struct {
+	cfg       *config.Config
+	secret    []byte
+	mu        sync.Mutex
+	outcome   FakeOutcomes
+	checkouts map[string]*fakeCheckouts
+	webhook   func([]byte, string) *apperror.AppError
+}
+
+type fakeCheckouts struct {
+	paymentId     uuid.UUID
+	transactionId string
+	amount        money.Amount
+	status        enums.PaymentStatus
+	refunded      money.Amount
+}
+
+// fakeEvents is the wire format of FakeProvider webhook events.
+type fakeEvents struct {
+	EventId        string              `json:"id"`
+	EventType      string              `json:"type"`
+	PaymentId      uuid.UUID           `json:"payment_id"`
+	SessionId      string              `json:"session_id"`
+	TransactionId  string              `json:"transaction_id"`
+	Status         enums.PaymentStatus `json:"status"`
+	RefundedAmount money.Amount        `json:"refunded_amount"`
+}
+
+func NewFakeProvider(cfg *config.Config) *FakeProvider {
+	outcome := FakeOutcomes(cfg.FakePaymentOutcome)
+	if _, ok := fakeOutcomeStatus[outcome]; !ok {
+		outcome = FakeSuccess
+	}
+
+	return &FakeProvider{
+		cfg:       cfg,
+		secret:    []byte(fakeId("whsec")),
+		outcome:   outcome,
+		checkouts: map[string]*fakeCheckouts{},
+	}
+}
+
+// SetWebhook sets where events are delivered. Events are dropped until it is
+// set.
+func (p *FakeProvider) SetWebhook(webhook func([]byte, string) *apperror.AppError) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.webhook = webhook
+}
+
+// SetOutcome changes how checkouts created from now on end.
+func (p *FakeProvider) SetOutcome(outcome FakeOutcomes) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.outcome = outcome
+}
+
+func (p *FakeProvider) CreateCheckout(checkout *ProviderCheckouts, payment *models.Payments) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sessionId := fakeId("cs_fake")
+	p.checkouts[sessionId] = &fakeCheckouts{
+		paymentId:     payment.PaymentId,
+		transactionId: fakeId("pi_fake"),
+		amount:        payment.Price,
+		status:        enums.PendingPayment,
+	}
+
+	*checkout = ProviderCheckouts{
+		SessionId: sessionId,
+		URL:       fmt.Sprintf("%v/success?payment_id=%v", p.cfg.FRONTEND_URL, payment.PaymentId),
+		ExpiresAt: time.Now().Add(p.delay()),
+	}
+
+	outcome := p.outcome
+	time.AfterFunc(p.delay(), func() {
+		p.settle(sessionId, outcome)
+	})
+
+	return nil
+}
+
+func (p *FakeProvider) settle(sessionId string, outcome FakeOutcomes) {
+	p.mu.Lock()
+	checkout := p.checkouts[sessionId]
+	if checkout.status != enums.PendingPayment {
+		p.mu.Unlock()
+		return
+	}
+	checkout.status = fakeOutcomeStatus[outcome]
+	event := &fakeEvents{
+		EventType:     "checkout." + string(outcome),
+		PaymentId:     checkout.paymentId,
+		SessionId:     sessionId,
+		TransactionId: checkout.transactionId,
+		Status:        checkout.status,
+	}
+	p.mu.Unlock()
+
+	p.deliver(event)
+}
+
+func (p *FakeProvider) ExpireCheckout(payment *models.Payments) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if payment.ProviderSessionId == nil {
+		return nil
+	}
+
+	checkout, ok := p.checkouts[*payment.ProviderSessionId]
+	if !ok {
+		return errors.New("unknown checkout session")
+	} else if checkout.status != enums.PendingPayment {
+		return errors.New("checkout session is not open")
+	}
+
+	checkout.status = enums.ExpiredPayment
+
+	return nil
+}
+
+func (p *FakeProvider) VerifyWebhook(event *ProviderEvents, payload []byte, signature string) error {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(p.sign(payload), expected) {
+		return ErrInvalidSignature
+	}
+
+	e := fakeEvents{}
+	err = json.Unmarshal(payload, &e)
+	if err != nil {
+		return err
+	}
+
+	*event = ProviderEvents(e)
+
+	return nil
+}
+
+func (p *FakeProvider) Refund(refunded *ProviderRefunds, payment *models.Payments, amount money.Amount) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var checkout *fakeCheckouts
+	for _, c := range p.checkouts {
+		if payment.ProviderPaymentId != nil && c.transactionId == *payment.ProviderPaymentId {
+			checkout = c
+		}
+	}
+
+	if checkout == nil || checkout.status != enums.SucceededPayment {
+		return ErrNotRefundable
+	}
+
+	if checkout.refunded+amount > checkout.amount {
+		return errors.New("refund exceeds the amount paid")
+	}
+
+	checkout.refunded += amount
+	event := &fakeEvents{
+		EventType:      "charge.refunded",
+		TransactionId:  checkout.transactionId,
+		Status:         enums.PartiallyRefundedPayment,
+		RefundedAmount: checkout.refunded,
+	}
+	if checkout.refunded == checkout.amount {
+		event.Status = enums.RefundedPayment
+	}
+
+	*refunded = ProviderRefunds{
+		RefundId: fakeId("re_fake"),
+		Amount:   amount,
+	}
+
+	time.AfterFunc(p.delay(), func() {
+		p.deliver(event)
+	})
+
+	return nil
+}
+
+func (p *FakeProvider) GetPaymentStatus(status *enums.PaymentStatus, payment *models.Payments) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if payment.ProviderSessionId == nil {
+		*status = payment.Status
+		return nil
+	}
+
+	checkout, ok := p.checkouts[*payment.ProviderSessionId]
+	if !ok {
+		return errors.New("unknown checkout session")
+	}
+
+	*status = checkout.status
+
+	return nil
+}
+
+func (p *FakeProvider) deliver(event *fakeEvents) {
+	p.mu.Lock()
+	webhook := p.webhook
+	p.mu.Unlock()
+
+	if webhook == nil {
+		return
+	}
+
+	event.EventId = fakeId("evt_fake")
+	payload, _ := json.Marshal(event)
+	webhook(payload, hex.EncodeToString(p.sign(payload)))
+}
+
+func (p *FakeProvider) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func (p *FakeProvider) delay() time.Duration {
+	return time.Duration(p.cfg.FakePaymentDelay) * time.Second
+}
+
+func fakeId(prefix string) string {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	return prefix + "_" + hex.EncodeToString(buf)
+}
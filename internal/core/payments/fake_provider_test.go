@@ -0,0 +1,74 @@
+package payments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+)
+
+func TestFakeProviderCheckout(t *testing.T) {
+	tests := []struct {
+		outcome   FakeOutcomes
+		status    enums.PaymentStatus
+		agreement enums.AgreementStatus
+		recorded  int
+	}{
+		{FakeSuccess, enums.SucceededPayment, enums.AwaitingPaymentAgreement, 1},
+		{FakeFailure, enums.FailedPayment, enums.AwaitingDepositAgreement, 0},
+		{FakeTimeout, enums.ExpiredPayment, enums.AwaitingDepositAgreement, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.outcome), func(t *testing.T) {
+			cfg := &config.Config{
+				FakePaymentOutcome: string(tt.outcome),
+				FRONTEND_URL:       "http://localhost:3000",
+			}
+			provider := NewFakeProvider(cfg)
+			agreement := newTestAgreement(enums.AgreementForRent, enums.AwaitingDepositAgreement)
+			test := newPaymentsTest(cfg, provider, agreement)
+
+			delivered := make(chan *apperror.AppError, 1)
+			provider.SetWebhook(func(payload []byte, signature string) *apperror.AppError {
+				apperr := test.service.HandleWebhook(payload, signature)
+				delivered <- apperr
+				return apperr
+			})
+
+			checkout := ProviderCheckouts{}
+			payment := models.Payments{
+				UserId:        agreement.Dweller.DwellerUserId,
+				AgreementId:   agreement.AgreementId,
+				PaymentMethod: enums.CREDIT_CARD,
+			}
+			if apperr := test.service.CreatePayment(&checkout, &payment); apperr != nil {
+				t.Fatal(apperr)
+			}
+			if checkout.URL == "" || payment.PaymentType != enums.DepositPayment || payment.Price != agreement.DepositAmount {
+				t.Fatalf("unexpected checkout %+v for payment %+v", checkout, payment)
+			}
+
+			select {
+			case apperr := <-delivered:
+				if apperr != nil {
+					t.Fatal(apperr)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("webhook was never delivered")
+			}
+
+			paid := test.expectPayment(t, payment.PaymentId, tt.status)
+			if paid.ProviderSessionId == nil || *paid.ProviderSessionId != checkout.SessionId {
+				t.Fatalf("checkout session was not saved: %v", paid.ProviderSessionId)
+			}
+			test.expectAgreement(t, tt.agreement)
+			if test.ledger.count() != tt.recorded {
+				t.Fatalf("payment was booked %v times, expected %v", test.ledger.count(), tt.recorded)
+			}
+		})
+	}
+}
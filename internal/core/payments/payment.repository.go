@@ -1,16 +1,55 @@
 package payments
 
 import (
+	"database/sql"
+	"time"
+
 	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
 	CreatePayment(*models.Payments) error
 	GetPaymentByUserId(*models.MyPaymentsResponse, uuid.UUID) error
 	GetHistoryPaymentByUserId(*[]models.HistoryResponse, uuid.UUID) error
+	GetPayment(*models.Payments, uuid.UUID) error
+	GetPaymentByProviderId(*models.Payments, string) error
+	GetPaymentByIdempotencyKey(*models.Payments, uuid.UUID, string) error
+	GetOpenPayment(*models.Payments, uuid.UUID, enums.PaymentTypes, *int) error
+	GetStalePayments(*[]models.Payments, time.Time) error
+	SetCheckout(uuid.UUID, string, string, time.Time) error
+	SetProviderReferences(uuid.UUID, string, string) error
+	UpdatePaymentStatus(*models.Payments, uuid.UUID, enums.PaymentStatus, []enums.PaymentStatus) (bool, error)
+	RefundPayment(*models.Payments, uuid.UUID, enums.PaymentStatus, money.Amount) (bool, error)
+	CountPaidInstallments(*int64, uuid.UUID) error
+	SumPaidAmount(*money.Amount, uuid.UUID) error
+	CreateRentInstallments(*[]models.RentInstallments) error
+	GetRentInstallments(*[]models.RentInstallments, uuid.UUID) error
+	GetUnscheduledAgreements(*[]models.Agreements) error
+	GetUninvoicedInstallments(*[]models.RentInstallments, time.Time) error
+	MarkInstallmentInvoiced(uuid.UUID, int) (bool, error)
+	GetLateInstallments(*[]models.LateInstallments, time.Time) error
+	EscalateInstallment(uuid.UUID, int, int, money.Amount) (bool, error)
+	ReleaseInstallmentReminder(uuid.UUID, int, int, int) error
+	CountOverdueInstallments(*int64, uuid.UUID, time.Time) error
+	CreateRefund(*models.Refunds) error
+	CountOpenRefunds(*int64, uuid.UUID) error
+	UpdateRefund(uuid.UUID, enums.RefundStatus, string) error
+	SettleRefunds(uuid.UUID) error
+	GetRefundsByPaymentIds(*[]models.Refunds, []uuid.UUID) error
+	CreateReceipt(*models.Receipts) error
+	SetReceiptFile(uuid.UUID, string) error
+	ClaimReceiptEmail(uuid.UUID) (bool, error)
+	ReleaseReceiptEmail(uuid.UUID) error
+	GetUnsentReceipts(*[]models.Receipts) error
+	GetUserEmail(*string, uuid.UUID) error
+	CountWebhookEvents(*int64, string) error
+	CreateWebhookEvent(*models.WebhookEvents) error
 }
 
 type repositoryImpl struct {
@@ -48,8 +87,8 @@ func (r *repositoryImpl) CreatePayment(payment *models.Payments) error {
 				Describe("FK constraint in agreement table")
 		}
 
-		paymentQuery := `INSERT INTO payments (payment_id , user_id , price ,IsSuccess ,name,agreement_id,payment_method) VALUES (?,?,?,?,?,?,?)`
-		if err := tx.Exec(paymentQuery, payment.PaymentId, payment.UserId, payment.Price, payment.IsSuccess, payment.Name, payment.AgreementId, payment.PaymentMethod).Error; err != nil {
+		paymentQuery := `INSERT INTO payments (payment_id , user_id , price ,IsSuccess ,name,agreement_id,payment_method,payment_type,installment,currency,idempotency_key) VALUES (?,?,?,?,?,?,?,?,?,?,?)`
+		if err := tx.Exec(paymentQuery, payment.PaymentId, payment.UserId, payment.Price, payment.IsSuccess, payment.Name, payment.AgreementId, payment.PaymentMethod, payment.PaymentType, payment.Installment, payment.Currency, payment.IdempotencyKey).Error; err != nil {
 			return err
 		}
 		return nil
@@ -66,7 +105,9 @@ func (r *repositoryImpl) GetPaymentByUserId(payments *models.MyPaymentsResponse,
 
 func (r *repositoryImpl) GetHistoryPaymentByUserId(history *[]models.HistoryResponse, userId uuid.UUID) error {
 	err := r.db.Table("payments").
-		Select("payments.*, agreements.*").
+		Select(`agreements.*, payments.payment_id, payments.user_id, payments.price, payments.IsSuccess AS is_success,
+			payments.name, payments.payment_method, payments.payment_type, payments.status AS payment_status,
+			payments.refunded_amount, payments.created_at`).
 		Joins("JOIN agreements ON payments.agreement_id = agreements.agreement_id").
 		Where("payments.user_id = ?", userId).
 		Scan(&history).Error
@@ -75,3 +116,348 @@ func (r *repositoryImpl) GetHistoryPaymentByUserId(history *[]models.HistoryResp
 	}
 	return nil
 }
+
+// paymentColumns are read explicitly since IsSuccess is not a snake_case
+// column.
+const paymentColumns = `payment_id, user_id, price, IsSuccess AS is_success, name, agreement_id, payment_method,
+	payment_type, installment, status, refunded_amount, currency, provider_session_id, provider_payment_id, checkout_url,
+	checkout_expires_at, idempotency_key, created_at, updated_at`
+
+func (r *repositoryImpl) GetPayment(payment *models.Payments, paymentId uuid.UUID) error {
+	return r.db.Raw(`SELECT `+paymentColumns+` FROM payments WHERE payment_id = ?`, paymentId).Scan(payment).Error
+}
+
+func (r *repositoryImpl) GetPaymentByProviderId(payment *models.Payments, providerPaymentId string) error {
+	return r.db.Raw(`SELECT `+paymentColumns+` FROM payments WHERE provider_payment_id = ?`, providerPaymentId).Scan(payment).Error
+}
+
+func (r *repositoryImpl) GetPaymentByIdempotencyKey(payment *models.Payments, userId uuid.UUID, key string) error {
+	return r.db.Raw(`SELECT `+paymentColumns+` FROM payments WHERE user_id = ? AND idempotency_key = ?`, userId, key).Scan(payment).Error
+}
+
+// GetOpenPayment loads the pending payment of an agreement for the deposit,
+// balance or installment given, of which there is at most one.
+func (r *repositoryImpl) GetOpenPayment(payment *models.Payments, agreementId uuid.UUID, paymentType enums.PaymentTypes, installment *int) error {
+	return r.db.Raw(`
+		SELECT `+paymentColumns+` FROM payments
+		WHERE agreement_id = ? AND payment_type = ? AND installment IS NOT DISTINCT FROM ? AND status = ?
+	`, agreementId, paymentType, installment, enums.PendingPayment).Scan(payment).Error
+}
+
+// GetStalePayments loads pending payments whose checkout expired before now.
+// Payments without a checkout are given as long as providers keep one open,
+// a day.
+func (r *repositoryImpl) GetStalePayments(payments *[]models.Payments, now time.Time) error {
+	return r.db.Raw(`
+		SELECT `+paymentColumns+` FROM payments
+		WHERE status = ? AND COALESCE(checkout_expires_at, created_at + INTERVAL '1 day') < ?
+		ORDER BY created_at
+	`, enums.PendingPayment, now).Scan(payments).Error
+}
+
+// SetCheckout stores the checkout page opened for a payment, so that asking
+// for it again returns the same page.
+func (r *repositoryImpl) SetCheckout(paymentId uuid.UUID, sessionId string, url string, expiresAt time.Time) error {
+	return r.db.Exec(`
+		UPDATE payments
+		SET provider_session_id = ?, checkout_url = ?, checkout_expires_at = ?
+		WHERE payment_id = ?
+	`, sessionId, url, expiresAt, paymentId).Error
+}
+
+// SetProviderReferences stores the ids the payment provider gave to the
+// checkout and to the money movement of a payment. Empty ids leave the stored
+// ones untouched.
+func (r *repositoryImpl) SetProviderReferences(paymentId uuid.UUID, sessionId string, providerPaymentId string) error {
+	return r.db.Exec(`
+		UPDATE payments
+		SET provider_session_id = COALESCE(NULLIF(?, ''), provider_session_id),
+			provider_payment_id = COALESCE(NULLIF(?, ''), provider_payment_id)
+		WHERE payment_id = ?
+	`, sessionId, providerPaymentId, paymentId).Error
+}
+
+// UpdatePaymentStatus moves a payment to status if it is currently in one of
+// from, then loads it. It reports whether the payment changed.
+func (r *repositoryImpl) UpdatePaymentStatus(payment *models.Payments, paymentId uuid.UUID, status enums.PaymentStatus, from []enums.PaymentStatus) (bool, error) {
+	result := r.db.Exec(`
+		UPDATE payments
+		SET status = ?, IsSuccess = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE payment_id = ? AND status IN ?
+	`, status, status == enums.SucceededPayment, paymentId, from)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	if err := r.GetPayment(payment, paymentId); err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// RefundPayment records that refunded of a successful payment has been paid
+// back, then loads it. Stale refund events, which carry a smaller total, are
+// ignored. It reports whether the payment changed.
+func (r *repositoryImpl) RefundPayment(payment *models.Payments, paymentId uuid.UUID, status enums.PaymentStatus, refunded money.Amount) (bool, error) {
+	result := r.db.Exec(`
+		UPDATE payments
+		SET status = ?, refunded_amount = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE payment_id = ? AND status IN ? AND refunded_amount < ?
+	`, status, refunded, paymentId, paidStatus, refunded)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	if err := r.GetPayment(payment, paymentId); err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// paidStatus are the statuses of payments whose money is, at least partly,
+// still with the owner.
+var paidStatus = []enums.PaymentStatus{enums.SucceededPayment, enums.PartiallyRefundedPayment}
+
+func (r *repositoryImpl) CountPaidInstallments(count *int64, agreementId uuid.UUID) error {
+	return r.db.Raw(`
+		SELECT COUNT(*) FROM payments
+		WHERE agreement_id = ? AND payment_type = ? AND status IN ?
+	`, agreementId, enums.InstallmentPayment, paidStatus).Scan(count).Error
+}
+
+// SumPaidAmount adds up everything paid towards agreementId, less refunds.
+func (r *repositoryImpl) SumPaidAmount(sum *money.Amount, agreementId uuid.UUID) error {
+	return r.db.Raw(`
+		SELECT COALESCE(SUM(price - refunded_amount), 0) FROM payments
+		WHERE agreement_id = ? AND status IN ?
+	`, agreementId, paidStatus).Scan(sum).Error
+}
+
+// CreateRentInstallments saves a rent schedule. Installments that already
+// exist are kept as they are.
+func (r *repositoryImpl) CreateRentInstallments(installments *[]models.RentInstallments) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(installments).Error
+}
+
+// installmentPayments picks the earliest successful payment of every
+// installment in rent_installments i.
+const installmentPayments = `
+	LEFT JOIN LATERAL (
+		SELECT payment_id, updated_at AS paid_at FROM payments
+		WHERE agreement_id = i.agreement_id AND payment_type = @type AND installment = i.installment AND status IN @paid
+		ORDER BY updated_at
+		LIMIT 1
+	) p ON TRUE`
+
+func (r *repositoryImpl) GetRentInstallments(installments *[]models.RentInstallments, agreementId uuid.UUID) error {
+	return r.db.Raw(`
+		SELECT i.*, p.payment_id, p.paid_at FROM rent_installments i`+installmentPayments+`
+		WHERE i.agreement_id = @agreement_id
+		ORDER BY i.installment
+	`, sql.Named("type", enums.InstallmentPayment),
+		sql.Named("paid", paidStatus),
+		sql.Named("agreement_id", agreementId)).Scan(installments).Error
+}
+
+// GetUnscheduledAgreements finds renting agreements that are being rented out
+// but have no rent schedule yet.
+func (r *repositoryImpl) GetUnscheduledAgreements(agreements *[]models.Agreements) error {
+	return r.db.Raw(`
+		SELECT a.* FROM agreements a
+		WHERE a.agreement_type = ? AND a.status IN ?
+			AND NOT EXISTS (SELECT 1 FROM rent_installments i WHERE i.agreement_id = a.agreement_id)
+	`, enums.AgreementForRent, rentedStatus).Scan(agreements).Error
+}
+
+// GetUninvoicedInstallments finds unpaid installments of agreements that are
+// being rented out, due on or before dueBy, that have not been invoiced.
+func (r *repositoryImpl) GetUninvoicedInstallments(installments *[]models.RentInstallments, dueBy time.Time) error {
+	return r.db.Raw(`
+		SELECT i.*, p.payment_id, p.paid_at FROM rent_installments i`+installmentPayments+`
+		JOIN agreements a ON a.agreement_id = i.agreement_id
+		WHERE i.invoiced_at IS NULL AND i.due_date <= @due_by AND p.payment_id IS NULL AND a.status IN @rented
+		ORDER BY i.due_date, i.installment
+	`, sql.Named("type", enums.InstallmentPayment),
+		sql.Named("paid", paidStatus),
+		sql.Named("due_by", dueBy),
+		sql.Named("rented", rentedStatus)).Scan(installments).Error
+}
+
+// MarkInstallmentInvoiced reports whether the installment was not invoiced
+// before, so only one caller sends the invoice.
+func (r *repositoryImpl) MarkInstallmentInvoiced(agreementId uuid.UUID, installment int) (bool, error) {
+	result := r.db.Exec(`
+		UPDATE rent_installments
+		SET invoiced_at = CURRENT_TIMESTAMP
+		WHERE agreement_id = ? AND installment = ? AND invoiced_at IS NULL
+	`, agreementId, installment)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetLateInstallments finds unpaid installments of agreements that are being
+// rented out that were due before today.
+func (r *repositoryImpl) GetLateInstallments(installments *[]models.LateInstallments, today time.Time) error {
+	return r.db.Raw(`
+		SELECT i.*, p.payment_id, p.paid_at,
+			a.status AS agreement_status, a.payment_duration,
+			pr.property_id, pr.property_name,
+			o.user_id AS owner_user_id, o.first_name AS owner_first_name, o.email AS owner_email,
+			d.user_id AS dweller_user_id, d.first_name AS dweller_first_name, d.last_name AS dweller_last_name, d.email AS dweller_email
+		FROM rent_installments i`+installmentPayments+`
+		JOIN agreements a ON a.agreement_id = i.agreement_id
+		JOIN properties pr ON pr.property_id = a.property_id
+		JOIN users o ON o.user_id = a.owner_user_id
+		JOIN users d ON d.user_id = a.dweller_user_id
+		WHERE i.due_date < @today AND p.payment_id IS NULL AND a.status IN @rented
+		ORDER BY a.owner_user_id, i.due_date, i.installment
+	`, sql.Named("type", enums.InstallmentPayment),
+		sql.Named("paid", paidStatus),
+		sql.Named("today", today),
+		sql.Named("rented", rentedStatus)).Scan(installments).Error
+}
+
+// EscalateInstallment records that the reminder at level has been sent for an
+// installment, charging lateFee unless a fee was charged before. It reports
+// whether the level had not been reached before, so only one caller sends it.
+func (r *repositoryImpl) EscalateInstallment(agreementId uuid.UUID, installment int, level int, lateFee money.Amount) (bool, error) {
+	result := r.db.Exec(`
+		UPDATE rent_installments
+		SET reminder_level = ?,
+			late_fee = CASE WHEN late_fee = 0 THEN ? ELSE late_fee END,
+			reminded_at = CURRENT_TIMESTAMP
+		WHERE agreement_id = ? AND installment = ? AND reminder_level < ?
+	`, level, lateFee, agreementId, installment, level)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReleaseInstallmentReminder undoes EscalateInstallment to level after the
+// reminder could not be sent, putting the installment back at previous.
+func (r *repositoryImpl) ReleaseInstallmentReminder(agreementId uuid.UUID, installment int, level int, previous int) error {
+	return r.db.Exec(`
+		UPDATE rent_installments
+		SET reminder_level = ?
+		WHERE agreement_id = ? AND installment = ? AND reminder_level = ?
+	`, previous, agreementId, installment, level).Error
+}
+
+// CountOverdueInstallments counts the unpaid installments of an agreement
+// that were due before dueBefore.
+func (r *repositoryImpl) CountOverdueInstallments(count *int64, agreementId uuid.UUID, dueBefore time.Time) error {
+	return r.db.Raw(`
+		SELECT COUNT(*) FROM rent_installments i`+installmentPayments+`
+		WHERE i.agreement_id = @agreement_id AND i.due_date < @due_before AND p.payment_id IS NULL
+	`, sql.Named("type", enums.InstallmentPayment),
+		sql.Named("paid", paidStatus),
+		sql.Named("agreement_id", agreementId),
+		sql.Named("due_before", dueBefore)).Scan(count).Error
+}
+
+// rentedStatus are the statuses of renting agreements whose rent is being
+// collected.
+var rentedStatus = []enums.AgreementStatus{enums.RentingAgreement, enums.OverdueAgreement}
+
+// CreateRefund saves a refund along with its deductions.
+func (r *repositoryImpl) CreateRefund(refund *models.Refunds) error {
+	return r.db.Create(refund).Error
+}
+
+// CountOpenRefunds counts the refunds of a payment that have not failed.
+func (r *repositoryImpl) CountOpenRefunds(count *int64, paymentId uuid.UUID) error {
+	return r.db.Model(&models.Refunds{}).
+		Where("payment_id = ? AND status <> ?", paymentId, enums.FailedRefund).
+		Count(count).Error
+}
+
+// UpdateRefund moves a refund to status. An empty providerRefundId leaves the
+// stored one untouched.
+func (r *repositoryImpl) UpdateRefund(refundId uuid.UUID, status enums.RefundStatus, providerRefundId string) error {
+	return r.db.Exec(`
+		UPDATE refunds
+		SET status = ?, provider_refund_id = COALESCE(NULLIF(?, ''), provider_refund_id), updated_at = CURRENT_TIMESTAMP
+		WHERE refund_id = ?
+	`, status, providerRefundId, refundId).Error
+}
+
+// SettleRefunds marks the pending refunds of a payment as succeeded once the
+// provider reports the money paid back.
+func (r *repositoryImpl) SettleRefunds(paymentId uuid.UUID) error {
+	return r.db.Exec(`
+		UPDATE refunds
+		SET status = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE payment_id = ? AND status = ?
+	`, enums.SucceededRefund, paymentId, enums.PendingRefund).Error
+}
+
+func (r *repositoryImpl) GetRefundsByPaymentIds(refunds *[]models.Refunds, paymentIds []uuid.UUID) error {
+	return r.db.Preload("Deductions").
+		Where("payment_id IN ?", paymentIds).
+		Order("created_at").
+		Find(refunds).Error
+}
+
+// CreateReceipt numbers a new receipt for a payment, then loads it. A payment
+// that already has one keeps it.
+func (r *repositoryImpl) CreateReceipt(receipt *models.Receipts) error {
+	err := r.db.Exec(`
+		INSERT INTO receipts (payment_id, amount, vat_percent, vat_amount)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (payment_id) DO NOTHING
+	`, receipt.PaymentId, receipt.Amount, receipt.VatPercent, receipt.VatAmount).Error
+	if err != nil {
+		return err
+	}
+
+	return r.db.Model(&models.Receipts{}).
+		Where("payment_id = ?", receipt.PaymentId).
+		First(receipt).Error
+}
+
+func (r *repositoryImpl) SetReceiptFile(paymentId uuid.UUID, key string) error {
+	return r.db.Model(&models.Receipts{}).
+		Where("payment_id = ?", paymentId).
+		Update("file_key", key).Error
+}
+
+// ClaimReceiptEmail marks a receipt as emailed unless it already is, and
+// reports whether it was marked, so that only one caller sends it.
+func (r *repositoryImpl) ClaimReceiptEmail(paymentId uuid.UUID) (bool, error) {
+	result := r.db.Exec(`
+		UPDATE receipts SET emailed_at = CURRENT_TIMESTAMP
+		WHERE payment_id = ? AND emailed_at IS NULL
+	`, paymentId)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReleaseReceiptEmail undoes ClaimReceiptEmail after the email could not be
+// sent.
+func (r *repositoryImpl) ReleaseReceiptEmail(paymentId uuid.UUID) error {
+	return r.db.Model(&models.Receipts{}).
+		Where("payment_id = ?", paymentId).
+		Update("emailed_at", nil).Error
+}
+
+// GetUnsentReceipts finds receipts that could not be stored or emailed when
+// they were issued.
+func (r *repositoryImpl) GetUnsentReceipts(receipts *[]models.Receipts) error {
+	return r.db.Model(&models.Receipts{}).
+		Where("file_key IS NULL OR emailed_at IS NULL").
+		Order("receipt_number").
+		Find(receipts).Error
+}
+
+func (r *repositoryImpl) GetUserEmail(email *string, userId uuid.UUID) error {
+	return r.db.Raw(`SELECT email FROM users WHERE user_id = ?`, userId).Scan(email).Error
+}
+
+func (r *repositoryImpl) CountWebhookEvents(count *int64, eventId string) error {
+	return r.db.Model(&models.WebhookEvents{}).Where("event_id = ?", eventId).Count(count).Error
+}
+
+func (r *repositoryImpl) CreateWebhookEvent(event *models.WebhookEvents) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event).Error
+}
@@ -1,44 +1,998 @@
 package payments
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/brain-flowing-company/pprp-backend/apperror"
 	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/emails"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/events"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
+	"github.com/brain-flowing-company/pprp-backend/storage"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type Service interface {
-	CreatePayment(*models.Payments) error
+	CreatePayment(*ProviderCheckouts, *models.Payments) *apperror.AppError
+	GetPaymentById(*models.Payments, uuid.UUID, uuid.UUID) *apperror.AppError
 	GetPaymentByUserId(*models.MyPaymentsResponse, uuid.UUID) error
 	GetHistoryPaymentByUserId(*[]models.HistoryResponse, uuid.UUID) error
+	CompletePayment(*models.Payments, uuid.UUID) *apperror.AppError
+	HandleWebhook([]byte, string) *apperror.AppError
+	CreateRefund(*models.Refunds, *models.CreatingRefunds, uuid.UUID, uuid.UUID) *apperror.AppError
+	GetRentSchedule(*models.RentSchedules, string, uuid.UUID) *apperror.AppError
+	GetReceipt(*models.Receipts, uuid.UUID, uuid.UUID) (io.ReadCloser, *apperror.AppError)
+	BillRent()
+	CollectOverdueRent()
+	IssueReceipts()
+	ExpireStalePayments()
+	RunBillingWorker()
+}
+
+const (
+	defaultBillingTick     = 60 * 60
+	defaultInvoiceLeadDays = 7
+	defaultGraceDays       = 3
+	finalReminderDays      = 7
+	maxRefundReason        = 500
+	maxDeductionLength     = 200
+	checkoutReuseMargin    = 5 * time.Minute
+	maxIdempotencyKey      = 255
+)
+
+// Reminders for a late installment escalate with how late it is: the day
+// after it is due, once the grace period is over and the agreement becomes
+// overdue, and finalReminderDays after that. Each is sent at most once.
+const (
+	lateReminder = iota + 1
+	overdueReminder
+	finalReminder
+)
+
+// AgreementService is the part of the agreements service that payments need to
+// find both sides of an agreement and move it along once it is paid.
+type AgreementService interface {
+	GetAgreementById(*models.AgreementDetails, string) *apperror.AppError
+	UpdateAgreementStatus(*models.UpdatingAgreementStatus, string) *apperror.AppError
+}
+
+// Notifier posts payment updates into the chat between the two sides of an
+// agreement. It is implemented by the chat hub.
+type Notifier interface {
+	SendNotificationMessage(interface{}, string, uuid.UUID, uuid.UUID) *apperror.AppError
+	SettlePaymentRequests(uuid.UUID, []uuid.UUID) *apperror.AppError
+}
+
+// Ledger books payments as owed to the owner of their agreement. It is
+// implemented by the ledger service.
+type Ledger interface {
+	RecordPayment(*models.Payments, uuid.UUID) *apperror.AppError
 }
 
 type serviceImpl struct {
-	repo   Repository
-	logger *zap.Logger
-	cfg    *config.Config
+	repo       Repository
+	logger     *zap.Logger
+	cfg        *config.Config
+	events     events.Publisher
+	agreements AgreementService
+	notifier   Notifier
+	provider   PaymentProvider
+	emails     emails.Service
+	ledger     Ledger
+	storage    storage.Storage
 }
 
-func NewService(logger *zap.Logger, repo Repository, cfg *config.Config) Service {
+func NewService(logger *zap.Logger, repo Repository, cfg *config.Config, events events.Publisher, agreements AgreementService, notifier Notifier, provider PaymentProvider, emails emails.Service, ledger Ledger, storage storage.Storage) Service {
 	return &serviceImpl{
 		repo,
 		logger,
 		cfg,
+		events,
+		agreements,
+		notifier,
+		provider,
+		emails,
+		ledger,
+		storage,
 	}
 }
 
-func (s *serviceImpl) CreatePayment(payment *models.Payments) error {
-	fmt.Println("service payment = ", payment)
+// paymentTransitions lists the statuses a payment may move to each status
+// from. Anything else is a stale or repeated event.
+var paymentTransitions = map[enums.PaymentStatus][]enums.PaymentStatus{
+	enums.SucceededPayment: {enums.PendingPayment, enums.FailedPayment, enums.ExpiredPayment},
+	enums.FailedPayment:    {enums.PendingPayment},
+	enums.ExpiredPayment:   {enums.PendingPayment, enums.FailedPayment},
+}
+
+// agreementProgress is the status an agreement of each type moves to once one
+// of its payments succeeds. A sale is complete, and archived, once its balance
+// is paid.
+var agreementProgress = map[enums.AgreementTypes]map[enums.AgreementStatus]enums.AgreementStatus{
+	enums.AgreementForRent: {
+		enums.AwaitingDepositAgreement: enums.AwaitingPaymentAgreement,
+		enums.AwaitingPaymentAgreement: enums.RentingAgreement,
+		enums.OverdueAgreement:         enums.RentingAgreement,
+	},
+	enums.AgreementForSell: {
+		enums.AwaitingDepositAgreement: enums.AwaitingPaymentAgreement,
+		enums.AwaitingPaymentAgreement: enums.ArchivedAgreement,
+	},
+}
+
+// CreatePayment prices a new payment from its agreement, ignoring whatever the
+// client asked for, and opens a checkout for it. Only the dweller can pay, and
+// only while the agreement is waiting for money.
+//
+// Asking again is safe. A request with an idempotency key that was seen before
+// gets the same payment back, and an open checkout for the same deposit,
+// balance or installment is handed out again instead of opening another one.
+func (s *serviceImpl) CreatePayment(checkout *ProviderCheckouts, payment *models.Payments) *apperror.AppError {
+	if payment.IdempotencyKey != nil {
+		found, apperr := s.getIdempotentPayment(checkout, payment)
+		if found || apperr != nil {
+			return apperr
+		}
+	}
+
+	agreement := models.AgreementDetails{}
+	apperr := s.agreements.GetAgreementById(&agreement, payment.AgreementId.String())
+	if apperr != nil {
+		return apperr
+	}
+
+	if agreement.Dweller.DwellerUserId != payment.UserId {
+		return apperror.
+			New(apperror.NotAgreementDweller).
+			Describe("Only the dweller of this agreement can pay for it")
+	}
+
+	apperr = s.priceAgreementPayment(payment, &agreement)
+	if apperr != nil {
+		return apperr
+	}
+
+	reused, apperr := s.reuseOpenPayment(checkout, payment)
+	if reused || apperr != nil {
+		return apperr
+	}
+
 	err := s.repo.CreatePayment(payment)
-	if err != nil {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// another request got there first
+		if payment.IdempotencyKey != nil {
+			if found, apperr := s.getIdempotentPayment(checkout, payment); found || apperr != nil {
+				return apperr
+			}
+		}
+
+		return apperror.
+			New(apperror.PaymentInProgress).
+			Describe("A checkout for this payment is already being opened")
+	} else if appErr, ok := err.(*apperror.AppError); ok {
+		return appErr
+	} else if err != nil {
 		s.logger.Error("Failed to create payment", zap.Error(err))
-		return err
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Failed to create payment")
 	}
+
+	err = s.provider.CreateCheckout(checkout, payment)
+	if err != nil {
+		s.logger.Error("Could not create checkout", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+
+		_, err = s.repo.UpdatePaymentStatus(payment, payment.PaymentId, enums.FailedPayment, paymentTransitions[enums.FailedPayment])
+		if err != nil {
+			s.logger.Error("Could not fail payment", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		}
+
+		return apperror.
+			New(apperror.ServiceUnavailable).
+			Describe("Could not reach the payment provider")
+	}
+
+	err = s.repo.SetCheckout(payment.PaymentId, checkout.SessionId, checkout.URL, checkout.ExpiresAt)
+	if err != nil {
+		s.logger.Error("Could not save checkout", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not update payment")
+	}
+
 	return nil
 }
 
+// getIdempotentPayment loads the payment created earlier by the payer with
+// the same idempotency key, with its checkout. It reports whether there was
+// one.
+func (s *serviceImpl) getIdempotentPayment(checkout *ProviderCheckouts, payment *models.Payments) (bool, *apperror.AppError) {
+	found := models.Payments{}
+	err := s.repo.GetPaymentByIdempotencyKey(&found, payment.UserId, *payment.IdempotencyKey)
+	if err != nil {
+		s.logger.Error("Could not get payment by idempotency key", zap.Error(err), zap.String("userId", payment.UserId.String()))
+		return false, apperror.
+			New(apperror.InternalServerError).
+			Describe("Failed to create payment")
+	}
+
+	if found.PaymentId == uuid.Nil {
+		return false, nil
+	}
+
+	asked := payment.Installment
+	if found.AgreementId != payment.AgreementId || found.PaymentMethod != payment.PaymentMethod ||
+		asked != nil && (found.Installment == nil || *found.Installment != *asked) {
+		return false, apperror.
+			New(apperror.IdempotencyKeyReused).
+			Describe("This Idempotency-Key was already used for a different checkout")
+	}
+
+	*payment = found
+	setCheckout(checkout, &found)
+
+	return true, nil
+}
+
+// reuseOpenPayment hands out the checkout that is already open for what
+// payment is priced as, if the payer can still use it. A checkout that does
+// not match, or is about to expire, is closed so that it cannot be paid on top
+// of the new one. It reports whether the open payment was reused.
+func (s *serviceImpl) reuseOpenPayment(checkout *ProviderCheckouts, payment *models.Payments) (bool, *apperror.AppError) {
+	open := models.Payments{}
+	err := s.repo.GetOpenPayment(&open, payment.AgreementId, payment.PaymentType, payment.Installment)
+	if err != nil {
+		s.logger.Error("Could not get open payment", zap.Error(err), zap.String("agreementId", payment.AgreementId.String()))
+		return false, apperror.
+			New(apperror.InternalServerError).
+			Describe("Failed to create payment")
+	}
+
+	if open.PaymentId == uuid.Nil {
+		return false, nil
+	}
+
+	usable := open.UserId == payment.UserId &&
+		open.PaymentMethod == payment.PaymentMethod &&
+		open.Price == payment.Price &&
+		open.CheckoutURL != nil &&
+		open.CheckoutExpiresAt != nil &&
+		time.Until(*open.CheckoutExpiresAt) > checkoutReuseMargin
+
+	// a payment whose checkout is still being opened has nothing to hand out
+	// yet, but must not be closed either
+	if !usable && open.CheckoutURL == nil && open.CreatedAt != nil && time.Since(*open.CreatedAt) < checkoutReuseMargin {
+		return false, apperror.
+			New(apperror.PaymentInProgress).
+			Describe("A checkout for this payment is already being opened")
+	}
+
+	if usable {
+		*payment = open
+		setCheckout(checkout, &open)
+		return true, nil
+	}
+
+	closed, apperr := s.closeCheckout(&open, true)
+	if apperr != nil {
+		return false, apperr
+	} else if !closed {
+		return false, apperror.
+			New(apperror.PaymentInProgress).
+			Describe("The previous payment for this is still being processed")
+	}
+
+	return false, nil
+}
+
+// closeCheckout makes sure a pending payment can no longer be paid and marks
+// it expired. The provider is asked first, since the payer may have paid
+// after all, in which case the payment is completed instead. A checkout the
+// provider still considers open is only closed when expire is set. It reports
+// whether the payment was expired.
+func (s *serviceImpl) closeCheckout(payment *models.Payments, expire bool) (bool, *apperror.AppError) {
+	status := enums.ExpiredPayment
+	if payment.ProviderSessionId != nil {
+		err := s.provider.GetPaymentStatus(&status, payment)
+		if err != nil {
+			s.logger.Warn("Could not get payment status from provider", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+			return false, apperror.
+				New(apperror.ServiceUnavailable).
+				Describe("Could not reach the payment provider")
+		}
+	}
+
+	switch status {
+	case enums.SucceededPayment:
+		return false, s.CompletePayment(payment, payment.PaymentId)
+
+	case enums.PendingPayment:
+		if !expire {
+			return false, nil
+		}
+
+		err := s.provider.ExpireCheckout(payment)
+		if err != nil {
+			s.logger.Warn("Could not expire checkout", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+			return false, nil
+		}
+	}
+
+	apperr := s.changePaymentStatus(payment, payment.PaymentId, enums.ExpiredPayment)
+	if apperr != nil {
+		return false, apperr
+	}
+
+	return true, nil
+}
+
+// ExpireStalePayments closes pending payments whose checkout has expired, so
+// they stop holding up a new checkout for the same thing. Payments the
+// provider is still processing, such as PromptPay transfers, are left alone.
+func (s *serviceImpl) ExpireStalePayments() {
+	payments := []models.Payments{}
+	err := s.repo.GetStalePayments(&payments, time.Now())
+	if err != nil {
+		s.logger.Error("Could not get stale payments", zap.Error(err))
+		return
+	}
+
+	for i := range payments {
+		_, apperr := s.closeCheckout(&payments[i], false)
+		if apperr != nil {
+			s.logger.Error("Could not expire stale payment", zap.Error(apperr), zap.String("paymentId", payments[i].PaymentId.String()))
+		}
+	}
+}
+
+func setCheckout(checkout *ProviderCheckouts, payment *models.Payments) {
+	*checkout = ProviderCheckouts{}
+	if payment.ProviderSessionId != nil {
+		checkout.SessionId = *payment.ProviderSessionId
+	}
+	if payment.CheckoutURL != nil {
+		checkout.URL = *payment.CheckoutURL
+	}
+	if payment.CheckoutExpiresAt != nil {
+		checkout.ExpiresAt = *payment.CheckoutExpiresAt
+	}
+}
+
+// GetPaymentById loads a payment for its payer or the owner of its agreement.
+// A payment the provider has not settled yet is refreshed from the provider
+// first, in case a webhook event went missing.
+func (s *serviceImpl) GetPaymentById(payment *models.Payments, paymentId uuid.UUID, userId uuid.UUID) *apperror.AppError {
+	err := s.repo.GetPayment(payment, paymentId)
+	if err != nil {
+		s.logger.Error("Could not get payment", zap.Error(err), zap.String("paymentId", paymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get payment")
+	}
+
+	if payment.PaymentId == uuid.Nil {
+		return apperror.
+			New(apperror.PaymentNotFound).
+			Describe("Could not find the specified payment")
+	}
+
+	if payment.UserId != userId {
+		agreement := models.AgreementDetails{}
+		apperr := s.agreements.GetAgreementById(&agreement, payment.AgreementId.String())
+		if apperr != nil {
+			return apperr
+		}
+
+		if agreement.Owner.OwnerUserId != userId {
+			return apperror.
+				New(apperror.PaymentNotFound).
+				Describe("Could not find the specified payment")
+		}
+	}
+
+	if payment.Status != enums.PendingPayment && payment.Status != enums.FailedPayment {
+		return nil
+	}
+
+	var status enums.PaymentStatus
+	err = s.provider.GetPaymentStatus(&status, payment)
+	if err != nil {
+		s.logger.Warn("Could not get payment status from provider", zap.Error(err), zap.String("paymentId", paymentId.String()))
+		return nil
+	}
+
+	switch status {
+	case enums.SucceededPayment:
+		return s.CompletePayment(payment, paymentId)
+	case enums.ExpiredPayment:
+		return s.changePaymentStatus(payment, paymentId, status)
+	}
+
+	return nil
+}
+
+// priceAgreementPayment sets what payment pays for and how much it is. The
+// deposit comes first; after that renting agreements are paid one monthly
+// installment at a time, a specific one if asked for, and selling agreements
+// pay whatever is left of the total, the deposit included.
+func (s *serviceImpl) priceAgreementPayment(payment *models.Payments, agreement *models.AgreementDetails) *apperror.AppError {
+	awaitingMoney := agreement.Status == enums.AwaitingPaymentAgreement || agreement.Status == enums.OverdueAgreement
+
+	asked := payment.Installment
+	payment.Installment = nil
+	payment.Currency = money.DefaultCurrency
+
+	switch {
+	case agreement.Status == enums.AwaitingDepositAgreement:
+		payment.PaymentType = enums.DepositPayment
+		payment.Name = "Deposit"
+		payment.Price = agreement.DepositAmount
+
+	case agreement.AgreementType == enums.AgreementForRent && (awaitingMoney || agreement.Status == enums.RentingAgreement):
+		installment := models.RentInstallments{}
+		apperr := s.getPayableInstallment(&installment, agreement, asked)
+		if apperr != nil {
+			return apperr
+		}
+
+		payment.PaymentType = enums.InstallmentPayment
+		payment.Installment = &installment.Installment
+		payment.Name = rentLabel(installment.Installment, agreement.PaymentDuration)
+		if installment.LateFee > 0 {
+			payment.Name += " with late fee"
+		}
+		payment.Price = installment.AmountDue()
+
+	case agreement.AgreementType == enums.AgreementForSell && awaitingMoney:
+		var paid money.Amount
+		err := s.repo.SumPaidAmount(&paid, agreement.AgreementId)
+		if err != nil {
+			s.logger.Error("Could not sum paid amount", zap.Error(err), zap.String("agreementId", agreement.AgreementId.String()))
+			return apperror.
+				New(apperror.InternalServerError).
+				Describe("Failed to create payment")
+		}
+
+		payment.PaymentType = enums.BalancePayment
+		payment.Name = "Remaining balance"
+		payment.Price = agreement.TotalPayment - paid
+
+	default:
+		return apperror.
+			New(apperror.AgreementNotPayable).
+			Describe("This agreement is not waiting for a payment")
+	}
+
+	if asked != nil && payment.PaymentType != enums.InstallmentPayment {
+		return apperror.
+			New(apperror.AgreementNotPayable).
+			Describe("This agreement is not waiting for rent")
+	}
+
+	if payment.Price <= 0 {
+		return apperror.
+			New(apperror.AgreementNotPayable).
+			Describe("There is nothing left to pay for this agreement")
+	}
+
+	return nil
+}
+
+// getPayableInstallment finds the installment a rent payment is for: the one
+// asked for, or else the earliest invoiced one that is still unpaid. Until the
+// agreement is rented out there is no schedule, and only the first
+// installment, which starts the renting, can be paid.
+func (s *serviceImpl) getPayableInstallment(installment *models.RentInstallments, agreement *models.AgreementDetails, asked *int) *apperror.AppError {
+	if asked != nil && (*asked < 1 || *asked > agreement.PaymentDuration) {
+		return apperror.
+			New(apperror.AgreementNotPayable).
+			Describe(fmt.Sprintf("This agreement has no installment %v", *asked))
+	}
+
+	if agreement.Status == enums.AwaitingPaymentAgreement {
+		var paid int64
+		err := s.repo.CountPaidInstallments(&paid, agreement.AgreementId)
+		if err != nil {
+			s.logger.Error("Could not count paid installments", zap.Error(err), zap.String("agreementId", agreement.AgreementId.String()))
+			return apperror.
+				New(apperror.InternalServerError).
+				Describe("Failed to create payment")
+		}
+
+		if paid > 0 || (asked != nil && *asked != 1) {
+			return apperror.
+				New(apperror.AgreementNotPayable).
+				Describe("Only the first installment can be paid before renting starts")
+		}
+
+		*installment = models.RentInstallments{
+			AgreementId: agreement.AgreementId,
+			Installment: 1,
+			Amount:      agreement.PaymentPerMonth,
+		}
+		return nil
+	}
+
+	installments := []models.RentInstallments{}
+	apperr := s.getRentInstallments(&installments, agreement.AgreementId, agreement.PaymentPerMonth, agreement.PaymentDuration)
+	if apperr != nil {
+		return apperr
+	}
+
+	for _, candidate := range installments {
+		if asked != nil && candidate.Installment != *asked {
+			continue
+		}
+
+		if candidate.PaymentId != nil {
+			if asked == nil {
+				continue
+			}
+			return apperror.
+				New(apperror.AgreementNotPayable).
+				Describe(fmt.Sprintf("Installment %v has already been paid", *asked))
+		}
+
+		if candidate.InvoicedAt == nil {
+			if asked == nil {
+				break
+			}
+			return apperror.
+				New(apperror.AgreementNotPayable).
+				Describe(fmt.Sprintf("Installment %v is not due yet", *asked))
+		}
+
+		*installment = candidate
+		return nil
+	}
+
+	return apperror.
+		New(apperror.AgreementNotPayable).
+		Describe("There is no rent due for this agreement")
+}
+
+// GetRentSchedule lists every installment of a renting agreement for either of
+// its sides. Agreements that are not rented out yet have an empty schedule.
+func (s *serviceImpl) GetRentSchedule(schedule *models.RentSchedules, agreementId string, userId uuid.UUID) *apperror.AppError {
+	agreement := models.AgreementDetails{}
+	apperr := s.agreements.GetAgreementById(&agreement, agreementId)
+	if apperr != nil {
+		return apperr
+	}
+
+	if agreement.Owner.OwnerUserId != userId && agreement.Dweller.DwellerUserId != userId {
+		return apperror.
+			New(apperror.AgreementNotFound).
+			Describe("Could not find the specified agreement")
+	}
+
+	if agreement.AgreementType != enums.AgreementForRent {
+		return apperror.
+			New(apperror.NotRentingAgreement).
+			Describe("Only renting agreements have a rent schedule")
+	}
+
+	*schedule = models.RentSchedules{
+		AgreementId:       agreement.AgreementId,
+		TotalInstallments: agreement.PaymentDuration,
+		Installments:      []models.RentInstallments{},
+	}
+
+	if agreement.Status != enums.RentingAgreement && agreement.Status != enums.OverdueAgreement {
+		return nil
+	}
+
+	apperr = s.getRentInstallments(&schedule.Installments, agreement.AgreementId, agreement.PaymentPerMonth, agreement.PaymentDuration)
+	if apperr != nil {
+		return apperr
+	}
+
+	for _, installment := range schedule.Installments {
+		if installment.Status == enums.PaidInstallment {
+			schedule.PaidInstallments++
+		}
+	}
+
+	return nil
+}
+
+// getRentInstallments loads the rent schedule of an agreement that is being
+// rented out, generating it first if it does not exist yet.
+func (s *serviceImpl) getRentInstallments(installments *[]models.RentInstallments, agreementId uuid.UUID, perMonth money.Amount, duration int) *apperror.AppError {
+	err := s.repo.GetRentInstallments(installments, agreementId)
+	if err == nil && len(*installments) == 0 {
+		apperr := s.createRentSchedule(agreementId, perMonth, duration)
+		if apperr != nil {
+			return apperr
+		}
+
+		err = s.repo.GetRentInstallments(installments, agreementId)
+	}
+
+	if err != nil {
+		s.logger.Error("Could not get rent installments", zap.Error(err), zap.String("agreementId", agreementId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get rent schedule")
+	}
+
+	cutoff := s.overdueCutoff()
+	for i := range *installments {
+		installment := &(*installments)[i]
+		switch {
+		case installment.PaymentId != nil:
+			installment.Status = enums.PaidInstallment
+		case installment.DueDate.Before(cutoff):
+			installment.Status = enums.OverdueInstallment
+		default:
+			installment.Status = enums.UnpaidInstallment
+		}
+		installment.Payable = installment.PaymentId == nil && installment.InvoicedAt != nil
+	}
+
+	return nil
+}
+
+// createRentSchedule splits a renting agreement into monthly installments, the
+// first one due today, which is when the agreement starts renting.
+func (s *serviceImpl) createRentSchedule(agreementId uuid.UUID, perMonth money.Amount, duration int) *apperror.AppError {
+	start := startOfDay(time.Now())
+
+	installments := make([]models.RentInstallments, 0, duration)
+	for i := 0; i < duration; i++ {
+		installments = append(installments, models.RentInstallments{
+			AgreementId: agreementId,
+			Installment: i + 1,
+			DueDate:     addMonths(start, i),
+			Amount:      perMonth,
+		})
+	}
+
+	if len(installments) == 0 {
+		return nil
+	}
+
+	err := s.repo.CreateRentInstallments(&installments)
+	if err != nil {
+		s.logger.Error("Could not create rent schedule", zap.Error(err), zap.String("agreementId", agreementId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not create rent schedule")
+	}
+
+	return nil
+}
+
+// BillRent generates the schedules that are missing and invoices every
+// installment that falls due within INVOICE_LEAD_DAYS, by sending the dweller
+// a payment request in the chat with the owner.
+func (s *serviceImpl) BillRent() {
+	agreements := []models.Agreements{}
+	err := s.repo.GetUnscheduledAgreements(&agreements)
+	if err != nil {
+		s.logger.Error("Could not get unscheduled agreements", zap.Error(err))
+	}
+
+	for _, agreement := range agreements {
+		_ = s.createRentSchedule(agreement.AgreementId, agreement.PaymentPerMonth, agreement.PaymentDuration)
+	}
+
+	leadDays := s.cfg.InvoiceLeadDays
+	if leadDays <= 0 {
+		leadDays = defaultInvoiceLeadDays
+	}
+
+	installments := []models.RentInstallments{}
+	err = s.repo.GetUninvoicedInstallments(&installments, startOfDay(time.Now()).AddDate(0, 0, leadDays))
+	if err != nil {
+		s.logger.Error("Could not get uninvoiced installments", zap.Error(err))
+		return
+	}
+
+	for i := range installments {
+		s.invoiceInstallment(&installments[i])
+	}
+}
+
+func (s *serviceImpl) invoiceInstallment(installment *models.RentInstallments) {
+	invoiced, err := s.repo.MarkInstallmentInvoiced(installment.AgreementId, installment.Installment)
+	if err != nil {
+		s.logger.Error("Could not invoice installment", zap.Error(err), zap.String("agreementId", installment.AgreementId.String()))
+		return
+	} else if !invoiced {
+		return
+	}
+
+	agreement := models.AgreementDetails{}
+	apperr := s.agreements.GetAgreementById(&agreement, installment.AgreementId.String())
+	if apperr != nil {
+		s.logger.Error("Could not get agreement of installment", zap.Error(apperr), zap.String("agreementId", installment.AgreementId.String()))
+		return
+	}
+
+	message := fmt.Sprintf("%v of %v THB is due on %v",
+		rentLabel(installment.Installment, agreement.PaymentDuration), installment.Amount, installment.DueDate.Format("2 Jan 2006"))
+
+	apperr = s.notifier.SendNotificationMessage(&models.PaymentRequests{
+		AgreementId:   agreement.AgreementId,
+		PropertyId:    agreement.Property.PropertyId,
+		OwnerUserId:   agreement.Owner.OwnerUserId,
+		DwellerUserId: agreement.Dweller.DwellerUserId,
+		Amount:        installment.Amount,
+		Installment:   &installment.Installment,
+	}, message, agreement.Owner.OwnerUserId, agreement.Dweller.DwellerUserId)
+	if apperr != nil {
+		s.logger.Error("Could not send rent invoice", zap.Error(apperr), zap.String("agreementId", installment.AgreementId.String()))
+	}
+}
+
+// CollectOverdueRent reminds the dwellers of late installments by chat and
+// email. Agreements with an installment unpaid past OVERDUE_GRACE_DAYS become
+// overdue and the installment is charged a LATE_FEE_PERCENT late fee. Owners
+// get a summary of their late installments whenever a dweller is reminded.
+func (s *serviceImpl) CollectOverdueRent() {
+	today := startOfDay(time.Now())
+
+	installments := []models.LateInstallments{}
+	err := s.repo.GetLateInstallments(&installments, today)
+	if err != nil {
+		s.logger.Error("Could not get late installments", zap.Error(err))
+		return
+	}
+
+	overdue := map[uuid.UUID]bool{}
+	reminded := map[uuid.UUID]bool{}
+	for i := range installments {
+		installment := &installments[i]
+		daysLate := daysBetween(installment.DueDate, today)
+		level := s.reminderLevel(daysLate)
+
+		if level >= overdueReminder && installment.AgreementStatus == enums.RentingAgreement && !overdue[installment.AgreementId] {
+			overdue[installment.AgreementId] = true
+			apperr := s.agreements.UpdateAgreementStatus(&models.UpdatingAgreementStatus{Status: enums.OverdueAgreement}, installment.AgreementId.String())
+			if apperr != nil {
+				s.logger.Error("Could not mark agreement overdue", zap.Error(apperr), zap.String("agreementId", installment.AgreementId.String()))
+			}
+		}
+
+		if level <= installment.ReminderLevel {
+			continue
+		}
+
+		lateFee := money.Amount(0)
+		if level >= overdueReminder {
+			lateFee = s.lateFee(installment.Amount)
+		}
+
+		escalated, err := s.repo.EscalateInstallment(installment.AgreementId, installment.Installment, level, lateFee)
+		if err != nil {
+			s.logger.Error("Could not escalate installment", zap.Error(err), zap.String("agreementId", installment.AgreementId.String()))
+			continue
+		} else if !escalated {
+			continue
+		}
+
+		if installment.LateFee == 0 {
+			installment.LateFee = lateFee
+		}
+		previous := installment.ReminderLevel
+		installment.ReminderLevel = level
+
+		apperr := s.remindDweller(installment, daysLate)
+		if apperr != nil {
+			s.logger.Error("Could not email rent reminder", zap.Error(apperr), zap.String("agreementId", installment.AgreementId.String()))
+			if err = s.repo.ReleaseInstallmentReminder(installment.AgreementId, installment.Installment, level, previous); err != nil {
+				s.logger.Error("Could not release installment reminder", zap.Error(err), zap.String("agreementId", installment.AgreementId.String()))
+			}
+			continue
+		}
+		reminded[installment.OwnerUserId] = true
+	}
+
+	s.summarizeOverdueRent(installments, reminded, today)
+}
+
+// remindDweller sends the reminder at the installment's level by chat and
+// email. A failed chat message is only logged, while a failed email is
+// returned so that the reminder can be sent again.
+func (s *serviceImpl) remindDweller(installment *models.LateInstallments, daysLate int) *apperror.AppError {
+	label := rentLabel(installment.Installment, installment.PaymentDuration)
+	dueDate := installment.DueDate.Format("2 Jan 2006")
+	amountDue := installment.AmountDue()
+
+	var heading, message string
+	switch installment.ReminderLevel {
+	case lateReminder:
+		heading = "your rent is due"
+		message = fmt.Sprintf("%v of %v THB was due on %v. Please pay it soon.", label, amountDue, dueDate)
+	case overdueReminder:
+		heading = "your rent is overdue"
+		message = fmt.Sprintf("%v is %v days overdue.", label, daysLate)
+		if installment.LateFee > 0 {
+			message += fmt.Sprintf(" A late fee of %v THB has been added.", installment.LateFee)
+		}
+		message += fmt.Sprintf(" %v THB is now due.", amountDue)
+	default:
+		heading = "this is the final reminder for your rent"
+		message = fmt.Sprintf("%v is %v days overdue and %v THB is still due. Please pay it now or contact the owner.", label, daysLate, amountDue)
+	}
+
+	apperr := s.notifier.SendNotificationMessage(&models.PaymentRequests{
+		AgreementId:   installment.AgreementId,
+		PropertyId:    installment.PropertyId,
+		OwnerUserId:   installment.OwnerUserId,
+		DwellerUserId: installment.DwellerUserId,
+		Amount:        amountDue,
+		Installment:   &installment.Installment,
+	}, message, installment.OwnerUserId, installment.DwellerUserId)
+	if apperr != nil {
+		s.logger.Error("Could not send rent reminder", zap.Error(apperr), zap.String("agreementId", installment.AgreementId.String()))
+	}
+
+	return s.emails.SendRentReminderEmail(installment.DwellerEmail, &models.RentReminderEmails{
+		FirstName:    installment.DwellerFirstName,
+		Heading:      heading,
+		Message:      message,
+		PropertyName: installment.PropertyName,
+		Installment:  label,
+		DueDate:      dueDate,
+		Amount:       installment.Amount,
+		LateFee:      installment.LateFee,
+		AmountDue:    amountDue,
+		Url:          s.cfg.FRONTEND_URL,
+	})
+}
+
+// summarizeOverdueRent emails every owner in owners all of their late
+// installments.
+func (s *serviceImpl) summarizeOverdueRent(installments []models.LateInstallments, owners map[uuid.UUID]bool, today time.Time) {
+	summaries := map[uuid.UUID]*models.OverdueSummaryEmails{}
+	addresses := map[uuid.UUID]string{}
+
+	for _, installment := range installments {
+		if !owners[installment.OwnerUserId] {
+			continue
+		}
+
+		summary, ok := summaries[installment.OwnerUserId]
+		if !ok {
+			summary = &models.OverdueSummaryEmails{
+				FirstName: installment.OwnerFirstName,
+				Url:       s.cfg.FRONTEND_URL,
+			}
+			summaries[installment.OwnerUserId] = summary
+			addresses[installment.OwnerUserId] = installment.OwnerEmail
+		}
+
+		summary.Installments = append(summary.Installments, models.OverdueSummaryEntries{
+			PropertyName: installment.PropertyName,
+			DwellerName:  fmt.Sprintf("%v %v", installment.DwellerFirstName, installment.DwellerLastName),
+			Installment:  rentLabel(installment.Installment, installment.PaymentDuration),
+			DueDate:      installment.DueDate.Format("2 Jan 2006"),
+			DaysLate:     daysBetween(installment.DueDate, today),
+			AmountDue:    installment.AmountDue(),
+		})
+		summary.TotalDue += installment.AmountDue()
+	}
+
+	for ownerId, summary := range summaries {
+		// a missed summary is not retried, the next one lists these installments again
+		apperr := s.emails.SendOverdueSummaryEmail(addresses[ownerId], summary)
+		if apperr != nil {
+			s.logger.Error("Could not email overdue summary", zap.Error(apperr), zap.String("ownerId", ownerId.String()))
+		}
+	}
+}
+
+// hasOverdueRent reports whether an overdue agreement still has installments
+// unpaid past the grace period, in which case paying one of them does not end
+// it.
+func (s *serviceImpl) hasOverdueRent(agreement *models.AgreementDetails) bool {
+	if agreement.Status != enums.OverdueAgreement || agreement.AgreementType != enums.AgreementForRent {
+		return false
+	}
+
+	var count int64
+	err := s.repo.CountOverdueInstallments(&count, agreement.AgreementId, s.overdueCutoff())
+	if err != nil {
+		s.logger.Error("Could not count overdue installments", zap.Error(err), zap.String("agreementId", agreement.AgreementId.String()))
+		return false
+	}
+
+	return count > 0
+}
+
+func (s *serviceImpl) reminderLevel(daysLate int) int {
+	grace := s.graceDays()
+
+	switch {
+	case daysLate > grace+finalReminderDays:
+		return finalReminder
+	case daysLate > grace:
+		return overdueReminder
+	case daysLate >= 1:
+		return lateReminder
+	}
+
+	return 0
+}
+
+func (s *serviceImpl) lateFee(amount money.Amount) money.Amount {
+	if s.cfg.LateFeePercent <= 0 {
+		return 0
+	}
+
+	return amount.Percent(s.cfg.LateFeePercent)
+}
+
+func (s *serviceImpl) graceDays() int {
+	if s.cfg.OverdueGraceDays <= 0 {
+		return defaultGraceDays
+	}
+
+	return s.cfg.OverdueGraceDays
+}
+
+// overdueCutoff is the due date installments unpaid since before are overdue.
+func (s *serviceImpl) overdueCutoff() time.Time {
+	return startOfDay(time.Now()).AddDate(0, 0, -s.graceDays())
+}
+
+// RunBillingWorker blocks, billing rent and chasing late rent every
+// BILLING_INTERVAL seconds.
+func (s *serviceImpl) RunBillingWorker() {
+	interval := s.cfg.BillingInterval
+	if interval <= 0 {
+		interval = defaultBillingTick
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.BillRent()
+		s.CollectOverdueRent()
+		s.IssueReceipts()
+		s.ExpireStalePayments()
+	}
+}
+
+func rentLabel(installment int, duration int) string {
+	return fmt.Sprintf("Rent %v/%v", installment, duration)
+}
+
+func daysBetween(from time.Time, to time.Time) int {
+	return int(startOfDay(to).Sub(startOfDay(from)).Hours() / 24)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// addMonths moves t n months ahead, keeping to the last day of shorter months
+// so that rent due on the 31st is due on the 30th in April, not in May.
+func addMonths(t time.Time, n int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(n), 1, 0, 0, 0, 0, t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, t.Location())
+}
+
 func (s *serviceImpl) GetPaymentByUserId(payments *models.MyPaymentsResponse, userId uuid.UUID) error {
 	err := s.repo.GetPaymentByUserId(payments, userId)
 	if err != nil {
@@ -54,5 +1008,687 @@ func (s *serviceImpl) GetHistoryPaymentByUserId(history *[]models.HistoryRespons
 		s.logger.Error("Failed to get history payment by user id", zap.Error(err))
 		return err
 	}
+
+	if len(*history) == 0 {
+		return nil
+	}
+
+	paymentIds := make([]uuid.UUID, 0, len(*history))
+	for _, payment := range *history {
+		paymentIds = append(paymentIds, payment.PaymentID)
+	}
+
+	refunds := []models.Refunds{}
+	err = s.repo.GetRefundsByPaymentIds(&refunds, paymentIds)
+	if err != nil {
+		s.logger.Error("Failed to get refunds of payments", zap.Error(err))
+		return err
+	}
+
+	for i := range *history {
+		payment := &(*history)[i]
+		payment.Refunds = []models.Refunds{}
+		for _, refund := range refunds {
+			if refund.PaymentId == payment.PaymentID {
+				payment.Refunds = append(payment.Refunds, refund)
+			}
+		}
+	}
+
 	return nil
 }
+
+// CreateRefund pays a deposit back to the dweller, less deductions, once its
+// agreement has been cancelled or completed. Only the owner can refund, and
+// only once; the refund is settled when the provider reports it.
+func (s *serviceImpl) CreateRefund(refund *models.Refunds, creating *models.CreatingRefunds, paymentId uuid.UUID, userId uuid.UUID) *apperror.AppError {
+	payment := models.Payments{}
+	err := s.repo.GetPayment(&payment, paymentId)
+	if err != nil {
+		s.logger.Error("Could not get payment", zap.Error(err), zap.String("paymentId", paymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not refund payment")
+	} else if payment.PaymentId == uuid.Nil {
+		return apperror.
+			New(apperror.PaymentNotFound).
+			Describe("Could not find the specified payment")
+	}
+
+	agreement := models.AgreementDetails{}
+	apperr := s.agreements.GetAgreementById(&agreement, payment.AgreementId.String())
+	if apperr != nil {
+		return apperr
+	}
+
+	if agreement.Owner.OwnerUserId != userId {
+		if payment.UserId != userId {
+			return apperror.
+				New(apperror.PaymentNotFound).
+				Describe("Could not find the specified payment")
+		}
+		return apperror.
+			New(apperror.NotAgreementOwner).
+			Describe("Only the owner of this agreement can refund it")
+	}
+
+	apperr = s.checkRefundable(&payment, &agreement)
+	if apperr != nil {
+		return apperr
+	}
+
+	amount, apperr := refundAmount(&payment, creating)
+	if apperr != nil {
+		return apperr
+	}
+
+	*refund = models.Refunds{
+		RefundId:    uuid.New(),
+		PaymentId:   payment.PaymentId,
+		AgreementId: payment.AgreementId,
+		RequestedBy: userId,
+		Amount:      amount,
+		Reason:      creating.Reason,
+		Status:      enums.PendingRefund,
+		Deductions:  creating.Deductions,
+	}
+
+	// saved before asking the provider, so the webhook that reports the
+	// refund always finds it
+	err = s.repo.CreateRefund(refund)
+	if err != nil {
+		s.logger.Error("Could not create refund", zap.Error(err), zap.String("paymentId", paymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not refund payment")
+	}
+
+	refunded := ProviderRefunds{}
+	err = s.provider.Refund(&refunded, &payment, amount)
+	if err != nil {
+		s.logger.Error("Could not refund payment with provider", zap.Error(err), zap.String("paymentId", paymentId.String()))
+
+		refund.Status = enums.FailedRefund
+		updateErr := s.repo.UpdateRefund(refund.RefundId, refund.Status, "")
+		if updateErr != nil {
+			s.logger.Error("Could not fail refund", zap.Error(updateErr), zap.String("refundId", refund.RefundId.String()))
+		}
+
+		if errors.Is(err, ErrNotRefundable) {
+			return apperror.
+				New(apperror.PaymentNotRefundable).
+				Describe("The payment provider can not refund this payment")
+		}
+		return apperror.
+			New(apperror.ServiceUnavailable).
+			Describe("Could not reach the payment provider")
+	}
+
+	err = s.repo.UpdateRefund(refund.RefundId, refund.Status, refunded.RefundId)
+	if err != nil {
+		s.logger.Error("Could not save provider refund id", zap.Error(err), zap.String("refundId", refund.RefundId.String()))
+	}
+
+	message := fmt.Sprintf("Refunding %v THB of %v: %v", amount, payment.Name, creating.Reason)
+	for _, deduction := range creating.Deductions {
+		message += fmt.Sprintf("\n- %v: %v THB deducted", deduction.Description, deduction.Amount)
+	}
+	s.notifyChat(&payment, &agreement, message, agreement.Owner.OwnerUserId, payment.UserId)
+
+	return nil
+}
+
+// checkRefundable makes sure payment is a paid deposit of an agreement that
+// is over, and that it has not been refunded yet.
+func (s *serviceImpl) checkRefundable(payment *models.Payments, agreement *models.AgreementDetails) *apperror.AppError {
+	if payment.PaymentType != enums.DepositPayment {
+		return apperror.
+			New(apperror.PaymentNotRefundable).
+			Describe("Only deposits can be refunded")
+	}
+
+	if agreement.Status != enums.CancelledAgreement && agreement.Status != enums.ArchivedAgreement {
+		return apperror.
+			New(apperror.PaymentNotRefundable).
+			Describe("Deposits can only be refunded once the agreement is cancelled or completed")
+	}
+
+	if payment.Status != enums.SucceededPayment {
+		return apperror.
+			New(apperror.PaymentNotRefundable).
+			Describe("This deposit has not been paid or has already been refunded")
+	}
+
+	var open int64
+	err := s.repo.CountOpenRefunds(&open, payment.PaymentId)
+	if err != nil {
+		s.logger.Error("Could not count refunds", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not refund payment")
+	} else if open > 0 {
+		return apperror.
+			New(apperror.PaymentNotRefundable).
+			Describe("This deposit has already been refunded")
+	}
+
+	return nil
+}
+
+// refundAmount is what is left of the deposit once the deductions are taken
+// out.
+func refundAmount(payment *models.Payments, creating *models.CreatingRefunds) (money.Amount, *apperror.AppError) {
+	if creating.Reason == "" || len(creating.Reason) > maxRefundReason {
+		return 0, apperror.
+			New(apperror.InvalidBody).
+			Describe(fmt.Sprintf("Reason is required and must be at most %v characters", maxRefundReason))
+	}
+
+	amount := payment.Price - payment.RefundedAmount
+	for _, deduction := range creating.Deductions {
+		if deduction.Description == "" || len(deduction.Description) > maxDeductionLength {
+			return 0, apperror.
+				New(apperror.InvalidBody).
+				Describe(fmt.Sprintf("Every deduction needs a description of at most %v characters", maxDeductionLength))
+		}
+
+		if deduction.Amount <= 0 {
+			return 0, apperror.
+				New(apperror.InvalidBody).
+				Describe("Every deduction needs a positive amount")
+		}
+
+		amount -= deduction.Amount
+	}
+
+	if amount <= 0 {
+		return 0, apperror.
+			New(apperror.PaymentNotRefundable).
+			Describe("The deductions take up the whole deposit")
+	}
+
+	return amount, nil
+}
+
+// GetReceipt gets the receipt of a paid payment as a PDF, issuing it first if
+// it is missing. Only the payer and the owner can get it.
+func (s *serviceImpl) GetReceipt(receipt *models.Receipts, paymentId uuid.UUID, userId uuid.UUID) (io.ReadCloser, *apperror.AppError) {
+	payment := models.Payments{}
+	err := s.repo.GetPayment(&payment, paymentId)
+	if err != nil {
+		s.logger.Error("Could not get payment", zap.Error(err), zap.String("paymentId", paymentId.String()))
+		return nil, apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get receipt")
+	} else if payment.PaymentId == uuid.Nil {
+		return nil, apperror.
+			New(apperror.PaymentNotFound).
+			Describe("Could not find the specified payment")
+	}
+
+	agreement := models.AgreementDetails{}
+	apperr := s.agreements.GetAgreementById(&agreement, payment.AgreementId.String())
+	if apperr != nil {
+		return nil, apperr
+	}
+
+	if payment.UserId != userId && agreement.Owner.OwnerUserId != userId {
+		return nil, apperror.
+			New(apperror.PaymentNotFound).
+			Describe("Could not find the specified payment")
+	}
+
+	if !hasReceipt(payment.Status) {
+		return nil, apperror.
+			New(apperror.PaymentNotPaid).
+			Describe("Receipts are only issued for paid payments")
+	}
+
+	apperr = s.issueReceipt(receipt, &payment, &agreement)
+	if apperr != nil {
+		return nil, apperr
+	}
+
+	reader, err := s.storage.Download(*receipt.FileKey)
+	if err != nil {
+		s.logger.Error("Could not download receipt", zap.Error(err), zap.String("paymentId", paymentId.String()))
+		return nil, apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not get receipt")
+	}
+
+	return reader, nil
+}
+
+// IssueReceipts finishes issuing receipts that could not be stored or
+// emailed when their payment completed.
+func (s *serviceImpl) IssueReceipts() {
+	receipts := []models.Receipts{}
+	err := s.repo.GetUnsentReceipts(&receipts)
+	if err != nil {
+		s.logger.Error("Could not get unsent receipts", zap.Error(err))
+		return
+	}
+
+	for i := range receipts {
+		payment := models.Payments{}
+		err = s.repo.GetPayment(&payment, receipts[i].PaymentId)
+		if err != nil {
+			s.logger.Error("Could not get payment of receipt", zap.Error(err), zap.String("paymentId", receipts[i].PaymentId.String()))
+			continue
+		}
+
+		agreement := models.AgreementDetails{}
+		apperr := s.agreements.GetAgreementById(&agreement, payment.AgreementId.String())
+		if apperr != nil {
+			s.logger.Error("Could not get agreement of receipt", zap.Error(apperr), zap.String("paymentId", payment.PaymentId.String()))
+			continue
+		}
+
+		_ = s.issueReceipt(&receipts[i], &payment, &agreement)
+	}
+}
+
+// issueReceipt numbers the receipt of a paid payment, stores it as a PDF and
+// emails it to the payer. Each step is done once, so issuing a receipt again
+// only finishes what failed before. Failing to email it is only logged.
+func (s *serviceImpl) issueReceipt(receipt *models.Receipts, payment *models.Payments, agreement *models.AgreementDetails) *apperror.AppError {
+	receipt.PaymentId = payment.PaymentId
+	receipt.Amount = payment.Price
+	receipt.VatPercent = math.Max(s.cfg.VatPercent, 0)
+	receipt.VatAmount = payment.Price.Included(receipt.VatPercent)
+
+	err := s.repo.CreateReceipt(receipt)
+	if err != nil {
+		s.logger.Error("Could not create receipt", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not issue receipt")
+	}
+
+	if receipt.FileKey != nil && receipt.EmailedAt != nil {
+		return nil
+	}
+
+	var email string
+	err = s.repo.GetUserEmail(&email, payment.UserId)
+	if err != nil {
+		s.logger.Error("Could not get payer email", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not issue receipt")
+	}
+
+	var file bytes.Buffer
+	err = renderReceipt(&file, s.cfg.ReceiptFont, &receiptDetails{
+		Receipt:    receipt,
+		Payment:    payment,
+		Agreement:  agreement,
+		PayerEmail: email,
+	})
+	if err != nil {
+		s.logger.Error("Could not render receipt", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not issue receipt")
+	}
+
+	if receipt.FileKey == nil {
+		key := fmt.Sprintf("receipts/%v.pdf", payment.PaymentId)
+		_, err = s.storage.Upload(key, bytes.NewReader(file.Bytes()), types.ObjectCannedACLPrivate)
+		if err == nil {
+			err = s.repo.SetReceiptFile(payment.PaymentId, key)
+		}
+		if err != nil {
+			s.logger.Error("Could not store receipt", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+			return apperror.
+				New(apperror.InternalServerError).
+				Describe("Could not issue receipt")
+		}
+		receipt.FileKey = &key
+	}
+
+	if receipt.EmailedAt == nil && email != "" {
+		s.emailReceipt(receipt, payment, agreement, email, file.Bytes())
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) emailReceipt(receipt *models.Receipts, payment *models.Payments, agreement *models.AgreementDetails, email string, file []byte) {
+	claimed, err := s.repo.ClaimReceiptEmail(payment.PaymentId)
+	if err != nil {
+		s.logger.Error("Could not claim receipt email", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		return
+	} else if !claimed {
+		return
+	}
+
+	apperr := s.emails.SendReceiptEmail(email, &models.ReceiptEmails{
+		FirstName:     agreement.Dweller.DwellerFirstName,
+		ReceiptNumber: receipt.Number(),
+		PaymentName:   payment.Name,
+		PropertyName:  agreement.Property.PropertyName,
+		Amount:        receipt.Amount,
+		PaidAt:        paidAt(payment).Format("2 Jan 2006"),
+		Url:           s.cfg.FRONTEND_URL,
+	}, &models.EmailAttachments{
+		FileName:    receipt.Number() + ".pdf",
+		ContentType: "application/pdf",
+		Content:     file,
+	})
+	if apperr != nil {
+		s.logger.Error("Could not email receipt", zap.Error(apperr), zap.String("paymentId", payment.PaymentId.String()))
+		if err = s.repo.ReleaseReceiptEmail(payment.PaymentId); err != nil {
+			s.logger.Error("Could not release receipt email", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		}
+	}
+}
+
+// hasReceipt tells whether payments in status were paid, even if they have
+// since been refunded.
+func hasReceipt(status enums.PaymentStatus) bool {
+	switch status {
+	case enums.SucceededPayment, enums.PartiallyRefundedPayment, enums.RefundedPayment:
+		return true
+	default:
+		return false
+	}
+}
+
+// CompletePayment marks a payment as successful once the provider confirms it,
+// moves its agreement along and lets both sides know. Completing a payment
+// again is a no-op.
+func (s *serviceImpl) CompletePayment(payment *models.Payments, paymentId uuid.UUID) *apperror.AppError {
+	updated, apperr := s.updatePaymentStatus(payment, paymentId, enums.SucceededPayment)
+	if apperr != nil && apperr.Name() == apperror.AlreadyPaid.Name {
+		return s.refundDuplicatePayment(payment, paymentId)
+	} else if apperr != nil || !updated {
+		return apperr
+	}
+
+	// the payment is saved, so failures past this point are only logged
+	agreement := models.AgreementDetails{}
+	apperr = s.agreements.GetAgreementById(&agreement, payment.AgreementId.String())
+	if apperr != nil {
+		s.logger.Error("Could not get agreement of payment", zap.Error(apperr), zap.String("paymentId", payment.PaymentId.String()))
+		return nil
+	}
+	parties := []uuid.UUID{payment.UserId, agreement.Owner.OwnerUserId}
+
+	// payments the ledger misses are picked up by the payout worker
+	_ = s.ledger.RecordPayment(payment, agreement.Owner.OwnerUserId)
+
+	// receipts that are not stored or emailed are retried by the billing
+	// worker, or issued when first downloaded
+	_ = s.issueReceipt(&models.Receipts{}, payment, &agreement)
+
+	event := &models.PaymentEvents{
+		PaymentId:   payment.PaymentId,
+		AgreementId: payment.AgreementId,
+		UserId:      payment.UserId,
+		Name:        payment.Name,
+		Price:       payment.Price,
+		PaidAt:      time.Now(),
+	}
+	if payment.UpdatedAt != nil {
+		event.PaidAt = *payment.UpdatedAt
+	}
+	s.events.Publish(parties, event)
+
+	if next, ok := agreementProgress[agreement.AgreementType][agreement.Status]; ok && !s.hasOverdueRent(&agreement) {
+		apperr = s.agreements.UpdateAgreementStatus(&models.UpdatingAgreementStatus{Status: next}, agreement.AgreementId.String())
+		if apperr != nil {
+			s.logger.Error("Could not advance agreement", zap.Error(apperr), zap.String("agreementId", agreement.AgreementId.String()))
+		} else if next == enums.RentingAgreement {
+			// missed schedules are picked up by the billing worker
+			_ = s.createRentSchedule(agreement.AgreementId, agreement.PaymentPerMonth, agreement.PaymentDuration)
+		}
+	}
+
+	apperr = s.notifier.SettlePaymentRequests(agreement.AgreementId, parties)
+	if apperr != nil {
+		s.logger.Error("Could not settle payment requests", zap.Error(apperr), zap.String("agreementId", agreement.AgreementId.String()))
+	}
+
+	s.notifyChat(payment, &agreement, fmt.Sprintf("Paid %v: %v THB", payment.Name, payment.Price), payment.UserId, agreement.Owner.OwnerUserId)
+
+	return nil
+}
+
+// refundDuplicatePayment gives back the money of a payment that went through
+// for an installment another payment had already paid, which the database
+// does not let succeed twice. The payment is marked failed instead.
+func (s *serviceImpl) refundDuplicatePayment(payment *models.Payments, paymentId uuid.UUID) *apperror.AppError {
+	_, err := s.repo.UpdatePaymentStatus(payment, paymentId, enums.FailedPayment, []enums.PaymentStatus{enums.PendingPayment, enums.FailedPayment, enums.ExpiredPayment})
+	if err != nil {
+		s.logger.Error("Could not fail duplicate payment", zap.Error(err), zap.String("paymentId", paymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not update payment")
+	}
+
+	s.logger.Warn("Refunding payment of an installment that is already paid", zap.String("paymentId", paymentId.String()))
+	err = s.provider.Refund(&ProviderRefunds{}, payment, payment.Price)
+	if err != nil {
+		s.logger.Error("Could not refund duplicate payment, it has to be refunded by hand", zap.Error(err), zap.String("paymentId", paymentId.String()))
+	}
+
+	s.publishPaymentUpdate(payment, "")
+
+	return nil
+}
+
+// HandleWebhook verifies a webhook payload from the payment provider against
+// its signature and applies the event to the payment it belongs to. Events
+// that have already been handled, or that payments do not care about, are
+// ignored.
+func (s *serviceImpl) HandleWebhook(payload []byte, signature string) *apperror.AppError {
+	event := ProviderEvents{}
+	err := s.provider.VerifyWebhook(&event, payload, signature)
+	if errors.Is(err, ErrInvalidSignature) {
+		return apperror.
+			New(apperror.InvalidSignature).
+			Describe("Could not verify webhook signature")
+	} else if err != nil {
+		return apperror.
+			New(apperror.InvalidBody).
+			Describe("Could not parse webhook event")
+	}
+
+	if event.Status == "" && event.PaymentId == uuid.Nil {
+		return nil
+	}
+
+	var count int64
+	err = s.repo.CountWebhookEvents(&count, event.EventId)
+	if err != nil {
+		s.logger.Error("Could not check webhook event", zap.Error(err), zap.String("eventId", event.EventId))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not handle webhook event")
+	} else if count > 0 {
+		return nil
+	}
+
+	// providers keep retrying events that are not acknowledged, which would
+	// not help with a payment that does not exist here
+	apperr := s.applyProviderEvent(&event)
+	if apperr != nil && apperr.Name() == apperror.PaymentNotFound.Name {
+		s.logger.Warn("Ignoring event of unknown payment", zap.String("eventId", event.EventId))
+	} else if apperr != nil {
+		return apperr
+	}
+
+	// a redelivery that slips through is still a no-op, since every
+	// transition is guarded by the current status of the payment
+	err = s.repo.CreateWebhookEvent(&models.WebhookEvents{
+		EventId:     event.EventId,
+		EventType:   event.EventType,
+		ProcessedAt: time.Now(),
+	})
+	if err != nil {
+		s.logger.Error("Could not record webhook event", zap.Error(err), zap.String("eventId", event.EventId))
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) applyProviderEvent(event *ProviderEvents) *apperror.AppError {
+	if event.Status == enums.RefundedPayment || event.Status == enums.PartiallyRefundedPayment {
+		return s.refundPayment(event)
+	}
+
+	if event.PaymentId == uuid.Nil {
+		s.logger.Warn("Ignoring event without payment", zap.String("eventId", event.EventId))
+		return nil
+	}
+
+	apperr := s.setProviderReferences(event.PaymentId, event.SessionId, event.TransactionId)
+	if apperr != nil {
+		return apperr
+	}
+
+	payment := models.Payments{}
+	switch event.Status {
+	case "":
+		return nil
+	case enums.SucceededPayment:
+		return s.CompletePayment(&payment, event.PaymentId)
+	default:
+		return s.changePaymentStatus(&payment, event.PaymentId, event.Status)
+	}
+}
+
+func (s *serviceImpl) refundPayment(event *ProviderEvents) *apperror.AppError {
+	payment := models.Payments{}
+	err := s.repo.GetPaymentByProviderId(&payment, event.TransactionId)
+	if err != nil {
+		s.logger.Error("Could not get refunded payment", zap.Error(err), zap.String("transactionId", event.TransactionId))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not refund payment")
+	} else if payment.PaymentId == uuid.Nil {
+		return apperror.
+			New(apperror.PaymentNotFound).
+			Describe("Could not find the specified payment")
+	}
+
+	updated, err := s.repo.RefundPayment(&payment, payment.PaymentId, event.Status, event.RefundedAmount)
+	if err != nil {
+		s.logger.Error("Could not refund payment", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not refund payment")
+	}
+
+	if updated {
+		err = s.repo.SettleRefunds(payment.PaymentId)
+		if err != nil {
+			s.logger.Error("Could not settle refunds", zap.Error(err), zap.String("paymentId", payment.PaymentId.String()))
+		}
+
+		agreement := models.AgreementDetails{}
+		apperr := s.agreements.GetAgreementById(&agreement, payment.AgreementId.String())
+		if apperr != nil {
+			s.logger.Error("Could not get agreement of payment", zap.Error(apperr), zap.String("paymentId", payment.PaymentId.String()))
+		} else {
+			_ = s.ledger.RecordPayment(&payment, agreement.Owner.OwnerUserId)
+		}
+
+		s.publishPaymentUpdate(&payment, fmt.Sprintf("Refunded %v THB of %v", event.RefundedAmount, payment.Name))
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) setProviderReferences(paymentId uuid.UUID, sessionId string, providerPaymentId string) *apperror.AppError {
+	err := s.repo.SetProviderReferences(paymentId, sessionId, providerPaymentId)
+	if err != nil {
+		s.logger.Error("Could not save provider references", zap.Error(err), zap.String("paymentId", paymentId.String()))
+		return apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not update payment")
+	}
+
+	return nil
+}
+
+// updatePaymentStatus moves a payment to status and reports whether it
+// changed.
+func (s *serviceImpl) updatePaymentStatus(payment *models.Payments, paymentId uuid.UUID, status enums.PaymentStatus) (bool, *apperror.AppError) {
+	updated, err := s.repo.UpdatePaymentStatus(payment, paymentId, status, paymentTransitions[status])
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return false, apperror.
+			New(apperror.AlreadyPaid).
+			Describe("This installment has already been paid")
+	} else if err != nil {
+		s.logger.Error("Could not update payment status", zap.Error(err), zap.String("paymentId", paymentId.String()))
+		return false, apperror.
+			New(apperror.InternalServerError).
+			Describe("Could not update payment")
+	}
+
+	if payment.PaymentId == uuid.Nil {
+		return false, apperror.
+			New(apperror.PaymentNotFound).
+			Describe("Could not find the specified payment")
+	}
+
+	return updated, nil
+}
+
+// changePaymentStatus moves a payment to a status other than succeeded and
+// lets both sides know.
+func (s *serviceImpl) changePaymentStatus(payment *models.Payments, paymentId uuid.UUID, status enums.PaymentStatus) *apperror.AppError {
+	updated, apperr := s.updatePaymentStatus(payment, paymentId, status)
+	if apperr != nil || !updated {
+		return apperr
+	}
+
+	s.publishPaymentUpdate(payment, "")
+
+	return nil
+}
+
+// publishPaymentUpdate pushes PAYMENT_UPDATED to the payer and the owner. A
+// non-empty message is also posted from the owner into their chat. The change
+// is already saved, so failures are only logged.
+func (s *serviceImpl) publishPaymentUpdate(payment *models.Payments, message string) {
+	agreement := models.AgreementDetails{}
+	apperr := s.agreements.GetAgreementById(&agreement, payment.AgreementId.String())
+	if apperr != nil {
+		s.logger.Error("Could not get agreement of payment", zap.Error(apperr), zap.String("paymentId", payment.PaymentId.String()))
+		return
+	}
+
+	event := &models.PaymentUpdateEvents{
+		PaymentId:      payment.PaymentId,
+		AgreementId:    payment.AgreementId,
+		UserId:         payment.UserId,
+		Name:           payment.Name,
+		Price:          payment.Price,
+		Status:         payment.Status,
+		RefundedAmount: payment.RefundedAmount,
+		UpdatedAt:      time.Now(),
+	}
+	if payment.UpdatedAt != nil {
+		event.UpdatedAt = *payment.UpdatedAt
+	}
+	s.events.Publish([]uuid.UUID{payment.UserId, agreement.Owner.OwnerUserId}, event)
+
+	if message != "" {
+		s.notifyChat(payment, &agreement, message, agreement.Owner.OwnerUserId, payment.UserId)
+	}
+}
+
+func (s *serviceImpl) notifyChat(payment *models.Payments, agreement *models.AgreementDetails, message string, senderId uuid.UUID, receiverId uuid.UUID) {
+	apperr := s.notifier.SendNotificationMessage(&models.PaymentNotifications{
+		PaymentId:   payment.PaymentId,
+		AgreementId: agreement.AgreementId,
+		PropertyId:  agreement.Property.PropertyId,
+	}, message, senderId, receiverId)
+	if apperr != nil {
+		s.logger.Error("Could not send payment message", zap.Error(apperr), zap.String("paymentId", payment.PaymentId.String()))
+	}
+}
@@ -0,0 +1,614 @@
+package payments
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/emails"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v76/webhook"
+	"go.uber.org/zap"
+)
+
+const testWebhookSecret = "whsec_test"
+
+// memRepo keeps payments in memory. Methods the tests do not reach are left
+// to the embedded interface and panic.
+type memRepo struct {
+	Repository
+
+	mu           sync.Mutex
+	payments     map[uuid.UUID]models.Payments
+	events       map[string]bool
+	installments []models.RentInstallments
+}
+
+func newMemRepo() *memRepo {
+	return &memRepo{
+		payments: map[uuid.UUID]models.Payments{},
+		events:   map[string]bool{},
+	}
+}
+
+func (r *memRepo) CreatePayment(payment *models.Payments) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if payment.PaymentId == uuid.Nil {
+		payment.PaymentId = uuid.New()
+	}
+	if payment.Status == "" {
+		payment.Status = enums.PendingPayment
+	}
+	now := time.Now()
+	payment.CreatedAt = &now
+	payment.UpdatedAt = &now
+	r.payments[payment.PaymentId] = *payment
+
+	return nil
+}
+
+func (r *memRepo) GetPayment(payment *models.Payments, paymentId uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	*payment = r.payments[paymentId]
+
+	return nil
+}
+
+func (r *memRepo) GetPaymentByProviderId(payment *models.Payments, providerPaymentId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range r.payments {
+		if p.ProviderPaymentId != nil && *p.ProviderPaymentId == providerPaymentId {
+			*payment = p
+		}
+	}
+
+	return nil
+}
+
+func (r *memRepo) GetPaymentByIdempotencyKey(*models.Payments, uuid.UUID, string) error {
+	return nil
+}
+
+func (r *memRepo) GetOpenPayment(*models.Payments, uuid.UUID, enums.PaymentTypes, *int) error {
+	return nil
+}
+
+func (r *memRepo) SetCheckout(paymentId uuid.UUID, sessionId string, url string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment := r.payments[paymentId]
+	payment.ProviderSessionId = &sessionId
+	payment.CheckoutURL = &url
+	payment.CheckoutExpiresAt = &expiresAt
+	r.payments[paymentId] = payment
+
+	return nil
+}
+
+func (r *memRepo) SetProviderReferences(paymentId uuid.UUID, sessionId string, providerPaymentId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment, ok := r.payments[paymentId]
+	if !ok {
+		return nil
+	}
+	if sessionId != "" {
+		payment.ProviderSessionId = &sessionId
+	}
+	if providerPaymentId != "" {
+		payment.ProviderPaymentId = &providerPaymentId
+	}
+	r.payments[paymentId] = payment
+
+	return nil
+}
+
+func (r *memRepo) UpdatePaymentStatus(payment *models.Payments, paymentId uuid.UUID, status enums.PaymentStatus, from []enums.PaymentStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.payments[paymentId]
+	updated := ok && slices.Contains(from, current.Status)
+	if updated {
+		now := time.Now()
+		current.Status = status
+		current.IsSuccess = status == enums.SucceededPayment
+		current.UpdatedAt = &now
+		r.payments[paymentId] = current
+	}
+	*payment = r.payments[paymentId]
+
+	return updated, nil
+}
+
+func (r *memRepo) RefundPayment(payment *models.Payments, paymentId uuid.UUID, status enums.PaymentStatus, refunded money.Amount) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.payments[paymentId]
+	updated := ok && slices.Contains(paidStatus, current.Status) && current.RefundedAmount < refunded
+	if updated {
+		now := time.Now()
+		current.Status = status
+		current.RefundedAmount = refunded
+		current.UpdatedAt = &now
+		r.payments[paymentId] = current
+	}
+	*payment = r.payments[paymentId]
+
+	return updated, nil
+}
+
+func (r *memRepo) SettleRefunds(uuid.UUID) error {
+	return nil
+}
+
+// CreateReceipt hands back a receipt that is already stored and emailed, so
+// that completing a payment does not reach for storage or email.
+func (r *memRepo) CreateReceipt(receipt *models.Receipts) error {
+	key := "receipts/" + receipt.PaymentId.String() + ".pdf"
+	now := time.Now()
+	receipt.FileKey = &key
+	receipt.EmailedAt = &now
+
+	return nil
+}
+
+func (r *memRepo) CreateRentInstallments(installments *[]models.RentInstallments) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.installments = append(r.installments, *installments...)
+
+	return nil
+}
+
+func (r *memRepo) CountOverdueInstallments(count *int64, _ uuid.UUID, _ time.Time) error {
+	*count = 0
+	return nil
+}
+
+func (r *memRepo) CountWebhookEvents(count *int64, eventId string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	*count = 0
+	if r.events[eventId] {
+		*count = 1
+	}
+
+	return nil
+}
+
+func (r *memRepo) CreateWebhookEvent(event *models.WebhookEvents) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events[event.EventId] = true
+
+	return nil
+}
+
+func (r *memRepo) payment(paymentId uuid.UUID) models.Payments {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.payments[paymentId]
+}
+
+// memAgreements holds the one agreement every payment of a test belongs to.
+type memAgreements struct {
+	mu        sync.Mutex
+	agreement models.AgreementDetails
+}
+
+func (a *memAgreements) GetAgreementById(agreement *models.AgreementDetails, _ string) *apperror.AppError {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	*agreement = a.agreement
+
+	return nil
+}
+
+func (a *memAgreements) UpdateAgreementStatus(status *models.UpdatingAgreementStatus, _ string) *apperror.AppError {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.agreement.Status = status.Status
+
+	return nil
+}
+
+func (a *memAgreements) status() enums.AgreementStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.agreement.Status
+}
+
+type nopPublisher struct{}
+
+func (nopPublisher) Publish([]uuid.UUID, models.OutBoundPayload) {}
+
+type nopNotifier struct{}
+
+func (nopNotifier) SendNotificationMessage(interface{}, string, uuid.UUID, uuid.UUID) *apperror.AppError {
+	return nil
+}
+
+func (nopNotifier) SettlePaymentRequests(uuid.UUID, []uuid.UUID) *apperror.AppError {
+	return nil
+}
+
+// countingLedger counts the payments booked, so that a payment applied twice
+// shows up.
+type countingLedger struct {
+	mu       sync.Mutex
+	recorded int
+}
+
+func (l *countingLedger) RecordPayment(*models.Payments, uuid.UUID) *apperror.AppError {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.recorded++
+
+	return nil
+}
+
+func (l *countingLedger) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.recorded
+}
+
+type paymentsTest struct {
+	service    Service
+	repo       *memRepo
+	agreements *memAgreements
+	ledger     *countingLedger
+}
+
+func newPaymentsTest(cfg *config.Config, provider PaymentProvider, agreement models.AgreementDetails) *paymentsTest {
+	test := &paymentsTest{
+		repo:       newMemRepo(),
+		agreements: &memAgreements{agreement: agreement},
+		ledger:     &countingLedger{},
+	}
+	test.service = NewService(zap.NewNop(), test.repo, cfg, nopPublisher{}, test.agreements, nopNotifier{}, provider, nil, test.ledger, nil)
+
+	return test
+}
+
+// newWebhookTest sets up a service verifying webhooks with the Stripe
+// provider and a pending payment towards agreement.
+func newWebhookTest(t *testing.T, agreement models.AgreementDetails, paymentType enums.PaymentTypes) (*paymentsTest, uuid.UUID) {
+	t.Helper()
+
+	cfg := &config.Config{StripeWebhookSecret: testWebhookSecret}
+	test := newPaymentsTest(cfg, NewStripeProvider(cfg), agreement)
+
+	payment := models.Payments{
+		UserId:        agreement.Dweller.DwellerUserId,
+		AgreementId:   agreement.AgreementId,
+		Name:          "Test payment",
+		Price:         1200000,
+		PaymentMethod: enums.CREDIT_CARD,
+		PaymentType:   paymentType,
+		Currency:      money.DefaultCurrency,
+	}
+	if paymentType == enums.InstallmentPayment {
+		installment := 1
+		payment.Installment = &installment
+	}
+	if err := test.repo.CreatePayment(&payment); err != nil {
+		t.Fatal(err)
+	}
+
+	return test, payment.PaymentId
+}
+
+func newTestAgreement(agreementType enums.AgreementTypes, status enums.AgreementStatus) models.AgreementDetails {
+	agreement := models.AgreementDetails{
+		AgreementId:     uuid.New(),
+		AgreementType:   agreementType,
+		Status:          status,
+		DepositAmount:   1200000,
+		PaymentPerMonth: 1200000,
+		PaymentDuration: 12,
+		TotalPayment:    14400000,
+	}
+	agreement.Owner.OwnerUserId = uuid.New()
+	agreement.Dweller.DwellerUserId = uuid.New()
+
+	return agreement
+}
+
+// signFixture fills in a webhook fixture and signs it with secret the way
+// Stripe does.
+func signFixture(t *testing.T, name string, secret string, eventId string, paymentId uuid.UUID) ([]byte, string) {
+	t.Helper()
+
+	template, err := os.ReadFile(filepath.Join("fixtures", name+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	payload := strings.NewReplacer(
+		"{{event_id}}", eventId,
+		"{{payment_id}}", paymentId.String(),
+		"{{created}}", strconv.FormatInt(now.Unix(), 10),
+	).Replace(string(template))
+
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload:   []byte(payload),
+		Secret:    secret,
+		Timestamp: now,
+	})
+
+	return signed.Payload, signed.Header
+}
+
+func (test *paymentsTest) deliver(t *testing.T, name string, eventId string, paymentId uuid.UUID) {
+	t.Helper()
+
+	payload, signature := signFixture(t, name, testWebhookSecret, eventId, paymentId)
+	if apperr := test.service.HandleWebhook(payload, signature); apperr != nil {
+		t.Fatalf("%v: %v", name, apperr)
+	}
+}
+
+func (test *paymentsTest) expectPayment(t *testing.T, paymentId uuid.UUID, status enums.PaymentStatus) models.Payments {
+	t.Helper()
+
+	payment := test.repo.payment(paymentId)
+	if payment.Status != status {
+		t.Fatalf("payment is %v, expected %v", payment.Status, status)
+	}
+
+	return payment
+}
+
+func (test *paymentsTest) expectAgreement(t *testing.T, status enums.AgreementStatus) {
+	t.Helper()
+
+	if got := test.agreements.status(); got != status {
+		t.Fatalf("agreement is %v, expected %v", got, status)
+	}
+}
+
+func TestHandleWebhookRejectsBadSignatures(t *testing.T) {
+	test, paymentId := newWebhookTest(t, newTestAgreement(enums.AgreementForRent, enums.AwaitingDepositAgreement), enums.DepositPayment)
+
+	payload, signature := signFixture(t, "checkout.session.completed", "whsec_other", "evt_forged", paymentId)
+	apperr := test.service.HandleWebhook(payload, signature)
+	if apperr == nil || apperr.Name() != apperror.InvalidSignature.Name {
+		t.Fatalf("expected %v, got %v", apperror.InvalidSignature.Name, apperr)
+	}
+
+	payload, signature = signFixture(t, "checkout.session.completed", testWebhookSecret, "evt_tampered", paymentId)
+	payload = []byte(strings.Replace(string(payload), `"paid"`, `"unpaid"`, 1))
+	apperr = test.service.HandleWebhook(payload, signature)
+	if apperr == nil || apperr.Name() != apperror.InvalidSignature.Name {
+		t.Fatalf("expected %v, got %v", apperror.InvalidSignature.Name, apperr)
+	}
+
+	test.expectPayment(t, paymentId, enums.PendingPayment)
+	test.expectAgreement(t, enums.AwaitingDepositAgreement)
+	if len(test.repo.events) != 0 {
+		t.Fatalf("rejected events were recorded: %v", test.repo.events)
+	}
+}
+
+func TestHandleWebhookPaysDeposit(t *testing.T) {
+	test, paymentId := newWebhookTest(t, newTestAgreement(enums.AgreementForRent, enums.AwaitingDepositAgreement), enums.DepositPayment)
+
+	test.deliver(t, "checkout.session.completed", "evt_deposit", paymentId)
+
+	payment := test.expectPayment(t, paymentId, enums.SucceededPayment)
+	if payment.ProviderPaymentId == nil || *payment.ProviderPaymentId != "pi_test_"+paymentId.String() {
+		t.Fatalf("provider payment id was not saved: %v", payment.ProviderPaymentId)
+	}
+	test.expectAgreement(t, enums.AwaitingPaymentAgreement)
+	if len(test.repo.installments) != 0 {
+		t.Fatalf("deposit created %v installments", len(test.repo.installments))
+	}
+}
+
+func TestHandleWebhookIgnoresReplayedEvents(t *testing.T) {
+	test, paymentId := newWebhookTest(t, newTestAgreement(enums.AgreementForRent, enums.AwaitingDepositAgreement), enums.DepositPayment)
+
+	test.deliver(t, "checkout.session.completed", "evt_replayed", paymentId)
+	test.deliver(t, "checkout.session.completed", "evt_replayed", paymentId)
+
+	if test.ledger.count() != 1 {
+		t.Fatalf("replayed event was booked %v times", test.ledger.count())
+	}
+
+	// the same outcome sent again under a new event id only finds a payment
+	// that has already moved on
+	test.deliver(t, "checkout.session.async_payment_succeeded", "evt_redelivered", paymentId)
+
+	if test.ledger.count() != 1 {
+		t.Fatalf("redelivered payment was booked %v times", test.ledger.count())
+	}
+	test.expectPayment(t, paymentId, enums.SucceededPayment)
+	test.expectAgreement(t, enums.AwaitingPaymentAgreement)
+}
+
+func TestHandleWebhookStartsRenting(t *testing.T) {
+	agreement := newTestAgreement(enums.AgreementForRent, enums.AwaitingPaymentAgreement)
+	test, paymentId := newWebhookTest(t, agreement, enums.InstallmentPayment)
+
+	test.deliver(t, "checkout.session.completed", "evt_first_rent", paymentId)
+
+	test.expectPayment(t, paymentId, enums.SucceededPayment)
+	test.expectAgreement(t, enums.RentingAgreement)
+	if len(test.repo.installments) != agreement.PaymentDuration {
+		t.Fatalf("created %v installments, expected %v", len(test.repo.installments), agreement.PaymentDuration)
+	}
+}
+
+func TestHandleWebhookArchivesSales(t *testing.T) {
+	test, paymentId := newWebhookTest(t, newTestAgreement(enums.AgreementForSell, enums.AwaitingPaymentAgreement), enums.BalancePayment)
+
+	test.deliver(t, "checkout.session.completed", "evt_balance", paymentId)
+
+	test.expectPayment(t, paymentId, enums.SucceededPayment)
+	test.expectAgreement(t, enums.ArchivedAgreement)
+	if len(test.repo.installments) != 0 {
+		t.Fatalf("sale created %v installments", len(test.repo.installments))
+	}
+}
+
+func TestHandleWebhookWaitsForDelayedPayments(t *testing.T) {
+	test, paymentId := newWebhookTest(t, newTestAgreement(enums.AgreementForRent, enums.AwaitingDepositAgreement), enums.DepositPayment)
+
+	test.deliver(t, "checkout.session.completed.unpaid", "evt_unpaid", paymentId)
+
+	test.expectPayment(t, paymentId, enums.PendingPayment)
+	test.expectAgreement(t, enums.AwaitingDepositAgreement)
+
+	test.deliver(t, "checkout.session.async_payment_succeeded", "evt_async_paid", paymentId)
+
+	test.expectPayment(t, paymentId, enums.SucceededPayment)
+	test.expectAgreement(t, enums.AwaitingPaymentAgreement)
+}
+
+func TestHandleWebhookClosesUnpaidPayments(t *testing.T) {
+	tests := []struct {
+		fixture string
+		status  enums.PaymentStatus
+	}{
+		{"checkout.session.async_payment_failed", enums.FailedPayment},
+		{"payment_intent.payment_failed", enums.FailedPayment},
+		{"checkout.session.expired", enums.ExpiredPayment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			test, paymentId := newWebhookTest(t, newTestAgreement(enums.AgreementForRent, enums.AwaitingDepositAgreement), enums.DepositPayment)
+
+			test.deliver(t, tt.fixture, "evt_closed", paymentId)
+
+			test.expectPayment(t, paymentId, tt.status)
+			test.expectAgreement(t, enums.AwaitingDepositAgreement)
+			if test.ledger.count() != 0 {
+				t.Fatalf("unpaid payment was booked %v times", test.ledger.count())
+			}
+		})
+	}
+}
+
+func TestHandleWebhookRefundsPayments(t *testing.T) {
+	test, paymentId := newWebhookTest(t, newTestAgreement(enums.AgreementForRent, enums.AwaitingDepositAgreement), enums.DepositPayment)
+
+	test.deliver(t, "checkout.session.completed", "evt_paid", paymentId)
+	test.deliver(t, "charge.refunded.partial", "evt_partial_refund", paymentId)
+
+	payment := test.expectPayment(t, paymentId, enums.PartiallyRefundedPayment)
+	if payment.RefundedAmount != 500000 {
+		t.Fatalf("refunded %v, expected 500000", payment.RefundedAmount)
+	}
+
+	test.deliver(t, "charge.refunded", "evt_full_refund", paymentId)
+
+	// a partial refund arriving late carries a smaller total and is stale
+	test.deliver(t, "charge.refunded.partial", "evt_late_refund", paymentId)
+
+	payment = test.expectPayment(t, paymentId, enums.RefundedPayment)
+	if payment.RefundedAmount != payment.Price {
+		t.Fatalf("refunded %v, expected %v", payment.RefundedAmount, payment.Price)
+	}
+}
+
+// reminderRepo holds a single late installment.
+type reminderRepo struct {
+	Repository
+
+	installment models.LateInstallments
+}
+
+func (r *reminderRepo) GetLateInstallments(installments *[]models.LateInstallments, _ time.Time) error {
+	*installments = []models.LateInstallments{r.installment}
+	return nil
+}
+
+func (r *reminderRepo) EscalateInstallment(_ uuid.UUID, _ int, level int, _ money.Amount) (bool, error) {
+	if r.installment.ReminderLevel >= level {
+		return false, nil
+	}
+	r.installment.ReminderLevel = level
+	return true, nil
+}
+
+func (r *reminderRepo) ReleaseInstallmentReminder(_ uuid.UUID, _ int, level int, previous int) error {
+	if r.installment.ReminderLevel == level {
+		r.installment.ReminderLevel = previous
+	}
+	return nil
+}
+
+// flakyEmails fails the first failures reminders it is asked to send.
+type flakyEmails struct {
+	emails.Service
+
+	failures int
+	sent     int
+}
+
+func (e *flakyEmails) SendRentReminderEmail(string, *models.RentReminderEmails) *apperror.AppError {
+	if e.failures > 0 {
+		e.failures--
+		return apperror.New(apperror.InternalServerError)
+	}
+	e.sent++
+	return nil
+}
+
+func (e *flakyEmails) SendOverdueSummaryEmail(string, *models.OverdueSummaryEmails) *apperror.AppError {
+	return nil
+}
+
+func TestCollectOverdueRentRetriesFailedReminders(t *testing.T) {
+	repo := &reminderRepo{}
+	repo.installment.AgreementId = uuid.New()
+	repo.installment.Installment = 2
+	repo.installment.Amount = 1200000
+	repo.installment.DueDate = startOfDay(time.Now()).AddDate(0, 0, -2)
+	repo.installment.AgreementStatus = enums.RentingAgreement
+
+	mail := &flakyEmails{failures: 1}
+	service := NewService(zap.NewNop(), repo, &config.Config{}, nopPublisher{}, &memAgreements{}, nopNotifier{}, nil, mail, nil, nil)
+
+	service.CollectOverdueRent()
+	if repo.installment.ReminderLevel != 0 || mail.sent != 0 {
+		t.Fatalf("failed reminder left level %v with %v sent", repo.installment.ReminderLevel, mail.sent)
+	}
+
+	service.CollectOverdueRent()
+	if repo.installment.ReminderLevel != lateReminder || mail.sent != 1 {
+		t.Fatalf("retried reminder left level %v with %v sent", repo.installment.ReminderLevel, mail.sent)
+	}
+}
@@ -1,6 +1,9 @@
 package payments
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/brain-flowing-company/pprp-backend/apperror"
 	"github.com/brain-flowing-company/pprp-backend/config"
 	"github.com/brain-flowing-company/pprp-backend/internal/enums"
@@ -14,6 +17,11 @@ type Handler interface {
 	CreatePayment(c *fiber.Ctx) error
 	GetPaymentByUserId(c *fiber.Ctx) error
 	GetHistoryPaymentByUserId(c *fiber.Ctx) error
+	GetPaymentById(c *fiber.Ctx) error
+	GetReceipt(c *fiber.Ctx) error
+	HandleWebhook(c *fiber.Ctx) error
+	GetRentSchedule(c *fiber.Ctx) error
+	CreateRefund(c *fiber.Ctx) error
 }
 
 type handlerImpl struct {
@@ -28,82 +36,78 @@ func NewHandler(cfg *config.Config, service Service) Handler {
 	}
 }
 
-// @router /api/v1/checkout [get]
+// @router /api/v1/checkout [post]
 // @summary     Create payment
-// @description Create payment
+// @description Create a payment for the next amount due on an agreement. The amount is the deposit while the agreement awaits it, then one monthly installment for renting or the remaining balance for selling. Renting agreements pay the earliest invoiced installment, or the one given by **installment**. Only the dweller can pay.
+// @description
+// @description Checkout is safe to retry. A request with an **Idempotency-Key** that was used before returns the payment it created, and while a checkout for the same deposit, balance or installment is still open its URL is returned instead of a new one.
 // @tags        payments
+// @accept      json
 // @produce     json
-// @param       agreement_id query string true "Agreement ID"
-// @param       name query string true "Name"
-// @param       price query string true "Price"
-// @param       payment_method query string true "Payment Method"
+// @param       Idempotency-Key header string false "Unique key of this checkout attempt, at most 255 characters"
+// @param       body body models.CreatingPayments true "Agreement, payment method and installment"
 // @success     200	{object}	models.Payments
 // @failure     400 {object}	models.ErrorResponses
 // @failure     401 {object}	models.ErrorResponses
+// @failure     403 {object}	models.ErrorResponses
+// @failure     404 {object}	models.ErrorResponses
+// @failure     409 {object}	models.ErrorResponses
 // @failure     500 {object}	models.ErrorResponses
+// @failure     503 {object}	models.ErrorResponses
 func (h *handlerImpl) CreatePayment(c *fiber.Ctx) error {
 	session, ok := c.Locals("session").(models.Sessions)
 	if !ok {
 		return utils.ResponseError(c, apperror.New(apperror.Unauthorized).Describe("Unauthorized"))
 	}
+
+	creating := models.CreatingPayments{}
+	err := c.BodyParser(&creating)
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe(fmt.Sprintf("Could not parse body: %v", err.Error())))
+	}
+
 	payment := models.Payments{
-		PaymentId: uuid.New(),
-		UserId:    session.UserId,
-		IsSuccess: false,
+		PaymentId:     uuid.New(),
+		UserId:        session.UserId,
+		AgreementId:   creating.AgreementId,
+		PaymentMethod: creating.PaymentMethod,
+		Installment:   creating.Installment,
+		IsSuccess:     false,
 	}
-	err := c.QueryParser(&payment)
-	if err != nil {
-		return utils.ResponseError(c, apperror.New(apperror.BadRequest).Describe("Failed to parse query"))
-	}
-	// Parse agreement_id
-	agreementID := c.Query("agreement_id")
-	if agreementID != "" {
-		parsedAgreementID, err := uuid.Parse(agreementID)
-		if err != nil {
-			return utils.ResponseError(c, apperror.New(apperror.BadRequest).Describe("Invalid agreement_id format"))
-		}
-		payment.AgreementId = parsedAgreementID
-	}
-	// Parse payment_method
-	paymentMethodStr := c.Query("payment_method")
-	if paymentMethodStr != "" {
-		// Use the enums package to parse the payment method
-		paymentMethod := enums.PaymentMethods(paymentMethodStr)
-		switch paymentMethod {
-		case enums.CREDIT_CARD:
-			payment.PaymentMethod = enums.CREDIT_CARD
-		case enums.PROMPTPAY:
-			payment.PaymentMethod = enums.PROMPTPAY
-		default:
-			return utils.ResponseError(c, apperror.New(apperror.BadRequest).Describe("Invalid payment_method"))
+
+	if key := c.Get("Idempotency-Key"); key != "" {
+		if len(key) > maxIdempotencyKey {
+			return utils.ResponseError(c, apperror.
+				New(apperror.BadRequest).
+				Describe(fmt.Sprintf("Idempotency-Key must be at most %v characters", maxIdempotencyKey)))
 		}
+		payment.IdempotencyKey = &key
 	}
+
 	// Check if the required fields are empty
-	if payment.Price <= 0 {
-		return utils.ResponseError(c, apperror.New(apperror.InvalidBody).Describe("Price is required and must be greater than 0"))
-	}
-	if payment.Name == "" {
-		return utils.ResponseError(c, apperror.New(apperror.InvalidBody).Describe("Name is required"))
-	}
 	if payment.AgreementId == uuid.Nil {
 		return utils.ResponseError(c, apperror.New(apperror.InvalidBody).Describe("Agreement id is required"))
 	}
-	if payment.PaymentMethod == "" {
+	switch payment.PaymentMethod {
+	case enums.CREDIT_CARD, enums.PROMPTPAY:
+	case "":
 		return utils.ResponseError(c, apperror.New(apperror.InvalidBody).Describe("Payment method is required"))
+	default:
+		return utils.ResponseError(c, apperror.New(apperror.BadRequest).Describe("Invalid payment_method"))
 	}
 
-	if err := h.service.CreatePayment(&payment); err != nil {
+	checkout := ProviderCheckouts{}
+	if err := h.service.CreatePayment(&checkout, &payment); err != nil {
 		return utils.ResponseError(c, err)
 	}
-	surl, err2 := CheckoutV2(c, payment.Name, payment.Price, string(payment.PaymentMethod), h.cfg)
-	if err2 != nil {
-		return utils.ResponseError(c, apperror.New(apperror.InternalServerError).Describe("Failed to create payment"))
-	}
 	return c.JSON(fiber.Map{
 		"payment_id": payment.PaymentId,
 		"success":    true,
 		"message":    "Payment created successfully",
-		"url":        surl,
+		"url":        checkout.URL,
+		"expires_at": checkout.ExpiresAt,
 	})
 
 }
@@ -156,3 +160,163 @@ func (h *handlerImpl) GetHistoryPaymentByUserId(c *fiber.Ctx) error {
 	}
 	return nil
 }
+
+// @router /api/v1/payments/{paymentId} [get]
+// @summary     Get payment by id
+// @description Get a payment of the current user, as its payer or the owner of its agreement. Payments still waiting for the provider are refreshed from it first.
+// @tags        payments
+// @produce     json
+// @param       paymentId path string true "Payment ID"
+// @success     200	{object}	models.Payments
+// @failure     400 {object}	models.ErrorResponses
+// @failure     401 {object}	models.ErrorResponses
+// @failure     404 {object}	models.ErrorResponses
+// @failure     500 {object}	models.ErrorResponses
+func (h *handlerImpl) GetPaymentById(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		return utils.ResponseError(c, apperror.New(apperror.Unauthorized).Describe("Unauthorized"))
+	}
+
+	paymentId, err := uuid.Parse(c.Params("paymentId"))
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid payment id"))
+	}
+
+	payment := models.Payments{}
+	apperr := h.service.GetPaymentById(&payment, paymentId, session.UserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.JSON(payment)
+}
+
+// @router /api/v1/payments/{paymentId}/receipt [get]
+// @summary     Get payment receipt
+// @description Download the numbered receipt of a paid payment as a PDF, a tax invoice when VAT_PERCENT is set. It shows the payer, the owner, the property address, the agreement reference, the amount with the VAT it includes and the payment method. Receipts are issued and emailed to the payer when the payment succeeds. Only the payer and the owner can get it.
+// @tags        payments
+// @produce     application/pdf
+// @param       paymentId path string true "Payment ID"
+// @success     200
+// @failure     400 {object}	models.ErrorResponses
+// @failure     401 {object}	models.ErrorResponses
+// @failure     404 {object}	models.ErrorResponses
+// @failure     500 {object}	models.ErrorResponses
+func (h *handlerImpl) GetReceipt(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		return utils.ResponseError(c, apperror.New(apperror.Unauthorized).Describe("Unauthorized"))
+	}
+
+	paymentId, err := uuid.Parse(c.Params("paymentId"))
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid payment id"))
+	}
+
+	receipt := models.Receipts{}
+	reader, apperr := h.service.GetReceipt(&receipt, paymentId, session.UserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", receipt.Number()+".pdf"))
+	c.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+
+	return c.SendStream(reader)
+}
+
+// @router /api/v1/payments/webhook [post]
+// @summary     Receive payment provider webhook events
+// @description Receives events from the payment provider, signed with STRIPE_WEBHOOK_SECRET for Stripe. Checkout completion, expiry, failed payments and refunds update the payment and notify both sides of its agreement. Each event is applied at most once.
+// @tags        payments
+// @accept      json
+// @produce     json
+// @param       Stripe-Signature header string true "Stripe signature"
+// @success     200	{object}	models.MessageResponses
+// @failure     400 {object}	models.ErrorResponses
+// @failure     500 {object}	models.ErrorResponses
+func (h *handlerImpl) HandleWebhook(c *fiber.Ctx) error {
+	err := h.service.HandleWebhook(c.Body(), c.Get("Stripe-Signature"))
+	if err != nil {
+		return utils.ResponseError(c, err)
+	}
+
+	return utils.ResponseMessage(c, http.StatusOK, "Event received")
+}
+
+// @router /api/v1/agreements/{agreementId}/schedule [get]
+// @summary     Get rent schedule
+// @description Get every monthly installment of a renting agreement with its due date, amount and whether it is paid, unpaid or overdue. Installments are payable once invoiced, which happens INVOICE_LEAD_DAYS before they are due. Only the owner and the dweller can see it, and it is empty until the agreement starts renting.
+// @tags        payments
+// @produce     json
+// @param       agreementId path string true "Agreement ID"
+// @success     200	{object}	models.RentSchedules
+// @failure     400 {object}	models.ErrorResponses
+// @failure     401 {object}	models.ErrorResponses
+// @failure     404 {object}	models.ErrorResponses
+// @failure     500 {object}	models.ErrorResponses
+func (h *handlerImpl) GetRentSchedule(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		return utils.ResponseError(c, apperror.New(apperror.Unauthorized).Describe("Unauthorized"))
+	}
+
+	schedule := models.RentSchedules{}
+	apperr := h.service.GetRentSchedule(&schedule, c.Params("agreementId"), session.UserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.JSON(schedule)
+}
+
+// @router /api/v1/payments/{paymentId}/refunds [post]
+// @summary     Refund a deposit
+// @description Pay a deposit back to the dweller once its agreement is cancelled or completed. The whole deposit is refunded, less any **deductions**, which are listed to the dweller in chat along with the **reason**. Only the owner can refund, once per deposit. The refund is pending until the payment provider confirms it.
+// @tags        payments
+// @accept      json
+// @produce     json
+// @param       paymentId path string true "Payment ID"
+// @param       body body models.CreatingRefunds true "Reason and deductions"
+// @success     201	{object}	models.Refunds
+// @failure     400 {object}	models.ErrorResponses
+// @failure     401 {object}	models.ErrorResponses
+// @failure     403 {object}	models.ErrorResponses
+// @failure     404 {object}	models.ErrorResponses
+// @failure     500 {object}	models.ErrorResponses
+// @failure     503 {object}	models.ErrorResponses
+func (h *handlerImpl) CreateRefund(c *fiber.Ctx) error {
+	session, ok := c.Locals("session").(models.Sessions)
+	if !ok {
+		return utils.ResponseError(c, apperror.New(apperror.Unauthorized).Describe("Unauthorized"))
+	}
+
+	paymentId, err := uuid.Parse(c.Params("paymentId"))
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe("Invalid payment id"))
+	}
+
+	creating := models.CreatingRefunds{}
+	err = c.BodyParser(&creating)
+	if err != nil {
+		return utils.ResponseError(c, apperror.
+			New(apperror.BadRequest).
+			Describe(fmt.Sprintf("Could not parse body: %v", err.Error())))
+	}
+
+	refund := models.Refunds{}
+	apperr := h.service.CreateRefund(&refund, &creating, paymentId, session.UserId)
+	if apperr != nil {
+		return utils.ResponseError(c, apperr)
+	}
+
+	return c.Status(http.StatusCreated).JSON(refund)
+}
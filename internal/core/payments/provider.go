@@ -0,0 +1,76 @@
+package payments
+
+import (
+	"errors"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
+	"github.com/google/uuid"
+)
+
+// PaymentProvider takes the money for payments. The service only talks to
+// providers through this interface so the whole payment flow can run against
+// FakeProvider without network access.
+type PaymentProvider interface {
+	// CreateCheckout opens a hosted checkout page for payment.
+	CreateCheckout(*ProviderCheckouts, *models.Payments) error
+
+	// ExpireCheckout closes the checkout page of payment so it can no longer
+	// be paid.
+	ExpireCheckout(*models.Payments) error
+
+	// VerifyWebhook checks the signature of a webhook payload and translates
+	// the event into ProviderEvents.
+	VerifyWebhook(*ProviderEvents, []byte, string) error
+
+	// Refund pays amount of a successful payment back to the payer. The
+	// outcome arrives later as a webhook event.
+	Refund(*ProviderRefunds, *models.Payments, money.Amount) error
+
+	// GetPaymentStatus asks the provider where the checkout of payment stands.
+	GetPaymentStatus(*enums.PaymentStatus, *models.Payments) error
+}
+
+// ProviderCheckouts is an open checkout page.
+type ProviderCheckouts struct {
+	SessionId string
+	URL       string
+	ExpiresAt time.Time
+}
+
+// ProviderEvents is a webhook event translated out of the provider's format.
+// Status is empty for events that change nothing, such as a checkout that
+// completed before delayed methods like PromptPay have paid. Refund events
+// only carry TransactionId and the total refunded so far.
+type ProviderEvents struct {
+	EventId        string
+	EventType      string
+	PaymentId      uuid.UUID
+	SessionId      string
+	TransactionId  string
+	Status         enums.PaymentStatus
+	RefundedAmount money.Amount
+}
+
+type ProviderRefunds struct {
+	RefundId string
+	Amount   money.Amount
+}
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrNotRefundable    = errors.New("payment has not been captured by the provider")
+)
+
+// NewProvider returns the provider named by PAYMENT_PROVIDER, Stripe unless it
+// is set to fake.
+func NewProvider(cfg *config.Config) PaymentProvider {
+	if cfg.PaymentProvider == "fake" {
+		return NewFakeProvider(cfg)
+	}
+
+	return NewStripeProvider(cfg)
+}
@@ -0,0 +1,263 @@
+package payments
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	receiptFont       = "receipt"
+	receiptLatinFont  = "Helvetica"
+	receiptMargin     = 20.0
+	receiptWidth      = 170.0
+	receiptLineHeight = 6.0
+	receiptAmountX    = 140.0
+	receiptAmountW    = 50.0
+	receiptTimezone   = "Asia/Bangkok"
+)
+
+var paymentMethodLabels = map[enums.PaymentMethods]string{
+	enums.PROMPTPAY:   "PromptPay",
+	enums.CREDIT_CARD: "Credit card",
+}
+
+// receiptDetails is everything printed on a receipt.
+type receiptDetails struct {
+	Receipt    *models.Receipts
+	Payment    *models.Payments
+	Agreement  *models.AgreementDetails
+	PayerEmail string
+}
+
+// receiptWriter writes text that may mix Thai and Latin script. The core PDF
+// fonts have no Thai glyphs, so Thai runs are set in RECEIPT_FONT.
+type receiptWriter struct {
+	pdf       *fpdf.Fpdf
+	translate func(string) string
+	thai      bool
+}
+
+// renderReceipt lays out a receipt, and a tax invoice when VAT is charged, as
+// a one page A4 PDF. Without a Thai font, Thai text comes out as dots.
+func renderReceipt(out io.Writer, fontPath string, details *receiptDetails) error {
+	loc, err := time.LoadLocation(receiptTimezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(receiptMargin, receiptMargin, receiptMargin)
+	pdf.SetAutoPageBreak(true, receiptMargin)
+	pdf.SetCreationDate(receiptIssuedAt(details.Receipt))
+
+	w := &receiptWriter{
+		pdf:       pdf,
+		translate: pdf.UnicodeTranslatorFromDescriptor(""),
+	}
+
+	if fontPath != "" {
+		font, err := os.ReadFile(fontPath)
+		if err != nil {
+			return fmt.Errorf("could not read receipt font: %w", err)
+		}
+
+		pdf.AddUTF8FontFromBytes(receiptFont, "", font)
+		w.thai = true
+	}
+
+	receipt := details.Receipt
+	payment := details.Payment
+	agreement := details.Agreement
+
+	title := "RECEIPT"
+	if receipt.VatPercent > 0 {
+		title = "RECEIPT / TAX INVOICE"
+	}
+
+	pdf.SetTitle(fmt.Sprintf("%v %v", title, receipt.Number()), false)
+	pdf.AddPage()
+
+	top := pdf.GetY()
+	w.line("B", 18, title)
+	w.line("", 10, "Sue Chao Khai - suechaokhai.com")
+
+	pdf.SetXY(receiptAmountX-30, top)
+	pdf.SetFont(receiptLatinFont, "B", 11)
+	pdf.CellFormat(receiptAmountW+30, receiptLineHeight, "No. "+receipt.Number(), "", 2, "R", false, 0, "")
+	pdf.SetFont(receiptLatinFont, "", 10)
+	pdf.CellFormat(receiptAmountW+30, receiptLineHeight, "Date "+receiptIssuedAt(receipt).In(loc).Format("2 January 2006"), "", 2, "R", false, 0, "")
+	pdf.SetXY(receiptMargin, top+3*receiptLineHeight)
+
+	partiesY := pdf.GetY()
+	w.column(receiptMargin, receiptWidth/2-5,
+		[]string{"B", "Issued by (owner)"},
+		[]string{"", fullName(agreement.Owner.OwnerFirstName, agreement.Owner.OwnerLastName)},
+		[]string{"", agreement.Owner.OwnerPhoneNumber},
+	)
+	ownerBottom := pdf.GetY()
+
+	pdf.SetY(partiesY)
+	w.column(receiptMargin+receiptWidth/2+5, receiptWidth/2-5,
+		[]string{"B", "Received from (payer)"},
+		[]string{"", fullName(agreement.Dweller.DwellerFirstName, agreement.Dweller.DwellerLastName)},
+		[]string{"", agreement.Dweller.DwellerPhoneNumber},
+		[]string{"", details.PayerEmail},
+	)
+	pdf.SetY(max(ownerBottom, pdf.GetY()) + receiptLineHeight)
+
+	w.column(receiptMargin, receiptWidth,
+		[]string{"B", "Property"},
+		[]string{"", agreement.Property.PropertyName},
+		[]string{"", propertyAddress(&agreement.Property)},
+	)
+	pdf.Ln(receiptLineHeight / 2)
+
+	w.column(receiptMargin, receiptWidth,
+		[]string{"", "Agreement reference: " + agreement.AgreementId.String()},
+		[]string{"", "Payment reference: " + payment.PaymentId.String()},
+		[]string{"", "Payment method: " + paymentMethodLabel(payment.PaymentMethod)},
+		[]string{"", "Paid on: " + paidAt(payment).In(loc).Format("2 January 2006 15:04 MST")},
+	)
+	pdf.Ln(receiptLineHeight)
+
+	w.row("B", "Description", "Amount (THB)")
+	pdf.Line(receiptMargin, pdf.GetY(), receiptMargin+receiptWidth, pdf.GetY())
+	pdf.Ln(1)
+	w.row("", payment.Name, receipt.Amount.String())
+	pdf.Ln(1)
+	pdf.Line(receiptMargin, pdf.GetY(), receiptMargin+receiptWidth, pdf.GetY())
+	pdf.Ln(1)
+
+	if receipt.VatPercent > 0 {
+		w.row("", "Amount before VAT", (receipt.Amount - receipt.VatAmount).String())
+		w.row("", fmt.Sprintf("VAT %v%%", receipt.VatPercent), receipt.VatAmount.String())
+	}
+	w.row("B", "Total paid", receipt.Amount.String())
+	pdf.Ln(receiptLineHeight)
+
+	note := "This receipt was issued electronically by suechaokhai.com on behalf of the property owner."
+	if receipt.VatPercent > 0 {
+		note += fmt.Sprintf(" Amounts are in Thai baht and include VAT at %v%%.", receipt.VatPercent)
+	}
+	w.column(receiptMargin, receiptWidth, []string{"", note})
+
+	return pdf.Output(out)
+}
+
+// line writes text on a line of its own.
+func (w *receiptWriter) line(style string, size float64, text string) {
+	w.write(style, size, text)
+	w.pdf.Ln(receiptLineHeight + size/4)
+}
+
+// column writes lines of {style, text} wrapped to a column. Empty lines are
+// left out.
+func (w *receiptWriter) column(x float64, width float64, lines ...[]string) {
+	w.pdf.SetLeftMargin(x)
+	w.pdf.SetRightMargin(210 - x - width)
+	defer w.pdf.SetMargins(receiptMargin, receiptMargin, receiptMargin)
+
+	for _, line := range lines {
+		if strings.TrimSpace(line[1]) == "" {
+			continue
+		}
+
+		w.pdf.SetX(x)
+		w.write(line[0], 10, line[1])
+		w.pdf.Ln(receiptLineHeight)
+	}
+}
+
+// row writes a description with its amount right aligned beside it.
+func (w *receiptWriter) row(style string, description string, amount string) {
+	y := w.pdf.GetY()
+	w.column(receiptMargin, receiptAmountX-receiptMargin-5, []string{style, description})
+	bottom := w.pdf.GetY()
+
+	w.pdf.SetXY(receiptAmountX, y)
+	w.pdf.SetFont(receiptLatinFont, style, 10)
+	w.pdf.CellFormat(receiptAmountW, receiptLineHeight, amount, "", 0, "R", false, 0, "")
+	w.pdf.SetXY(receiptMargin, max(bottom, y+receiptLineHeight))
+}
+
+// write sets text from the current position, switching to the Thai font for
+// runs of Thai script. The Thai font has no bold.
+func (w *receiptWriter) write(style string, size float64, text string) {
+	for len(text) > 0 {
+		thai := isThai([]rune(text)[0])
+		end := strings.IndexFunc(text, func(r rune) bool { return isThai(r) != thai })
+		if end < 0 {
+			end = len(text)
+		}
+		run := text[:end]
+		text = text[end:]
+
+		if thai && w.thai {
+			w.pdf.SetFont(receiptFont, "", size)
+			w.pdf.Write(receiptLineHeight, run)
+		} else {
+			w.pdf.SetFont(receiptLatinFont, style, size)
+			w.pdf.Write(receiptLineHeight, w.translate(run))
+		}
+	}
+}
+
+func isThai(r rune) bool {
+	return unicode.Is(unicode.Thai, r)
+}
+
+func fullName(first string, last string) string {
+	return strings.TrimSpace(first + " " + last)
+}
+
+func propertyAddress(property *models.PropertyAgreementDetails) string {
+	parts := []string{}
+	for _, part := range []string{
+		property.Address,
+		property.Alley,
+		property.Street,
+		property.SubDistrict,
+		property.District,
+		property.Province,
+		property.PostalCode,
+		property.Country,
+	} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func paymentMethodLabel(method enums.PaymentMethods) string {
+	if label, ok := paymentMethodLabels[method]; ok {
+		return label
+	}
+
+	return string(method)
+}
+
+func paidAt(payment *models.Payments) time.Time {
+	if payment.UpdatedAt != nil {
+		return *payment.UpdatedAt
+	}
+
+	return time.Now()
+}
+
+func receiptIssuedAt(receipt *models.Receipts) time.Time {
+	if receipt.IssuedAt != nil {
+		return *receipt.IssuedAt
+	}
+
+	return time.Now()
+}
@@ -0,0 +1,221 @@
+package payments
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/config"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
+	"github.com/google/uuid"
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/checkout/session"
+	"github.com/stripe/stripe-go/v76/refund"
+	"github.com/stripe/stripe-go/v76/webhook"
+)
+
+// StripeProvider takes payments through Stripe Checkout. The payment id is
+// attached to every session and its payment intent so webhook events can be
+// traced back to the payments row.
+type StripeProvider struct {
+	cfg      *config.Config
+	sessions session.Client
+	refunds  refund.Client
+}
+
+func NewStripeProvider(cfg *config.Config) *StripeProvider {
+	backend := stripe.GetBackend(stripe.APIBackend)
+
+	return &StripeProvider{
+		cfg,
+		session.Client{B: backend, Key: cfg.STRIPE_SECRET_KEY},
+		refund.Client{B: backend, Key: cfg.STRIPE_SECRET_KEY},
+	}
+}
+
+func (p *StripeProvider) CreateCheckout(checkout *ProviderCheckouts, payment *models.Payments) error {
+	method := string(stripe.PaymentMethodTypeCard)
+	if payment.PaymentMethod == enums.PROMPTPAY {
+		method = string(stripe.PaymentMethodTypePromptPay)
+	}
+
+	metadata := map[string]string{
+		"payment_id": payment.PaymentId.String(),
+	}
+
+	params := &stripe.CheckoutSessionParams{
+		Mode:              stripe.String(string(stripe.CheckoutSessionModePayment)),
+		ClientReferenceID: stripe.String(payment.PaymentId.String()),
+		Metadata:          metadata,
+		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
+			Metadata: metadata,
+		},
+		PaymentMethodTypes: []*string{
+			stripe.String(method),
+		},
+		LineItems: []*stripe.CheckoutSessionLineItemParams{
+			{
+				PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
+					Currency: stripe.String(strings.ToLower(string(payment.Currency))),
+					ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+						Name: stripe.String(payment.Name),
+					},
+					UnitAmount: stripe.Int64(int64(payment.Price)),
+				},
+				Quantity: stripe.Int64(1),
+			},
+		},
+		SuccessURL: stripe.String(p.cfg.FRONTEND_URL + "/success"),
+		CancelURL:  stripe.String(p.cfg.FRONTEND_URL + "/cancel"),
+	}
+	// a retried request for the same payment gets the same session back
+	params.SetIdempotencyKey("checkout:" + payment.PaymentId.String())
+
+	s, err := p.sessions.New(params)
+	if err != nil {
+		return err
+	}
+
+	*checkout = ProviderCheckouts{
+		SessionId: s.ID,
+		URL:       s.URL,
+		ExpiresAt: time.Unix(s.ExpiresAt, 0),
+	}
+
+	return nil
+}
+
+func (p *StripeProvider) ExpireCheckout(payment *models.Payments) error {
+	if payment.ProviderSessionId == nil {
+		return nil
+	}
+
+	_, err := p.sessions.Expire(*payment.ProviderSessionId, nil)
+	return err
+}
+
+func (p *StripeProvider) VerifyWebhook(event *ProviderEvents, payload []byte, signature string) error {
+	e, err := webhook.ConstructEventWithOptions(payload, signature, p.cfg.StripeWebhookSecret, webhook.ConstructEventOptions{
+		IgnoreAPIVersionMismatch: true,
+	})
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	*event = ProviderEvents{
+		EventId:   e.ID,
+		EventType: string(e.Type),
+	}
+
+	switch e.Type {
+	case stripe.EventTypeCheckoutSessionCompleted,
+		stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded,
+		stripe.EventTypeCheckoutSessionAsyncPaymentFailed,
+		stripe.EventTypeCheckoutSessionExpired:
+		checkout := stripe.CheckoutSession{}
+		err = json.Unmarshal(e.Data.Raw, &checkout)
+		if err != nil {
+			return err
+		}
+
+		event.PaymentId, _ = uuid.Parse(checkout.ClientReferenceID)
+		event.SessionId = checkout.ID
+		if checkout.PaymentIntent != nil {
+			event.TransactionId = checkout.PaymentIntent.ID
+		}
+
+		switch e.Type {
+		// delayed methods such as PromptPay complete the session before the
+		// money arrives and send async_payment_succeeded later
+		case stripe.EventTypeCheckoutSessionCompleted:
+			if checkout.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid {
+				event.Status = enums.SucceededPayment
+			}
+		case stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded:
+			event.Status = enums.SucceededPayment
+		case stripe.EventTypeCheckoutSessionAsyncPaymentFailed:
+			event.Status = enums.FailedPayment
+		case stripe.EventTypeCheckoutSessionExpired:
+			event.Status = enums.ExpiredPayment
+		}
+
+	case stripe.EventTypePaymentIntentPaymentFailed:
+		intent := stripe.PaymentIntent{}
+		err = json.Unmarshal(e.Data.Raw, &intent)
+		if err != nil {
+			return err
+		}
+
+		event.PaymentId, _ = uuid.Parse(intent.Metadata["payment_id"])
+		event.TransactionId = intent.ID
+		event.Status = enums.FailedPayment
+
+	case stripe.EventTypeChargeRefunded:
+		charge := stripe.Charge{}
+		err = json.Unmarshal(e.Data.Raw, &charge)
+		if err != nil {
+			return err
+		}
+
+		if charge.PaymentIntent == nil {
+			return nil
+		}
+
+		event.TransactionId = charge.PaymentIntent.ID
+		event.RefundedAmount = money.Amount(charge.AmountRefunded)
+		event.Status = enums.PartiallyRefundedPayment
+		if charge.Refunded {
+			event.Status = enums.RefundedPayment
+		}
+	}
+
+	return nil
+}
+
+func (p *StripeProvider) Refund(refunded *ProviderRefunds, payment *models.Payments, amount money.Amount) error {
+	if payment.ProviderPaymentId == nil {
+		return ErrNotRefundable
+	}
+
+	r, err := p.refunds.New(&stripe.RefundParams{
+		PaymentIntent: stripe.String(*payment.ProviderPaymentId),
+		Amount:        stripe.Int64(int64(amount)),
+	})
+	if err != nil {
+		return err
+	}
+
+	*refunded = ProviderRefunds{
+		RefundId: r.ID,
+		Amount:   money.Amount(r.Amount),
+	}
+
+	return nil
+}
+
+// GetPaymentStatus reports where the checkout session stands. Refunds are not
+// reflected here, they only arrive through webhooks.
+func (p *StripeProvider) GetPaymentStatus(status *enums.PaymentStatus, payment *models.Payments) error {
+	if payment.ProviderSessionId == nil {
+		*status = payment.Status
+		return nil
+	}
+
+	s, err := p.sessions.Get(*payment.ProviderSessionId, nil)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case s.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid:
+		*status = enums.SucceededPayment
+	case s.Status == stripe.CheckoutSessionStatusExpired:
+		*status = enums.ExpiredPayment
+	default:
+		*status = enums.PendingPayment
+	}
+
+	return nil
+}
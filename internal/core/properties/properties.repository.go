@@ -6,6 +6,7 @@ import (
 
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
 	"github.com/brain-flowing-company/pprp-backend/internal/utils"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -21,6 +22,7 @@ type Repository interface {
 	AddFavoriteProperty(*models.FavoriteProperties) error
 	RemoveFavoriteProperty(string, string) error
 	GetFavoritePropertiesByUserId(*models.MyFavoritePropertiesResponses, string, *utils.PaginatedQuery, *utils.SortedQuery) error
+	GetFavoritedUserIds(*[]uuid.UUID, string) error
 	GetTop10Properties(*[]models.Properties, string) error
 }
 
@@ -469,3 +471,9 @@ func (repo *repositoryImpl) GetTop10Properties(properties *[]models.Properties,
 	})
 
 }
+
+func (repo *repositoryImpl) GetFavoritedUserIds(userIds *[]uuid.UUID, propertyId string) error {
+	return repo.db.Model(&models.FavoriteProperties{}).
+		Where("property_id = ?", propertyId).
+		Pluck("user_id", userIds).Error
+}
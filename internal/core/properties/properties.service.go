@@ -9,6 +9,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/internal/core/events"
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
 	"github.com/brain-flowing-company/pprp-backend/internal/utils"
 	"github.com/brain-flowing-company/pprp-backend/storage"
@@ -34,13 +35,15 @@ type serviceImpl struct {
 	repo    Repository
 	logger  *zap.Logger
 	storage storage.Storage
+	events  events.Publisher
 }
 
-func NewService(logger *zap.Logger, repo Repository, storage storage.Storage) Service {
+func NewService(logger *zap.Logger, repo Repository, storage storage.Storage, events events.Publisher) Service {
 	return &serviceImpl{
 		repo,
 		logger,
 		storage,
+		events,
 	}
 }
 
@@ -225,9 +228,42 @@ func (s *serviceImpl) UpdatePropertyById(property *models.PropertyInfos, propert
 			Describe("Could not update property. Please try again later.")
 	}
 
+	s.publishPriceDrop(existingProperty, property)
+
 	return nil
 }
 
+// publishPriceDrop tells users who favorited the property that its price or
+// rent went down. A price of 0 means the property is no longer listed that
+// way and does not count as a drop.
+func (s *serviceImpl) publishPriceDrop(existing *models.Properties, updated *models.PropertyInfos) {
+	event := &models.PriceDropEvents{
+		PropertyId:   existing.PropertyId,
+		PropertyName: updated.PropertyName,
+	}
+
+	if oldPrice := existing.SellingProperty.Price; updated.Price > 0 && updated.Price < oldPrice {
+		event.OldPrice, event.NewPrice = &oldPrice, &updated.Price
+	}
+
+	if oldPrice := existing.RentingProperty.PricePerMonth; updated.PricePerMonth > 0 && updated.PricePerMonth < oldPrice {
+		event.OldPricePerMonth, event.NewPricePerMonth = &oldPrice, &updated.PricePerMonth
+	}
+
+	if event.NewPrice == nil && event.NewPricePerMonth == nil {
+		return
+	}
+
+	userIds := []uuid.UUID{}
+	err := s.repo.GetFavoritedUserIds(&userIds, existing.PropertyId.String())
+	if err != nil {
+		s.logger.Error("Could not get users who favorited property", zap.Error(err), zap.String("id", existing.PropertyId.String()))
+		return
+	}
+
+	s.events.Publish(userIds, event)
+}
+
 func (s *serviceImpl) DeletePropertyById(propertyId string) *apperror.AppError {
 	if !utils.IsValidUUID(propertyId) {
 		return apperror.
@@ -0,0 +1,9 @@
+package enums
+
+type ChatReportStatus string
+
+const (
+	PendingChatReport   ChatReportStatus = "PENDING"
+	ReviewedChatReport  ChatReportStatus = "REVIEWED"
+	DismissedChatReport ChatReportStatus = "DISMISSED"
+)
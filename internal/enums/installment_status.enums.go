@@ -0,0 +1,11 @@
+package enums
+
+// InstallmentStatus is where a rent installment stands. It is worked out from
+// the payments of the installment and its due date rather than stored.
+type InstallmentStatus string
+
+const (
+	PaidInstallment    InstallmentStatus = "PAID"
+	UnpaidInstallment  InstallmentStatus = "UNPAID"
+	OverdueInstallment InstallmentStatus = "OVERDUE"
+)
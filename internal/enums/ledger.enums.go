@@ -0,0 +1,27 @@
+package enums
+
+// LedgerAccounts are the accounts money moves between in the ledger. The owner
+// accounts are kept per owner.
+type LedgerAccounts string
+
+const (
+	// CashAccount is the money held with the payment provider.
+	CashAccount LedgerAccounts = "CASH"
+	// OwnerPayableAccount is what the platform owes an owner.
+	OwnerPayableAccount LedgerAccounts = "OWNER_PAYABLE"
+	// OwnerPayoutsPendingAccount is what is on its way to an owner's bank.
+	OwnerPayoutsPendingAccount LedgerAccounts = "OWNER_PAYOUTS_PENDING"
+	// PlatformFeesAccount is what the platform has earned in fees.
+	PlatformFeesAccount LedgerAccounts = "PLATFORM_FEES"
+)
+
+// LedgerTransactionKinds tells what a ledger transaction records.
+type LedgerTransactionKinds string
+
+const (
+	PaymentTransaction      LedgerTransactionKinds = "PAYMENT"
+	RefundTransaction       LedgerTransactionKinds = "REFUND"
+	PayoutTransaction       LedgerTransactionKinds = "PAYOUT"
+	PayoutPaidTransaction   LedgerTransactionKinds = "PAYOUT_PAID"
+	PayoutFailedTransaction LedgerTransactionKinds = "PAYOUT_FAILED"
+)
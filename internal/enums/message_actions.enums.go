@@ -0,0 +1,18 @@
+package enums
+
+type MessageActionTypes string
+
+const (
+	AppointmentRequestAction MessageActionTypes = "APPOINTMENT_REQUEST"
+	AgreementOfferAction     MessageActionTypes = "AGREEMENT_OFFER"
+	PaymentRequestAction     MessageActionTypes = "PAYMENT_REQUEST"
+)
+
+type MessageActionStatus string
+
+const (
+	PendingAction  MessageActionStatus = "PENDING"
+	AcceptedAction MessageActionStatus = "ACCEPTED"
+	RejectedAction MessageActionStatus = "REJECTED"
+	PaidAction     MessageActionStatus = "PAID"
+)
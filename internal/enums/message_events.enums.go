@@ -3,15 +3,41 @@ package enums
 type MessageInboundEvents string
 
 const (
-	INBOUND_MSG  MessageInboundEvents = "MSG"
-	INBOUND_JOIN MessageInboundEvents = "JOIN"
-	INBOUND_LEFT MessageInboundEvents = "LEFT"
+	INBOUND_MSG     MessageInboundEvents = "MSG"
+	INBOUND_JOIN    MessageInboundEvents = "JOIN"
+	INBOUND_LEFT    MessageInboundEvents = "LEFT"
+	INBOUND_EDIT    MessageInboundEvents = "EDIT"
+	INBOUND_DELETE  MessageInboundEvents = "DELETE"
+	INBOUND_REACT   MessageInboundEvents = "REACT"
+	INBOUND_UNREACT MessageInboundEvents = "UNREACT"
+	INBOUND_READ    MessageInboundEvents = "READ"
+
+	// responses to action messages
+	INBOUND_ACCEPT     MessageInboundEvents = "ACCEPT"
+	INBOUND_REJECT     MessageInboundEvents = "REJECT"
+	INBOUND_RESCHEDULE MessageInboundEvents = "RESCHEDULE"
 )
 
 type MessageOutboundEvents string
 
 const (
-	OUTBOUND_MSG  MessageOutboundEvents = "MSG"
-	OUTBOUND_READ MessageOutboundEvents = "READ"
-	OUTBOUND_OK   MessageOutboundEvents = "OK"
+	OUTBOUND_MSG       MessageOutboundEvents = "MSG"
+	OUTBOUND_READ      MessageOutboundEvents = "READ"
+	OUTBOUND_OK        MessageOutboundEvents = "OK"
+	OUTBOUND_EDIT      MessageOutboundEvents = "EDIT"
+	OUTBOUND_DELETE    MessageOutboundEvents = "DELETE"
+	OUTBOUND_REACT     MessageOutboundEvents = "REACT"
+	OUTBOUND_UNREACT   MessageOutboundEvents = "UNREACT"
+	OUTBOUND_MEMBERS   MessageOutboundEvents = "MEMBERS"
+	OUTBOUND_DELIVERED MessageOutboundEvents = "DELIVERED"
+	OUTBOUND_UNREAD    MessageOutboundEvents = "UNREAD"
+	OUTBOUND_ACTION    MessageOutboundEvents = "ACTION"
+	OUTBOUND_ERROR     MessageOutboundEvents = "ERROR"
+
+	// events outside of chats, pushed over the same connection
+	OUTBOUND_APPOINTMENT_UPDATED MessageOutboundEvents = "APPOINTMENT_UPDATED"
+	OUTBOUND_AGREEMENT_UPDATED   MessageOutboundEvents = "AGREEMENT_UPDATED"
+	OUTBOUND_PAYMENT_SUCCEEDED   MessageOutboundEvents = "PAYMENT_SUCCEEDED"
+	OUTBOUND_PAYMENT_UPDATED     MessageOutboundEvents = "PAYMENT_UPDATED"
+	OUTBOUND_FAVORITE_PRICE_DROP MessageOutboundEvents = "FAVORITE_PRICE_DROP"
 )
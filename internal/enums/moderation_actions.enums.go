@@ -0,0 +1,23 @@
+package enums
+
+type ModerationActions string
+
+// ordered from least to most severe
+const (
+	FlagModeration  ModerationActions = "FLAG"
+	MaskModeration  ModerationActions = "MASK"
+	BlockModeration ModerationActions = "BLOCK"
+)
+
+func (a ModerationActions) Severity() int {
+	switch a {
+	case FlagModeration:
+		return 1
+	case MaskModeration:
+		return 2
+	case BlockModeration:
+		return 3
+	default:
+		return 0
+	}
+}
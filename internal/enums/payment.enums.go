@@ -6,3 +6,12 @@ const (
 	CREDIT_CARD PaymentMethods = "CREDIT_CARD"
 	PROMPTPAY   PaymentMethods = "PROMPTPAY"
 )
+
+// PaymentTypes tells what part of an agreement a payment pays for.
+type PaymentTypes string
+
+const (
+	DepositPayment     PaymentTypes = "DEPOSIT"
+	InstallmentPayment PaymentTypes = "INSTALLMENT"
+	BalancePayment     PaymentTypes = "BALANCE"
+)
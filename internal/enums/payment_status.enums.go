@@ -0,0 +1,12 @@
+package enums
+
+type PaymentStatus string
+
+const (
+	PendingPayment           PaymentStatus = "PENDING"
+	SucceededPayment         PaymentStatus = "SUCCEEDED"
+	FailedPayment            PaymentStatus = "FAILED"
+	ExpiredPayment           PaymentStatus = "EXPIRED"
+	PartiallyRefundedPayment PaymentStatus = "PARTIALLY_REFUNDED"
+	RefundedPayment          PaymentStatus = "REFUNDED"
+)
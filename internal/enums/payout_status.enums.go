@@ -0,0 +1,9 @@
+package enums
+
+type PayoutStatus string
+
+const (
+	PendingPayout PayoutStatus = "PENDING"
+	PaidPayout    PayoutStatus = "PAID"
+	FailedPayout  PayoutStatus = "FAILED"
+)
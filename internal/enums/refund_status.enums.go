@@ -0,0 +1,9 @@
+package enums
+
+type RefundStatus string
+
+const (
+	PendingRefund   RefundStatus = "PENDING"
+	SucceededRefund RefundStatus = "SUCCEEDED"
+	FailedRefund    RefundStatus = "FAILED"
+)
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// WithBodyLimit rejects bodies above limit on every route except POSTs to a
+// path matching uploads. fasthttp reads the body before routing, so the server
+// wide BodyLimit has to fit the largest upload and the other routes are held
+// to the smaller limit here.
+func (m *Middleware) WithBodyLimit(limit int, uploads *regexp.Regexp) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if len(c.Body()) <= limit || (c.Method() == fiber.MethodPost && uploads.MatchString(c.Path())) {
+			return c.Next()
+		}
+
+		return utils.ResponseMessage(c, http.StatusRequestEntityTooLarge, "Request body is too large")
+	}
+}
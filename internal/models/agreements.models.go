@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -15,10 +16,10 @@ type Agreements struct {
 	DwellerUserId    uuid.UUID             `json:"dweller_user_id" example:"00000000-0000-0000-0000-000000000000"`
 	AgreementDate    time.Time             `json:"agreement_date" example:"2021-01-01T00:00:00Z"`
 	Status           enums.AgreementStatus `json:"status" example:"AWAITING_DEPOSIT"`
-	DepositAmount    float64               `json:"deposit_amount" example:"1000000"`
-	PaymentPerMonth  float64               `json:"payment_per_month" example:"1000000"`
+	DepositAmount    money.Amount          `json:"deposit_amount" example:"1000000" swaggertype:"number"`
+	PaymentPerMonth  money.Amount          `json:"payment_per_month" example:"1000000" swaggertype:"number"`
 	PaymentDuration  int                   `json:"payment_duration" example:"12"`
-	TotalPayment     float64               `json:"total_payment" example:"12000000"`
+	TotalPayment     money.Amount          `json:"total_payment" example:"12000000" swaggertype:"number"`
 	CancelledMessage string                `json:"cancelled_message" example:"This is cancelled message."`
 	CommonModels
 }
@@ -31,10 +32,10 @@ type CreatingAgreements struct {
 	DwellerUserId   uuid.UUID             `json:"dweller_user_id" example:"00000000-0000-0000-0000-000000000000"`
 	AgreementDate   time.Time             `json:"agreement_date" example:"2021-01-01T00:00:00Z"`
 	Status          enums.AgreementStatus `json:"status" example:"AWAITING_DEPOSIT"`
-	DepositAmount   float64               `json:"deposit_amount" example:"1000000"`
-	PaymentPerMonth float64               `json:"payment_per_month" example:"1000000"`
+	DepositAmount   money.Amount          `json:"deposit_amount" example:"1000000" swaggertype:"number"`
+	PaymentPerMonth money.Amount          `json:"payment_per_month" example:"1000000" swaggertype:"number"`
 	PaymentDuration int                   `json:"payment_duration" example:"12"`
-	TotalPayment    float64               `json:"total_payment" example:"12000000"`
+	TotalPayment    money.Amount          `json:"total_payment" example:"12000000" swaggertype:"number"`
 }
 
 func (a Agreements) TableName() string {
@@ -100,10 +101,10 @@ type AgreementDetails struct {
 	Dweller          DwellerAgreementDetails  `json:"dweller" gorm:"foreignKey:AgreementId; references:AgreementId; embedded"`
 	AgreementDate    time.Time                `json:"agreement_date" example:"2021-01-01T00:00:00Z"`
 	Status           enums.AgreementStatus    `json:"status" example:"AWAITING_DEPOSIT"`
-	DepositAmount    float64                  `json:"deposit_amount" example:"1000000"`
-	PaymentPerMonth  float64                  `json:"payment_per_month" example:"1000000"`
+	DepositAmount    money.Amount             `json:"deposit_amount" example:"1000000" swaggertype:"number"`
+	PaymentPerMonth  money.Amount             `json:"payment_per_month" example:"1000000" swaggertype:"number"`
 	PaymentDuration  int                      `json:"payment_duration" example:"12"`
-	TotalPayment     float64                  `json:"total_payment" example:"12000000"`
+	TotalPayment     money.Amount             `json:"total_payment" example:"12000000" swaggertype:"number"`
 	CancelledMessage string                   `json:"cancelled_message" example:"This is cancelled message."`
 	CommonModels
 }
@@ -122,8 +123,8 @@ type PropertyAgreementDetails struct {
 	Country        string                    `json:"country" example:"Thailand"`
 	PostalCode     string                    `json:"postal_code" example:"10110"`
 	PropertyImages []PropertyImageAgreements `json:"property_images" gorm:"foreignKey:PropertyId; references:PropertyId"`
-	Price          float64                   `json:"price" example:"1000000"`
-	PricePerMonth  float64                   `json:"price_per_month" example:"1000000"`
+	Price          money.Amount              `json:"price" example:"1000000" swaggertype:"number"`
+	PricePerMonth  money.Amount              `json:"price_per_month" example:"1000000" swaggertype:"number"`
 }
 
 type OwnerAgreementDetails struct {
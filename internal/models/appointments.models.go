@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -37,6 +38,10 @@ type UpdatingAppointmentStatus struct {
 	CancelledMessage string                  `json:"cancelled_message" example:"This is a cancelled message"`
 }
 
+type ReschedulingAppointments struct {
+	AppointmentDate time.Time `json:"appointment_date" example:"2024-02-18T11:00:00Z"`
+}
+
 // Data Structure for My Appointments
 
 type MyAppointmentRequests struct {
@@ -110,8 +115,8 @@ type PropertyAppointmentDetails struct {
 	Country        string                      `json:"country" example:"Thailand"`
 	PostalCode     string                      `json:"postal_code" example:"69096"`
 	PropertyImages []PropertyImageAppointments `json:"property_images" gorm:"foreignKey:PropertyId; references:PropertyId"`
-	Price          float64                     `json:"price" example:"12345.67"`
-	PricePerMonth  float64                     `json:"price_per_month" example:"12345.67"`
+	Price          money.Amount                `json:"price" example:"12345.67" swaggertype:"number"`
+	PricePerMonth  money.Amount                `json:"price_per_month" example:"12345.67" swaggertype:"number"`
 }
 
 type OwnerAppointmentDetails struct {
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/google/uuid"
+)
+
+type UserBlocks struct {
+	BlockerId uuid.UUID `json:"-"`
+	BlockedId uuid.UUID `json:"user_id"    example:"123e4567-e89b-12d3-a456-426614174000"`
+	CreatedAt time.Time `json:"created_at" example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (b UserBlocks) TableName() string {
+	return "user_blocks"
+}
+
+type BlockedUsers struct {
+	UserId          uuid.UUID `json:"user_id"           example:"123e4567-e89b-12d3-a456-426614174000"`
+	ProfileImageUrl string    `json:"profile_image_url" example:"www.image.com/profile"`
+	FirstName       string    `json:"first_name"        example:"John"`
+	LastName        string    `json:"last_name"         example:"Doe"`
+	BlockedAt       time.Time `json:"blocked_at"        example:"2024-02-22T03:06:53.313735Z"`
+}
+
+type ChatMutes struct {
+	UserId    uuid.UUID `json:"-"`
+	ChatId    uuid.UUID `json:"chat_id"    example:"123e4567-e89b-12d3-a456-426614174000" gorm:"column:conversation_id"`
+	CreatedAt time.Time `json:"created_at" example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (m ChatMutes) TableName() string {
+	return "chat_mutes"
+}
+
+type ChatReports struct {
+	ReportId       uuid.UUID              `json:"report_id"        example:"123e4567-e89b-12d3-a456-426614174000"`
+	ReporterId     uuid.UUID              `json:"reporter_id"      example:"123e4567-e89b-12d3-a456-426614174000"`
+	ReportedUserId uuid.UUID              `json:"reported_user_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ChatId         uuid.UUID              `json:"chat_id"          example:"123e4567-e89b-12d3-a456-426614174000" gorm:"column:conversation_id"`
+	Reason         string                 `json:"reason"           example:"Asked me to transfer the deposit outside the app"`
+	Status         enums.ChatReportStatus `json:"status"           example:"PENDING"`
+	CreatedAt      time.Time              `json:"created_at"       example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (r ChatReports) TableName() string {
+	return "chat_reports"
+}
+
+type CreatingChatReports struct {
+	Reason string     `json:"reason"  example:"Asked me to transfer the deposit outside the app"`
+	UserId *uuid.UUID `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
+
+type MessageModerations struct {
+	ModerationId uuid.UUID               `json:"moderation_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	MessageId    uuid.UUID               `json:"message_id"    example:"123e4567-e89b-12d3-a456-426614174000"`
+	ChatId       uuid.UUID               `json:"chat_id"       example:"123e4567-e89b-12d3-a456-426614174000" gorm:"column:conversation_id"`
+	SenderId     uuid.UUID               `json:"sender_id"     example:"123e4567-e89b-12d3-a456-426614174000"`
+	Rule         string                  `json:"rule"          example:"contact"`
+	Action       enums.ModerationActions `json:"action"        example:"MASK"`
+	Content      string                  `json:"content"       example:"call me at 0812345678"`
+	CreatedAt    time.Time               `json:"created_at"    example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (m MessageModerations) TableName() string {
+	return "message_moderations"
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
+	"github.com/google/uuid"
+)
+
+// ChatTranscripts is a self-contained record of a chat for disputes. Every
+// attachment is resolved so the transcript still makes sense after the
+// property, appointment or agreement has changed or been deleted.
+type ChatTranscripts struct {
+	ChatId       uuid.UUID                `json:"chat_id"      example:"123e4567-e89b-12d3-a456-426614174000"`
+	IsGroup      bool                     `json:"is_group"     example:"false"`
+	Name         *string                  `json:"name"         example:"Viewing on Saturday"`
+	Property     *TranscriptProperties    `json:"property"`
+	Participants []TranscriptParticipants `json:"participants"`
+	ExportedBy   uuid.UUID                `json:"exported_by"  example:"123e4567-e89b-12d3-a456-426614174000"`
+	ExportedAt   time.Time                `json:"exported_at"  example:"2024-02-22T03:06:53.313735Z"`
+	Messages     []TranscriptMessages     `json:"messages"`
+}
+
+type TranscriptParticipants struct {
+	UserId    uuid.UUID `json:"user_id"    example:"123e4567-e89b-12d3-a456-426614174000"`
+	FirstName string    `json:"first_name" example:"John"`
+	LastName  string    `json:"last_name"  example:"Doe"`
+	JoinedAt  time.Time `json:"joined_at"  example:"2024-02-22T03:06:53.313735Z"`
+}
+
+type TranscriptMessages struct {
+	MessageId   uuid.UUID               `json:"message_id"  example:"123e4567-e89b-12d3-a456-426614174000"`
+	SenderId    uuid.UUID               `json:"sender_id"   example:"123e4567-e89b-12d3-a456-426614174000"`
+	SenderName  string                  `json:"sender_name" example:"John Doe"`
+	Content     string                  `json:"content"     example:"hello, world"`
+	SentAt      time.Time               `json:"sent_at"     example:"2024-02-22T03:06:53.313735Z"`
+	EditedAt    *time.Time              `json:"edited_at"   example:"2024-02-22T03:06:53.313735Z"`
+	DeletedAt   *time.Time              `json:"deleted_at"  example:"2024-02-22T03:06:53.313735Z"`
+	Receipts    []MessageReceipts       `json:"receipts"`
+	Files       []TranscriptFiles       `json:"files"`
+	Property    *TranscriptProperties   `json:"property,omitempty"`
+	Appointment *TranscriptAppointments `json:"appointment,omitempty"`
+	Agreement   *TranscriptAgreements   `json:"agreement,omitempty"`
+	Action      *MessageActions         `json:"action,omitempty"`
+}
+
+type TranscriptFiles struct {
+	FileName    string `json:"file_name"    example:"payslip.pdf"`
+	ContentType string `json:"content_type" example:"application/pdf"`
+	Size        int64  `json:"size"         example:"102400"`
+}
+
+type TranscriptProperties struct {
+	PropertyId   uuid.UUID `json:"property_id"   example:"123e4567-e89b-12d3-a456-426614174000"`
+	PropertyName string    `json:"property_name" example:"The Base Sukhumvit 77"`
+}
+
+type TranscriptAppointments struct {
+	AppointmentId    uuid.UUID               `json:"appointment_id"    example:"123e4567-e89b-12d3-a456-426614174000"`
+	AppointmentDate  time.Time               `json:"appointment_date"  example:"2024-02-18T11:00:00Z"`
+	Status           enums.AppointmentStatus `json:"status"            example:"CONFIRMED"`
+	Note             string                  `json:"note"              example:"This is a note"`
+	CancelledMessage *string                 `json:"cancelled_message" example:"This is a cancelled message"`
+}
+
+type TranscriptAgreements struct {
+	AgreementId      uuid.UUID             `json:"agreement_id"      example:"123e4567-e89b-12d3-a456-426614174000"`
+	AgreementType    enums.AgreementTypes  `json:"agreement_type"    example:"RENTING"`
+	AgreementDate    time.Time             `json:"agreement_date"    example:"2024-02-18T11:00:00Z"`
+	Status           enums.AgreementStatus `json:"status"            example:"RENTING"`
+	DepositAmount    *money.Amount         `json:"deposit_amount"    example:"20000" swaggertype:"number"`
+	PaymentPerMonth  *money.Amount         `json:"payment_per_month" example:"10000" swaggertype:"number"`
+	PaymentDuration  *int                  `json:"payment_duration"  example:"12"`
+	TotalPayment     *money.Amount         `json:"total_payment"     example:"140000" swaggertype:"number"`
+	CancelledMessage *string               `json:"cancelled_message" example:"This is a cancelled message"`
+}
@@ -1,8 +1,39 @@
 package models
 
-import "github.com/golang-jwt/jwt"
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
 
 type SessionClaims struct {
 	jwt.StandardClaims
 	Session Sessions `json:"session"`
 }
+
+// SessionId is the jti of the token. Tokens issued before sessions had ids
+// return uuid.Nil and can not be revoked.
+func (c *SessionClaims) SessionId() uuid.UUID {
+	sessionId, err := uuid.Parse(c.Id)
+	if err != nil {
+		return uuid.Nil
+	}
+
+	return sessionId
+}
+
+func (c *SessionClaims) ExpiresTime() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+type RevokedSessions struct {
+	SessionId uuid.UUID `gorm:"primaryKey"`
+	UserId    uuid.UUID
+	ExpiresAt time.Time
+	RevokedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (s RevokedSessions) TableName() string {
+	return "revoked_sessions"
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/google/uuid"
+)
+
+type Conversations struct {
+	ConversationId uuid.UUID   `json:"chat_id"      example:"123e4567-e89b-12d3-a456-426614174000"`
+	PropertyId     *uuid.UUID  `json:"property_id"  example:"123e4567-e89b-12d3-a456-426614174000"`
+	IsGroup        bool        `json:"is_group"     example:"false"`
+	Name           *string     `json:"name"         example:"Flatmates"`
+	CreatedBy      *uuid.UUID  `json:"created_by"   example:"123e4567-e89b-12d3-a456-426614174000"`
+	DirectKey      *string     `json:"-"`
+	CreatedAt      time.Time   `json:"created_at"   example:"2024-02-22T03:06:53.313735Z"`
+	Participants   []uuid.UUID `json:"participants" gorm:"-"`
+}
+
+func (c Conversations) TableName() string {
+	return "conversations"
+}
+
+// PartnerOf returns the other participant of a one-to-one conversation.
+// It is meaningless for group conversations.
+func (c *Conversations) PartnerOf(userId uuid.UUID) uuid.UUID {
+	for _, participant := range c.Participants {
+		if participant != userId {
+			return participant
+		}
+	}
+
+	return uuid.Nil
+}
+
+func (c *Conversations) HasParticipant(userId uuid.UUID) bool {
+	for _, participant := range c.Participants {
+		if participant == userId {
+			return true
+		}
+	}
+
+	return false
+}
+
+type ConversationParticipants struct {
+	ConversationId uuid.UUID `json:"chat_id"   example:"123e4567-e89b-12d3-a456-426614174000"`
+	UserId         uuid.UUID `json:"user_id"   example:"123e4567-e89b-12d3-a456-426614174000"`
+	JoinedAt       time.Time `json:"joined_at" example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (p ConversationParticipants) TableName() string {
+	return "conversation_participants"
+}
+
+type CreatingConversations struct {
+	UserId     uuid.UUID  `json:"user_id"     example:"123e4567-e89b-12d3-a456-426614174000"`
+	PropertyId *uuid.UUID `json:"property_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
+
+type CreatingGroupConversations struct {
+	Name       string      `json:"name"        example:"Flatmates"`
+	PropertyId *uuid.UUID  `json:"property_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	UserIds    []uuid.UUID `json:"user_ids"`
+}
+
+type AddingConversationMembers struct {
+	UserIds []uuid.UUID `json:"user_ids"`
+}
+
+type MemberEvents struct {
+	ChatId       uuid.UUID   `json:"chat_id"`
+	Participants []uuid.UUID `json:"participants"`
+}
+
+func (e *MemberEvents) ToOutBound() *OutBoundMessages {
+	tmp := *e
+	return &OutBoundMessages{
+		Event:   enums.OUTBOUND_MEMBERS,
+		Payload: tmp,
+	}
+}
@@ -1,5 +1,7 @@
 package models
 
+import "github.com/brain-flowing-company/pprp-backend/internal/money"
+
 type SendingEmailRequests struct {
 	Emails []string `json:"emails"`
 }
@@ -15,3 +17,71 @@ type VerificationEmails struct {
 func (v VerificationEmails) Path() string {
 	return "internal/templates/VerificationEmail.html"
 }
+
+type ChatDigestEmails struct {
+	FirstName      string
+	UnreadMessages int
+	Chats          []ChatDigestEntries
+	Url            string
+}
+
+type ChatDigestEntries struct {
+	SenderName     string
+	UnreadMessages int
+	LatestContent  string
+}
+
+func (c ChatDigestEmails) Path() string {
+	return "internal/templates/ChatDigestEmail.html"
+}
+
+type RentReminderEmails struct {
+	FirstName    string
+	Heading      string
+	Message      string
+	PropertyName string
+	Installment  string
+	DueDate      string
+	Amount       money.Amount
+	LateFee      money.Amount
+	AmountDue    money.Amount
+	Url          string
+}
+
+func (r RentReminderEmails) Path() string {
+	return "internal/templates/RentReminderEmail.html"
+}
+
+type OverdueSummaryEmails struct {
+	FirstName    string
+	Installments []OverdueSummaryEntries
+	TotalDue     money.Amount
+	Url          string
+}
+
+type OverdueSummaryEntries struct {
+	PropertyName string
+	DwellerName  string
+	Installment  string
+	DueDate      string
+	DaysLate     int
+	AmountDue    money.Amount
+}
+
+func (o OverdueSummaryEmails) Path() string {
+	return "internal/templates/OverdueSummaryEmail.html"
+}
+
+type ReceiptEmails struct {
+	FirstName     string
+	ReceiptNumber string
+	PaymentName   string
+	PropertyName  string
+	Amount        money.Amount
+	PaidAt        string
+	Url           string
+}
+
+func (r ReceiptEmails) Path() string {
+	return "internal/templates/ReceiptEmail.html"
+}
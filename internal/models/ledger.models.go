@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
+	"github.com/google/uuid"
+)
+
+// LedgerTransactions is a balanced set of ledger entries recording one money
+// movement. Reference is unique, so the same movement is never booked twice.
+type LedgerTransactions struct {
+	TransactionId uuid.UUID `gorm:"primaryKey"`
+	Kind          enums.LedgerTransactionKinds
+	Reference     string
+	Description   string
+	PaymentId     *uuid.UUID
+	PayoutId      *uuid.UUID
+	Entries       []LedgerEntries `gorm:"foreignKey:TransactionId"`
+	CreatedAt     *time.Time      `gorm:"autoCreateTime"`
+}
+
+func (t LedgerTransactions) TableName() string {
+	return "ledger_transactions"
+}
+
+// LedgerEntries moves Amount into an account, or out of it when negative.
+// UserId is the owner the entry concerns.
+type LedgerEntries struct {
+	TransactionId uuid.UUID
+	Account       enums.LedgerAccounts
+	UserId        *uuid.UUID
+	Amount        money.Amount
+}
+
+func (e LedgerEntries) TableName() string {
+	return "ledger_entries"
+}
+
+// LedgerSums adds up the entries of one kind of transaction in one account.
+type LedgerSums struct {
+	Kind    enums.LedgerTransactionKinds
+	Account enums.LedgerAccounts
+	Amount  money.Amount
+}
+
+type Payouts struct {
+	PayoutId          uuid.UUID          `json:"payout_id"           gorm:"primaryKey" example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	UserId            uuid.UUID          `json:"-"`
+	Amount            money.Amount       `json:"amount"                                example:"29100" swaggertype:"number"`
+	BankName          enums.BankNames    `json:"bank_name"                             example:"KBANK"`
+	BankAccountNumber string             `json:"bank_account_number"                   example:"******7890"`
+	Status            enums.PayoutStatus `json:"status"                                example:"PENDING"`
+	Reference         *string            `json:"reference"                             example:"KB20240301-000123"`
+	FailureReason     *string            `json:"failure_reason"                        example:"Account closed"`
+	PaidAt            *time.Time         `json:"paid_at"                               example:"2024-03-02T10:00:00Z"`
+	CreatedAt         *time.Time         `json:"created_at"          gorm:"autoCreateTime"`
+	UpdatedAt         *time.Time         `json:"-"                   gorm:"autoUpdateTime"`
+}
+
+func (p Payouts) TableName() string {
+	return "payouts"
+}
+
+// OwnerBalances is what can be paid out to an owner and where to.
+type OwnerBalances struct {
+	UserId            uuid.UUID
+	Balance           money.Amount
+	BankName          enums.BankNames
+	BankAccountNumber string
+}
+
+// UnrecordedPayments is a paid payment that is missing from the ledger.
+type UnrecordedPayments struct {
+	Payments
+	OwnerUserId uuid.UUID
+}
+
+type OwnerEarnings struct {
+	Balance           money.Amount    `json:"balance"             example:"29100" swaggertype:"number"`
+	Pending           money.Amount    `json:"pending"             example:"0" swaggertype:"number"`
+	PaidOut           money.Amount    `json:"paid_out"            example:"58200" swaggertype:"number"`
+	Earned            money.Amount    `json:"earned"              example:"87300" swaggertype:"number"`
+	Fees              money.Amount    `json:"fees"                example:"2700" swaggertype:"number"`
+	Refunded          money.Amount    `json:"refunded"            example:"0" swaggertype:"number"`
+	BankName          enums.BankNames `json:"bank_name"           example:"KBANK"`
+	BankAccountNumber string          `json:"bank_account_number" example:"******7890"`
+	Payouts           []Payouts       `json:"payouts"`
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -12,6 +13,7 @@ type OutBoundPayload interface {
 }
 
 type OutBoundMessages struct {
+	Version int                         `json:"v,omitempty"`
 	Event   enums.MessageOutboundEvents `json:"event"`
 	Tag     string                      `json:"tag,omitempty"`
 	Payload interface{}                 `json:"payload"`
@@ -19,23 +21,32 @@ type OutBoundMessages struct {
 
 type InBoundMessages struct {
 	Event       enums.MessageInboundEvents `json:"event"`
+	MessageId   uuid.UUID                  `json:"message_id"`
 	Content     string                     `json:"content"`
 	SentAt      time.Time                  `json:"sent_at"`
 	Attatchment MessageAttatchments        `json:"attatchment"`
 	Tag         string                     `json:"tag"`
+
+	// proposed date of a RESCHEDULE response
+	AppointmentDate *time.Time `json:"appointment_date"`
 }
 
 type Messages struct {
 	MessageId   uuid.UUID           `json:"message_id"    example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
-	ChatId      uuid.UUID           `json:"chat_id"       example:"27b79b15-a56f-464a-90f7-bab515ba4c02" gorm:"-"`
-	SenderId    uuid.UUID           `json:"-"             example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
-	ReceiverId  uuid.UUID           `json:"-"             example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	ChatId      uuid.UUID           `json:"chat_id"       example:"27b79b15-a56f-464a-90f7-bab515ba4c02" gorm:"column:conversation_id"`
+	SenderId    uuid.UUID           `json:"sender_id"     example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
 	Content     string              `json:"content"       example:"hello, world"`
-	ReadAt      *time.Time          `json:"read_at"       example:"2024-02-22T03:06:53.313735Z"`
 	SentAt      time.Time           `json:"sent_at"       example:"2024-02-22T03:06:53.313735Z"`
+	EditedAt    *time.Time          `json:"edited_at"     example:"2024-02-22T03:06:53.313735Z"`
+	DeletedAt   *time.Time          `json:"deleted_at"    example:"2024-02-22T03:06:53.313735Z"`
 	Author      bool                `json:"author"        example:"true"                                 gorm:"-"`
 	Tag         string              `json:"-"             gorm:"-"`
+	BlockedBy   []uuid.UUID         `json:"-"             gorm:"-"`
 	Attatchment MessageAttatchments `json:"attatchment"   gorm:"embedded"`
+	Reactions   []MessageReactions  `json:"reactions"     gorm:"-"`
+	Files       []MessageFiles      `json:"files"         gorm:"-"`
+	Receipts    []MessageReceipts   `json:"receipts"      gorm:"-"`
+	Action      *MessageActions     `json:"action,omitempty" gorm:"-"`
 }
 
 type MessageAttatchments struct {
@@ -53,9 +64,173 @@ func (e *Messages) ToOutBound() *OutBoundMessages {
 	}
 }
 
+// MessageActions turns a message into a card the recipient can respond to.
+// ActorId is the user whose response is awaited.
+type MessageActions struct {
+	MessageId       uuid.UUID                 `json:"-"                          example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	ActionType      enums.MessageActionTypes  `json:"action_type"                example:"APPOINTMENT_REQUEST"`
+	Status          enums.MessageActionStatus `json:"status"                     example:"PENDING"`
+	ActorId         uuid.UUID                 `json:"actor_id"                   example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	AppointmentDate *time.Time                `json:"appointment_date,omitempty" example:"2024-02-18T11:00:00Z"`
+	Amount          *money.Amount             `json:"amount,omitempty"           example:"12000" swaggertype:"number"`
+	Installment     *int                      `json:"installment,omitempty"      example:"3"`
+	Note            *string                   `json:"note,omitempty"             example:"I am not available that day"`
+	UpdatedAt       time.Time                 `json:"updated_at"                 example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (a MessageActions) TableName() string {
+	return "message_actions"
+}
+
+type RespondingActions struct {
+	MessageId       uuid.UUID
+	UserId          uuid.UUID
+	Response        enums.MessageInboundEvents
+	Reason          string
+	AppointmentDate *time.Time
+}
+
+// ActionResponses is what responding to an action changes besides the action
+// itself. Only the fields of the action's appointment or agreement are set.
+type ActionResponses struct {
+	AppointmentId     *uuid.UUID
+	AppointmentStatus *UpdatingAppointmentStatus
+	Rescheduling      *ReschedulingAppointments
+	AgreementId       *uuid.UUID
+	AgreementStatus   *UpdatingAgreementStatus
+}
+
+type ActionEvents struct {
+	ChatId      uuid.UUID           `json:"chat_id"`
+	MessageId   uuid.UUID           `json:"message_id"`
+	Attatchment MessageAttatchments `json:"attatchment"`
+	Action      MessageActions      `json:"action"`
+}
+
+func (e *ActionEvents) ToOutBound() *OutBoundMessages {
+	tmp := *e
+	return &OutBoundMessages{
+		Event:   enums.OUTBOUND_ACTION,
+		Payload: tmp,
+	}
+}
+
+// PaymentRequests asks the dweller of an agreement to pay Amount. Requests for
+// rent name their Installment and are kept to one open card per installment.
+type PaymentRequests struct {
+	AgreementId   uuid.UUID
+	PropertyId    uuid.UUID
+	OwnerUserId   uuid.UUID
+	DwellerUserId uuid.UUID
+	Amount        money.Amount
+	Installment   *int
+}
+
+// PaymentNotifications tells the other side of an agreement about one of its
+// payments.
+type PaymentNotifications struct {
+	PaymentId   uuid.UUID
+	AgreementId uuid.UUID
+	PropertyId  uuid.UUID
+}
+
+type MessageFiles struct {
+	FileId       uuid.UUID `json:"file_id"                 example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	MessageId    uuid.UUID `json:"-"                       example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	FileName     string    `json:"file_name"               example:"payslip.pdf"`
+	ContentType  string    `json:"content_type"            example:"application/pdf"`
+	Size         int64     `json:"size"                    example:"102400"`
+	FileKey      string    `json:"-"`
+	ThumbnailKey *string   `json:"-"`
+	Url          string    `json:"url"                     example:"/api/v1/chats/files/27b79b15-a56f-464a-90f7-bab515ba4c02" gorm:"-"`
+	ThumbnailUrl *string   `json:"thumbnail_url,omitempty" example:"/api/v1/chats/files/27b79b15-a56f-464a-90f7-bab515ba4c02?thumbnail=true" gorm:"-"`
+	CreatedAt    time.Time `json:"created_at"              example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (f MessageFiles) TableName() string {
+	return "message_files"
+}
+
+// MessageReceipts tracks whether one recipient has received and read a message.
+type MessageReceipts struct {
+	MessageId   uuid.UUID  `json:"-"            example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	UserId      uuid.UUID  `json:"user_id"      example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	DeliveredAt *time.Time `json:"delivered_at" example:"2024-02-22T03:06:53.313735Z"`
+	ReadAt      *time.Time `json:"read_at"      example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (r MessageReceipts) TableName() string {
+	return "message_receipts"
+}
+
+type MessageReactions struct {
+	MessageId uuid.UUID `json:"-"          example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	UserId    uuid.UUID `json:"user_id"    example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	Emoji     string    `json:"emoji"      example:"👍"`
+	CreatedAt time.Time `json:"created_at" example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (r MessageReactions) TableName() string {
+	return "message_reactions"
+}
+
+type EditEvents struct {
+	ChatId    uuid.UUID `json:"chat_id"`
+	MessageId uuid.UUID `json:"message_id"`
+	Content   string    `json:"content"`
+	EditedAt  time.Time `json:"edited_at"`
+}
+
+func (e *EditEvents) ToOutBound() *OutBoundMessages {
+	tmp := *e
+	return &OutBoundMessages{
+		Event:   enums.OUTBOUND_EDIT,
+		Payload: tmp,
+	}
+}
+
+type DeleteEvents struct {
+	ChatId    uuid.UUID `json:"chat_id"`
+	MessageId uuid.UUID `json:"message_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func (e *DeleteEvents) ToOutBound() *OutBoundMessages {
+	tmp := *e
+	return &OutBoundMessages{
+		Event:   enums.OUTBOUND_DELETE,
+		Payload: tmp,
+	}
+}
+
+type ReactionEvents struct {
+	ChatId    uuid.UUID `json:"chat_id"`
+	MessageId uuid.UUID `json:"message_id"`
+	UserId    uuid.UUID `json:"user_id"`
+	Emoji     string    `json:"emoji"`
+	Removed   bool      `json:"-"`
+}
+
+func (e *ReactionEvents) ToOutBound() *OutBoundMessages {
+	tmp := *e
+	event := enums.OUTBOUND_REACT
+	if e.Removed {
+		event = enums.OUTBOUND_UNREACT
+	}
+
+	return &OutBoundMessages{
+		Event:   event,
+		Payload: tmp,
+	}
+}
+
+// ReadEvents tells the other participants that UserId has read every message
+// up to and including MessageId.
 type ReadEvents struct {
-	ChatId uuid.UUID `json:"chat_id"`
-	ReadAt time.Time `json:"read_at"`
+	ChatId    uuid.UUID `json:"chat_id"`
+	UserId    uuid.UUID `json:"user_id"`
+	MessageId uuid.UUID `json:"message_id"`
+	ReadAt    time.Time `json:"read_at"`
 }
 
 func (e *ReadEvents) ToOutBound() *OutBoundMessages {
@@ -66,13 +241,59 @@ func (e *ReadEvents) ToOutBound() *OutBoundMessages {
 	}
 }
 
+type DeliveryEvents struct {
+	ChatId      uuid.UUID `json:"chat_id"`
+	UserId      uuid.UUID `json:"user_id"`
+	MessageId   uuid.UUID `json:"message_id"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+func (e *DeliveryEvents) ToOutBound() *OutBoundMessages {
+	tmp := *e
+	return &OutBoundMessages{
+		Event:   enums.OUTBOUND_DELIVERED,
+		Payload: tmp,
+	}
+}
+
+type UnreadChats struct {
+	ChatId         uuid.UUID `json:"chat_id"         example:"123e4567-e89b-12d3-a456-426614174000"`
+	UnreadMessages int64     `json:"unread_messages" example:"9"`
+	Muted          bool      `json:"muted"           example:"false"`
+}
+
+// UnreadSummaries is the unread state of every chat of a user. Total leaves
+// out muted chats so it can be used as the app badge.
+type UnreadSummaries struct {
+	Total int64         `json:"total" example:"9"`
+	Chats []UnreadChats `json:"chats"`
+}
+
+func (e *UnreadSummaries) ToOutBound() *OutBoundMessages {
+	tmp := *e
+	return &OutBoundMessages{
+		Event:   enums.OUTBOUND_UNREAD,
+		Payload: tmp,
+	}
+}
+
+// ChatPreviews describes a chat from the current user's point of view. The
+// user fields are the other participant of a one-to-one chat and are empty for
+// group chats.
 type ChatPreviews struct {
-	UserId          uuid.UUID `json:"user_id"           example:"123e4567-e89b-12d3-a456-426614174000"`
-	ProfileImageUrl string    `json:"profile_image_url" example:"www.image.com/profile"`
-	FirstName       string    `json:"first_name"        example:"John"`
-	LastName        string    `json:"last_name"         example:"Doe"`
-	UnreadMessages  int64     `json:"unread_messages"   example:"9"`
-	Content         string    `json:"content"           example:"hello, world"`
+	ChatId           uuid.UUID  `json:"chat_id"            example:"123e4567-e89b-12d3-a456-426614174000"`
+	IsGroup          bool       `json:"is_group"           example:"false"`
+	Name             *string    `json:"name"               example:"Flatmates"`
+	UserId           uuid.UUID  `json:"user_id"            example:"123e4567-e89b-12d3-a456-426614174000"`
+	ProfileImageUrl  string     `json:"profile_image_url"  example:"www.image.com/profile"`
+	FirstName        string     `json:"first_name"         example:"John"`
+	LastName         string     `json:"last_name"          example:"Doe"`
+	PropertyId       *uuid.UUID `json:"property_id"        example:"123e4567-e89b-12d3-a456-426614174000"`
+	PropertyName     *string    `json:"property_name"      example:"Supalai"`
+	PropertyImageUrl *string    `json:"property_image_url" example:"www.image.com/property"`
+	UnreadMessages   int64      `json:"unread_messages"    example:"9"`
+	Content          string     `json:"content"            example:"hello, world"`
+	Muted            bool       `json:"muted"              example:"false"`
 }
 
 type OKResponses struct{}
@@ -82,3 +303,75 @@ func (e *OKResponses) ToOutBound() *OutBoundMessages {
 		Event: enums.OUTBOUND_OK,
 	}
 }
+
+type MessageCursors struct {
+	SentAt    time.Time
+	MessageId uuid.UUID
+}
+
+type GettingChatHistories struct {
+	UserId uuid.UUID
+	ChatId uuid.UUID
+	Before string
+	After  string
+	Around string
+	Limit  int
+}
+
+type ChatHistories struct {
+	Messages      []Messages `json:"messages"`
+	HasMoreBefore bool       `json:"has_more_before" example:"true"`
+	HasMoreAfter  bool       `json:"has_more_after"  example:"false"`
+	BeforeCursor  string     `json:"before_cursor,omitempty" example:"MjAyNC0wMi0yMlQwMzowNjo1My4zMTM3MzVafDI3Yjc5YjE1LWE1NmYtNDY0YS05MGY3LWJhYjUxNWJhNGMwMg"`
+	AfterCursor   string     `json:"after_cursor,omitempty"  example:"MjAyNC0wMi0yMlQwMzowNjo1My4zMTM3MzVafDI3Yjc5YjE1LWE1NmYtNDY0YS05MGY3LWJhYjUxNWJhNGMwMg"`
+}
+
+type SearchingMessages struct {
+	UserId uuid.UUID
+	Query  string
+	Cursor *MessageCursors
+	Limit  int
+}
+
+type ChatSearchRows struct {
+	Messages
+	ChatPropertyId         *uuid.UUID
+	ChatPropertyName       *string
+	ChatIsGroup            bool
+	ChatName               *string
+	PartnerId              uuid.UUID
+	PartnerFirstName       string
+	PartnerLastName        string
+	PartnerProfileImageUrl string
+}
+
+// SearchContextRows is a message next to the search hit HitId.
+type SearchContextRows struct {
+	Messages
+	HitId    uuid.UUID
+	IsBefore bool
+}
+
+type ChatSearchResults struct {
+	Hits       []ChatSearchHits `json:"hits"`
+	NextCursor string           `json:"next_cursor,omitempty" example:"MjAyNC0wMi0yMlQwMzowNjo1My4zMTM3MzVafDI3Yjc5YjE1LWE1NmYtNDY0YS05MGY3LWJhYjUxNWJhNGMwMg"`
+}
+
+type ChatSearchHits struct {
+	Chat    ChatSearchPreviews `json:"chat"`
+	Message Messages           `json:"message"`
+	Before  []Messages         `json:"before"`
+	After   []Messages         `json:"after"`
+}
+
+type ChatSearchPreviews struct {
+	ChatId          uuid.UUID  `json:"chat_id"           example:"123e4567-e89b-12d3-a456-426614174000"`
+	IsGroup         bool       `json:"is_group"          example:"false"`
+	Name            *string    `json:"name"              example:"Flatmates"`
+	UserId          uuid.UUID  `json:"user_id"           example:"123e4567-e89b-12d3-a456-426614174000"`
+	ProfileImageUrl string     `json:"profile_image_url" example:"www.image.com/profile"`
+	FirstName       string     `json:"first_name"        example:"John"`
+	LastName        string     `json:"last_name"         example:"Doe"`
+	PropertyId      *uuid.UUID `json:"property_id"       example:"123e4567-e89b-12d3-a456-426614174000"`
+	PropertyName    *string    `json:"property_name"     example:"Supalai"`
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"time"
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+)
+
+type PushSubscriptions struct {
+	SubscriptionId uuid.UUID `json:"subscription_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	UserId         uuid.UUID `json:"-"`
+	Endpoint       string    `json:"endpoint"        example:"https://fcm.googleapis.com/fcm/send/abc123"`
+	P256dh         string    `json:"-"`
+	Auth           string    `json:"-"`
+	UserAgent      *string   `json:"user_agent"      example:"Mozilla/5.0"`
+	CreatedAt      time.Time `json:"created_at"      example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (p PushSubscriptions) TableName() string {
+	return "push_subscriptions"
+}
+
+// CreatingPushSubscriptions mirrors PushSubscription.toJSON() from the browser.
+type CreatingPushSubscriptions struct {
+	Endpoint string `json:"endpoint" example:"https://fcm.googleapis.com/fcm/send/abc123"`
+	Keys     struct {
+		P256dh string `json:"p256dh" example:"BNcRdreALRFXTkOOUHK1EtK2wtaz5Ry4YfYCA_0QTpQtUbVlUls0VJXg7A8u-Ts1XbjhazAkj7I99e8QcYP7DkM"`
+		Auth   string `json:"auth"   example:"tBHItJI5svbpez7KI4CCXg"`
+	} `json:"keys"`
+}
+
+type DeletingPushSubscriptions struct {
+	Endpoint string `json:"endpoint" example:"https://fcm.googleapis.com/fcm/send/abc123"`
+}
+
+type VapidKeys struct {
+	PublicKey string `json:"public_key" example:"BNcRdreALRFXTkOOUHK1EtK2wtaz5Ry4YfYCA_0QTpQtUbVlUls0VJXg7A8u-Ts1XbjhazAkj7I99e8QcYP7DkM"`
+}
+
+// QuietHours is a daily window, in the user's timezone, during which no
+// notification is sent. The window may wrap around midnight.
+type QuietHours struct {
+	QuietHoursStart *string `json:"quiet_hours_start" example:"22:00"`
+	QuietHoursEnd   *string `json:"quiet_hours_end"   example:"07:00"`
+	Timezone        string  `json:"timezone"          example:"Asia/Bangkok"`
+}
+
+func (q QuietHours) Contains(t time.Time) bool {
+	if q.QuietHoursStart == nil || q.QuietHoursEnd == nil {
+		return false
+	}
+
+	start, err := time.Parse("15:04", *q.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+
+	end, err := time.Parse("15:04", *q.QuietHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+
+	if from <= to {
+		return from <= now && now < to
+	}
+
+	return now >= from || now < to
+}
+
+type NotificationPreferences struct {
+	UserId             uuid.UUID `json:"-"`
+	WebPush            bool      `json:"web_push"             example:"true"`
+	EmailDigest        bool      `json:"email_digest"         example:"true"`
+	DigestDelayMinutes int       `json:"digest_delay_minutes" example:"15"`
+	QuietHours
+	UpdatedAt time.Time `json:"updated_at" example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (p NotificationPreferences) TableName() string {
+	return "notification_preferences"
+}
+
+// PushTargets is a push subscription of a recipient who wants web push for the
+// chat, together with the quiet hours of its owner.
+type PushTargets struct {
+	PushSubscriptions
+	QuietHours
+}
+
+type PushPayloads struct {
+	ChatId    uuid.UUID `json:"chat_id"`
+	MessageId uuid.UUID `json:"message_id"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Url       string    `json:"url"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// DigestMessages is an unread message that has waited longer than its
+// recipient's digest delay and has not been emailed yet.
+type DigestMessages struct {
+	UserId          uuid.UUID
+	Email           string
+	FirstName       string
+	ChatId          uuid.UUID
+	MessageId       uuid.UUID
+	SenderFirstName string
+	SenderLastName  string
+	Content         string
+	SentAt          time.Time
+	QuietHours
+}
@@ -4,20 +4,135 @@ import (
 	"time"
 
 	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
 	"github.com/google/uuid"
 )
 
 type Payments struct {
-	PaymentId     uuid.UUID            `json:"payment_id" `
-	UserId        uuid.UUID            `json:"user_id" `
-	Price         float64              `json:"price" `
-	IsSuccess     bool                 `json:"is_success"`
-	Name          string               `json:"name"`
-	AgreementId   uuid.UUID            `json:"agreement_id" `
-	PaymentMethod enums.PaymentMethods `json:"payment_method" `
+	PaymentId         uuid.UUID            `json:"payment_id" `
+	UserId            uuid.UUID            `json:"user_id" `
+	Price             money.Amount         `json:"price"  swaggertype:"number"`
+	IsSuccess         bool                 `json:"is_success"`
+	Name              string               `json:"name"`
+	AgreementId       uuid.UUID            `json:"agreement_id" `
+	PaymentMethod     enums.PaymentMethods `json:"payment_method" `
+	PaymentType       enums.PaymentTypes   `json:"payment_type"`
+	Installment       *int                 `json:"installment,omitempty"`
+	Status            enums.PaymentStatus  `json:"status"`
+	RefundedAmount    money.Amount         `json:"refunded_amount" swaggertype:"number"`
+	Currency          money.Currency       `json:"currency" swaggertype:"string" example:"THB"`
+	ProviderSessionId *string              `json:"-"`
+	ProviderPaymentId *string              `json:"-"`
+	CheckoutURL       *string              `json:"-"`
+	CheckoutExpiresAt *time.Time           `json:"-"`
+	IdempotencyKey    *string              `json:"-"`
 	CommonModels
 }
 
+// WebhookEvents records a handled payment provider webhook event.
+type WebhookEvents struct {
+	EventId     string `gorm:"primaryKey"`
+	EventType   string
+	ProcessedAt time.Time
+}
+
+func (e WebhookEvents) TableName() string {
+	return "webhook_events"
+}
+
+// RentInstallments is one monthly installment of a renting agreement. The
+// installment is invoiced to the dweller ahead of its due date and becomes
+// payable from then on.
+type RentInstallments struct {
+	AgreementId   uuid.UUID               `json:"-"           gorm:"primaryKey"`
+	Installment   int                     `json:"installment" gorm:"primaryKey" example:"2"`
+	DueDate       time.Time               `json:"due_date"                      example:"2024-03-01T00:00:00Z"`
+	Amount        money.Amount            `json:"amount"                        example:"15000" swaggertype:"number"`
+	InvoicedAt    *time.Time              `json:"invoiced_at"                   example:"2024-02-23T00:00:00Z"`
+	LateFee       money.Amount            `json:"late_fee"                      example:"750" swaggertype:"number"`
+	ReminderLevel int                     `json:"-"`
+	RemindedAt    *time.Time              `json:"-"`
+	PaymentId     *uuid.UUID              `json:"payment_id"  gorm:"->"         example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	PaidAt        *time.Time              `json:"paid_at"     gorm:"->"         example:"2024-02-25T10:00:00Z"`
+	Status        enums.InstallmentStatus `json:"status"      gorm:"-"          example:"UNPAID"`
+	Payable       bool                    `json:"payable"     gorm:"-"          example:"true"`
+	CreatedAt     *time.Time              `json:"-"           gorm:"autoCreateTime"`
+}
+
+func (i RentInstallments) TableName() string {
+	return "rent_installments"
+}
+
+// AmountDue is what is left to pay for the installment, late fee included.
+func (i RentInstallments) AmountDue() money.Amount {
+	return i.Amount + i.LateFee
+}
+
+// LateInstallments is an unpaid installment past its due date along with the
+// two sides of its agreement.
+type LateInstallments struct {
+	RentInstallments
+	AgreementStatus  enums.AgreementStatus
+	PaymentDuration  int
+	PropertyId       uuid.UUID
+	PropertyName     string
+	OwnerUserId      uuid.UUID
+	OwnerFirstName   string
+	OwnerEmail       string
+	DwellerUserId    uuid.UUID
+	DwellerFirstName string
+	DwellerLastName  string
+	DwellerEmail     string
+}
+
+type RentSchedules struct {
+	AgreementId       uuid.UUID          `json:"agreement_id"       example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	TotalInstallments int                `json:"total_installments" example:"12"`
+	PaidInstallments  int                `json:"paid_installments"  example:"1"`
+	Installments      []RentInstallments `json:"installments"`
+}
+
+// Refunds is money of a deposit paid back to the dweller by the owner, less
+// Deductions.
+type Refunds struct {
+	RefundId         uuid.UUID          `json:"refund_id"    gorm:"primaryKey" example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	PaymentId        uuid.UUID          `json:"payment_id"                     example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	AgreementId      uuid.UUID          `json:"agreement_id"                   example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	RequestedBy      uuid.UUID          `json:"requested_by"                   example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	Amount           money.Amount       `json:"amount"                         example:"8000" swaggertype:"number"`
+	Reason           string             `json:"reason"                         example:"Moved out on 1 Mar"`
+	Status           enums.RefundStatus `json:"status"                         example:"PENDING"`
+	ProviderRefundId *string            `json:"-"`
+	Deductions       []RefundDeductions `json:"deductions"   gorm:"foreignKey:RefundId"`
+	CreatedAt        *time.Time         `json:"created_at"   gorm:"autoCreateTime"`
+	UpdatedAt        *time.Time         `json:"-"            gorm:"autoUpdateTime"`
+}
+
+func (r Refunds) TableName() string {
+	return "refunds"
+}
+
+type RefundDeductions struct {
+	RefundId    uuid.UUID    `json:"-"`
+	Description string       `json:"description" example:"Broken window"`
+	Amount      money.Amount `json:"amount"      example:"2000" swaggertype:"number"`
+}
+
+func (d RefundDeductions) TableName() string {
+	return "refund_deductions"
+}
+
+type CreatingPayments struct {
+	AgreementId   uuid.UUID            `json:"agreement_id"   example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	PaymentMethod enums.PaymentMethods `json:"payment_method" example:"PROMPTPAY"`
+	Installment   *int                 `json:"installment"    example:"2"`
+}
+
+type CreatingRefunds struct {
+	Reason     string             `json:"reason"     example:"Moved out on 1 Mar"`
+	Deductions []RefundDeductions `json:"deductions"`
+}
+
 type MyPaymentsResponse struct {
 	Payments []Payments `json:"payments"`
 }
@@ -25,21 +140,25 @@ type MyPaymentsResponse struct {
 type HistoryResponse struct {
 	PaymentID        uuid.UUID             `json:"payment_id"`
 	UserID           uuid.UUID             `json:"user_id"`
-	Price            float64               `json:"price"`
+	Price            money.Amount          `json:"price" swaggertype:"number"`
 	IsSuccess        bool                  `json:"is_success"`
 	Name             string                `json:"name"`
 	AgreementID      uuid.UUID             `json:"agreement_id"`
 	PaymentMethod    enums.PaymentMethods  `json:"payment_method"`
+	PaymentType      enums.PaymentTypes    `json:"payment_type"`
+	PaymentStatus    enums.PaymentStatus   `json:"payment_status"`
+	RefundedAmount   money.Amount          `json:"refunded_amount" swaggertype:"number"`
+	Refunds          []Refunds             `json:"refunds" gorm:"-"`
 	AgreementType    enums.AgreementTypes  `json:"agreement_type"`
 	PropertyID       uuid.UUID             `json:"property_id"`
 	OwnerUserID      uuid.UUID             `json:"owner_user_id"`
 	DwellerUserID    uuid.UUID             `json:"dweller_user_id"`
 	AgreementDate    time.Time             `json:"agreement_date"`
 	Status           enums.AgreementStatus `json:"status"`
-	DepositAmount    float64               `json:"deposit_amount"`
-	PaymentPerMonth  float64               `json:"payment_per_month"`
+	DepositAmount    money.Amount          `json:"deposit_amount" swaggertype:"number"`
+	PaymentPerMonth  money.Amount          `json:"payment_per_month" swaggertype:"number"`
 	PaymentDuration  int                   `json:"payment_duration"`
-	TotalPayment     float64               `json:"total_payment"`
+	TotalPayment     money.Amount          `json:"total_payment" swaggertype:"number"`
 	CancelledMessage string                `json:"cancelled_message"`
 	CommonModels
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
 	"github.com/google/uuid"
 )
 
@@ -40,16 +41,16 @@ type PropertyImages struct {
 }
 
 type SellingProperties struct {
-	PropertyId   uuid.UUID `json:"-"`
-	Price        float64   `json:"price"   example:"12345.67" sortmapper:"price" filtermapper:"price"`
-	IsSold       bool      `json:"is_sold" example:"true"`
+	PropertyId   uuid.UUID    `json:"-"`
+	Price        money.Amount `json:"price"   example:"12345.67" sortmapper:"price" filtermapper:"price" swaggertype:"number"`
+	IsSold       bool         `json:"is_sold" example:"true"`
 	CommonModels `sortmapper:"-"`
 }
 
 type RentingProperties struct {
-	PropertyId    uuid.UUID `json:"-"`
-	PricePerMonth float64   `json:"price_per_month" example:"12345.67" sortmapper:"price_per_month" filtermapper:"price_per_month"`
-	IsOccupied    bool      `json:"is_occupied"     example:"true"`
+	PropertyId    uuid.UUID    `json:"-"`
+	PricePerMonth money.Amount `json:"price_per_month" example:"12345.67" sortmapper:"price_per_month" filtermapper:"price_per_month" swaggertype:"number"`
+	IsOccupied    bool         `json:"is_occupied"     example:"true"`
 	CommonModels  `sortmapper:"-"`
 }
 
@@ -80,9 +81,9 @@ type PropertyInfos struct {
 	FloorSizeUnit       enums.FloorSizeUnits `json:"floor_size_unit" form:"floor_size_unit" gorm:"default:SQM" validate:"fs_unit"           example:"SQM"`
 	UnitNumber          int64                `json:"unit_number" form:"unit_number"             validate:"number"                           example:"123"`
 	ImageUrls           []string             `json:"image_urls" form:"image_urls"               example:"https://image_url.com/abcd,https://image_url.com/abcd,https://image_url.com/abcd"`
-	Price               float64              `json:"price" form:"price"                         validate:"number"                           example:"12345.67"`
+	Price               money.Amount         `json:"price" form:"price"                         validate:"number"                           example:"12345.67" swaggertype:"number"`
 	IsSold              bool                 `json:"is_sold" form:"is_sold"                     gorm:"default:false"                        example:"true"`
-	PricePerMonth       float64              `json:"price_per_month" form:"price_per_month"     validate:"number"                           example:"12345.67"`
+	PricePerMonth       money.Amount         `json:"price_per_month" form:"price_per_month"     validate:"number"                           example:"12345.67" swaggertype:"number"`
 	IsOccupied          bool                 `json:"is_occupied" form:"is_occupied"             gorm:"default:false"                        example:"false"`
 }
 
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
+	"github.com/google/uuid"
+)
+
+// Receipts is the receipt of a paid payment. Its number is given by the
+// database so that receipts are numbered without gaps.
+type Receipts struct {
+	PaymentId     uuid.UUID `gorm:"primaryKey"`
+	ReceiptNumber int64     `gorm:"->"`
+	Amount        money.Amount
+	VatPercent    float64
+	VatAmount     money.Amount
+	FileKey       *string
+	EmailedAt     *time.Time
+	IssuedAt      *time.Time `gorm:"autoCreateTime"`
+}
+
+func (r Receipts) TableName() string {
+	return "receipts"
+}
+
+// Number is the receipt number printed on the receipt.
+func (r Receipts) Number() string {
+	return fmt.Sprintf("RC-%08d", r.ReceiptNumber)
+}
+
+// EmailAttachments is a file attached to an email.
+type EmailAttachments struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
+	"github.com/google/uuid"
+)
+
+// AppointmentEvents is pushed to the owner and the dweller whenever the
+// status or the date of their appointment changes.
+type AppointmentEvents struct {
+	AppointmentId   uuid.UUID               `json:"appointment_id"   example:"123e4567-e89b-12d3-a456-426614174000"`
+	PropertyId      uuid.UUID               `json:"property_id"      example:"123e4567-e89b-12d3-a456-426614174000"`
+	Status          enums.AppointmentStatus `json:"status"           example:"CONFIRMED"`
+	AppointmentDate time.Time               `json:"appointment_date" example:"2024-02-18T11:00:00Z"`
+	UpdatedAt       time.Time               `json:"updated_at"       example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (e *AppointmentEvents) ToOutBound() *OutBoundMessages {
+	tmp := *e
+	return &OutBoundMessages{
+		Event:   enums.OUTBOUND_APPOINTMENT_UPDATED,
+		Payload: tmp,
+	}
+}
+
+// AgreementEvents is pushed to the owner and the dweller whenever the status
+// of their agreement changes.
+type AgreementEvents struct {
+	AgreementId uuid.UUID             `json:"agreement_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	PropertyId  uuid.UUID             `json:"property_id"  example:"123e4567-e89b-12d3-a456-426614174000"`
+	Status      enums.AgreementStatus `json:"status"       example:"AWAITING_PAYMENT"`
+	UpdatedAt   time.Time             `json:"updated_at"   example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (e *AgreementEvents) ToOutBound() *OutBoundMessages {
+	tmp := *e
+	return &OutBoundMessages{
+		Event:   enums.OUTBOUND_AGREEMENT_UPDATED,
+		Payload: tmp,
+	}
+}
+
+// PaymentEvents is pushed to the payer and the owner once a payment goes
+// through.
+type PaymentEvents struct {
+	PaymentId   uuid.UUID    `json:"payment_id"   example:"123e4567-e89b-12d3-a456-426614174000"`
+	AgreementId uuid.UUID    `json:"agreement_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	UserId      uuid.UUID    `json:"user_id"      example:"123e4567-e89b-12d3-a456-426614174000"`
+	Name        string       `json:"name"         example:"Deposit"`
+	Price       money.Amount `json:"price"        example:"12000" swaggertype:"number"`
+	PaidAt      time.Time    `json:"paid_at"      example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (e *PaymentEvents) ToOutBound() *OutBoundMessages {
+	tmp := *e
+	return &OutBoundMessages{
+		Event:   enums.OUTBOUND_PAYMENT_SUCCEEDED,
+		Payload: tmp,
+	}
+}
+
+// PaymentUpdateEvents is pushed to the payer and the owner when a payment
+// fails, expires or is refunded.
+type PaymentUpdateEvents struct {
+	PaymentId      uuid.UUID           `json:"payment_id"      example:"123e4567-e89b-12d3-a456-426614174000"`
+	AgreementId    uuid.UUID           `json:"agreement_id"    example:"123e4567-e89b-12d3-a456-426614174000"`
+	UserId         uuid.UUID           `json:"user_id"         example:"123e4567-e89b-12d3-a456-426614174000"`
+	Name           string              `json:"name"            example:"Deposit"`
+	Price          money.Amount        `json:"price"           example:"12000" swaggertype:"number"`
+	Status         enums.PaymentStatus `json:"status"          example:"REFUNDED"`
+	RefundedAmount money.Amount        `json:"refunded_amount" example:"12000" swaggertype:"number"`
+	UpdatedAt      time.Time           `json:"updated_at"      example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (e *PaymentUpdateEvents) ToOutBound() *OutBoundMessages {
+	tmp := *e
+	return &OutBoundMessages{
+		Event:   enums.OUTBOUND_PAYMENT_UPDATED,
+		Payload: tmp,
+	}
+}
+
+// PriceDropEvents is pushed to users who have favorited a property when its
+// selling price or monthly rent goes down. Fields of the price that did not
+// drop are left out.
+type PriceDropEvents struct {
+	PropertyId       uuid.UUID     `json:"property_id"                      example:"123e4567-e89b-12d3-a456-426614174000"`
+	PropertyName     string        `json:"property_name"                    example:"Supalai"`
+	OldPrice         *money.Amount `json:"old_price,omitempty"              example:"3500000" swaggertype:"number"`
+	NewPrice         *money.Amount `json:"new_price,omitempty"              example:"3200000" swaggertype:"number"`
+	OldPricePerMonth *money.Amount `json:"old_price_per_month,omitempty"    example:"15000" swaggertype:"number"`
+	NewPricePerMonth *money.Amount `json:"new_price_per_month,omitempty"    example:"13500" swaggertype:"number"`
+}
+
+func (e *PriceDropEvents) ToOutBound() *OutBoundMessages {
+	tmp := *e
+	return &OutBoundMessages{
+		Event:   enums.OUTBOUND_FAVORITE_PRICE_DROP,
+		Payload: tmp,
+	}
+}
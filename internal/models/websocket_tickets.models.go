@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// WebsocketTickets let clients that can not send the session cookie, such as
+// native apps and frontends on another domain, open the chat websocket. A
+// ticket can be used once, before ExpiresAt, from the origin it was issued to.
+type WebsocketTickets struct {
+	Ticket    string         `json:"ticket"     example:"q3Xz2f0bR1m7bT8sV5yJkA9cW4eH6nLpU2dG0iO1tYs"`
+	ExpiresAt time.Time      `json:"expires_at" example:"2024-02-22T03:07:23.313735Z"`
+	Origin    string         `json:"-"`
+	Claims    *SessionClaims `json:"-"`
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/google/uuid"
+)
+
+// Chat websocket subprotocols. Clients pick one with the
+// Sec-WebSocket-Protocol header; connections that do not ask for any speak
+// v1, the flat InBoundMessages format, which is kept for old apps only.
+const (
+	ChatProtocolV1 = "suechaokhai.chat.v1"
+	ChatProtocolV2 = "suechaokhai.chat.v2"
+
+	ChatProtocolVersion = 2
+)
+
+// ChatProtocols lists the supported subprotocols, most preferred first.
+var ChatProtocols = []string{ChatProtocolV2, ChatProtocolV1}
+
+// InBoundEnvelopes wraps every v2 frame sent by a client. Tag is echoed back
+// on the response to the frame, including ERROR frames.
+type InBoundEnvelopes struct {
+	Version int                        `json:"v"       validate:"eq=2"        example:"2"`
+	Event   enums.MessageInboundEvents `json:"event"   validate:"required"    example:"MSG"`
+	Tag     string                     `json:"tag"     validate:"max=64"      example:"4f1c"`
+	Payload json.RawMessage            `json:"payload"`
+}
+
+// InBoundPayloads is the payload of one v2 inbound event. ToInBound copies it
+// into the flat message the chat handlers work with.
+type InBoundPayloads interface {
+	ToInBound(*InBoundMessages)
+}
+
+// InBoundPayloadTypes maps every v2 inbound event to a constructor for its
+// payload. Events missing here are rejected with unknown-event.
+var InBoundPayloadTypes = map[enums.MessageInboundEvents]func() InBoundPayloads{
+	enums.INBOUND_MSG:        func() InBoundPayloads { return &SendingMessagePayloads{} },
+	enums.INBOUND_JOIN:       func() InBoundPayloads { return &JoiningChatPayloads{} },
+	enums.INBOUND_LEFT:       func() InBoundPayloads { return &LeavingChatPayloads{} },
+	enums.INBOUND_EDIT:       func() InBoundPayloads { return &EditingMessagePayloads{} },
+	enums.INBOUND_DELETE:     func() InBoundPayloads { return &DeletingMessagePayloads{} },
+	enums.INBOUND_REACT:      func() InBoundPayloads { return &ReactingMessagePayloads{} },
+	enums.INBOUND_UNREACT:    func() InBoundPayloads { return &ReactingMessagePayloads{} },
+	enums.INBOUND_READ:       func() InBoundPayloads { return &ReadingMessagePayloads{} },
+	enums.INBOUND_ACCEPT:     func() InBoundPayloads { return &RespondingActionPayloads{} },
+	enums.INBOUND_REJECT:     func() InBoundPayloads { return &RespondingActionPayloads{} },
+	enums.INBOUND_RESCHEDULE: func() InBoundPayloads { return &ReschedulingActionPayloads{} },
+}
+
+// OutBoundPayloadTypes describes the payload of every outbound event. It is
+// only used to generate the protocol spec.
+var OutBoundPayloadTypes = map[enums.MessageOutboundEvents]interface{}{
+	enums.OUTBOUND_MSG:       Messages{},
+	enums.OUTBOUND_READ:      ReadEvents{},
+	enums.OUTBOUND_OK:        nil,
+	enums.OUTBOUND_EDIT:      EditEvents{},
+	enums.OUTBOUND_DELETE:    DeleteEvents{},
+	enums.OUTBOUND_REACT:     ReactionEvents{},
+	enums.OUTBOUND_UNREACT:   ReactionEvents{},
+	enums.OUTBOUND_MEMBERS:   MemberEvents{},
+	enums.OUTBOUND_DELIVERED: DeliveryEvents{},
+	enums.OUTBOUND_UNREAD:    UnreadSummaries{},
+	enums.OUTBOUND_ACTION:    ActionEvents{},
+	enums.OUTBOUND_ERROR:     ErrorResponses{},
+
+	enums.OUTBOUND_APPOINTMENT_UPDATED: AppointmentEvents{},
+	enums.OUTBOUND_AGREEMENT_UPDATED:   AgreementEvents{},
+	enums.OUTBOUND_PAYMENT_SUCCEEDED:   PaymentEvents{},
+	enums.OUTBOUND_PAYMENT_UPDATED:     PaymentUpdateEvents{},
+	enums.OUTBOUND_FAVORITE_PRICE_DROP: PriceDropEvents{},
+}
+
+// SendingMessagePayloads is a new chat message. SentAt is accepted from older
+// clients but ignored, messages are timestamped by the server.
+type SendingMessagePayloads struct {
+	Content string    `json:"content" validate:"required,max=4096" example:"hello, world"`
+	SentAt  time.Time `json:"sent_at"                              example:"2024-02-22T03:06:53.313735Z"`
+}
+
+func (p *SendingMessagePayloads) ToInBound(msg *InBoundMessages) {
+	msg.Content = p.Content
+	msg.SentAt = p.SentAt
+}
+
+type JoiningChatPayloads struct {
+	ChatId     uuid.UUID  `json:"chat_id"     validate:"required" example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	PropertyId *uuid.UUID `json:"property_id"                     example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+}
+
+func (p *JoiningChatPayloads) ToInBound(msg *InBoundMessages) {
+	msg.Content = p.ChatId.String()
+	msg.Attatchment.PropertyId = p.PropertyId
+}
+
+type LeavingChatPayloads struct{}
+
+func (p *LeavingChatPayloads) ToInBound(msg *InBoundMessages) {}
+
+type EditingMessagePayloads struct {
+	MessageId uuid.UUID `json:"message_id" validate:"required"          example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	Content   string    `json:"content"    validate:"required,max=4096" example:"hello, world"`
+}
+
+func (p *EditingMessagePayloads) ToInBound(msg *InBoundMessages) {
+	msg.MessageId = p.MessageId
+	msg.Content = p.Content
+}
+
+type DeletingMessagePayloads struct {
+	MessageId uuid.UUID `json:"message_id" validate:"required" example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+}
+
+func (p *DeletingMessagePayloads) ToInBound(msg *InBoundMessages) {
+	msg.MessageId = p.MessageId
+}
+
+type ReactingMessagePayloads struct {
+	MessageId uuid.UUID `json:"message_id" validate:"required"        example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	Emoji     string    `json:"emoji"      validate:"required,max=32" example:"👍"`
+}
+
+func (p *ReactingMessagePayloads) ToInBound(msg *InBoundMessages) {
+	msg.MessageId = p.MessageId
+	msg.Content = p.Emoji
+}
+
+type ReadingMessagePayloads struct {
+	MessageId uuid.UUID `json:"message_id" validate:"required" example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+}
+
+func (p *ReadingMessagePayloads) ToInBound(msg *InBoundMessages) {
+	msg.MessageId = p.MessageId
+}
+
+type RespondingActionPayloads struct {
+	MessageId uuid.UUID `json:"message_id" validate:"required" example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	Reason    string    `json:"reason"     validate:"max=500"  example:"I am not available that day"`
+}
+
+func (p *RespondingActionPayloads) ToInBound(msg *InBoundMessages) {
+	msg.MessageId = p.MessageId
+	msg.Content = p.Reason
+}
+
+type ReschedulingActionPayloads struct {
+	MessageId       uuid.UUID `json:"message_id"       validate:"required" example:"27b79b15-a56f-464a-90f7-bab515ba4c02"`
+	AppointmentDate time.Time `json:"appointment_date" validate:"required" example:"2024-02-18T11:00:00Z"`
+	Reason          string    `json:"reason"           validate:"max=500"  example:"Can we make it later?"`
+}
+
+func (p *ReschedulingActionPayloads) ToInBound(msg *InBoundMessages) {
+	msg.MessageId = p.MessageId
+	msg.AppointmentDate = &p.AppointmentDate
+	msg.Content = p.Reason
+}
@@ -0,0 +1,183 @@
+// Package money holds amounts of money as whole numbers of the minor unit of
+// their currency, satang for baht, so that adding them up never drifts.
+//
+// Amounts cross the API as decimal numbers of baht with at most two decimals,
+// and are stored in BIGINT columns as satang. Anything finer than a satang,
+// whether typed in or computed as a share of an amount, is rounded half away
+// from zero.
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code.
+type Currency string
+
+const (
+	THB Currency = "THB"
+)
+
+// DefaultCurrency is the currency amounts are in unless stated otherwise.
+const DefaultCurrency = THB
+
+// minorUnits is how many satang make a baht.
+const minorUnits = 100
+
+// Amount is an amount of money in satang.
+type Amount int64
+
+var ErrInvalidAmount = errors.New("amount must be a decimal number of baht")
+
+// FromBaht converts a number of baht, such as a configured threshold, to an
+// Amount.
+func FromBaht(baht float64) Amount {
+	return Amount(math.Round(baht * minorUnits))
+}
+
+// Parse reads a decimal number of baht, such as "1234.5".
+func Parse(text string) (Amount, error) {
+	text = strings.TrimSpace(text)
+	sign := int64(1)
+	if strings.HasPrefix(text, "-") {
+		sign, text = -1, text[1:]
+	} else {
+		text = strings.TrimPrefix(text, "+")
+	}
+
+	whole, decimals, _ := strings.Cut(text, ".")
+	if whole == "" && decimals == "" || strings.ContainsAny(whole+decimals, "+-eE") {
+		return 0, ErrInvalidAmount
+	}
+
+	baht := int64(0)
+	if whole != "" {
+		var err error
+		baht, err = strconv.ParseInt(whole, 10, 64)
+		if err != nil || baht > math.MaxInt64/minorUnits-1 {
+			return 0, ErrInvalidAmount
+		}
+	}
+
+	satang := int64(0)
+	if decimals != "" {
+		for _, digit := range decimals {
+			if digit < '0' || digit > '9' {
+				return 0, ErrInvalidAmount
+			}
+		}
+
+		padded := (decimals + "00")[:2]
+		satang, _ = strconv.ParseInt(padded, 10, 64)
+		if len(decimals) > 2 && decimals[2] >= '5' {
+			satang++
+		}
+	}
+
+	return Amount(sign * (baht*minorUnits + satang)), nil
+}
+
+// Percent is percent of the amount, rounded to the satang.
+func (a Amount) Percent(percent float64) Amount {
+	return Amount(math.Round(float64(a) * percent / 100))
+}
+
+// Included is the part of the amount that is a percent surcharge already
+// added to it, such as the VAT in a price that includes VAT.
+func (a Amount) Included(percent float64) Amount {
+	return Amount(math.Round(float64(a) * percent / (100 + percent)))
+}
+
+// Decimal writes the amount in baht with two decimals, such as "1234.50".
+func (a Amount) Decimal() string {
+	sign := ""
+	satang := int64(a)
+	if satang < 0 {
+		sign, satang = "-", -satang
+	}
+
+	return fmt.Sprintf("%v%d.%02d", sign, satang/minorUnits, satang%minorUnits)
+}
+
+// String writes the amount in baht with thousands separators, such as
+// "1,234.50".
+func (a Amount) String() string {
+	text := a.Decimal()
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+
+	whole, decimals, _ := strings.Cut(text, ".")
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+
+	return sign + whole + "." + decimals
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.Decimal()), nil
+}
+
+// UnmarshalJSON reads a number of baht, or a string holding one.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	return a.UnmarshalText(bytes.Trim(data, `"`))
+}
+
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.Decimal()), nil
+}
+
+// UnmarshalText reads a number of baht from a form or query value.
+func (a *Amount) UnmarshalText(text []byte) error {
+	amount, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = amount
+	return nil
+}
+
+func (a Amount) Value() (driver.Value, error) {
+	return int64(a), nil
+}
+
+func (a *Amount) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = 0
+	case int64:
+		*a = Amount(v)
+	case []byte:
+		return a.scanText(string(v))
+	case string:
+		return a.scanText(v)
+	default:
+		return fmt.Errorf("cannot scan %T into an amount", value)
+	}
+
+	return nil
+}
+
+// scanText reads satang written out by the database, as it does for SUM.
+func (a *Amount) scanText(text string) error {
+	satang, err := strconv.ParseInt(strings.TrimSuffix(text, ".0"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into an amount", text)
+	}
+
+	*a = Amount(satang)
+	return nil
+}
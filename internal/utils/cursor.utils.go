@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/brain-flowing-company/pprp-backend/internal/models"
+	"github.com/google/uuid"
+)
+
+func EncodeMessageCursor(cursor *models.MessageCursors) string {
+	raw := cursor.SentAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.MessageId.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeMessageCursor(cursor *models.MessageCursors, encoded string) error {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+
+	sentAt, messageId, found := strings.Cut(string(raw), "|")
+	if !found {
+		return errors.New("malformed cursor")
+	}
+
+	cursor.SentAt, err = time.Parse(time.RFC3339Nano, sentAt)
+	if err != nil {
+		return err
+	}
+
+	cursor.MessageId, err = uuid.Parse(messageId)
+	return err
+}
@@ -7,15 +7,19 @@ import (
 	"strings"
 
 	"github.com/brain-flowing-company/pprp-backend/internal/enums"
+	"github.com/brain-flowing-company/pprp-backend/internal/money"
 )
 
 type FilteredQuery struct {
-	items  []string
-	mapper map[string]string
+	items   []string
+	mapper  map[string]string
+	amounts map[string]bool
 }
 
+var amountType = reflect.TypeOf(money.Amount(0))
+
 func NewFilteredQuery(model interface{}) *FilteredQuery {
-	s := &FilteredQuery{mapper: map[string]string{}}
+	s := &FilteredQuery{mapper: map[string]string{}, amounts: map[string]bool{}}
 	t := reflect.TypeOf(model)
 
 	parents := NewStack[string]()
@@ -54,6 +58,7 @@ func (s *FilteredQuery) assignMapper(parents *Stack[string], t reflect.Type) {
 		parents.Push(json)
 		key := strings.Join(parents.Seek(), ".")
 		s.mapper[key] = sortmap
+		s.amounts[key] = f.Type == amountType
 		parents.Pop()
 	}
 }
@@ -94,6 +99,17 @@ func (s *FilteredQuery) ParseQuery(query string) error {
 			return errors.New("sort direction can only be 'lte' or 'gte'")
 		}
 
+		// money is filtered in baht but stored in satang
+		if s.amounts[fld] {
+			value, err := money.Parse(filter[cl+1:])
+			if err != nil {
+				return err
+			}
+
+			s.items = append(s.items, fmt.Sprintf("%s %s %d", field, operation, int64(value)))
+			continue
+		}
+
 		var value float32
 		_, err := fmt.Sscanf(filter[cl+1:], "%f", &value)
 		if err != nil {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
 func CreateJwtToken(session models.Sessions, maxAge time.Duration, jwtSecret string) (string, error) {
@@ -14,6 +15,7 @@ func CreateJwtToken(session models.Sessions, maxAge time.Duration, jwtSecret str
 	customClaim := models.SessionClaims{
 		Session: session,
 		StandardClaims: jwt.StandardClaims{
+			Id:        uuid.NewString(),
 			IssuedAt:  issuedTime,
 			ExpiresAt: expiresTime,
 		},
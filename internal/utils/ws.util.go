@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/brain-flowing-company/pprp-backend/apperror"
+	"github.com/brain-flowing-company/pprp-backend/internal/enums"
 	"github.com/brain-flowing-company/pprp-backend/internal/models"
 	"github.com/gofiber/contrib/websocket"
 )
@@ -38,6 +39,16 @@ func WebsocketError(conn *websocket.Conn, err interface{}) error {
 	return conn.WriteJSON(r)
 }
 
+// WebsocketErrorFrame is the v2 error frame. Tag is the tag of the inbound
+// frame that caused the error, if any.
+func WebsocketErrorFrame(tag string, err interface{}) *models.OutBoundMessages {
+	return &models.OutBoundMessages{
+		Event:   enums.OUTBOUND_ERROR,
+		Tag:     tag,
+		Payload: parseError(err),
+	}
+}
+
 func WebsocketFatal(conn *websocket.Conn, err interface{}) error {
 	r := struct{ Error models.ErrorResponses }{
 		Error: parseError(err),
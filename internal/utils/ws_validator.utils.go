@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// NewWebsocketValidator validates websocket payloads. Fields are reported by
+// their json names since that is what clients send.
+func NewWebsocketValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+
+		return name
+	})
+
+	return v
+}
+
+// DescribeValidationError turns the first failed rule into a message such as
+// "content failed on max=4096".
+func DescribeValidationError(err error) string {
+	verrs, ok := err.(validator.ValidationErrors)
+	if !ok || len(verrs) == 0 {
+		return err.Error()
+	}
+
+	rule := verrs[0].Tag()
+	if param := verrs[0].Param(); param != "" {
+		rule = fmt.Sprintf("%v=%v", rule, param)
+	}
+
+	return fmt.Sprintf("%v failed on %v", verrs[0].Field(), rule)
+}
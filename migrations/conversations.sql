@@ -0,0 +1,79 @@
+-- Moves the one-to-one messages keyed by sender_id/receiver_id into
+-- conversations. Run it once against a database created before conversations,
+-- before `make migrate`; atlas can not add the NOT NULL conversation_id to a
+-- table that already has messages. Every pair of users gets one conversation
+-- without a property, and the read_at of each message becomes the read state
+-- of its receiver in message_receipts.
+
+BEGIN;
+
+-- users and properties are views by now, the tables are defined as in
+-- schema.sql but reference the renamed tables underneath
+
+CREATE TABLE conversations (
+    conversation_id UUID PRIMARY KEY DEFAULT gen_random_uuid()                 NOT NULL,
+    property_id     UUID REFERENCES _properties (property_id) ON DELETE SET NULL DEFAULT NULL,
+    is_group        BOOLEAN                                                    DEFAULT FALSE NOT NULL,
+    name            VARCHAR(100)                                               DEFAULT NULL,
+    created_by      UUID REFERENCES _users (user_id) ON DELETE SET NULL        DEFAULT NULL,
+    direct_key      VARCHAR(73)                                                DEFAULT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE                                   DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE conversation_participants (
+    conversation_id UUID REFERENCES conversations (conversation_id) ON DELETE CASCADE NOT NULL,
+    user_id         UUID REFERENCES _users        (user_id)         ON DELETE CASCADE NOT NULL,
+    joined_at       TIMESTAMP WITH TIME ZONE                                          DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (conversation_id, user_id)
+);
+
+CREATE TABLE message_receipts (
+    message_id   UUID REFERENCES messages (message_id) ON DELETE CASCADE NOT NULL,
+    user_id      UUID REFERENCES _users   (user_id)    ON DELETE CASCADE NOT NULL,
+    delivered_at TIMESTAMP WITH TIME ZONE                                DEFAULT NULL,
+    read_at      TIMESTAMP WITH TIME ZONE                                DEFAULT NULL,
+    notified_at  TIMESTAMP WITH TIME ZONE                                DEFAULT NULL,
+    PRIMARY KEY (message_id, user_id)
+);
+
+-- the direct key is the sorted pair of user ids, compared byte by byte like
+-- directKey in internal/core/chats does
+CREATE TEMPORARY TABLE direct_pairs ON COMMIT DROP AS
+SELECT
+    LEAST(sender_id::TEXT COLLATE "C", receiver_id::TEXT COLLATE "C") || ':' ||
+        GREATEST(sender_id::TEXT COLLATE "C", receiver_id::TEXT COLLATE "C") AS direct_key,
+    (ARRAY_AGG(sender_id ORDER BY sent_at ASC))[1] AS created_by,
+    MIN(sent_at) AS created_at,
+    gen_random_uuid() AS conversation_id
+FROM messages
+GROUP BY 1;
+
+INSERT INTO conversations (conversation_id, created_by, direct_key, created_at)
+SELECT conversation_id, created_by, direct_key, created_at
+FROM direct_pairs;
+
+INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
+SELECT conversation_id, split_part(direct_key, ':', 1)::UUID, created_at FROM direct_pairs
+UNION
+SELECT conversation_id, split_part(direct_key, ':', 2)::UUID, created_at FROM direct_pairs;
+
+ALTER TABLE messages ADD COLUMN conversation_id UUID REFERENCES conversations (conversation_id) ON DELETE CASCADE;
+
+UPDATE messages SET conversation_id = direct_pairs.conversation_id
+FROM direct_pairs
+WHERE direct_pairs.direct_key =
+    LEAST(sender_id::TEXT COLLATE "C", receiver_id::TEXT COLLATE "C") || ':' ||
+    GREATEST(sender_id::TEXT COLLATE "C", receiver_id::TEXT COLLATE "C");
+
+ALTER TABLE messages ALTER COLUMN conversation_id SET NOT NULL;
+
+-- a read message was delivered no later than it was read. Unread messages are
+-- marked as notified so that the first unread digest does not go through the
+-- whole history
+INSERT INTO message_receipts (message_id, user_id, delivered_at, read_at, notified_at)
+SELECT message_id, receiver_id, read_at, read_at, CASE WHEN read_at IS NULL THEN CURRENT_TIMESTAMP END
+FROM messages;
+
+ALTER TABLE messages DROP COLUMN receiver_id, DROP COLUMN read_at;
+
+COMMIT;
@@ -0,0 +1,36 @@
+-- Clears out the duplicate payments that the unique indexes on payments do not
+-- allow. Run it once against a database created before checkout became
+-- idempotent, before `make migrate`.
+
+BEGIN;
+
+-- only the newest pending payment of a deposit, balance or installment keeps
+-- its checkout open
+UPDATE payments SET status = 'EXPIRED', updated_at = CURRENT_TIMESTAMP
+WHERE status = 'PENDING' AND payment_id NOT IN (
+    SELECT DISTINCT ON (agreement_id, payment_type, COALESCE(installment, 0)) payment_id
+    FROM payments
+    WHERE status = 'PENDING'
+    ORDER BY agreement_id, payment_type, COALESCE(installment, 0), created_at DESC
+);
+
+-- installments paid more than once have to have the extra payments refunded
+-- through the payment provider before the index can be built
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(agreement_id || ' installment ' || installment, ', ') INTO duplicates
+    FROM (
+        SELECT agreement_id, installment FROM payments
+        WHERE installment IS NOT NULL AND status IN ('SUCCEEDED', 'PARTIALLY_REFUNDED')
+        GROUP BY agreement_id, installment
+        HAVING COUNT(*) > 1
+    ) paid_twice;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'installments paid more than once: %', duplicates;
+    END IF;
+END $$;
+
+COMMIT;
@@ -0,0 +1,59 @@
+-- Moves every amount of money from DOUBLE PRECISION baht to BIGINT satang and
+-- gives payments a currency. Run it once against a database created before
+-- the change, before `make migrate`; atlas would otherwise convert the columns
+-- without scaling them. Amounts go through NUMERIC so that ROUND takes half a
+-- satang away from zero, like the application does.
+
+BEGIN;
+
+-- the views select every column of their table, so they have to go while the
+-- column types change
+DROP VIEW agreements;
+DROP VIEW selling_properties;
+DROP VIEW renting_properties;
+
+ALTER TABLE _selling_properties ALTER COLUMN price TYPE BIGINT USING ROUND(price::NUMERIC * 100);
+ALTER TABLE _renting_properties ALTER COLUMN price_per_month TYPE BIGINT USING ROUND(price_per_month::NUMERIC * 100);
+
+ALTER TABLE _agreements
+    ALTER COLUMN deposit_amount    TYPE BIGINT USING ROUND(deposit_amount::NUMERIC * 100),
+    ALTER COLUMN payment_per_month TYPE BIGINT USING ROUND(payment_per_month::NUMERIC * 100),
+    ALTER COLUMN total_payment     TYPE BIGINT USING ROUND(total_payment::NUMERIC * 100);
+
+ALTER TABLE message_actions ALTER COLUMN amount TYPE BIGINT USING ROUND(amount::NUMERIC * 100);
+
+ALTER TABLE payments
+    ALTER COLUMN price           TYPE BIGINT USING ROUND(price::NUMERIC * 100),
+    ALTER COLUMN refunded_amount DROP DEFAULT,
+    ALTER COLUMN refunded_amount TYPE BIGINT USING ROUND(refunded_amount::NUMERIC * 100),
+    ALTER COLUMN refunded_amount SET DEFAULT 0,
+    ADD COLUMN currency VARCHAR(3) DEFAULT 'THB' NOT NULL;
+
+ALTER TABLE rent_installments
+    ALTER COLUMN amount   TYPE BIGINT USING ROUND(amount::NUMERIC * 100),
+    ALTER COLUMN late_fee DROP DEFAULT,
+    ALTER COLUMN late_fee TYPE BIGINT USING ROUND(late_fee::NUMERIC * 100),
+    ALTER COLUMN late_fee SET DEFAULT 0;
+
+ALTER TABLE refunds ALTER COLUMN amount TYPE BIGINT USING ROUND(amount::NUMERIC * 100);
+ALTER TABLE refund_deductions ALTER COLUMN amount TYPE BIGINT USING ROUND(amount::NUMERIC * 100);
+
+ALTER TABLE receipts
+    ALTER COLUMN amount     TYPE BIGINT USING ROUND(amount::NUMERIC * 100),
+    ALTER COLUMN vat_amount TYPE BIGINT USING ROUND(vat_amount::NUMERIC * 100);
+
+ALTER TABLE payouts ALTER COLUMN amount TYPE BIGINT USING ROUND(amount::NUMERIC * 100);
+ALTER TABLE ledger_entries ALTER COLUMN amount TYPE BIGINT USING ROUND(amount::NUMERIC * 100);
+
+CREATE VIEW selling_properties AS SELECT * FROM _selling_properties WHERE property_id IN (SELECT property_id FROM properties WHERE deleted_at IS NULL);
+CREATE VIEW renting_properties AS SELECT * FROM _renting_properties WHERE property_id IN (SELECT property_id FROM properties WHERE deleted_at IS NULL);
+CREATE VIEW agreements AS SELECT *
+    FROM _agreements
+    WHERE (
+        deleted_at IS NULL AND
+        property_id IN (SELECT property_id FROM properties WHERE deleted_at IS NULL) AND
+        dweller_user_id IN (SELECT user_id FROM _users WHERE deleted_at IS NULL) AND
+        owner_user_id IN (SELECT user_id FROM _users WHERE deleted_at IS NULL)
+    );
+
+COMMIT;
@@ -1,3 +1,6 @@
+-- trigram indexes let chat search match inside Thai text, which has no spaces
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 CREATE TYPE bank_names AS ENUM('KBANK', 'BBL', 'KTB', 'BAY', 'CIMB', 'TTB', 'SCB', 'GSB');
 
 CREATE TYPE registered_types AS ENUM('EMAIL', 'GOOGLE');
@@ -17,6 +20,28 @@ CREATE TYPE furnishing AS ENUM('UNFURNISHED', 'PARTIALLY_FURNISHED', 'FULLY_FURN
 CREATE TYPE floor_size_units AS ENUM('SQM', 'SQFT');
 
 CREATE TYPE payment_methods AS ENUM('CREDIT_CARD', 'PROMPTPAY');
+
+CREATE TYPE payment_types AS ENUM('DEPOSIT', 'INSTALLMENT', 'BALANCE');
+
+CREATE TYPE payment_status AS ENUM('PENDING', 'SUCCEEDED', 'FAILED', 'EXPIRED', 'PARTIALLY_REFUNDED', 'REFUNDED');
+
+CREATE TYPE refund_status AS ENUM('PENDING', 'SUCCEEDED', 'FAILED');
+
+CREATE TYPE ledger_accounts AS ENUM('CASH', 'OWNER_PAYABLE', 'OWNER_PAYOUTS_PENDING', 'PLATFORM_FEES');
+
+CREATE TYPE ledger_transaction_kinds AS ENUM('PAYMENT', 'REFUND', 'PAYOUT', 'PAYOUT_PAID', 'PAYOUT_FAILED');
+
+CREATE TYPE payout_status AS ENUM('PENDING', 'PAID', 'FAILED');
+
+CREATE TYPE chat_report_status AS ENUM('PENDING', 'REVIEWED', 'DISMISSED');
+
+CREATE TYPE moderation_actions AS ENUM('FLAG', 'MASK', 'BLOCK');
+
+CREATE TYPE message_action_types AS ENUM('APPOINTMENT_REQUEST', 'AGREEMENT_OFFER', 'PAYMENT_REQUEST');
+
+CREATE TYPE message_action_status AS ENUM('PENDING', 'ACCEPTED', 'REJECTED', 'PAID');
+
+-- amounts of money are BIGINT satang, see internal/money
  
 CREATE TABLE email_verification_codes
 (
@@ -121,7 +146,7 @@ CREATE TABLE property_images
 CREATE TABLE selling_properties
 (
     property_id UUID REFERENCES properties (property_id) ON DELETE CASCADE          NOT NULL,
-    price       DOUBLE PRECISION                                                        NOT NULL,
+    price       BIGINT                                                                  NOT NULL,
     is_sold     BOOLEAN                                                                 NOT NULL,
     created_at               TIMESTAMP(0) WITH TIME ZONE                                DEFAULT CURRENT_TIMESTAMP,
     updated_at               TIMESTAMP(0) WITH TIME ZONE                                DEFAULT CURRENT_TIMESTAMP,
@@ -131,7 +156,7 @@ CREATE TABLE selling_properties
 CREATE TABLE renting_properties
 (
     property_id     UUID REFERENCES properties (property_id) ON DELETE CASCADE          NOT NULL,
-    price_per_month DOUBLE PRECISION                                                        NOT NULL,
+    price_per_month BIGINT                                                                  NOT NULL,
     is_occupied     BOOLEAN                                                                 NOT NULL,
     created_at               TIMESTAMP(0) WITH TIME ZONE                                    DEFAULT CURRENT_TIMESTAMP,
     updated_at               TIMESTAMP(0) WITH TIME ZONE                                    DEFAULT CURRENT_TIMESTAMP,
@@ -170,10 +195,10 @@ CREATE TABLE agreements
     dweller_user_id     UUID REFERENCES users (user_id)                     ON DELETE CASCADE   NOT NULL,
     agreement_date      TIMESTAMP(0) WITH TIME ZONE                         NOT NULL,
     status              agreement_status DEFAULT 'AWAITING_DEPOSIT'       NOT NULL,
-    deposit_amount      DOUBLE PRECISION                                    DEFAULT NULL,
-    payment_per_month   DOUBLE PRECISION                                    DEFAULT NULL,
+    deposit_amount      BIGINT                                              DEFAULT NULL,
+    payment_per_month   BIGINT                                              DEFAULT NULL,
     payment_duration    INTEGER                                             DEFAULT NULL,
-    total_payment       DOUBLE PRECISION                                    DEFAULT NULL,
+    total_payment       BIGINT                                              DEFAULT NULL,
     cancelled_message   TEXT                                                DEFAULT NULL,
     created_at          TIMESTAMP(0) WITH TIME ZONE                         DEFAULT CURRENT_TIMESTAMP,
     updated_at          TIMESTAMP(0) WITH TIME ZONE                         DEFAULT CURRENT_TIMESTAMP,
@@ -181,13 +206,44 @@ CREATE TABLE agreements
     UNIQUE (property_id, agreement_date)
 );
 
+CREATE TABLE conversations (
+    conversation_id UUID PRIMARY KEY DEFAULT gen_random_uuid()                 NOT NULL,
+    property_id     UUID REFERENCES properties (property_id) ON DELETE SET NULL DEFAULT NULL,
+    is_group        BOOLEAN                                                    DEFAULT FALSE NOT NULL,
+    name            VARCHAR(100)                                               DEFAULT NULL,
+    created_by      UUID REFERENCES users (user_id) ON DELETE SET NULL         DEFAULT NULL,
+    -- sorted pair of participant ids, used to find an existing 1:1 conversation.
+    -- NULL for group conversations
+    direct_key      VARCHAR(73)                                                DEFAULT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE                                   DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE conversation_participants (
+    conversation_id UUID REFERENCES conversations (conversation_id) ON DELETE CASCADE NOT NULL,
+    user_id         UUID REFERENCES users         (user_id)         ON DELETE CASCADE NOT NULL,
+    joined_at       TIMESTAMP WITH TIME ZONE                                          DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (conversation_id, user_id)
+);
+
 CREATE TABLE messages (
-    message_id  UUID PRIMARY KEY         NOT NULL,
+    message_id      UUID PRIMARY KEY         NOT NULL,
+    conversation_id UUID                     NOT NULL REFERENCES conversations(conversation_id) ON DELETE CASCADE,
     sender_id   UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
-    receiver_id UUID                     NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
     content     VARCHAR(4096)            NOT NULL,
-    read_at     TIMESTAMP WITH TIME ZONE DEFAULT NULL,
-    sent_at     TIMESTAMP WITH TIME ZONE NOT NULL
+    sent_at     TIMESTAMP WITH TIME ZONE NOT NULL,
+    edited_at   TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    deleted_at  TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+-- one row for every recipient of a message, i.e. every participant other than
+-- the sender at the time it was sent
+CREATE TABLE message_receipts (
+    message_id   UUID REFERENCES messages (message_id) ON DELETE CASCADE NOT NULL,
+    user_id      UUID REFERENCES users    (user_id)    ON DELETE CASCADE NOT NULL,
+    delivered_at TIMESTAMP WITH TIME ZONE                                DEFAULT NULL,
+    read_at      TIMESTAMP WITH TIME ZONE                                DEFAULT NULL,
+    notified_at  TIMESTAMP WITH TIME ZONE                                DEFAULT NULL,
+    PRIMARY KEY (message_id, user_id)
 );
 
 CREATE TABLE message_attatchments (
@@ -197,19 +253,233 @@ CREATE TABLE message_attatchments (
     agreement_id   UUID REFERENCES agreements   (agreement_id)   DEFAULT NULL
 );
 
+-- interactive cards; the appointment or agreement they act on is in
+-- message_attatchments
+CREATE TABLE message_actions (
+    message_id       UUID REFERENCES messages (message_id) ON DELETE CASCADE PRIMARY KEY NOT NULL,
+    action_type      message_action_types                                            NOT NULL,
+    status           message_action_status DEFAULT 'PENDING'                         NOT NULL,
+    actor_id         UUID REFERENCES users    (user_id)    ON DELETE CASCADE         NOT NULL,
+    appointment_date TIMESTAMP WITH TIME ZONE                                        DEFAULT NULL,
+    amount           BIGINT                                                          DEFAULT NULL,
+    installment      INT                                                             DEFAULT NULL,
+    note             TEXT                                                            DEFAULT NULL,
+    updated_at       TIMESTAMP WITH TIME ZONE                                        DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE message_files (
+    file_id       UUID PRIMARY KEY                                        NOT NULL,
+    message_id    UUID REFERENCES messages (message_id) ON DELETE CASCADE NOT NULL,
+    file_name     VARCHAR(255)                                            NOT NULL,
+    content_type  VARCHAR(100)                                            NOT NULL,
+    size          BIGINT                                                  NOT NULL,
+    file_key      VARCHAR(2000)                                           NOT NULL,
+    thumbnail_key VARCHAR(2000)                                           DEFAULT NULL,
+    created_at    TIMESTAMP WITH TIME ZONE                                DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE message_reactions (
+    message_id UUID REFERENCES messages (message_id) ON DELETE CASCADE NOT NULL,
+    user_id    UUID REFERENCES users    (user_id)    ON DELETE CASCADE NOT NULL,
+    emoji      VARCHAR(16)                                             NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE                                DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id, emoji)
+);
+
+CREATE TABLE user_blocks (
+    blocker_id UUID REFERENCES users (user_id) ON DELETE CASCADE NOT NULL,
+    blocked_id UUID REFERENCES users (user_id) ON DELETE CASCADE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE                          DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+
+CREATE TABLE chat_mutes (
+    user_id         UUID REFERENCES users         (user_id)         ON DELETE CASCADE NOT NULL,
+    conversation_id UUID REFERENCES conversations (conversation_id) ON DELETE CASCADE NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE                                          DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, conversation_id)
+);
+
+CREATE TABLE chat_reports (
+    report_id        UUID PRIMARY KEY                                  NOT NULL,
+    reporter_id      UUID REFERENCES users (user_id) ON DELETE CASCADE NOT NULL,
+    reported_user_id UUID REFERENCES users (user_id) ON DELETE CASCADE NOT NULL,
+    conversation_id  UUID REFERENCES conversations (conversation_id) ON DELETE SET NULL DEFAULT NULL,
+    reason           TEXT                                              NOT NULL,
+    status           chat_report_status DEFAULT 'PENDING'              NOT NULL,
+    created_at       TIMESTAMP WITH TIME ZONE                          DEFAULT CURRENT_TIMESTAMP
+);
+
+-- copies of the reported conversation so that later edits or deletions
+-- cannot tamper with what moderators review
+CREATE TABLE chat_report_messages (
+    report_id   UUID REFERENCES chat_reports (report_id) ON DELETE CASCADE NOT NULL,
+    message_id  UUID                                                       NOT NULL,
+    sender_id   UUID                                                       NOT NULL,
+    content     VARCHAR(4096)                                              NOT NULL,
+    sent_at     TIMESTAMP WITH TIME ZONE                                   NOT NULL,
+    edited_at   TIMESTAMP WITH TIME ZONE                                   DEFAULT NULL,
+    deleted_at  TIMESTAMP WITH TIME ZONE                                   DEFAULT NULL,
+    PRIMARY KEY (report_id, message_id)
+);
+
+-- every moderation rule hit on an outgoing message. message_id is not a
+-- foreign key since blocked messages are never saved
+CREATE TABLE message_moderations (
+    moderation_id   UUID PRIMARY KEY                                                  DEFAULT gen_random_uuid(),
+    message_id      UUID                                                              NOT NULL,
+    conversation_id UUID REFERENCES conversations (conversation_id) ON DELETE CASCADE NOT NULL,
+    sender_id       UUID REFERENCES users         (user_id)         ON DELETE CASCADE NOT NULL,
+    rule            VARCHAR(50)                                                       NOT NULL,
+    action          moderation_actions                                                NOT NULL,
+    content         VARCHAR(4096)                                                     NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE                                          DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE push_subscriptions (
+    subscription_id UUID PRIMARY KEY                                  DEFAULT gen_random_uuid(),
+    user_id         UUID REFERENCES users (user_id) ON DELETE CASCADE NOT NULL,
+    endpoint        TEXT UNIQUE                                       NOT NULL,
+    p256dh          VARCHAR(255)                                      NOT NULL,
+    auth            VARCHAR(255)                                      NOT NULL,
+    user_agent      VARCHAR(255)                                      DEFAULT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE                          DEFAULT CURRENT_TIMESTAMP
+);
+
+-- sessions are stateless jwts so logging out records the jti here. rows can
+-- be dropped once expires_at has passed
+CREATE TABLE revoked_sessions (
+    session_id UUID PRIMARY KEY                                  NOT NULL,
+    user_id    UUID REFERENCES users (user_id) ON DELETE CASCADE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE                          NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE                          DEFAULT CURRENT_TIMESTAMP
+);
+
+-- users without a row get the defaults below
+CREATE TABLE notification_preferences (
+    user_id              UUID PRIMARY KEY REFERENCES users (user_id) ON DELETE CASCADE NOT NULL,
+    web_push             BOOLEAN                  DEFAULT TRUE                          NOT NULL,
+    email_digest         BOOLEAN                  DEFAULT TRUE                          NOT NULL,
+    digest_delay_minutes INTEGER                  DEFAULT 15                            NOT NULL,
+    quiet_hours_start    VARCHAR(5)               DEFAULT NULL,
+    quiet_hours_end      VARCHAR(5)               DEFAULT NULL,
+    timezone             VARCHAR(64)              DEFAULT 'Asia/Bangkok'                NOT NULL,
+    updated_at           TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
 CREATE TABLE payments(
     payment_id UUID PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
     user_id    UUID REFERENCES users(user_id)              NOT NULL, 
     agreement_id UUID REFERENCES agreements(agreement_id) NOT NULL,
     payment_method  payment_methods                     NOT NULL,
-    price     DOUBLE PRECISION                           NOT NULL,
+    price     BIGINT                                     NOT NULL,
     IsSuccess BOOLEAN                                    NOT NULL, 
     Name       VARCHAR(50)                               NOT NULL,
+    payment_type payment_types                            DEFAULT 'INSTALLMENT' NOT NULL,
+    installment  INTEGER                                  DEFAULT NULL,
+    status     payment_status                             DEFAULT 'PENDING' NOT NULL,
+    refunded_amount BIGINT                                DEFAULT 0         NOT NULL,
+    currency   VARCHAR(3)                                 DEFAULT 'THB'     NOT NULL,
+    provider_session_id VARCHAR(255) UNIQUE               DEFAULT NULL,
+    provider_payment_id VARCHAR(255) UNIQUE               DEFAULT NULL,
+    checkout_url        VARCHAR(2000)                     DEFAULT NULL,
+    checkout_expires_at TIMESTAMP WITH TIME ZONE          DEFAULT NULL,
+    idempotency_key     VARCHAR(255)                      DEFAULT NULL,
     created_at TIMESTAMP(0) WITH TIME ZONE                DEFAULT CURRENT_TIMESTAMP, 
     updated_at TIMESTAMP(0) WITH TIME ZONE                DEFAULT CURRENT_TIMESTAMP, 
     deleted_at TIMESTAMP(0) WITH TIME ZONE                DEFAULT NULL
 );
 
+-- one row per monthly installment of a renting agreement, generated once the
+-- agreement starts renting. An installment is paid when one of its payments
+-- has succeeded. reminder_level is the last reminder sent while it is late.
+CREATE TABLE rent_installments (
+    agreement_id UUID REFERENCES agreements (agreement_id) ON DELETE CASCADE NOT NULL,
+    installment  INTEGER                                                     NOT NULL,
+    due_date     DATE                                                        NOT NULL,
+    amount       BIGINT                                                      NOT NULL,
+    invoiced_at  TIMESTAMP WITH TIME ZONE                                    DEFAULT NULL,
+    late_fee     BIGINT                                                      DEFAULT 0 NOT NULL,
+    reminder_level INTEGER                                                   DEFAULT 0 NOT NULL,
+    reminded_at  TIMESTAMP WITH TIME ZONE                                    DEFAULT NULL,
+    created_at   TIMESTAMP WITH TIME ZONE                                    DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (agreement_id, installment)
+);
+
+-- deposits paid back by owners once their agreement is over, less deductions
+CREATE TABLE refunds (
+    refund_id          UUID PRIMARY KEY DEFAULT gen_random_uuid()            NOT NULL,
+    payment_id         UUID REFERENCES payments (payment_id) ON DELETE CASCADE NOT NULL,
+    agreement_id       UUID REFERENCES agreements (agreement_id) ON DELETE CASCADE NOT NULL,
+    requested_by       UUID REFERENCES users (user_id)                       NOT NULL,
+    amount             BIGINT                                                NOT NULL,
+    reason             TEXT                                                  NOT NULL,
+    status             refund_status            DEFAULT 'PENDING'            NOT NULL,
+    provider_refund_id VARCHAR(255)             DEFAULT NULL,
+    created_at         TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at         TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE refund_deductions (
+    refund_id   UUID REFERENCES refunds (refund_id) ON DELETE CASCADE NOT NULL,
+    description VARCHAR(200)                                          NOT NULL,
+    amount      BIGINT                                                NOT NULL
+);
+
+-- the numbered receipt and tax invoice of a paid payment, stored as a PDF
+CREATE TABLE receipts (
+    payment_id     UUID PRIMARY KEY REFERENCES payments (payment_id)   NOT NULL,
+    receipt_number BIGINT GENERATED ALWAYS AS IDENTITY UNIQUE          NOT NULL,
+    amount         BIGINT                                              NOT NULL,
+    vat_percent    DOUBLE PRECISION                                    NOT NULL,
+    vat_amount     BIGINT                                              NOT NULL,
+    file_key       VARCHAR(255)             DEFAULT NULL,
+    emailed_at     TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    issued_at      TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- money sent to an owner's bank account, a batch of everything they were owed
+CREATE TABLE payouts (
+    payout_id           UUID PRIMARY KEY DEFAULT gen_random_uuid()       NOT NULL,
+    user_id             UUID REFERENCES users (user_id)                  NOT NULL,
+    amount              BIGINT                                           NOT NULL,
+    bank_name           bank_names                                       NOT NULL,
+    bank_account_number VARCHAR(20)                                      NOT NULL,
+    status              payout_status            DEFAULT 'PENDING'       NOT NULL,
+    reference           VARCHAR(255)             DEFAULT NULL,
+    failure_reason      TEXT                     DEFAULT NULL,
+    paid_at             TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- double-entry ledger: the entries of every transaction add up to zero
+CREATE TABLE ledger_transactions (
+    transaction_id UUID PRIMARY KEY DEFAULT gen_random_uuid()    NOT NULL,
+    kind           ledger_transaction_kinds                      NOT NULL,
+    reference      VARCHAR(255) UNIQUE                           NOT NULL,
+    description    TEXT                                          NOT NULL,
+    payment_id     UUID REFERENCES payments (payment_id)         DEFAULT NULL,
+    payout_id      UUID REFERENCES payouts (payout_id)           DEFAULT NULL,
+    created_at     TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- amount is positive into the account and negative out of it
+CREATE TABLE ledger_entries (
+    transaction_id UUID REFERENCES ledger_transactions (transaction_id) ON DELETE CASCADE NOT NULL,
+    account        ledger_accounts                                                       NOT NULL,
+    user_id        UUID REFERENCES users (user_id)                                       DEFAULT NULL,
+    amount         BIGINT                                                                NOT NULL
+);
+
+-- every payment provider webhook event that has been handled, so redeliveries
+-- are skipped
+CREATE TABLE webhook_events (
+    event_id     VARCHAR(255) PRIMARY KEY NOT NULL,
+    event_type   VARCHAR(64)              NOT NULL,
+    processed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
 CREATE TABLE reviews(
     review_id UUID PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
     property_id UUID REFERENCES properties(property_id) NOT NULL, 
@@ -336,44 +606,61 @@ INSERT INTO property_images (property_id, image_url) VALUES
 ('e3f29fb7-f830-43de-91ab-c67fd0c170a3', 'https://suechaokhai.s3.ap-southeast-1.amazonaws.com/properties/e3f29fb7-f830-43de-91ab-c67fd0c170a3-2.jpeg');
 
 INSERT INTO selling_properties (property_id, price, is_sold) VALUES
-('0bd03187-91ac-457d-957c-3ba2f6c0d24b', 2588830.71, FALSE),
-('21b492b6-8d4f-45a6-af25-2fa9c1eb2042', 2588840.72, FALSE),
-('2dd819db-6b5f-4c29-b173-0f0bf04769fb', 2588850.73, FALSE),
-('4ed284f5-1c61-4605-ae8e-44edc9ce0e91', 2588860.74, FALSE),
-('7faf0793-3937-47f3-aa97-76ed81134c70', 2588870.75, FALSE),
-('8c32a8b1-c096-4f28-abd7-771ec5b02b1e', 2588880.76, FALSE),
-('b1f3bbfd-e5da-4fe1-9add-eac66357d790', 2588890.77, FALSE),
-('b68f14db-fac6-4b5c-8bb3-68a2ce7efbe9', 2588900.78, FALSE),
-('e3f29fb7-f830-43de-91ab-c67fd0c170a3', 2588910.79, FALSE);
+('0bd03187-91ac-457d-957c-3ba2f6c0d24b', 258883071, FALSE),
+('21b492b6-8d4f-45a6-af25-2fa9c1eb2042', 258884072, FALSE),
+('2dd819db-6b5f-4c29-b173-0f0bf04769fb', 258885073, FALSE),
+('4ed284f5-1c61-4605-ae8e-44edc9ce0e91', 258886074, FALSE),
+('7faf0793-3937-47f3-aa97-76ed81134c70', 258887075, FALSE),
+('8c32a8b1-c096-4f28-abd7-771ec5b02b1e', 258888076, FALSE),
+('b1f3bbfd-e5da-4fe1-9add-eac66357d790', 258889077, FALSE),
+('b68f14db-fac6-4b5c-8bb3-68a2ce7efbe9', 258890078, FALSE),
+('e3f29fb7-f830-43de-91ab-c67fd0c170a3', 258891079, FALSE);
 
 INSERT INTO renting_properties (property_id, price_per_month, is_occupied) VALUES
-('0bd03187-91ac-457d-957c-3ba2f6c0d24b', 15500.50, FALSE),
-('21b492b6-8d4f-45a6-af25-2fa9c1eb2042', 15500.51, FALSE),
-('2dd819db-6b5f-4c29-b173-0f0bf04769fb', 15500.52, FALSE),
-('4ed284f5-1c61-4605-ae8e-44edc9ce0e91', 15500.53, FALSE),
-('7faf0793-3937-47f3-aa97-76ed81134c70', 15500.54, FALSE),
-('8c32a8b1-c096-4f28-abd7-771ec5b02b1e', 15500.55, FALSE),
-('b1f3bbfd-e5da-4fe1-9add-eac66357d790', 15500.56, FALSE),
-('b68f14db-fac6-4b5c-8bb3-68a2ce7efbe9', 15500.57, FALSE),
-('e3f29fb7-f830-43de-91ab-c67fd0c170a3', 15500.58, FALSE);
+('0bd03187-91ac-457d-957c-3ba2f6c0d24b', 1550050, FALSE),
+('21b492b6-8d4f-45a6-af25-2fa9c1eb2042', 1550051, FALSE),
+('2dd819db-6b5f-4c29-b173-0f0bf04769fb', 1550052, FALSE),
+('4ed284f5-1c61-4605-ae8e-44edc9ce0e91', 1550053, FALSE),
+('7faf0793-3937-47f3-aa97-76ed81134c70', 1550054, FALSE),
+('8c32a8b1-c096-4f28-abd7-771ec5b02b1e', 1550055, FALSE),
+('b1f3bbfd-e5da-4fe1-9add-eac66357d790', 1550056, FALSE),
+('b68f14db-fac6-4b5c-8bb3-68a2ce7efbe9', 1550057, FALSE),
+('e3f29fb7-f830-43de-91ab-c67fd0c170a3', 1550058, FALSE);
 
 INSERT INTO appointments (appointment_id, property_id, owner_user_id, dweller_user_id, status, appointment_date, note) VALUES
 ('1b024950-f27d-4edf-b62a-9ac0dce43964', '0bd03187-91ac-457d-957c-3ba2f6c0d24b', 'f38f80b3-f326-4825-9afc-ebc331626555', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', 'PENDING', '2024-02-21 15:50:00.000+07', NULL),
 ('a4d27fe4-c575-405b-8401-ce5137dc6f4a', '21b492b6-8d4f-45a6-af25-2fa9c1eb2042', 'f38f80b3-f326-4825-9afc-ebc331626555', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', 'PENDING', '2024-02-21 15:51:00.000+07', 'Good morning');
 
 INSERT INTO agreements (agreement_id, agreement_type, property_id, owner_user_id, dweller_user_id, agreement_date, status, deposit_amount, payment_per_month, payment_duration, total_payment, cancelled_message) VALUES
-('481095e8-f136-4e5b-b7fd-179e5fa40a28', 'RENTING', '0bd03187-91ac-457d-957c-3ba2f6c0d24b', 'f38f80b3-f326-4825-9afc-ebc331626555', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', '2024-02-21 15:50:00.000+07', 'AWAITING_DEPOSIT', 10000.00, 1000.00, 10000.00, 10, NULL),
-('12424193-cb57-47f5-9f83-2ff2210450c3', 'SELLING', '21b492b6-8d4f-45a6-af25-2fa9c1eb2042', 'f38f80b3-f326-4825-9afc-ebc331626555', '62dd40da-f326-4825-9afc-2d68e06e0282', '2024-02-22 15:51:00.000+07', 'AWAITING_DEPOSIT', 10000.00, 1000.00, 10000.00, 10, NULL),
-('c7314051-2e35-4f79-ba7a-e54ceabdec2c', 'RENTING', '21b492b6-8d4f-45a6-af25-2fa9c1eb2042', 'f38f80b3-f326-4825-9afc-ebc331626555', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', '2024-02-23 15:52:00.000+07', 'CANCELLED', 10000.00, 1000.00, 10000.00, 10, 'nope');
-
-INSERT INTO messages (message_id, sender_id, receiver_id, content, read_at, sent_at) VALUES
-('541dfc60-2f5b-473a-ac09-76a2aa3e5276', 'f38f80b3-f326-4825-9afc-ebc331626555', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', 'Good morning' , NULL, '2024-02-25 19:04:18.818+07'),
-('e74361f2-00de-40d8-b3fc-dc1f85547700', 'f38f80b3-f326-4825-9afc-ebc331626555', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', 'Hello mate' , NULL, '2024-02-25 19:04:27.436+07'),
-('3f25b89f-b183-4ba8-b7b5-98d5f5fd374a', 'f38f80b3-f326-4825-9afc-ebc331626555', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', 'what are you up to?' , NULL, '2024-02-25 19:04:36.119+07'),
-('f48c2f66-3450-41f1-8307-db6386187472', '62dd40da-f326-4825-9afc-2d68e06e0282', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', 'Hi' , NULL, '2024-02-25 19:05:10.519+07'),
-('8d7a913b-0bd4-4554-8286-bc8ad2b8817e', '62dd40da-f326-4825-9afc-2d68e06e0282', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', '?' , NULL, '2024-02-25 19:05:12.953+07'),
-('5d7ad256-0e0b-45e5-a985-7c0a4e439047', 'f38f80b3-f326-4825-9afc-ebc331626555', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', 'hi', NULL, '2024-04-02 13:23:26.943+07'),
-('ae45bf81-8214-46ec-9032-fa683d6b90a5', 'f38f80b3-f326-4825-9afc-ebc331626555', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', 'just hi', NULL, '2024-04-02 13:23:28.689+07');
+('481095e8-f136-4e5b-b7fd-179e5fa40a28', 'RENTING', '0bd03187-91ac-457d-957c-3ba2f6c0d24b', 'f38f80b3-f326-4825-9afc-ebc331626555', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', '2024-02-21 15:50:00.000+07', 'AWAITING_DEPOSIT', 1000000, 100000, 10000, 1000, NULL),
+('12424193-cb57-47f5-9f83-2ff2210450c3', 'SELLING', '21b492b6-8d4f-45a6-af25-2fa9c1eb2042', 'f38f80b3-f326-4825-9afc-ebc331626555', '62dd40da-f326-4825-9afc-2d68e06e0282', '2024-02-22 15:51:00.000+07', 'AWAITING_DEPOSIT', 1000000, 100000, 10000, 1000, NULL),
+('c7314051-2e35-4f79-ba7a-e54ceabdec2c', 'RENTING', '21b492b6-8d4f-45a6-af25-2fa9c1eb2042', 'f38f80b3-f326-4825-9afc-ebc331626555', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555', '2024-02-23 15:52:00.000+07', 'CANCELLED', 1000000, 100000, 10000, 1000, 'nope');
+
+INSERT INTO conversations (conversation_id, property_id, created_by, direct_key) VALUES
+('0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c01', NULL, 'f38f80b3-f326-4825-9afc-ebc331626555', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555:f38f80b3-f326-4825-9afc-ebc331626555'),
+('0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c02', NULL, '62dd40da-f326-4825-9afc-2d68e06e0282', '62dd40da-f326-4825-9afc-2d68e06e0282:bc5891ce-d6f2-d6f2-d6f2-ebc331626555');
+
+INSERT INTO conversation_participants (conversation_id, user_id) VALUES
+('0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c01', 'f38f80b3-f326-4825-9afc-ebc331626555'),
+('0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c01', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555'),
+('0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c02', '62dd40da-f326-4825-9afc-2d68e06e0282'),
+('0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c02', 'bc5891ce-d6f2-d6f2-d6f2-ebc331626555');
+
+INSERT INTO messages (message_id, conversation_id, sender_id, content, sent_at) VALUES
+('541dfc60-2f5b-473a-ac09-76a2aa3e5276', '0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c01', 'f38f80b3-f326-4825-9afc-ebc331626555', 'Good morning' , '2024-02-25 19:04:18.818+07'),
+('e74361f2-00de-40d8-b3fc-dc1f85547700', '0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c01', 'f38f80b3-f326-4825-9afc-ebc331626555', 'Hello mate' , '2024-02-25 19:04:27.436+07'),
+('3f25b89f-b183-4ba8-b7b5-98d5f5fd374a', '0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c01', 'f38f80b3-f326-4825-9afc-ebc331626555', 'what are you up to?' , '2024-02-25 19:04:36.119+07'),
+('f48c2f66-3450-41f1-8307-db6386187472', '0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c02', '62dd40da-f326-4825-9afc-2d68e06e0282', 'Hi' , '2024-02-25 19:05:10.519+07'),
+('8d7a913b-0bd4-4554-8286-bc8ad2b8817e', '0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c02', '62dd40da-f326-4825-9afc-2d68e06e0282', '?' , '2024-02-25 19:05:12.953+07'),
+('5d7ad256-0e0b-45e5-a985-7c0a4e439047', '0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c01', 'f38f80b3-f326-4825-9afc-ebc331626555', 'hi', '2024-04-02 13:23:26.943+07'),
+('ae45bf81-8214-46ec-9032-fa683d6b90a5', '0c1c4b1e-3a53-4c2b-9d0e-6f7f2a6b1c01', 'f38f80b3-f326-4825-9afc-ebc331626555', 'just hi', '2024-04-02 13:23:28.689+07');
+
+INSERT INTO message_receipts (message_id, user_id)
+SELECT messages.message_id, conversation_participants.user_id
+FROM messages
+JOIN conversation_participants
+ON conversation_participants.conversation_id = messages.conversation_id
+    AND conversation_participants.user_id <> messages.sender_id;
 
 INSERT INTO message_attatchments (message_id, property_id, appointment_id, agreement_id) VALUES
 ('541dfc60-2f5b-473a-ac09-76a2aa3e5276', '2dd819db-6b5f-4c29-b173-0f0bf04769fb', NULL, NULL),
@@ -439,4 +726,26 @@ CREATE INDEX idx_properties_deleted_at                  ON _properties (deleted_
 CREATE INDEX idx_property_images_deleted_at             ON _property_images (deleted_at);
 CREATE INDEX idx_selling_properties_deleted_at          ON _selling_properties (deleted_at);
 CREATE INDEX idx_renting_properties_deleted_at          ON _renting_properties (deleted_at);
-CREATE INDEX idx_appointments_deleted_at                ON _appointments (deleted_at);
\ No newline at end of file
+CREATE INDEX idx_appointments_deleted_at                ON _appointments (deleted_at);
+CREATE INDEX idx_message_files_message_id               ON message_files (message_id);
+CREATE INDEX idx_messages_sent_at                       ON messages (conversation_id, sent_at, message_id);
+CREATE INDEX idx_message_receipts_unread                ON message_receipts (user_id) WHERE read_at IS NULL;
+CREATE INDEX idx_conversation_participants_user_id      ON conversation_participants (user_id);
+CREATE INDEX idx_message_receipts_pending_digest      ON message_receipts (user_id) WHERE read_at IS NULL AND notified_at IS NULL;
+CREATE INDEX idx_message_moderations_sender_id           ON message_moderations (sender_id, created_at);
+CREATE INDEX idx_push_subscriptions_user_id             ON push_subscriptions (user_id);
+CREATE UNIQUE INDEX idx_conversations_direct_key        ON conversations (direct_key, COALESCE(property_id, '00000000-0000-0000-0000-000000000000')) WHERE direct_key IS NOT NULL;
+CREATE INDEX idx_messages_content_search                ON messages USING GIN (to_tsvector('simple', content));
+CREATE INDEX idx_messages_content_trigram               ON messages USING GIN (content gin_trgm_ops);
+CREATE INDEX idx_rent_installments_uninvoiced           ON rent_installments (due_date) WHERE invoiced_at IS NULL;
+CREATE UNIQUE INDEX idx_refunds_open_payment            ON refunds (payment_id) WHERE status <> 'FAILED';
+CREATE INDEX idx_refund_deductions_refund_id            ON refund_deductions (refund_id);
+CREATE INDEX idx_receipts_unsent                        ON receipts (payment_id) WHERE file_key IS NULL OR emailed_at IS NULL;
+CREATE UNIQUE INDEX idx_payouts_pending_user_id         ON payouts (user_id) WHERE status = 'PENDING';
+CREATE INDEX idx_ledger_entries_transaction_id          ON ledger_entries (transaction_id);
+CREATE INDEX idx_ledger_entries_account_user_id         ON ledger_entries (account, user_id);
+CREATE INDEX idx_payments_installment                   ON payments (agreement_id, installment) WHERE installment IS NOT NULL;
+CREATE UNIQUE INDEX idx_payments_paid_installment       ON payments (agreement_id, installment) WHERE installment IS NOT NULL AND status IN ('SUCCEEDED', 'PARTIALLY_REFUNDED');
+CREATE UNIQUE INDEX idx_payments_open_checkout          ON payments (agreement_id, payment_type, COALESCE(installment, 0)) WHERE status = 'PENDING';
+CREATE UNIQUE INDEX idx_payments_idempotency_key        ON payments (user_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
+CREATE INDEX idx_payments_pending                       ON payments (checkout_expires_at) WHERE status = 'PENDING';
\ No newline at end of file
@@ -14,6 +14,8 @@ import (
 
 type Storage interface {
 	Upload(string, io.Reader, types.ObjectCannedACL) (string, error)
+	Download(string) (io.ReadCloser, error)
+	Delete(string) error
 }
 
 type storageImpl struct {
@@ -52,3 +54,25 @@ func (s *storageImpl) Upload(filename string, file io.Reader, acl types.ObjectCa
 
 	return result.Location, nil
 }
+
+func (s *storageImpl) Download(filename string) (io.ReadCloser, error) {
+	result, err := s.client.GetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(filename),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Body, nil
+}
+
+func (s *storageImpl) Delete(filename string) error {
+	_, err := s.client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(filename),
+	})
+
+	return err
+}